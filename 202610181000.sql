@@ -0,0 +1,12 @@
+-- upload_tickets（署名付き直接アップロード）テーブル
+CREATE TABLE IF NOT EXISTS upload_tickets (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(36) NOT NULL,
+  target_type VARCHAR(20) NOT NULL,  -- floor または pin
+  target_id VARCHAR(36) NOT NULL,
+  public_id VARCHAR(255) NOT NULL UNIQUE,
+  status VARCHAR(20) NOT NULL DEFAULT 'pending',
+  expires_at TIMESTAMP NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+-- floor_georeferences（フロア地理参照）テーブル
+CREATE TABLE IF NOT EXISTS floor_georeferences (
+  floor_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  control_points TEXT NOT NULL,      -- 画像座標と緯度経度の対応点(JSON)
+  transform TEXT NOT NULL,           -- 画像座標→経度緯度のアフィン変換係数(JSON)
+  rms_error DOUBLE NOT NULL DEFAULT 0,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (floor_id) REFERENCES floors(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- pins（ピン）テーブルにカテゴリーを追加
+ALTER TABLE pins ADD COLUMN category VARCHAR(100) NOT NULL DEFAULT '' AFTER description;
@@ -0,0 +1,3 @@
+-- pins（ピン）テーブルに一括インポート用の外部キーを追加
+ALTER TABLE pins ADD COLUMN external_key VARCHAR(255) NOT NULL DEFAULT '' AFTER editor_nickname;
+CREATE INDEX idx_pins_external_key ON pins(floor_id, external_key);
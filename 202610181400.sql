@@ -0,0 +1,13 @@
+-- pdf_jobs（印刷用PDF生成ジョブ）テーブル
+CREATE TABLE IF NOT EXISTS pdf_jobs (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  user_id VARCHAR(36) NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'queued',
+  error TEXT NOT NULL,
+  file_path VARCHAR(512) NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  completed_at TIMESTAMP NULL,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,46 @@
+-- stamp_rallies（スタンプラリー）テーブル
+CREATE TABLE IF NOT EXISTS stamp_rallies (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  title VARCHAR(255) NOT NULL,
+  description TEXT,
+  completion_rule VARCHAR(20) NOT NULL DEFAULT 'all',
+  required_count INT NOT NULL DEFAULT 0,
+  is_active BOOLEAN DEFAULT TRUE,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+-- stamp_rally_checkpoints（チェックポイント）テーブル
+CREATE TABLE IF NOT EXISTS stamp_rally_checkpoints (
+  rally_id VARCHAR(36) NOT NULL,
+  pin_id VARCHAR(36) NOT NULL,
+  position INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (rally_id, pin_id),
+  FOREIGN KEY (rally_id) REFERENCES stamp_rallies(id) ON DELETE CASCADE,
+  FOREIGN KEY (pin_id) REFERENCES pins(id) ON DELETE CASCADE
+);
+
+-- rally_sessions（来場者の匿名セッション）テーブル
+CREATE TABLE IF NOT EXISTS rally_sessions (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  rally_id VARCHAR(36) NOT NULL,
+  token VARCHAR(255) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  completed_at TIMESTAMP NULL,
+  FOREIGN KEY (rally_id) REFERENCES stamp_rallies(id) ON DELETE CASCADE
+);
+
+-- rally_check_ins（チェックイン）テーブル
+CREATE TABLE IF NOT EXISTS rally_check_ins (
+  session_id VARCHAR(36) NOT NULL,
+  pin_id VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (session_id, pin_id),
+  FOREIGN KEY (session_id) REFERENCES rally_sessions(id) ON DELETE CASCADE,
+  FOREIGN KEY (pin_id) REFERENCES pins(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_stamp_rallies_map_id ON stamp_rallies(map_id);
+CREATE INDEX idx_rally_sessions_rally_id ON rally_sessions(rally_id);
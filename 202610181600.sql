@@ -0,0 +1,13 @@
+-- analytics_hourly（ビューワー利用状況の1時間集計）テーブル
+-- 生のイベントは保存せず、集計値のみを保持する
+CREATE TABLE IF NOT EXISTS analytics_hourly (
+  map_id VARCHAR(36) NOT NULL,
+  bucket_hour DATETIME NOT NULL,
+  event_type VARCHAR(20) NOT NULL,
+  dimension VARCHAR(255) NOT NULL DEFAULT '',
+  count INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (map_id, bucket_hour, event_type, dimension),
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_analytics_hourly_map_type ON analytics_hourly(map_id, event_type, bucket_hour);
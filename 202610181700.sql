@@ -0,0 +1,11 @@
+-- maps テーブルに公開状態と公開予約を追加
+ALTER TABLE maps
+  ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft' AFTER is_publicly_editable,
+  ADD COLUMN publish_at TIMESTAMP NULL AFTER status,
+  ADD COLUMN unpublish_at TIMESTAMP NULL AFTER publish_at;
+
+-- 既存のマップはこれまで通り閲覧できるよう公開中とする
+UPDATE maps SET status = 'published';
+
+CREATE INDEX idx_maps_status_publish_at ON maps(status, publish_at);
+CREATE INDEX idx_maps_status_unpublish_at ON maps(status, unpublish_at);
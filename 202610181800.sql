@@ -0,0 +1,21 @@
+-- map_snapshots（マップの公開スナップショット）テーブル
+CREATE TABLE IF NOT EXISTS map_snapshots (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  version INT NOT NULL,
+  note VARCHAR(255) NOT NULL DEFAULT '',
+  data LONGTEXT NOT NULL,
+  created_by VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_map_snapshots_version (map_id, version),
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+-- map_published_snapshots（ビューワーが配信するスナップショット）テーブル
+CREATE TABLE IF NOT EXISTS map_published_snapshots (
+  map_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  snapshot_id VARCHAR(36) NOT NULL,
+  published_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE,
+  FOREIGN KEY (snapshot_id) REFERENCES map_snapshots(id) ON DELETE CASCADE
+);
@@ -0,0 +1,16 @@
+-- share_links（未公開マップの共有リンク）テーブル
+CREATE TABLE IF NOT EXISTS share_links (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  slug VARCHAR(32) NOT NULL UNIQUE,
+  password_hash VARCHAR(255) NOT NULL DEFAULT '',
+  expires_at TIMESTAMP NULL,
+  max_uses INT NULL,
+  use_count INT NOT NULL DEFAULT 0,
+  revoked_at TIMESTAMP NULL,
+  created_by VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_share_links_map_id ON share_links(map_id);
@@ -0,0 +1,14 @@
+-- maps テーブルにスラッグを追加
+ALTER TABLE maps
+  ADD COLUMN slug VARCHAR(50) NULL UNIQUE AFTER id;
+
+-- map_slug_history（過去のスラッグ）テーブル
+-- 変更前のスラッグから新しいスラッグへリダイレクトするために使用する
+CREATE TABLE IF NOT EXISTS map_slug_history (
+  slug VARCHAR(50) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_map_slug_history_map_id ON map_slug_history(map_id);
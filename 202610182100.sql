@@ -0,0 +1,15 @@
+-- translations（マップ・フロア・ピンの多言語コンテンツ）テーブル
+-- 元の項目はデフォルト言語として扱い、ここには他の言語の値のみを保存する
+CREATE TABLE IF NOT EXISTS translations (
+  map_id VARCHAR(36) NOT NULL,
+  entity_type VARCHAR(10) NOT NULL,
+  entity_id VARCHAR(36) NOT NULL,
+  locale VARCHAR(16) NOT NULL,
+  field VARCHAR(32) NOT NULL,
+  value TEXT NOT NULL,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (entity_type, entity_id, locale, field),
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_translations_map_locale ON translations(map_id, locale);
@@ -0,0 +1,44 @@
+-- pin_comments（来場者によるピンへのコメント）テーブル
+CREATE TABLE IF NOT EXISTS pin_comments (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  pin_id VARCHAR(36) NOT NULL,
+  map_id VARCHAR(36) NOT NULL,
+  body TEXT NOT NULL,
+  author_type VARCHAR(10) NOT NULL,
+  author_id VARCHAR(36) NOT NULL,
+  author_name VARCHAR(50) NOT NULL,
+  status VARCHAR(10) NOT NULL DEFAULT 'visible',
+  created_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at TIMESTAMP(6) DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6),
+  FOREIGN KEY (pin_id) REFERENCES pins(id) ON DELETE CASCADE,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_pin_comments_pin ON pin_comments(pin_id, status, created_at, id);
+CREATE INDEX idx_pin_comments_map ON pin_comments(map_id, status, created_at);
+
+-- pin_reactions（ピンへの絵文字リアクション）テーブル
+-- 同じ利用者は同じ絵文字を1つのピンに1回だけ付けられる
+CREATE TABLE IF NOT EXISTS pin_reactions (
+  pin_id VARCHAR(36) NOT NULL,
+  map_id VARCHAR(36) NOT NULL,
+  emoji VARCHAR(16) NOT NULL,
+  author_type VARCHAR(10) NOT NULL,
+  author_id VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (pin_id, emoji, author_type, author_id),
+  FOREIGN KEY (pin_id) REFERENCES pins(id) ON DELETE CASCADE,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_pin_reactions_map ON pin_reactions(map_id);
+
+-- map_interaction_settings（マップごとのコメント・リアクションの設定）テーブル
+-- 行がないマップはどちらも有効として扱う
+CREATE TABLE IF NOT EXISTS map_interaction_settings (
+  map_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  comments_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  reactions_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
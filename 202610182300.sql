@@ -0,0 +1,36 @@
+-- content_flags（通報された内容ごとの対応状況）テーブル
+CREATE TABLE IF NOT EXISTS content_flags (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  target_type VARCHAR(10) NOT NULL,
+  target_id VARCHAR(36) NOT NULL,
+  report_count INT NOT NULL DEFAULT 0,
+  status VARCHAR(10) NOT NULL DEFAULT 'open',
+  hidden_at TIMESTAMP NULL,
+  resolved_by VARCHAR(36) NULL,
+  resolved_at TIMESTAMP NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  UNIQUE KEY uniq_content_flags_target (target_type, target_id),
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_content_flags_map_status ON content_flags(map_id, status, updated_at);
+CREATE INDEX idx_content_flags_status ON content_flags(status, updated_at);
+
+-- content_reports（来場者・ユーザーからの個別の通報）テーブル
+-- 同じ利用者は同じ内容を1回だけ通報できる
+CREATE TABLE IF NOT EXISTS content_reports (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  flag_id VARCHAR(36) NOT NULL,
+  reason VARCHAR(20) NOT NULL,
+  details VARCHAR(500) NOT NULL DEFAULT '',
+  reporter_type VARCHAR(10) NOT NULL,
+  reporter_id VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uniq_content_reports_reporter (flag_id, reporter_type, reporter_id),
+  FOREIGN KEY (flag_id) REFERENCES content_flags(id) ON DELETE CASCADE
+);
+
+-- 公開編集者の編集禁止
+ALTER TABLE public_editors ADD COLUMN banned_at TIMESTAMP NULL;
@@ -0,0 +1,30 @@
+-- 公開編集者の端末識別と取り消し
+ALTER TABLE public_editors ADD COLUMN network_fingerprint VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE public_editors ADD COLUMN device_fingerprint VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE public_editors ADD COLUMN revoked_at TIMESTAMP NULL;
+
+-- public_editor_bans（マップごとの編集禁止の端末）テーブル
+CREATE TABLE IF NOT EXISTS public_editor_bans (
+  map_id VARCHAR(36) NOT NULL,
+  fingerprint VARCHAR(64) NOT NULL,
+  editor_id VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (map_id, fingerprint),
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_public_editor_bans_editor ON public_editor_bans(editor_id);
+
+-- public_edit_permissions（マップごとの公開編集者の権限）テーブル
+-- 行がないマップは作成・自分のピンの削除・画像の指定を許可し、他人のピンの編集は許可しない
+CREATE TABLE IF NOT EXISTS public_edit_permissions (
+  map_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  can_create BOOLEAN NOT NULL DEFAULT TRUE,
+  can_edit_others BOOLEAN NOT NULL DEFAULT FALSE,
+  can_delete BOOLEAN NOT NULL DEFAULT TRUE,
+  can_upload_images BOOLEAN NOT NULL DEFAULT TRUE,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_pins_editor_id ON pins(editor_id);
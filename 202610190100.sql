@@ -0,0 +1,28 @@
+-- public_edit_invites（公開編集者の招待コード）テーブル
+-- 権限の列がNULLの場合はマップの権限に従う
+CREATE TABLE IF NOT EXISTS public_edit_invites (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NOT NULL,
+  code VARCHAR(16) NOT NULL,
+  label VARCHAR(100) NOT NULL DEFAULT '',
+  expires_at TIMESTAMP NULL,
+  max_uses INT NULL,
+  use_count INT NOT NULL DEFAULT 0,
+  can_create BOOLEAN NULL,
+  can_edit_others BOOLEAN NULL,
+  can_delete BOOLEAN NULL,
+  can_upload_images BOOLEAN NULL,
+  revoked_at TIMESTAMP NULL,
+  created_by VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_public_edit_invites_code (code),
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_public_edit_invites_map ON public_edit_invites(map_id, created_at);
+
+-- 招待制のマップと、編集者が登録に使った招待コード
+ALTER TABLE public_edit_permissions ADD COLUMN invite_only BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE public_editors ADD COLUMN invite_id VARCHAR(36) NULL;
+
+CREATE INDEX idx_public_editors_invite ON public_editors(invite_id);
@@ -0,0 +1,26 @@
+-- content_filter_settings（マップごとの公開編集の内容フィルター）テーブル
+-- 行がないマップは既定の設定（拒否・URL禁止）を使う
+CREATE TABLE IF NOT EXISTS content_filter_settings (
+  map_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  action VARCHAR(10) NOT NULL DEFAULT 'reject',
+  block_urls BOOLEAN NOT NULL DEFAULT TRUE,
+  max_nickname_length INT NOT NULL DEFAULT 30,
+  max_title_length INT NOT NULL DEFAULT 100,
+  max_description_length INT NOT NULL DEFAULT 2000,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+-- content_filter_words（禁止語）テーブル
+-- map_idがNULLの語はすべてのマップに適用する
+CREATE TABLE IF NOT EXISTS content_filter_words (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  map_id VARCHAR(36) NULL,
+  word VARCHAR(100) NOT NULL,
+  normalized VARCHAR(100) NOT NULL,
+  created_by VARCHAR(36) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (map_id) REFERENCES maps(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_content_filter_words_map ON content_filter_words(map_id);
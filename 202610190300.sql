@@ -0,0 +1,9 @@
+-- rate_limit_buckets（複数のサーバーで共有する回数制限）テーブル
+-- RATE_LIMIT_BACKEND=mysql の場合に使用する
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+  bucket_key VARCHAR(191) NOT NULL PRIMARY KEY,
+  tokens DOUBLE NOT NULL,
+  updated_at DATETIME(3) NOT NULL
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated ON rate_limit_buckets(updated_at);
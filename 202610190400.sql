@@ -0,0 +1,18 @@
+-- audit_logs（監査ログ）テーブル
+-- 追記専用とし、アプリケーションからは更新・削除しない
+CREATE TABLE IF NOT EXISTS audit_logs (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  actor_id VARCHAR(36) NOT NULL DEFAULT '',
+  action VARCHAR(50) NOT NULL,
+  target_type VARCHAR(20) NOT NULL DEFAULT '',
+  target_id VARCHAR(255) NOT NULL DEFAULT '',
+  ip VARCHAR(45) NOT NULL DEFAULT '',
+  user_agent VARCHAR(255) NOT NULL DEFAULT '',
+  before_data JSON NULL,
+  after_data JSON NULL,
+  created_at DATETIME(3) NOT NULL
+);
+
+CREATE INDEX idx_audit_logs_created ON audit_logs(created_at);
+CREATE INDEX idx_audit_logs_actor ON audit_logs(actor_id, created_at);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action, created_at);
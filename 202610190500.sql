@@ -0,0 +1,30 @@
+-- user_two_factor（ユーザーのTOTPによる二段階認証）テーブル
+-- enabled_atがNULLの行は設定途中（確認コードの検証前）を表す
+CREATE TABLE IF NOT EXISTS user_two_factor (
+  user_id VARCHAR(36) NOT NULL PRIMARY KEY,
+  secret VARCHAR(64) NOT NULL,
+  enabled_at DATETIME NULL,
+  last_used_step BIGINT NOT NULL DEFAULT 0,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- user_recovery_codes（二段階認証の回復コード）テーブル
+-- コードはハッシュ化して保存し、一度使うとused_atを設定する
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(36) NOT NULL,
+  code_hash CHAR(64) NOT NULL,
+  used_at DATETIME NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_user_recovery_codes (user_id, code_hash),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- security_settings（サービス全体のセキュリティ設定）テーブル
+-- 行は1件（id = 1）のみで、行がない場合は既定の設定を使う
+CREATE TABLE IF NOT EXISTS security_settings (
+  id TINYINT NOT NULL PRIMARY KEY,
+  require_admin_two_factor BOOLEAN NOT NULL DEFAULT FALSE,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
@@ -0,0 +1,31 @@
+-- user_identities（外部のOpenID Connectプロバイダーのアカウントとの連携）テーブル
+-- 1人のユーザーはプロバイダーごとに1つのアカウントだけを連携できる
+CREATE TABLE IF NOT EXISTS user_identities (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(36) NOT NULL,
+  provider VARCHAR(50) NOT NULL,
+  subject VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL DEFAULT '',
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  last_login_at DATETIME NULL,
+  UNIQUE KEY uq_user_identities_subject (provider, subject),
+  UNIQUE KEY uq_user_identities_user (user_id, provider),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- oidc_login_states（認可リクエスト中のstate・nonce・PKCEのコード検証値）テーブル
+-- user_idがある行はログイン中のユーザーへの連携を表す。コールバックで1回だけ使う
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+  state VARCHAR(64) NOT NULL PRIMARY KEY,
+  provider VARCHAR(50) NOT NULL,
+  nonce VARCHAR(64) NOT NULL,
+  code_verifier VARCHAR(128) NOT NULL,
+  user_id VARCHAR(36) NULL,
+  expires_at DATETIME NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_oidc_login_states_expires ON oidc_login_states(expires_at);
+
+-- 外部アカウントのみで登録したユーザーはパスワードを持たない
+ALTER TABLE users MODIFY password VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+-- personal_access_tokens（スクリプトなどの自動処理に使う個人用アクセストークン）テーブル
+-- トークン本体は保存せず、SHA-256のハッシュと一覧表示用の先頭部分だけを保持する
+-- scopesは許可する権限をカンマ区切りで保持する
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  user_id VARCHAR(36) NOT NULL,
+  name VARCHAR(100) NOT NULL,
+  token_hash CHAR(64) NOT NULL,
+  token_prefix VARCHAR(20) NOT NULL,
+  scopes VARCHAR(255) NOT NULL,
+  expires_at DATETIME NULL,
+  last_used_at DATETIME NULL,
+  revoked_at DATETIME NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE KEY uq_personal_access_tokens_hash (token_hash),
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_personal_access_tokens_user ON personal_access_tokens(user_id, created_at);
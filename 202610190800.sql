@@ -0,0 +1,29 @@
+-- organizations（実行委員会などの組織）テーブル
+CREATE TABLE IF NOT EXISTS organizations (
+  id VARCHAR(36) NOT NULL PRIMARY KEY,
+  name VARCHAR(100) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+-- organization_members（組織のメンバーと役割）テーブル
+-- roleは owner（組織の削除・所有者の変更）、admin（メンバーの管理・マップの移管）、member（マップの編集）のいずれか
+CREATE TABLE IF NOT EXISTS organization_members (
+  organization_id VARCHAR(36) NOT NULL,
+  user_id VARCHAR(36) NOT NULL,
+  role VARCHAR(20) NOT NULL,
+  created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (organization_id, user_id),
+  FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE,
+  FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_organization_members_user ON organization_members(user_id);
+
+-- マップは個人（user_id）または組織（organization_id）のどちらか一方が所有する
+-- 組織のマップはメンバーが退会・卒業してアカウントを削除しても残るよう、user_idをNULLにする
+-- マップが残っている組織は削除できない
+ALTER TABLE maps MODIFY user_id VARCHAR(36) NULL;
+ALTER TABLE maps ADD COLUMN organization_id VARCHAR(36) NULL AFTER user_id;
+ALTER TABLE maps ADD CONSTRAINT fk_maps_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE RESTRICT;
+CREATE INDEX idx_maps_organization ON maps(organization_id);
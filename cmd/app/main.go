@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/shimaf4979/pamfree-backend/config"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/routes"
 )
 
@@ -25,15 +26,25 @@ func main() {
 		gin.SetMode(gin.DebugMode)
 	}
 
+	// 検証エラーメッセージの設定
+	i18n.Setup()
+
 	// Ginルーターの作成
 	router := gin.Default()
 
+	// X-Forwarded-Forは設定したプロキシからの場合のみ信頼する
+	// 信頼しないと、ヘッダーを変えるだけでIPアドレスごとの回数制限やログインのロックを回避できる
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("信頼するプロキシの設定に失敗しました: %v", err)
+	}
+
 	// CORSの設定
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", cfg.AllowedOrigins)
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Visitor-Token, X-Editor-Id, X-Editor-Token")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Retry-After")
 
 		// プリフライトリクエストの処理
 		if c.Request.Method == "OPTIONS" {
@@ -4,6 +4,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -21,6 +23,27 @@ type Config struct {
 	CloudinaryName   string
 	CloudinaryKey    string
 	CloudinarySecret string
+	UploadTicketTTL  int
+	PDFFontPath      string
+	ExportDir        string
+	PDFRetention     int
+	ViewerBaseURL    string
+	RallySecret      string
+	VisitorSecret    string
+	CommentLimit     int
+	ReactionLimit    int
+	ReportThreshold  int
+	RateLimitBackend string
+	LoginMaxFailures int
+	AccountFailures  int
+	LoginLockout     int
+	TrustedProxies   []string
+	TOTPIssuer       string
+	OIDCProviders    []OIDCProviderConfig
+	OIDCRedirectURL  string
+	AnalyticsFlush   int
+	PublishInterval  int
+	Locales          []string
 	AllowedOrigins   string
 	AllowCredentials bool
 	AllowedMethods   []string
@@ -50,21 +73,75 @@ func LoadConfig() (*Config, error) {
 		CloudinaryName:   getEnv("CLOUDINARY_CLOUD_NAME", ""),
 		CloudinaryKey:    getEnv("CLOUDINARY_API_KEY", ""),
 		CloudinarySecret: getEnv("CLOUDINARY_API_SECRET", ""),
+		UploadTicketTTL:  getEnvInt("UPLOAD_TICKET_TTL_MINUTES", 10),
+		PDFFontPath:      getEnv("PDF_FONT_PATH", "./fonts/ipaexg.ttf"), // TrueType形式の日本語フォント（Dockerイメージに同梱）
+		ExportDir:        getEnv("EXPORT_DIR", "./exports"),
+		PDFRetention:     getEnvInt("PDF_RETENTION_HOURS", 24), // 生成したPDFを保存しておく時間（0で削除しない）
+		ViewerBaseURL:    getEnv("VIEWER_BASE_URL", "http://localhost:3000/viewer"),
+		RallySecret:      getEnv("RALLY_SECRET", getEnv("JWT_SECRET", "your-secret-key")),
+		VisitorSecret:    getEnv("VISITOR_SECRET", getEnv("JWT_SECRET", "your-secret-key")),
+		CommentLimit:     getEnvInt("COMMENT_RATE_LIMIT", 5),          // 1人あたり1分間のコメント数
+		ReactionLimit:    getEnvInt("REACTION_RATE_LIMIT", 30),        // 1人あたり1分間のリアクション数
+		ReportThreshold:  getEnvInt("REPORT_HIDE_THRESHOLD", 3),       // 自動的に非表示にする通報件数（0で無効）
+		RateLimitBackend: getEnv("RATE_LIMIT_BACKEND", "memory"),      // memory または mysql（複数のサーバーで共有）
+		LoginMaxFailures: getEnvInt("LOGIN_MAX_FAILURES", 5),          // ロックするまでのログインの失敗回数（0で無効、サーバーごとに数える）
+		AccountFailures:  getEnvInt("LOGIN_ACCOUNT_MAX_FAILURES", 20), // 接続元を問わずアカウントをロックするまでの失敗回数（0で無効）
+		LoginLockout:     getEnvInt("LOGIN_LOCKOUT_SECONDS", 60),      // 最初にロックする秒数（以降は失敗するたびに2倍）
+		TrustedProxies:   getEnvList("TRUSTED_PROXIES", nil),          // X-Forwarded-Forを信頼するプロキシ（未設定の場合は接続元のIPアドレスを使う）
+		TOTPIssuer:       getEnv("TOTP_ISSUER", "PamFree"),            // 認証アプリに表示するサービス名
+		AnalyticsFlush:   getEnvInt("ANALYTICS_FLUSH_SECONDS", 30),
+		PublishInterval:  getEnvInt("PUBLISH_SCHEDULER_SECONDS", 60),
+		Locales:          getEnvList("SUPPORTED_LOCALES", []string{"ja", "en", "zh-Hans", "zh-Hant", "ko"}), // 先頭がデフォルト言語
 		AllowedOrigins:   getEnv("ALLOWED_ORIGINS", "*"),
 		AllowCredentials: getEnvBool("ALLOW_CREDENTIALS", true),
 		AllowedMethods: []string{
 			"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS",
 		},
 		AllowedHeaders: []string{
-			"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With",
+			"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "X-Visitor-Token", "X-Editor-Id", "X-Editor-Token",
 		},
-		ExposedHeaders: []string{},
+		ExposedHeaders: []string{"Retry-After"},
 		MaxAge:         86400, // 24時間
 	}
+	config.OIDCRedirectURL = getEnv("OIDC_REDIRECT_URL", "http://localhost:3000/auth/callback") // 認可後に戻るフロントエンドのURL
+	config.OIDCProviders = loadOIDCProviders()
 
 	return config, nil
 }
 
+// OIDCProviderConfig はOpenID Connectのプロバイダーの設定を表す構造体
+type OIDCProviderConfig struct {
+	ID           string
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// loadOIDCProviders はOpenID Connectのプロバイダーの設定を読み込む
+// OIDC_PROVIDERSにIDをカンマ区切りで指定し、IDごとに OIDC_<ID>_ISSUER などを設定する
+// 発行者またはクライアントIDが未設定のプロバイダーは無視する
+func loadOIDCProviders() []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, id := range getEnvList("OIDC_PROVIDERS", nil) {
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(id, "-", "_")) + "_"
+		provider := OIDCProviderConfig{
+			ID:           strings.ToLower(id),
+			Name:         getEnv(prefix+"NAME", id),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Scopes:       getEnvList(prefix+"SCOPES", []string{"openid", "email", "profile"}),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			continue
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
+
 // getEnv は環境変数を取得し、未設定の場合はデフォルト値を返す
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -82,3 +159,26 @@ func getEnvBool(key string, defaultValue bool) bool {
 	}
 	return value == "true" || value == "1" || value == "yes"
 }
+
+// getEnvInt は環境変数をint値として取得する
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvList は環境変数をカンマ区切りのリストとして取得する
+func getEnvList(key string, defaultValue []string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
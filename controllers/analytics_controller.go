@@ -0,0 +1,90 @@
+// backend/controllers/analytics_controller.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// AnalyticsController はビューワーの利用状況に関するAPIエンドポイントを管理する
+type AnalyticsController struct {
+	analyticsService services.AnalyticsService
+}
+
+// NewAnalyticsController は新しいAnalyticsControllerを作成する
+func NewAnalyticsController(analyticsService services.AnalyticsService) *AnalyticsController {
+	return &AnalyticsController{
+		analyticsService: analyticsService,
+	}
+}
+
+// IngestEvents はビューワーから送信されたイベントをまとめて受け付ける
+func (c *AnalyticsController) IngestEvents(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+
+	var req models.AnalyticsBatch
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	accepted, err := c.analyticsService.Ingest(ctx, mapID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrMapNotFound) {
+			i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+			return
+		}
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"accepted": accepted})
+}
+
+// GetDashboard はマップの利用状況を取得する
+// from/toはRFC3339または日付（YYYY-MM-DD）、granularityはhourまたはday
+func (c *AnalyticsController) GetDashboard(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	from, err := parseAnalyticsTime(ctx.Query("from"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "fromの形式が不正です")
+		return
+	}
+	to, err := parseAnalyticsTime(ctx.Query("to"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "toの形式が不正です")
+		return
+	}
+
+	dashboard, err := c.analyticsService.GetDashboard(ctx, userID.(string), mapID, from, to, ctx.Query("granularity"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dashboard)
+}
+
+// parseAnalyticsTime は集計期間の指定を解析する
+// 未指定の場合はゼロ値を返す
+func parseAnalyticsTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,107 @@
+// backend/controllers/audit_log_controller.go
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// AuditLogController は監査ログの閲覧に関するAPIエンドポイントを管理する（管理者用）
+type AuditLogController struct {
+	auditService services.AuditService
+}
+
+// NewAuditLogController は新しいAuditLogControllerを作成する
+func NewAuditLogController(auditService services.AuditService) *AuditLogController {
+	return &AuditLogController{
+		auditService: auditService,
+	}
+}
+
+// GetLogs は監査ログを検索する（?actor=&action=&from=&to=&limit=&offset=）
+func (c *AuditLogController) GetLogs(ctx *gin.Context) {
+	filter, ok := auditLogFilter(ctx)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := pageParams(ctx)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
+	filter.Offset = offset
+
+	page, err := c.auditService.Query(ctx, filter)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// ExportLogs は検索条件に一致する監査ログをCSVで出力する
+func (c *AuditLogController) ExportLogs(ctx *gin.Context) {
+	filter, ok := auditLogFilter(ctx)
+	if !ok {
+		return
+	}
+
+	rows, err := c.auditService.Export(ctx, filter)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	data, err := utils.WriteCSV(rows)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ファイルの生成に失敗しました")
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="audit-logs.csv"`)
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// auditLogFilter は監査ログの検索条件をクエリパラメータから取得する
+// from/toはRFC3339または日付（YYYY-MM-DD）
+func auditLogFilter(ctx *gin.Context) (*models.AuditLogFilter, bool) {
+	from, err := parseAnalyticsTime(ctx.Query("from"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "fromの形式が不正です")
+		return nil, false
+	}
+	to, err := parseAnalyticsTime(ctx.Query("to"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "toの形式が不正です")
+		return nil, false
+	}
+
+	return &models.AuditLogFilter{
+		ActorID: ctx.Query("actor"),
+		Action:  ctx.Query("action"),
+		From:    from,
+		To:      to,
+	}, true
+}
+
+// recordAudit はリクエストの操作者・IPアドレス・ユーザーエージェントと共に監査ログを記録する
+// 記録に失敗しても操作自体は成功として扱うため、ログの出力のみ行う
+func recordAudit(ctx *gin.Context, auditService services.AuditService, entry *models.AuditLog, before, after interface{}) {
+	if entry.ActorID == "" {
+		entry.ActorID = ctx.GetString("userID")
+	}
+	entry.IP = ctx.ClientIP()
+	entry.UserAgent = ctx.Request.UserAgent()
+
+	if err := auditService.Record(ctx, entry, before, after); err != nil {
+		log.Printf("監査ログの記録に失敗しました: %v", err)
+	}
+}
@@ -3,8 +3,11 @@ package controllers
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/services"
 	"github.com/shimaf4979/pamfree-backend/utils"
@@ -12,15 +15,30 @@ import (
 
 // AuthController 認証コントローラー
 type AuthController struct {
-	authService *services.AuthService
-	jwtSecret   string
+	authService      *services.AuthService
+	auditService     services.AuditService
+	twoFactorService services.TwoFactorService
+	jwtSecret        string
+	lockout          *utils.LoginLockout
+	accountLockout   *utils.LoginLockout
 }
 
 // NewAuthController 新しい認証コントローラーを作成
-func NewAuthController(authService *services.AuthService, jwtSecret string) *AuthController {
+func NewAuthController(
+	authService *services.AuthService,
+	auditService services.AuditService,
+	twoFactorService services.TwoFactorService,
+	jwtSecret string,
+	lockout *utils.LoginLockout,
+	accountLockout *utils.LoginLockout,
+) *AuthController {
 	return &AuthController{
-		authService: authService,
-		jwtSecret:   jwtSecret,
+		authService:      authService,
+		auditService:     auditService,
+		twoFactorService: twoFactorService,
+		jwtSecret:        jwtSecret,
+		lockout:          lockout,
+		accountLockout:   accountLockout,
 	}
 }
 
@@ -28,25 +46,25 @@ func NewAuthController(authService *services.AuthService, jwtSecret string) *Aut
 func (c *AuthController) Register(ctx *gin.Context) {
 	var req models.UserRegister
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	// メールアドレスの重複チェック
 	exists, err := c.authService.EmailExists(ctx, req.Email)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
 	if exists {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "このメールアドレスは既に登録されています"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "このメールアドレスは既に登録されています")
 		return
 	}
 
 	// パスワードのハッシュ化
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "パスワードの処理に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "パスワードの処理に失敗しました")
 		return
 	}
 
@@ -59,7 +77,7 @@ func (c *AuthController) Register(ctx *gin.Context) {
 	}
 
 	if err := c.authService.CreateUser(ctx, user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーの登録に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ユーザーの登録に失敗しました")
 		return
 	}
 
@@ -76,40 +94,134 @@ func (c *AuthController) Register(ctx *gin.Context) {
 func (c *AuthController) Login(ctx *gin.Context) {
 	var req models.UserLogin
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	// 失敗が続いているメールアドレスはロックが解除されるまで拒否する
+	// 第三者が失敗を繰り返して本人をロックできないよう、メールアドレスと接続元の組ごとに数える
+	// 多数の接続元からの総当たりに備え、メールアドレスのみでもより多い回数で数える
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	lockoutKey := email + "|" + ctx.ClientIP()
+	remaining := c.lockout.Locked(lockoutKey)
+	if accountRemaining := c.accountLockout.Locked(email); accountRemaining > remaining {
+		remaining = accountRemaining
+	}
+	if remaining > 0 {
+		c.respondLocked(ctx, remaining)
 		return
 	}
 
 	// メールアドレスからユーザーを検索
 	user, err := c.authService.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
-	if user == nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "メールアドレスまたはパスワードが正しくありません"})
+
+	// パスワードの検証
+	if user == nil || utils.CheckPassword(user.Password, req.Password) != nil {
+		failure := &models.AuditLog{Action: models.AuditActionLoginFailed, TargetType: models.AuditTargetUser}
+		if user != nil {
+			failure.TargetID = user.ID
+		}
+		recordAudit(ctx, c.auditService, failure, nil, gin.H{"email": email})
+
+		lockout := c.lockout.Fail(lockoutKey)
+		if accountLockout := c.accountLockout.Fail(email); accountLockout > lockout {
+			lockout = accountLockout
+		}
+		if lockout > 0 {
+			c.respondLocked(ctx, lockout)
+			return
+		}
+		i18n.RespondError(ctx, http.StatusUnauthorized, "メールアドレスまたはパスワードが正しくありません")
+		return
+	}
+	c.lockout.Reset(lockoutKey)
+	c.accountLockout.Reset(email)
+
+	completeLogin(ctx, c.twoFactorService, c.auditService, c.jwtSecret, user, nil, nil)
+}
+
+// LoginTwoFactor ログインの二段階目（確認コードの検証）ハンドラー
+// 認証アプリの確認コードまたは回復コードを受け付ける
+func (c *AuthController) LoginTwoFactor(ctx *gin.Context) {
+	var req models.LoginTwoFactor
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
-	// パスワードの検証
-	if err := utils.CheckPassword(user.Password, req.Password); err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "メールアドレスまたはパスワードが正しくありません"})
+	userID, err := utils.ValidateLoginChallenge(req.ChallengeToken, c.jwtSecret)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "ログインの有効期限が切れました。もう一度ログインしてください")
 		return
 	}
 
-	// JWTトークンの生成
-	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, c.jwtSecret)
+	// 確認コードの総当たりを防ぐため、パスワードとは別に失敗回数を数える
+	lockoutKey := "2fa:" + userID
+	if remaining := c.lockout.Locked(lockoutKey); remaining > 0 {
+		c.respondLocked(ctx, remaining)
+		return
+	}
+
+	usedRecoveryCode, err := c.twoFactorService.Verify(ctx, userID, req.Code)
+	if err == services.ErrTwoFactorInvalidCode {
+		recordAudit(ctx, c.auditService, &models.AuditLog{
+			Action:     models.AuditActionLoginFailed,
+			TargetType: models.AuditTargetUser,
+			TargetID:   userID,
+		}, nil, gin.H{"two_factor": true})
+
+		if lockout := c.lockout.Fail(lockoutKey); lockout > 0 {
+			c.respondLocked(ctx, lockout)
+			return
+		}
+		i18n.RespondError(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if err == services.ErrTwoFactorNotEnabled {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "ログインの有効期限が切れました。もう一度ログインしてください")
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "認証トークンの生成に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
+	c.lockout.Reset(lockoutKey)
 
-	// レスポンスから機密情報を削除
-	userResponse := user.ToResponse()
+	user, err := c.authService.GetUserByID(ctx, userID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
+		return
+	}
+	if user == nil {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "ログインの有効期限が切れました。もう一度ログインしてください")
+		return
+	}
+
+	method := "totp"
+	if usedRecoveryCode {
+		method = "recovery_code"
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		ActorID:    user.ID,
+		Action:     models.AuditActionLogin,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	}, nil, gin.H{"two_factor": method})
+
+	token, err := utils.GenerateTwoFactorToken(user.ID, user.Email, user.Role, c.jwtSecret)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "認証トークンの生成に失敗しました")
+		return
+	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"token": token,
-		"user":  userResponse,
+		"token":              token,
+		"user":               user.ToResponse(),
+		"used_recovery_code": usedRecoveryCode,
 	})
 }
 
@@ -117,18 +229,18 @@ func (c *AuthController) Login(ctx *gin.Context) {
 func (c *AuthController) GetMe(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	// ユーザーIDからユーザーを検索
 	user, err := c.authService.GetUserByID(ctx, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
 	if user == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "ユーザーが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "ユーザーが見つかりません")
 		return
 	}
 
@@ -142,7 +254,7 @@ func (c *AuthController) GetMe(ctx *gin.Context) {
 func (c *AuthController) UpdateProfile(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
@@ -151,18 +263,18 @@ func (c *AuthController) UpdateProfile(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	// ユーザー取得
 	user, err := c.authService.GetUserByID(ctx, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
 	if user == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "ユーザーが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "ユーザーが見つかりません")
 		return
 	}
 
@@ -171,7 +283,7 @@ func (c *AuthController) UpdateProfile(ctx *gin.Context) {
 
 	// ユーザー情報を更新
 	if err := c.authService.UpdateUser(ctx, user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "プロフィールの更新に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "プロフィールの更新に失敗しました")
 		return
 	}
 
@@ -185,44 +297,64 @@ func (c *AuthController) UpdateProfile(ctx *gin.Context) {
 }
 
 // ChangePassword パスワード変更ハンドラー
+// パスワードを持たないユーザー（外部アカウントのみで登録）は現在のパスワードなしで設定でき、
+// 二段階認証が有効な場合は確認コードで本人確認する
 func (c *AuthController) ChangePassword(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	var req struct {
-		CurrentPassword string `json:"currentPassword" binding:"required"`
+		CurrentPassword string `json:"currentPassword"`
 		NewPassword     string `json:"newPassword" binding:"required"`
+		Code            string `json:"code" binding:"max=32"`
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	// ユーザー取得
 	user, err := c.authService.GetUserByID(ctx, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
 	if user == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "ユーザーが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "ユーザーが見つかりません")
 		return
 	}
 
 	// 現在のパスワードを検証
-	if err := utils.CheckPassword(user.Password, req.CurrentPassword); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "現在のパスワードが正しくありません"})
-		return
+	if user.Password != "" {
+		if err := utils.CheckPassword(user.Password, req.CurrentPassword); err != nil {
+			i18n.RespondError(ctx, http.StatusBadRequest, "現在のパスワードが正しくありません")
+			return
+		}
+	} else {
+		enabled, err := c.twoFactorService.IsEnabled(ctx, user.ID)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
+			return
+		}
+		if enabled {
+			if _, err := c.twoFactorService.Verify(ctx, user.ID, req.Code); err == services.ErrTwoFactorInvalidCode {
+				i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+				return
+			} else if err != nil {
+				i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
+				return
+			}
+		}
 	}
 
 	// 新しいパスワードをハッシュ化
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "パスワードの処理に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "パスワードの処理に失敗しました")
 		return
 	}
 
@@ -231,9 +363,14 @@ func (c *AuthController) ChangePassword(ctx *gin.Context) {
 
 	// ユーザー情報を更新
 	if err := c.authService.UpdateUser(ctx, user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "パスワードの更新に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "パスワードの更新に失敗しました")
 		return
 	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionPasswordChange,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	}, nil, nil)
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "パスワードを更新しました",
@@ -244,7 +381,7 @@ func (c *AuthController) ChangePassword(ctx *gin.Context) {
 func (c *AuthController) GetAllUsers(ctx *gin.Context) {
 	users, err := c.authService.GetAllUsers(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザー一覧の取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ユーザー一覧の取得に失敗しました")
 		return
 	}
 	if users == nil {
@@ -269,42 +406,50 @@ func (c *AuthController) UpdateUser(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	// ロールのバリデーション
 	if req.Role != "admin" && req.Role != "user" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "有効な役割を指定してください"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "有効な役割を指定してください")
 		return
 	}
 
 	// ユーザー取得
 	user, err := c.authService.GetUserByID(ctx, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
 	if user == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "ユーザーが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "ユーザーが見つかりません")
 		return
 	}
 
 	// 自分自身のロールは変更不可
 	adminID, exists := ctx.Get("userID")
 	if exists && adminID.(string) == userID {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "自分自身の役割は変更できません"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "自分自身の役割は変更できません")
 		return
 	}
 
 	// ロールを更新
+	previousRole := user.Role
 	user.Role = req.Role
 
 	// ユーザー情報を更新
 	if err := c.authService.UpdateUser(ctx, user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーの更新に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ユーザーの更新に失敗しました")
 		return
 	}
+	if previousRole != user.Role {
+		recordAudit(ctx, c.auditService, &models.AuditLog{
+			Action:     models.AuditActionRoleChange,
+			TargetType: models.AuditTargetUser,
+			TargetID:   user.ID,
+		}, gin.H{"role": previousRole}, gin.H{"role": user.Role})
+	}
 
 	// レスポンスから機密情報を削除
 	userResponse := user.ToResponse()
@@ -319,28 +464,108 @@ func (c *AuthController) DeleteUser(ctx *gin.Context) {
 	// 自分自身の削除は不可
 	adminID, exists := ctx.Get("userID")
 	if exists && adminID.(string) == userID {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "自分自身を削除することはできません"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "自分自身を削除することはできません")
 		return
 	}
 
 	// ユーザー取得
 	user, err := c.authService.GetUserByID(ctx, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "サーバーエラーが発生しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
 		return
 	}
 	if user == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "ユーザーが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "ユーザーが見つかりません")
 		return
 	}
 
 	// ユーザーを削除
 	if err := c.authService.DeleteUser(ctx, userID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ユーザーの削除に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ユーザーの削除に失敗しました")
 		return
 	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionUserDelete,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	}, user.ToResponse(), nil)
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "ユーザーが正常に削除されました",
 	})
 }
+
+// respondLocked はログインがロックされていることをRetry-Afterヘッダーと共に返す
+func (c *AuthController) respondLocked(ctx *gin.Context, remaining time.Duration) {
+	ctx.Header("Retry-After", utils.RetryAfterSeconds(remaining))
+	i18n.RespondError(ctx, http.StatusTooManyRequests, "ログインの失敗が続いたため、しばらくログインできません")
+}
+
+// completeLogin は本人確認が済んだユーザーのログインを完了する
+// 二段階認証が有効な場合は確認待ちのトークンだけを返し、確認コードの検証後にJWTを発行する
+// detailsは監査ログに記録する内容、extraはレスポンスに追加する項目
+func completeLogin(
+	ctx *gin.Context,
+	twoFactorService services.TwoFactorService,
+	auditService services.AuditService,
+	jwtSecret string,
+	user *models.User,
+	details gin.H,
+	extra gin.H,
+) {
+	twoFactorEnabled, err := twoFactorService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
+		return
+	}
+	if twoFactorEnabled {
+		challenge, err := utils.GenerateLoginChallenge(user.ID, jwtSecret)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "認証トークンの生成に失敗しました")
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+			"expires_in":          int(utils.LoginChallengeTTL.Seconds()),
+		})
+		return
+	}
+
+	recordAudit(ctx, auditService, &models.AuditLog{
+		ActorID:    user.ID,
+		Action:     models.AuditActionLogin,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	}, nil, details)
+
+	// JWTトークンの生成
+	token, err := utils.GenerateToken(user.ID, user.Email, user.Role, jwtSecret)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "認証トークンの生成に失敗しました")
+		return
+	}
+
+	// 二段階認証が必須の管理者には設定を促す（設定するまで管理者用のAPIは使えない）
+	setupRequired := false
+	if user.Role == "admin" {
+		if setupRequired, err = twoFactorService.AdminTwoFactorRequired(ctx); err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
+			return
+		}
+	}
+
+	// レスポンスから機密情報を削除
+	userResponse := user.ToResponse()
+
+	response := gin.H{
+		"token":                     token,
+		"user":                      userResponse,
+		"two_factor_setup_required": setupRequired,
+	}
+	for key, value := range extra {
+		response[key] = value
+	}
+	ctx.JSON(http.StatusOK, response)
+}
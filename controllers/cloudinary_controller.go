@@ -4,23 +4,33 @@ package controllers
 import (
 	"context"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
 	"github.com/gin-gonic/gin"
 	"github.com/shimaf4979/pamfree-backend/config"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
 )
 
 // CloudinaryController はCloudinaryとの画像連携を行うコントローラー
 type CloudinaryController struct {
-	cloudinary *cloudinary.Cloudinary
-	config     *config.Config
+	cloudinary    *cloudinary.Cloudinary
+	config        *config.Config
+	uploadService services.UploadService
+	auditService  services.AuditService
 }
 
 // NewCloudinaryController は新しいCloudinaryControllerを作成する
-func NewCloudinaryController(cfg *config.Config) (*CloudinaryController, error) {
+func NewCloudinaryController(cfg *config.Config, uploadService services.UploadService, auditService services.AuditService) (*CloudinaryController, error) {
 	// Cloudinaryクライアントの初期化
 	cld, err := cloudinary.NewFromParams(
 		cfg.CloudinaryName,
@@ -32,8 +42,10 @@ func NewCloudinaryController(cfg *config.Config) (*CloudinaryController, error)
 	}
 
 	return &CloudinaryController{
-		cloudinary: cld,
-		config:     cfg,
+		cloudinary:    cld,
+		config:        cfg,
+		uploadService: uploadService,
+		auditService:  auditService,
 	}, nil
 }
 
@@ -42,14 +54,14 @@ func (c *CloudinaryController) UploadImage(ctx *gin.Context) {
 	// マルチパートフォームファイルを取得
 	file, header, err := ctx.Request.FormFile("image")
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "画像ファイルが必要です"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "画像ファイルが必要です")
 		return
 	}
 	defer file.Close()
 
 	// ファイルタイプの検証
 	if !isValidImageType(header.Filename) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効な画像形式です"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "無効な画像形式です")
 		return
 	}
 
@@ -69,7 +81,7 @@ func (c *CloudinaryController) UploadImage(ctx *gin.Context) {
 		uploadParams,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "画像のアップロードに失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "画像のアップロードに失敗しました")
 		return
 	}
 
@@ -92,7 +104,7 @@ func (c *CloudinaryController) DeleteImage(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "公開IDが必要です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
@@ -102,18 +114,115 @@ func (c *CloudinaryController) DeleteImage(ctx *gin.Context) {
 		uploader.DestroyParams{PublicID: req.PublicID},
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "画像の削除に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "画像の削除に失敗しました")
 		return
 	}
 
 	if result.Result != "ok" {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "画像の削除に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "画像の削除に失敗しました")
 		return
 	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionImageDelete,
+		TargetType: models.AuditTargetImage,
+		TargetID:   req.PublicID,
+	}, nil, nil)
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "画像が正常に削除されました"})
 }
 
+// SignUpload はフロアまたはピン用の署名付き直接アップロードパラメータを発行する
+func (c *CloudinaryController) SignUpload(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.UploadSignRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	// アップロードチケットを発行
+	ttl := time.Duration(c.config.UploadTicketTTL) * time.Minute
+	ticket, err := c.uploadService.CreateTicket(ctx, userID.(string), &req, ttl)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// 公開IDとタイムスタンプに署名（クライアントは同じ値でアップロードする必要がある）
+	timestamp := time.Now().Unix()
+	params := url.Values{}
+	params.Set("public_id", ticket.PublicID)
+	params.Set("timestamp", strconv.FormatInt(timestamp, 10))
+
+	signature, err := api.SignParameters(params, c.config.CloudinarySecret)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "署名の生成に失敗しました")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.UploadSignResponse{
+		UploadURL: "https://api.cloudinary.com/v1_1/" + c.config.CloudinaryName + "/image/upload",
+		APIKey:    c.config.CloudinaryKey,
+		CloudName: c.config.CloudinaryName,
+		PublicID:  ticket.PublicID,
+		Timestamp: timestamp,
+		Signature: signature,
+		ExpiresAt: ticket.ExpiresAt,
+	})
+}
+
+// CompleteUpload は直接アップロードされた画像を検証し、対象に設定する
+func (c *CloudinaryController) CompleteUpload(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.UploadComplete
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	ticket, err := c.uploadService.GetPendingTicket(ctx, userID.(string), req.PublicID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Cloudinary上に画像が存在するか確認
+	asset, err := c.cloudinary.Admin.Asset(context.Background(), admin.AssetParams{PublicID: ticket.PublicID})
+	if err != nil || asset.Error.Message != "" {
+		i18n.RespondError(ctx, http.StatusBadRequest, "アップロードされた画像が見つかりません")
+		return
+	}
+
+	if asset.ResourceType != "image" || !isValidImageType("."+asset.Format) {
+		i18n.RespondError(ctx, http.StatusBadRequest, "無効な画像形式です")
+		return
+	}
+
+	// 画像URLを対象に設定
+	result, err := c.uploadService.CompleteTicket(ctx, ticket, asset.SecureURL)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"url":         asset.SecureURL,
+		"public_id":   asset.PublicID,
+		"target_type": ticket.TargetType,
+		"target":      result,
+	})
+}
+
 // isValidImageType は有効な画像形式かどうかをチェックする
 func isValidImageType(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -0,0 +1,171 @@
+// backend/controllers/content_filter_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// ContentFilterController は公開編集の内容フィルターに関するAPIエンドポイントを管理する
+type ContentFilterController struct {
+	contentFilterService services.ContentFilterService
+}
+
+// NewContentFilterController は新しいContentFilterControllerを作成する
+func NewContentFilterController(contentFilterService services.ContentFilterService) *ContentFilterController {
+	return &ContentFilterController{
+		contentFilterService: contentFilterService,
+	}
+}
+
+// GetSettings はマップの内容フィルターの設定を取得する
+func (c *ContentFilterController) GetSettings(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	settings, err := c.contentFilterService.GetSettings(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings はマップの内容フィルターの設定を更新する
+func (c *ContentFilterController) UpdateSettings(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.ContentFilterSettingsUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	settings, err := c.contentFilterService.UpdateSettings(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// GetMapWords はマップ固有の禁止語を取得する
+func (c *ContentFilterController) GetMapWords(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	words, err := c.contentFilterService.GetMapWords(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, words)
+}
+
+// AddMapWord はマップ固有の禁止語を追加する
+func (c *ContentFilterController) AddMapWord(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.ContentFilterWordCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	word, err := c.contentFilterService.AddMapWord(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, word)
+}
+
+// DeleteMapWord はマップ固有の禁止語を削除する
+func (c *ContentFilterController) DeleteMapWord(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	wordID := ctx.Param("wordId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.contentFilterService.DeleteMapWord(ctx, userID.(string), mapID, wordID); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "禁止語を削除しました", "id": wordID})
+}
+
+// GetGlobalWords はすべてのマップに適用する禁止語を取得する（管理者用）
+func (c *ContentFilterController) GetGlobalWords(ctx *gin.Context) {
+	words, err := c.contentFilterService.GetGlobalWords(ctx)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, words)
+}
+
+// AddGlobalWord はすべてのマップに適用する禁止語を追加する（管理者用）
+func (c *ContentFilterController) AddGlobalWord(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.ContentFilterWordCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	word, err := c.contentFilterService.AddGlobalWord(ctx, userID.(string), &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, word)
+}
+
+// DeleteGlobalWord はすべてのマップに適用する禁止語を削除する（管理者用）
+func (c *ContentFilterController) DeleteGlobalWord(ctx *gin.Context) {
+	wordID := ctx.Param("wordId")
+
+	if err := c.contentFilterService.DeleteGlobalWord(ctx, wordID); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "禁止語を削除しました", "id": wordID})
+}
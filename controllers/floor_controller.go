@@ -5,19 +5,22 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/services"
 )
 
 // FloorController はフロア関連のAPIエンドポイントを管理する
 type FloorController struct {
-	floorService services.FloorService
+	floorService  services.FloorService
+	viewerService services.ViewerService
 }
 
 // NewFloorController は新しいFloorControllerを作成する
-func NewFloorController(floorService services.FloorService) *FloorController {
+func NewFloorController(floorService services.FloorService, viewerService services.ViewerService) *FloorController {
 	return &FloorController{
-		floorService: floorService,
+		floorService:  floorService,
+		viewerService: viewerService,
 	}
 }
 
@@ -26,13 +29,13 @@ func (c *FloorController) CreateFloor(ctx *gin.Context) {
 	mapID := ctx.Param("mapId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	var req models.FloorCreate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
@@ -40,7 +43,7 @@ func (c *FloorController) CreateFloor(ctx *gin.Context) {
 
 	floor, err := c.floorService.CreateFloor(ctx, req, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -51,34 +54,26 @@ func (c *FloorController) CreateFloor(ctx *gin.Context) {
 func (c *FloorController) GetFloors(ctx *gin.Context) {
 	mapID := ctx.Param("mapId")
 
-	floors, err := c.floorService.GetFloorsByMapID(ctx, mapID)
+	data, err := c.viewerService.GetMapData(ctx, ctx.GetString("userID"), mapID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondViewerError(ctx, err)
 		return
 	}
-	if floors == nil {
-		floors = []*models.Floor{}
-	}
 
-	ctx.JSON(http.StatusOK, floors)
+	ctx.JSON(http.StatusOK, data.Floors)
 }
 
 // GetFloorByID はIDによりフロアを取得する
 func (c *FloorController) GetFloorByID(ctx *gin.Context) {
 	floorID := ctx.Param("floorId")
 
-	floor, err := c.floorService.GetFloorByID(ctx, floorID)
+	data, err := c.viewerService.GetFloorData(ctx, ctx.GetString("userID"), floorID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondViewerError(ctx, err)
 		return
 	}
 
-	if floor == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "フロアが見つかりません"})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, floor)
+	ctx.JSON(http.StatusOK, data.Floors[0])
 }
 
 // UpdateFloorImage はフロアの画像を更新する
@@ -86,7 +81,7 @@ func (c *FloorController) UpdateFloorImage(ctx *gin.Context) {
 	floorID := ctx.Param("floorId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
@@ -96,7 +91,7 @@ func (c *FloorController) UpdateFloorImage(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "画像URLが必要です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
@@ -107,7 +102,7 @@ func (c *FloorController) UpdateFloorImage(ctx *gin.Context) {
 
 	floor, err := c.floorService.UpdateFloor(ctx, floorID, update, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -119,19 +114,19 @@ func (c *FloorController) UpdateFloor(ctx *gin.Context) {
 	floorID := ctx.Param("floorId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	var req models.FloorUpdate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	floor, err := c.floorService.UpdateFloor(ctx, floorID, req, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -143,13 +138,13 @@ func (c *FloorController) DeleteFloor(ctx *gin.Context) {
 	floorID := ctx.Param("floorId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	err := c.floorService.DeleteFloor(ctx, floorID, userID.(string))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -0,0 +1,120 @@
+// backend/controllers/geojson_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// GeoJSONController はGeoJSONの入出力に関するAPIエンドポイントを管理する
+type GeoJSONController struct {
+	geoJSONService services.GeoJSONService
+	floorService   services.FloorService
+	viewerService  services.ViewerService
+}
+
+// NewGeoJSONController は新しいGeoJSONControllerを作成する
+func NewGeoJSONController(
+	geoJSONService services.GeoJSONService,
+	floorService services.FloorService,
+	viewerService services.ViewerService,
+) *GeoJSONController {
+	return &GeoJSONController{
+		geoJSONService: geoJSONService,
+		floorService:   floorService,
+		viewerService:  viewerService,
+	}
+}
+
+// ExportFloor はフロアのピンをGeoJSONとして出力する
+func (c *GeoJSONController) ExportFloor(ctx *gin.Context) {
+	floorID := ctx.Param("floorId")
+
+	data, err := c.viewerService.GetFloorData(ctx, ctx.GetString("userID"), floorID)
+	if err != nil {
+		respondViewerError(ctx, err)
+		return
+	}
+
+	c.export(ctx, data)
+}
+
+// ExportMap はマップ全体のピンをGeoJSONとして出力する
+func (c *GeoJSONController) ExportMap(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+
+	data, err := c.viewerService.GetMapData(ctx, ctx.GetString("userID"), mapID)
+	if err != nil {
+		respondViewerError(ctx, err)
+		return
+	}
+
+	c.export(ctx, data)
+}
+
+// export は閲覧者に返すフロアとピンをGeoJSONとして出力する
+func (c *GeoJSONController) export(ctx *gin.Context, data *models.ViewerData) {
+	fc, err := c.geoJSONService.Export(ctx, data.Floors, data.Pins, ctx.Query("coords"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.Header("Content-Type", "application/geo+json; charset=utf-8")
+	ctx.JSON(http.StatusOK, fc)
+}
+
+// ImportFloor はGeoJSONからフロアにピンを取り込む
+func (c *GeoJSONController) ImportFloor(ctx *gin.Context) {
+	floorID := ctx.Param("floorId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	floor, err := c.floorService.GetFloorByID(ctx, floorID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if floor == nil {
+		i18n.RespondError(ctx, http.StatusNotFound, "フロアが見つかりません")
+		return
+	}
+
+	c.importCollection(ctx, userID.(string), floor.MapID, floorID)
+}
+
+// ImportMap はGeoJSONからマップにピンを取り込む（各Featureのfloor_idを使用）
+func (c *GeoJSONController) ImportMap(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	c.importCollection(ctx, userID.(string), mapID, "")
+}
+
+// importCollection はリクエストボディのFeatureCollectionを取り込む
+func (c *GeoJSONController) importCollection(ctx *gin.Context, userID, mapID, floorID string) {
+	var fc models.GeoJSONFeatureCollection
+	if err := ctx.ShouldBindJSON(&fc); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	result, err := c.geoJSONService.Import(ctx, userID, mapID, floorID, ctx.Query("coords"), &fc)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,114 @@
+// backend/controllers/georeference_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// GeoreferenceController はフロアの地理参照に関するAPIエンドポイントを管理する
+type GeoreferenceController struct {
+	georeferenceService services.GeoreferenceService
+	viewerService       services.ViewerService
+}
+
+// NewGeoreferenceController は新しいGeoreferenceControllerを作成する
+func NewGeoreferenceController(georeferenceService services.GeoreferenceService, viewerService services.ViewerService) *GeoreferenceController {
+	return &GeoreferenceController{
+		georeferenceService: georeferenceService,
+		viewerService:       viewerService,
+	}
+}
+
+// GetGeoreference はフロアの地理参照情報を取得する
+func (c *GeoreferenceController) GetGeoreference(ctx *gin.Context) {
+	floorID := ctx.Param("floorId")
+
+	// 閲覧できないフロアは存在しないものとして扱う
+	if _, err := c.viewerService.GetFloorData(ctx, ctx.GetString("userID"), floorID); err != nil {
+		respondViewerError(ctx, err)
+		return
+	}
+
+	geo, err := c.georeferenceService.GetByFloorID(ctx, floorID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if geo == nil {
+		i18n.RespondError(ctx, http.StatusNotFound, "このフロアは位置情報が設定されていません")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, geo)
+}
+
+// UpdateGeoreference は対応点からフロアの地理参照情報を設定する
+func (c *GeoreferenceController) UpdateGeoreference(ctx *gin.Context) {
+	floorID := ctx.Param("floorId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.FloorGeoreferenceUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	geo, err := c.georeferenceService.Calibrate(ctx, userID.(string), floorID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, geo)
+}
+
+// DeleteGeoreference はフロアの地理参照情報を削除する
+func (c *GeoreferenceController) DeleteGeoreference(ctx *gin.Context) {
+	floorID := ctx.Param("floorId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.georeferenceService.Delete(ctx, userID.(string), floorID); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "位置情報を削除しました", "floor_id": floorID})
+}
+
+// Locate はGPS位置をフロア座標に変換する
+func (c *GeoreferenceController) Locate(ctx *gin.Context) {
+	floorID := ctx.Param("floorId")
+
+	var req models.GeoLocate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	if _, err := c.viewerService.GetFloorData(ctx, ctx.GetString("userID"), floorID); err != nil {
+		respondViewerError(ctx, err)
+		return
+	}
+
+	result, err := c.georeferenceService.Locate(ctx, floorID, req.Latitude, req.Longitude)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
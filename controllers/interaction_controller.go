@@ -0,0 +1,294 @@
+// backend/controllers/interaction_controller.go
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// InteractionController はピンへのコメント・リアクションに関するAPIエンドポイントを管理する
+type InteractionController struct {
+	interactionService services.InteractionService
+}
+
+// NewInteractionController は新しいInteractionControllerを作成する
+func NewInteractionController(interactionService services.InteractionService) *InteractionController {
+	return &InteractionController{
+		interactionService: interactionService,
+	}
+}
+
+// IssueVisitor は匿名の来場者トークンを発行する
+func (c *InteractionController) IssueVisitor(ctx *gin.Context) {
+	visitor, err := c.interactionService.IssueVisitor(ctx.ClientIP())
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, visitor)
+}
+
+// GetComments はピンのコメントを取得する
+func (c *InteractionController) GetComments(ctx *gin.Context) {
+	pinID := ctx.Param("pinId")
+
+	author, ok := c.author(ctx)
+	if !ok {
+		return
+	}
+
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "無効なリクエストです")
+		return
+	}
+
+	page, err := c.interactionService.GetComments(ctx, ctx.GetString("userID"), author, pinID, ctx.Query("cursor"), limit)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// CreateComment はピンにコメントを投稿する
+func (c *InteractionController) CreateComment(ctx *gin.Context) {
+	pinID := ctx.Param("pinId")
+
+	author, ok := c.author(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.PinCommentCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	comment, err := c.interactionService.CreateComment(ctx, author, pinID, &req)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, comment)
+}
+
+// DeleteComment はコメントを削除する（投稿者本人またはマップの所有者）
+func (c *InteractionController) DeleteComment(ctx *gin.Context) {
+	commentID := ctx.Param("commentId")
+
+	author, ok := c.author(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.interactionService.DeleteComment(ctx, ctx.GetString("userID"), author, commentID); err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "コメントを削除しました", "id": commentID})
+}
+
+// ModerateComment はコメントを表示・非表示に切り替える
+func (c *InteractionController) ModerateComment(ctx *gin.Context) {
+	commentID := ctx.Param("commentId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.PinCommentModerate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	comment, err := c.interactionService.ModerateComment(ctx, userID.(string), commentID, &req)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comment)
+}
+
+// GetMapComments はマップ全体のコメントを取得する（?status=visible|hidden）
+func (c *InteractionController) GetMapComments(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "無効なリクエストです")
+		return
+	}
+
+	page, err := c.interactionService.GetMapComments(ctx, userID.(string), mapID, ctx.Query("status"), ctx.Query("cursor"), limit)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetReactions はピンのリアクションを取得する
+func (c *InteractionController) GetReactions(ctx *gin.Context) {
+	pinID := ctx.Param("pinId")
+
+	author, ok := c.author(ctx)
+	if !ok {
+		return
+	}
+
+	summary, err := c.interactionService.GetReactions(ctx, author, pinID)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// AddReaction はピンにリアクションを付ける
+func (c *InteractionController) AddReaction(ctx *gin.Context) {
+	pinID := ctx.Param("pinId")
+
+	author, ok := c.author(ctx)
+	if !ok {
+		return
+	}
+
+	var req models.PinReactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	summary, err := c.interactionService.AddReaction(ctx, author, pinID, req.Emoji)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// RemoveReaction はピンに付けたリアクションを取り消す
+func (c *InteractionController) RemoveReaction(ctx *gin.Context) {
+	pinID := ctx.Param("pinId")
+
+	author, ok := c.author(ctx)
+	if !ok {
+		return
+	}
+
+	summary, err := c.interactionService.RemoveReaction(ctx, author, pinID, ctx.Param("emoji"))
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// GetSettings はマップのコメント・リアクションの設定を取得する
+func (c *InteractionController) GetSettings(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	settings, err := c.interactionService.GetSettings(ctx, userID.(string), mapID)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings はマップのコメント・リアクションの設定を更新する
+func (c *InteractionController) UpdateSettings(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.MapInteractionSettingsUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	settings, err := c.interactionService.UpdateSettings(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// author はログインユーザーまたはX-Visitor-Tokenヘッダーから投稿者を特定する
+// 来場者トークンが不正な場合はエラーを返してfalseとなる
+func (c *InteractionController) author(ctx *gin.Context) (*models.Author, bool) {
+	author, err := c.interactionService.ResolveAuthor(ctx, ctx.GetString("userID"), ctx.GetHeader("X-Visitor-Token"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusUnauthorized, err.Error())
+		return nil, false
+	}
+	return author, true
+}
+
+// respondServiceError はサービスのエラーに応じたステータスコードで返す
+// 回数制限の場合はRetry-Afterヘッダーを付与する
+func respondServiceError(ctx *gin.Context, err error) {
+	var rateLimitErr *services.RateLimitError
+	var filterErr *services.ContentFilterError
+	switch {
+	case errors.As(err, &rateLimitErr):
+		ctx.Header("Retry-After", utils.RetryAfterSeconds(rateLimitErr.RetryAfter))
+		i18n.RespondError(ctx, http.StatusTooManyRequests, err.Error())
+	case errors.As(err, &filterErr):
+		i18n.RespondErrorOf(ctx, http.StatusUnprocessableEntity, err)
+	case errors.Is(err, services.ErrMapNotFound):
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrAuthorRequired):
+		i18n.RespondError(ctx, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, services.ErrCommentsDisabled), errors.Is(err, services.ErrReactionsDisabled):
+		i18n.RespondError(ctx, http.StatusForbidden, err.Error())
+	default:
+		i18n.RespondErrorOf(ctx, http.StatusInternalServerError, err)
+	}
+}
+
+// queryInt はクエリパラメータを整数として取得する（未指定の場合は0）
+func queryInt(ctx *gin.Context, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
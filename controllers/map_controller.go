@@ -5,33 +5,41 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/services"
 )
 
 // MapController マップコントローラー
 type MapController struct {
-	mapService services.MapService
+	mapService   services.MapService
+	auditService services.AuditService
 }
 
 // NewMapController 新しいマップコントローラーを作成
-func NewMapController(mapService services.MapService) *MapController {
+func NewMapController(mapService services.MapService, auditService services.AuditService) *MapController {
 	return &MapController{
-		mapService: mapService,
+		mapService:   mapService,
+		auditService: auditService,
 	}
 }
 
 // GetMaps ユーザーのマップ一覧取得ハンドラー
+// 個人のマップと所属する組織のマップを返す（?organization_id=で組織を絞り込める）
 func (c *MapController) GetMaps(ctx *gin.Context) {
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
-	maps, err := c.mapService.GetMapsByUserID(ctx, userID.(string))
+	maps, err := c.mapService.GetMaps(ctx, userID.(string), ctx.Query("organization_id"))
+	if err == services.ErrMapOrganizationNotFound {
+		respondMapError(ctx, err)
+		return
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
 		return
 	}
 	if maps == nil {
@@ -46,26 +54,31 @@ func (c *MapController) GetMapByID(ctx *gin.Context) {
 	mapID := ctx.Param("mapId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	// マップを取得
 	m, err := c.mapService.GetMapByID(ctx, mapID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
 		return
 	}
 	if m == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "マップが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
 		return
 	}
 
-	// マップの所有者を確認
-	if m.UserID != userID.(string) {
+	// マップの所有者・組織のメンバーを確認
+	canEdit, err := c.mapService.CanEdit(ctx, m, userID.(string))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if !canEdit {
 		userRole, exists := ctx.Get("userRole")
 		if !exists || userRole.(string) != "admin" {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "このマップにアクセスする権限がありません"})
+			i18n.RespondError(ctx, http.StatusForbidden, "このマップにアクセスする権限がありません")
 			return
 		}
 	}
@@ -77,13 +90,13 @@ func (c *MapController) GetMapByID(ctx *gin.Context) {
 func (c *MapController) CreateMap(ctx *gin.Context) {
 	var req models.MapCreate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
@@ -94,10 +107,21 @@ func (c *MapController) CreateMap(ctx *gin.Context) {
 		Description:        req.Description,
 		UserID:             userID.(string),
 		IsPubliclyEditable: req.IsPubliclyEditable,
+		Status:             req.Status,
+	}
+	if req.Slug != "" {
+		m.Slug = &req.Slug
+	}
+	if req.OrganizationID != "" {
+		m.OrganizationID = &req.OrganizationID
 	}
 
 	if err := c.mapService.CreateMap(ctx, m); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの作成に失敗しました"})
+		if err == services.ErrMapOrganizationNotFound {
+			respondMapError(ctx, err)
+			return
+		}
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの作成に失敗しました")
 		return
 	}
 
@@ -109,30 +133,35 @@ func (c *MapController) UpdateMap(ctx *gin.Context) {
 	mapID := ctx.Param("mapId")
 	var req models.MapUpdate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "入力データが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	// マップを取得
 	m, err := c.mapService.GetMapByID(ctx, mapID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
 		return
 	}
 	if m == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "マップが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
 		return
 	}
 
-	// マップの所有者を確認
-	if m.UserID != userID.(string) {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "このマップを編集する権限がありません"})
+	// マップの所有者・組織のメンバーを確認
+	canEdit, err := c.mapService.CanEdit(ctx, m, userID.(string))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if !canEdit {
+		i18n.RespondError(ctx, http.StatusForbidden, "このマップを編集する権限がありません")
 		return
 	}
 
@@ -141,10 +170,84 @@ func (c *MapController) UpdateMap(ctx *gin.Context) {
 		m.Title = req.Title
 	}
 	m.Description = req.Description
+	wasPubliclyEditable := m.IsPubliclyEditable
 	m.IsPubliclyEditable = req.IsPubliclyEditable
 
 	if err := c.mapService.UpdateMap(ctx, m); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの更新に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの更新に失敗しました")
+		return
+	}
+	if wasPubliclyEditable != m.IsPubliclyEditable {
+		recordAudit(ctx, c.auditService, &models.AuditLog{
+			Action:     models.AuditActionMapPublicEdit,
+			TargetType: models.AuditTargetMap,
+			TargetID:   m.ID,
+		}, gin.H{"is_publicly_editable": wasPubliclyEditable}, gin.H{"is_publicly_editable": m.IsPubliclyEditable})
+	}
+
+	ctx.JSON(http.StatusOK, m)
+}
+
+// UpdatePublication マップの公開状態・公開予約更新ハンドラー
+func (c *MapController) UpdatePublication(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	var req models.MapPublicationUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	m, err := c.mapService.UpdatePublication(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, m)
+}
+
+// CheckSlugAvailability スラッグの利用可否確認ハンドラー
+func (c *MapController) CheckSlugAvailability(ctx *gin.Context) {
+	slug := ctx.Query("slug")
+	if slug == "" {
+		i18n.RespondError(ctx, http.StatusBadRequest, "スラッグが必要です")
+		return
+	}
+
+	// 既存マップのスラッグ変更時はmapIdを指定する
+	availability, err := c.mapService.CheckSlugAvailability(ctx, ctx.Query("mapId"), slug)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "スラッグの確認に失敗しました")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
+
+// UpdateSlug マップのスラッグ更新ハンドラー
+func (c *MapController) UpdateSlug(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	var req models.MapSlugUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	m, err := c.mapService.UpdateSlug(ctx, userID.(string), mapID, req.Slug)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -156,34 +259,101 @@ func (c *MapController) DeleteMap(ctx *gin.Context) {
 	mapID := ctx.Param("mapId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	// マップを取得
 	m, err := c.mapService.GetMapByID(ctx, mapID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
 		return
 	}
 	if m == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "マップが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
 		return
 	}
 
-	// マップの所有者または管理者を確認
-	if m.UserID != userID.(string) {
+	// マップの所有者（組織のマップは組織の所有者・管理者）またはシステム管理者を確認
+	canManage, err := c.mapService.CanManage(ctx, m, userID.(string))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if !canManage {
 		userRole, exists := ctx.Get("userRole")
 		if !exists || userRole.(string) != "admin" {
-			ctx.JSON(http.StatusForbidden, gin.H{"error": "このマップを削除する権限がありません"})
+			i18n.RespondError(ctx, http.StatusForbidden, "このマップを削除する権限がありません")
 			return
 		}
 	}
 
 	if err := c.mapService.DeleteMap(ctx, mapID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの削除に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの削除に失敗しました")
 		return
 	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionMapDelete,
+		TargetType: models.AuditTargetMap,
+		TargetID:   m.ID,
+	}, m, nil)
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "マップが正常に削除されました"})
 }
+
+// TransferMap マップの所有者の移管ハンドラー
+// 卒業・引き継ぎに備えて、個人のマップを組織に移したり、別のユーザーに引き渡したりする
+func (c *MapController) TransferMap(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	var req models.MapTransfer
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	before, err := c.mapService.GetMapByID(ctx, mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if before == nil {
+		respondMapError(ctx, services.ErrMapNotFound)
+		return
+	}
+	previous := gin.H{"user_id": before.UserID, "organization_id": before.OrganizationID}
+
+	m, err := c.mapService.TransferMap(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		respondMapError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionMapTransfer,
+		TargetType: models.AuditTargetMap,
+		TargetID:   m.ID,
+	}, previous, gin.H{"user_id": m.UserID, "organization_id": m.OrganizationID})
+
+	ctx.JSON(http.StatusOK, m)
+}
+
+// respondMapError はマップの作成・移管のエラーを適切なステータスコードで返す
+func respondMapError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrMapNotFound, services.ErrMapOrganizationNotFound, services.ErrMapTransferUserNotFound:
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+	case services.ErrMapTransferForbidden, services.ErrMapTransferOrganization:
+		i18n.RespondError(ctx, http.StatusForbidden, err.Error())
+	case services.ErrMapTransferTarget:
+		i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+	case services.ErrMapTransferSameOwner:
+		i18n.RespondError(ctx, http.StatusConflict, err.Error())
+	default:
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
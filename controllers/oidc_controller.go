@@ -0,0 +1,162 @@
+// backend/controllers/oidc_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// OIDCController はOpenID Connectによる外部アカウントでのログインと連携のAPIエンドポイントを管理する
+type OIDCController struct {
+	oidcService      services.OIDCService
+	twoFactorService services.TwoFactorService
+	auditService     services.AuditService
+	jwtSecret        string
+}
+
+// NewOIDCController は新しいOIDCControllerを作成する
+func NewOIDCController(
+	oidcService services.OIDCService,
+	twoFactorService services.TwoFactorService,
+	auditService services.AuditService,
+	jwtSecret string,
+) *OIDCController {
+	return &OIDCController{
+		oidcService:      oidcService,
+		twoFactorService: twoFactorService,
+		auditService:     auditService,
+		jwtSecret:        jwtSecret,
+	}
+}
+
+// GetProviders はログインに使えるプロバイダーの一覧を取得する
+func (c *OIDCController) GetProviders(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.oidcService.Providers())
+}
+
+// StartLogin は外部アカウントでのログインを開始し、認可画面のURLを返す
+func (c *OIDCController) StartLogin(ctx *gin.Context) {
+	authorization, err := c.oidcService.Start(ctx, ctx.Param("provider"), "")
+	if err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, authorization)
+}
+
+// StartLink はログイン中のユーザーへの外部アカウントの連携を開始し、認可画面のURLを返す
+func (c *OIDCController) StartLink(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	authorization, err := c.oidcService.Start(ctx, ctx.Param("provider"), userID.(string))
+	if err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, authorization)
+}
+
+// Callback は認可後にフロントエンドが受け取ったstateと認可コードを処理する
+// ログインの場合は通常のログインと同じ形式で返し、連携の場合は連携したアカウントを返す
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	var req models.OIDCCallback
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	result, err := c.oidcService.Callback(ctx, &req)
+	if err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+
+	if result.Linked {
+		recordAudit(ctx, c.auditService, &models.AuditLog{
+			ActorID:    result.User.ID,
+			Action:     models.AuditActionIdentityLink,
+			TargetType: models.AuditTargetUser,
+			TargetID:   result.User.ID,
+		}, nil, gin.H{"provider": result.Identity.Provider, "email": result.Identity.Email})
+
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":  "外部アカウントを連携しました",
+			"identity": result.Identity,
+		})
+		return
+	}
+
+	completeLogin(ctx, c.twoFactorService, c.auditService, c.jwtSecret, result.User,
+		gin.H{"provider": result.Identity.Provider},
+		gin.H{"created": result.Created})
+}
+
+// GetIdentities はログイン中のユーザーが連携している外部アカウントの一覧を取得する
+func (c *OIDCController) GetIdentities(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	identities, err := c.oidcService.GetIdentities(ctx, userID.(string))
+	if err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"identities": identities,
+		"providers":  c.oidcService.Providers(),
+	})
+}
+
+// Unlink は外部アカウントとの連携を解除する
+func (c *OIDCController) Unlink(ctx *gin.Context) {
+	provider := ctx.Param("provider")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.oidcService.Unlink(ctx, userID.(string), provider); err != nil {
+		respondOIDCError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionIdentityUnlink,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.(string),
+	}, gin.H{"provider": provider}, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "外部アカウントの連携を解除しました", "provider": provider})
+}
+
+// respondOIDCError は外部アカウントでのログイン・連携のエラーを適切なステータスコードで返す
+func respondOIDCError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrOIDCProviderNotFound, services.ErrIdentityNotFound:
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+	case services.ErrOIDCStateInvalid, services.ErrOIDCVerificationFailed:
+		i18n.RespondError(ctx, http.StatusUnauthorized, err.Error())
+	case services.ErrOIDCEmailRequired, services.ErrOIDCEmailUnverified:
+		i18n.RespondError(ctx, http.StatusForbidden, err.Error())
+	case services.ErrIdentityLinkedToOther, services.ErrIdentityAlreadyLinked, services.ErrIdentityLastLogin:
+		i18n.RespondError(ctx, http.StatusConflict, err.Error())
+	case services.ErrOIDCProviderFailed:
+		i18n.RespondError(ctx, http.StatusBadGateway, err.Error())
+	default:
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
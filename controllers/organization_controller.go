@@ -0,0 +1,230 @@
+// backend/controllers/organization_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// OrganizationController は組織とメンバーに関するAPIエンドポイントを管理する
+type OrganizationController struct {
+	orgService   services.OrganizationService
+	auditService services.AuditService
+}
+
+// NewOrganizationController は新しいOrganizationControllerを作成する
+func NewOrganizationController(
+	orgService services.OrganizationService,
+	auditService services.AuditService,
+) *OrganizationController {
+	return &OrganizationController{
+		orgService:   orgService,
+		auditService: auditService,
+	}
+}
+
+// GetOrganizations はログイン中のユーザーが所属する組織の一覧を取得する
+func (c *OrganizationController) GetOrganizations(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	orgs, err := c.orgService.GetByUserID(ctx, userID.(string))
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orgs)
+}
+
+// CreateOrganization は新しい組織を作成する
+func (c *OrganizationController) CreateOrganization(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.OrganizationCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	org, err := c.orgService.Create(ctx, userID.(string), &req)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, org)
+}
+
+// GetOrganization は組織とメンバーの一覧を取得する
+func (c *OrganizationController) GetOrganization(ctx *gin.Context) {
+	orgID := ctx.Param("orgId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	org, err := c.orgService.Get(ctx, userID.(string), orgID)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, org)
+}
+
+// UpdateOrganization は組織の情報を更新する
+func (c *OrganizationController) UpdateOrganization(ctx *gin.Context) {
+	orgID := ctx.Param("orgId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.OrganizationUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	org, err := c.orgService.Update(ctx, userID.(string), orgID, &req)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganization は組織を削除する
+func (c *OrganizationController) DeleteOrganization(ctx *gin.Context) {
+	orgID := ctx.Param("orgId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.orgService.Delete(ctx, userID.(string), orgID); err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionOrgDelete,
+		TargetType: models.AuditTargetOrg,
+		TargetID:   orgID,
+	}, nil, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "組織を削除しました"})
+}
+
+// AddMember は組織にメンバーを追加する
+func (c *OrganizationController) AddMember(ctx *gin.Context) {
+	orgID := ctx.Param("orgId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.OrganizationMemberAdd
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	member, err := c.orgService.AddMember(ctx, userID.(string), orgID, &req)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionOrgMemberAdd,
+		TargetType: models.AuditTargetOrg,
+		TargetID:   orgID,
+	}, nil, gin.H{"user_id": member.UserID, "role": member.Role})
+
+	ctx.JSON(http.StatusCreated, member)
+}
+
+// UpdateMember はメンバーの役割を変更する
+func (c *OrganizationController) UpdateMember(ctx *gin.Context) {
+	orgID := ctx.Param("orgId")
+	memberID := ctx.Param("userId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.OrganizationMemberUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	member, err := c.orgService.UpdateMember(ctx, userID.(string), orgID, memberID, &req)
+	if err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionOrgMemberRole,
+		TargetType: models.AuditTargetOrg,
+		TargetID:   orgID,
+	}, nil, gin.H{"user_id": member.UserID, "role": member.Role})
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// RemoveMember はメンバーを組織から外す（自分自身を指定した場合は退会）
+func (c *OrganizationController) RemoveMember(ctx *gin.Context) {
+	orgID := ctx.Param("orgId")
+	memberID := ctx.Param("userId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.orgService.RemoveMember(ctx, userID.(string), orgID, memberID); err != nil {
+		respondOrganizationError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionOrgMemberRemove,
+		TargetType: models.AuditTargetOrg,
+		TargetID:   orgID,
+	}, gin.H{"user_id": memberID}, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "メンバーを組織から外しました"})
+}
+
+// respondOrganizationError は組織の操作のエラーを適切なステータスコードで返す
+func respondOrganizationError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrOrganizationNameRequired:
+		i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+	case services.ErrOrganizationNotFound, services.ErrOrganizationUserNotFound, services.ErrOrganizationMemberNotFound:
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+	case services.ErrOrganizationForbidden, services.ErrOrganizationOwnerRequired:
+		i18n.RespondError(ctx, http.StatusForbidden, err.Error())
+	case services.ErrOrganizationHasMaps, services.ErrOrganizationMemberExists, services.ErrOrganizationLastOwner:
+		i18n.RespondError(ctx, http.StatusConflict, err.Error())
+	default:
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,96 @@
+// backend/controllers/pdf_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// PDFController は印刷用パンフレットPDFに関するAPIエンドポイントを管理する
+type PDFController struct {
+	pdfService services.PDFService
+}
+
+// NewPDFController は新しいPDFControllerを作成する
+func NewPDFController(pdfService services.PDFService) *PDFController {
+	return &PDFController{
+		pdfService: pdfService,
+	}
+}
+
+// GenerateMapPDF はマップのPDF生成ジョブを登録する
+func (c *PDFController) GenerateMapPDF(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	job, err := c.pdfService.Enqueue(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"job":          job,
+		"status_url":   "/api/pdf-jobs/" + job.ID,
+		"download_url": "/api/pdf-jobs/" + job.ID + "/download",
+	})
+}
+
+// GetJob はPDF生成ジョブの状態を取得する
+func (c *PDFController) GetJob(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	job, err := c.pdfService.GetJob(ctx, userID.(string), jobID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if job == nil {
+		i18n.RespondError(ctx, http.StatusNotFound, "ジョブが見つかりません")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// Download は生成済みのPDFをダウンロードする
+func (c *PDFController) Download(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	job, err := c.pdfService.GetJob(ctx, userID.(string), jobID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if job == nil {
+		i18n.RespondError(ctx, http.StatusNotFound, "ジョブが見つかりません")
+		return
+	}
+
+	if job.Status != models.JobStatusCompleted {
+		i18n.RespondError(ctx, http.StatusConflict, "PDFはまだ生成されていません", gin.H{"status": job.Status})
+		return
+	}
+
+	ctx.FileAttachment(job.FilePath, "pamphlet-"+job.MapID+".pdf")
+}
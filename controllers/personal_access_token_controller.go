@@ -0,0 +1,113 @@
+// backend/controllers/personal_access_token_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// PersonalAccessTokenController は個人用アクセストークンに関するAPIエンドポイントを管理する
+type PersonalAccessTokenController struct {
+	tokenService services.PersonalAccessTokenService
+	auditService services.AuditService
+}
+
+// NewPersonalAccessTokenController は新しいPersonalAccessTokenControllerを作成する
+func NewPersonalAccessTokenController(
+	tokenService services.PersonalAccessTokenService,
+	auditService services.AuditService,
+) *PersonalAccessTokenController {
+	return &PersonalAccessTokenController{
+		tokenService: tokenService,
+		auditService: auditService,
+	}
+}
+
+// GetTokens はログイン中のユーザーの個人用アクセストークン一覧を取得する
+func (c *PersonalAccessTokenController) GetTokens(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	tokens, err := c.tokenService.GetByUserID(ctx, userID.(string))
+	if err != nil {
+		respondAccessTokenError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"tokens": tokens,
+		"scopes": models.PersonalAccessTokenScopes,
+	})
+}
+
+// CreateToken は新しい個人用アクセストークンを作成する
+// トークン本体はこのレスポンスでしか返さない
+func (c *PersonalAccessTokenController) CreateToken(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.PersonalAccessTokenCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	token, err := c.tokenService.Create(ctx, userID.(string), &req)
+	if err != nil {
+		respondAccessTokenError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionTokenCreate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.(string),
+	}, nil, gin.H{"token_id": token.ID, "name": token.Name, "scopes": token.Scopes, "expires_at": token.ExpiresAt})
+
+	ctx.JSON(http.StatusCreated, token)
+}
+
+// RevokeToken は個人用アクセストークンを無効化する
+func (c *PersonalAccessTokenController) RevokeToken(ctx *gin.Context) {
+	tokenID := ctx.Param("tokenId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.tokenService.Revoke(ctx, userID.(string), tokenID); err != nil {
+		respondAccessTokenError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionTokenRevoke,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.(string),
+	}, gin.H{"token_id": tokenID}, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "アクセストークンを無効化しました"})
+}
+
+// respondAccessTokenError は個人用アクセストークンの操作のエラーを適切なステータスコードで返す
+func respondAccessTokenError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrAccessTokenNameRequired:
+		i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+	case services.ErrAccessTokenNotFound:
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+	case services.ErrAccessTokenLimit:
+		i18n.RespondError(ctx, http.StatusConflict, err.Error())
+	default:
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -5,19 +5,22 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/services"
 )
 
 // PinController はピン関連のAPIエンドポイントを管理する
 type PinController struct {
-	pinService services.PinService
+	pinService    services.PinService
+	viewerService services.ViewerService
 }
 
 // NewPinController は新しいPinControllerを作成する
-func NewPinController(pinService services.PinService) *PinController {
+func NewPinController(pinService services.PinService, viewerService services.ViewerService) *PinController {
 	return &PinController{
-		pinService: pinService,
+		pinService:    pinService,
+		viewerService: viewerService,
 	}
 }
 
@@ -26,13 +29,13 @@ func (c *PinController) CreatePin(ctx *gin.Context) {
 	floorID := ctx.Param("floorId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	var req models.PinCreate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
@@ -40,7 +43,7 @@ func (c *PinController) CreatePin(ctx *gin.Context) {
 
 	pin, err := c.pinService.Create(ctx, userID.(string), &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -51,30 +54,22 @@ func (c *PinController) CreatePin(ctx *gin.Context) {
 func (c *PinController) GetPinsByFloorID(ctx *gin.Context) {
 	floorID := ctx.Param("floorId")
 
-	pins, err := c.pinService.GetByFloorID(ctx, floorID)
+	data, err := c.viewerService.GetFloorData(ctx, ctx.GetString("userID"), floorID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondViewerError(ctx, err)
 		return
 	}
-	if pins == nil {
-		pins = []*models.Pin{}
-	}
 
-	ctx.JSON(http.StatusOK, pins)
+	ctx.JSON(http.StatusOK, data.Pins)
 }
 
 // GetPinByID はIDによりピンを取得する
 func (c *PinController) GetPinByID(ctx *gin.Context) {
 	pinID := ctx.Param("pinId")
 
-	pin, err := c.pinService.GetByID(ctx, pinID)
+	pin, err := c.viewerService.GetPin(ctx, ctx.GetString("userID"), pinID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	if pin == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "ピンが見つかりません"})
+		respondViewerError(ctx, err)
 		return
 	}
 
@@ -86,19 +81,19 @@ func (c *PinController) UpdatePin(ctx *gin.Context) {
 	pinID := ctx.Param("pinId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	var req models.PinUpdate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	pin, err := c.pinService.Update(ctx, userID.(string), pinID, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -110,13 +105,13 @@ func (c *PinController) DeletePin(ctx *gin.Context) {
 	pinID := ctx.Param("pinId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
 	err := c.pinService.Delete(ctx, userID.(string), pinID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -128,7 +123,7 @@ func (c *PinController) UpdatePinImage(ctx *gin.Context) {
 	pinID := ctx.Param("pinId")
 	userID, exists := ctx.Get("userID")
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
 		return
 	}
 
@@ -138,7 +133,7 @@ func (c *PinController) UpdatePinImage(ctx *gin.Context) {
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "画像URLが必要です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
@@ -149,7 +144,7 @@ func (c *PinController) UpdatePinImage(ctx *gin.Context) {
 
 	pin, err := c.pinService.Update(ctx, userID.(string), pinID, update)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -157,22 +152,21 @@ func (c *PinController) UpdatePinImage(ctx *gin.Context) {
 }
 
 // CreatePublicPin は公開編集で新しいピンを作成する
+// 編集者はPublicEditorMiddlewareで検証する
 func (c *PinController) CreatePublicPin(ctx *gin.Context) {
 	var req models.PinCreate
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
-	// 必須フィールドの確認
-	if req.FloorID == "" || req.Title == "" || req.EditorID == "" || req.EditorNickname == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "フロアID、タイトル、編集者情報は必須です"})
-		return
-	}
+	// 編集者はボディではなく検証済みの編集者を使う
+	req.EditorID = ctx.GetString("editorID")
+	req.EditorNickname = ctx.GetString("editorNickname")
 
 	pin, err := c.pinService.CreatePublic(ctx, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondServiceError(ctx, err)
 		return
 	}
 
@@ -180,17 +174,17 @@ func (c *PinController) CreatePublicPin(ctx *gin.Context) {
 }
 
 // UpdatePublicPin は公開編集でピン情報を更新する
+// 編集者はPublicEditorMiddlewareで検証する
 func (c *PinController) UpdatePublicPin(ctx *gin.Context) {
 	pinID := ctx.Param("pinId")
 
 	var req struct {
 		Title       string `json:"title" binding:"required"`
 		Description string `json:"description"`
-		EditorID    string `json:"editorId" binding:"required"`
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "無効なリクエストです"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
@@ -199,9 +193,9 @@ func (c *PinController) UpdatePublicPin(ctx *gin.Context) {
 		Description: req.Description,
 	}
 
-	pin, err := c.pinService.UpdatePublic(ctx, req.EditorID, pinID, update)
+	pin, err := c.pinService.UpdatePublic(ctx, ctx.GetString("editorID"), pinID, update)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondServiceError(ctx, err)
 		return
 	}
 
@@ -209,18 +203,13 @@ func (c *PinController) UpdatePublicPin(ctx *gin.Context) {
 }
 
 // DeletePublicPin は公開編集でピンを削除する
+// 編集者はPublicEditorMiddlewareで検証する
 func (c *PinController) DeletePublicPin(ctx *gin.Context) {
 	pinID := ctx.Param("pinId")
-	editorID := ctx.Query("editorId")
-
-	if editorID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "編集者IDは必須です"})
-		return
-	}
 
-	err := c.pinService.DeletePublic(ctx, editorID, pinID)
+	err := c.pinService.DeletePublic(ctx, ctx.GetString("editorID"), pinID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondServiceError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,129 @@
+// backend/controllers/pin_spreadsheet_controller.go
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/services"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// maxImportFileSize はインポートファイルの最大サイズ（5MB）
+const maxImportFileSize = 5 << 20
+
+// PinSpreadsheetController はピンのCSV/XLSX入出力に関するAPIエンドポイントを管理する
+type PinSpreadsheetController struct {
+	pinSpreadsheetService services.PinSpreadsheetService
+}
+
+// NewPinSpreadsheetController は新しいPinSpreadsheetControllerを作成する
+func NewPinSpreadsheetController(pinSpreadsheetService services.PinSpreadsheetService) *PinSpreadsheetController {
+	return &PinSpreadsheetController{
+		pinSpreadsheetService: pinSpreadsheetService,
+	}
+}
+
+// ExportPins はマップまたはフロアのピンをCSV/XLSXで出力する
+func (c *PinSpreadsheetController) ExportPins(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		i18n.RespondError(ctx, http.StatusBadRequest, "形式はcsvまたはxlsxを指定してください")
+		return
+	}
+
+	rows, err := c.pinSpreadsheetService.Export(ctx, userID.(string), mapID, ctx.Query("floorId"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	var data []byte
+	contentType := "text/csv; charset=utf-8"
+	if format == "xlsx" {
+		data, err = utils.WriteXLSX("pins", rows)
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	} else {
+		data, err = utils.WriteCSV(rows)
+	}
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ファイルの生成に失敗しました")
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="pins-`+mapID+`.`+format+`"`)
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// ImportPins はCSV/XLSXからピンを一括で作成または更新する
+func (c *PinSpreadsheetController) ImportPins(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	file, header, err := ctx.Request.FormFile("file")
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "ファイルが必要です")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileSize+1))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "ファイルの読み込みに失敗しました")
+		return
+	}
+	if len(data) > maxImportFileSize {
+		i18n.RespondError(ctx, http.StatusBadRequest, "ファイルサイズが大きすぎます")
+		return
+	}
+
+	// 形式は拡張子から判定（formatクエリで上書き可能）
+	format := ctx.Query("format")
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	}
+
+	var rows [][]string
+	switch format {
+	case "csv":
+		rows, err = utils.ReadCSV(data)
+	case "xlsx":
+		rows, err = utils.ReadXLSX(data)
+	default:
+		i18n.RespondError(ctx, http.StatusBadRequest, "CSVまたはXLSXファイルを指定してください")
+		return
+	}
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "ファイルの解析に失敗しました")
+		return
+	}
+
+	dryRun := ctx.Query("dry_run") == "true"
+	result, err := c.pinSpreadsheetService.Import(ctx, userID.(string), mapID, rows, dryRun)
+	if err != nil {
+		i18n.RespondErrorOf(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
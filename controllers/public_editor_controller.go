@@ -2,9 +2,13 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"net"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/services"
 )
@@ -30,46 +34,79 @@ func NewPublicEditorController(
 func (c *PublicEditorController) Register(ctx *gin.Context) {
 	var req models.PublicEditorRegister
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "リクエストが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	// マップが存在するか確認
 	mapData, err := c.mapService.GetMapByID(ctx, req.MapID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
 		return
 	}
 	if mapData == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "マップが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
+		return
+	}
+
+	// 公開中でないマップは存在しないものとして扱う
+	canView, err := c.mapService.CanView(ctx, mapData, "")
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if !canView {
+		i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
 		return
 	}
 
 	// マップが公開編集可能か確認
 	if !mapData.IsPubliclyEditable {
-		ctx.JSON(http.StatusForbidden, gin.H{"error": "このマップは公開編集が許可されていません"})
+		i18n.RespondError(ctx, http.StatusForbidden, "このマップは公開編集が許可されていません")
 		return
 	}
 
 	// 編集者を登録
-	editor, err := c.publicEditorService.Register(ctx, req.MapID, req.Nickname)
+	fingerprint := &models.ClientFingerprint{
+		Network: networkFingerprint(ctx.ClientIP()),
+		Device:  req.Fingerprint,
+	}
+	editor, err := c.publicEditorService.Register(ctx, req.MapID, req.Nickname, req.InviteCode, fingerprint)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "編集者の登録に失敗しました"})
+		var filterErr *services.ContentFilterError
+		if errors.As(err, &filterErr) {
+			respondServiceError(ctx, err)
+			return
+		}
+		if errors.Is(err, services.ErrPublicEditorBanned) ||
+			errors.Is(err, services.ErrInviteCodeRequired) ||
+			errors.Is(err, services.ErrInviteCodeInvalid) {
+			i18n.RespondError(ctx, http.StatusForbidden, err.Error())
+			return
+		}
+		i18n.RespondError(ctx, http.StatusInternalServerError, "編集者の登録に失敗しました")
 		return
 	}
 
 	if editor == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "編集者が見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "編集者が見つかりません")
+		return
+	}
+
+	permissions, err := c.publicEditorService.GetEditorPermissions(ctx, editor)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	// レスポンスを構築
 	response := models.PublicEditorResponse{
-		EditorID: editor.ID,
-		Nickname: editor.Nickname,
-		Token:    editor.EditorToken,
-		MapID:    editor.MapID,
-		Verified: true,
+		EditorID:    editor.ID,
+		Nickname:    editor.Nickname,
+		Token:       editor.EditorToken,
+		MapID:       editor.MapID,
+		Verified:    true,
+		Permissions: permissions,
 	}
 
 	ctx.JSON(http.StatusCreated, response)
@@ -79,30 +116,208 @@ func (c *PublicEditorController) Register(ctx *gin.Context) {
 func (c *PublicEditorController) Verify(ctx *gin.Context) {
 	var req models.PublicEditorVerify
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "リクエストが不正です"})
+		i18n.RespondBindingError(ctx, err)
 		return
 	}
 
 	// トークンを検証
 	editor, err := c.publicEditorService.Verify(ctx, req.EditorID, req.Token)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "無効なトークンです", "verified": false})
+		i18n.RespondError(ctx, http.StatusUnauthorized, "無効なトークンです", gin.H{"verified": false})
 		return
 	}
 
 	// 最終アクティブ時間を更新
 	if err := c.publicEditorService.UpdateLastActive(ctx, req.EditorID); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "更新に失敗しました", "verified": false})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "更新に失敗しました", gin.H{"verified": false})
+		return
+	}
+
+	permissions, err := c.publicEditorService.GetEditorPermissions(ctx, editor)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error(), gin.H{"verified": false})
 		return
 	}
 
 	// レスポンスを構築
 	response := models.PublicEditorResponse{
-		EditorID: editor.ID,
-		Nickname: editor.Nickname,
-		MapID:    editor.MapID,
-		Verified: true,
+		EditorID:    editor.ID,
+		Nickname:    editor.Nickname,
+		MapID:       editor.MapID,
+		Verified:    true,
+		Permissions: permissions,
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// GetEditors はマップの公開編集者を活動状況と共に取得する
+func (c *PublicEditorController) GetEditors(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	summaries, err := c.publicEditorService.GetSummaries(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, summaries)
+}
+
+// RevokeEditor は公開編集者の編集権限を取り消す
+func (c *PublicEditorController) RevokeEditor(ctx *gin.Context) {
+	c.manageEditor(ctx, c.publicEditorService.Revoke)
+}
+
+// BanEditor は公開編集者の編集を禁止する
+func (c *PublicEditorController) BanEditor(ctx *gin.Context) {
+	c.manageEditor(ctx, c.publicEditorService.Ban)
+}
+
+// UnbanEditor は公開編集者の編集禁止を解除する
+func (c *PublicEditorController) UnbanEditor(ctx *gin.Context) {
+	c.manageEditor(ctx, c.publicEditorService.Unban)
+}
+
+// GetPermissions はマップの公開編集者の権限を取得する
+func (c *PublicEditorController) GetPermissions(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	permissions, err := c.publicEditorService.GetMapPermissions(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, permissions)
+}
+
+// UpdatePermissions はマップの公開編集者の権限を更新する
+func (c *PublicEditorController) UpdatePermissions(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.PublicEditPermissionsUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	permissions, err := c.publicEditorService.UpdatePermissions(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, permissions)
+}
+
+// CreateInvite は公開編集者の招待コードを作成する
+func (c *PublicEditorController) CreateInvite(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	// すべて任意項目のため、ボディが空でも受け付ける
+	var req models.PublicEditInviteCreate
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			i18n.RespondBindingError(ctx, err)
+			return
+		}
+	}
+
+	invite, err := c.publicEditorService.CreateInvite(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, invite)
+}
+
+// GetInvites はマップの招待コード一覧を取得する
+func (c *PublicEditorController) GetInvites(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	invites, err := c.publicEditorService.GetInvites(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, invites)
+}
+
+// RevokeInvite は招待コードを無効化し、そのコードで登録した編集者の編集権限を取り消す
+func (c *PublicEditorController) RevokeInvite(ctx *gin.Context) {
+	inviteID := ctx.Param("inviteId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	revoked, err := c.publicEditorService.RevokeInvite(ctx, userID.(string), inviteID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "招待コードを無効化しました", "id": inviteID, "revoked_editors": revoked})
+}
+
+// manageEditor は所有者による公開編集者への操作を実行し、更新後の編集者を返す
+func (c *PublicEditorController) manageEditor(
+	ctx *gin.Context,
+	action func(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error),
+) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	editor, err := action(ctx, userID.(string), ctx.Param("mapId"), ctx.Param("editorId"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"editor": editor, "status": editor.Status()})
+}
+
+// networkFingerprint は編集禁止の判定に使うネットワークの識別子を返す
+// User-Agentは自由に変えられるため含めず、IPv6は利用者が自由に選べる/64の範囲をまとめて扱う
+func networkFingerprint(clientIP string) string {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return clientIP
+	}
+	if ip.To4() != nil {
+		return ip.String()
+	}
+	return ip.Mask(net.CIDRMask(64, 128)).String() + "/64"
+}
@@ -0,0 +1,201 @@
+// backend/controllers/qr_controller.go
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+	"github.com/shimaf4979/pamfree-backend/utils"
+	"github.com/skip2/go-qrcode"
+)
+
+// QRコード画像サイズの範囲（ピクセル）
+const (
+	minQRSize     = 64
+	maxQRSize     = 2048
+	defaultQRSize = 256
+)
+
+// QRController はQRコード生成に関するAPIエンドポイントを管理する
+type QRController struct {
+	qrService   services.QRService
+	pdfFontPath string
+}
+
+// NewQRController は新しいQRControllerを作成する
+func NewQRController(qrService services.QRService, pdfFontPath string) *QRController {
+	return &QRController{
+		qrService:   qrService,
+		pdfFontPath: pdfFontPath,
+	}
+}
+
+// GetMapQR はマップを開くQRコードを生成する
+func (c *QRController) GetMapQR(ctx *gin.Context) {
+	c.renderTarget(ctx, models.QRTargetMap, ctx.Param("mapId"))
+}
+
+// GetFloorQR はフロアを開くQRコードを生成する
+func (c *QRController) GetFloorQR(ctx *gin.Context) {
+	c.renderTarget(ctx, models.QRTargetFloor, ctx.Param("floorId"))
+}
+
+// GetPinQR はピンを開くQRコードを生成する
+func (c *QRController) GetPinQR(ctx *gin.Context) {
+	c.renderTarget(ctx, models.QRTargetPin, ctx.Param("pinId"))
+}
+
+// GetMapPinQRBatch はマップ内の全ピンのQRコードをZIPまたは印刷用PDFでまとめて生成する
+func (c *QRController) GetMapPinQRBatch(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	size, level, ok := c.parseOptions(ctx)
+	if !ok {
+		return
+	}
+
+	map_, targets, err := c.qrService.GetPinTargets(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	labels := make([]utils.QRLabel, len(targets))
+	for i, target := range targets {
+		png, err := utils.QRCodePNG(target.URL, size, level)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "QRコードの生成に失敗しました")
+			return
+		}
+		labels[i] = utils.QRLabel{Label: target.Label, PNG: png}
+	}
+
+	var buf bytes.Buffer
+	switch ctx.DefaultQuery("format", "zip") {
+	case "zip":
+		if err := writeQRZip(&buf, targets, labels); err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "ZIPファイルの生成に失敗しました")
+			return
+		}
+		ctx.Header("Content-Disposition", `attachment; filename="qr-`+mapID+`.zip"`)
+		ctx.Data(http.StatusOK, "application/zip", buf.Bytes())
+	case "pdf":
+		if err := utils.RenderQRSheet(&buf, c.pdfFontPath, map_.Title, labels); err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "PDFの生成に失敗しました")
+			return
+		}
+		ctx.Header("Content-Disposition", `attachment; filename="qr-`+mapID+`.pdf"`)
+		ctx.Data(http.StatusOK, "application/pdf", buf.Bytes())
+	default:
+		i18n.RespondError(ctx, http.StatusBadRequest, "形式はzipまたはpdfを指定してください")
+	}
+}
+
+// renderTarget は対象へのリンクをPNGまたはSVGのQRコードとして返す
+func (c *QRController) renderTarget(ctx *gin.Context, targetType, id string) {
+	size, level, ok := c.parseOptions(ctx)
+	if !ok {
+		return
+	}
+
+	target, err := c.qrService.GetTarget(ctx, ctx.GetString("userID"), targetType, id)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+		return
+	}
+
+	switch ctx.DefaultQuery("format", "png") {
+	case "png":
+		data, err := utils.QRCodePNG(target.URL, size, level)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "QRコードの生成に失敗しました")
+			return
+		}
+		ctx.Data(http.StatusOK, "image/png", data)
+	case "svg":
+		data, err := utils.QRCodeSVG(target.URL, size, level)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "QRコードの生成に失敗しました")
+			return
+		}
+		ctx.Data(http.StatusOK, "image/svg+xml", data)
+	default:
+		i18n.RespondError(ctx, http.StatusBadRequest, "形式はpngまたはsvgを指定してください")
+	}
+}
+
+// parseOptions はサイズと誤り訂正レベルのクエリを解析する
+func (c *QRController) parseOptions(ctx *gin.Context) (int, qrcode.RecoveryLevel, bool) {
+	size := defaultQRSize
+	if value := ctx.Query("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < minQRSize || parsed > maxQRSize {
+			i18n.RespondErrorf(ctx, http.StatusBadRequest, "サイズは%d〜%dの範囲で指定してください", minQRSize, maxQRSize)
+			return 0, 0, false
+		}
+		size = parsed
+	}
+
+	level, err := utils.ParseQRLevel(ctx.Query("level"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+		return 0, 0, false
+	}
+
+	return size, level, true
+}
+
+// writeQRZip はQRコード画像と一覧CSVをZIPにまとめる
+func writeQRZip(buf *bytes.Buffer, targets []*models.QRTarget, labels []utils.QRLabel) error {
+	zw := zip.NewWriter(buf)
+
+	index := [][]string{{"number", "label", "url", "file"}}
+	for i, label := range labels {
+		name := fmt.Sprintf("%03d_%s.png", i+1, sanitizeFileName(label.Label))
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(label.PNG); err != nil {
+			return err
+		}
+		index = append(index, []string{strconv.Itoa(i + 1), label.Label, targets[i].URL, name})
+	}
+
+	csvData, err := utils.WriteCSV(index)
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create("index.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(csvData); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// sanitizeFileName はファイル名に使えない文字を置き換える
+func sanitizeFileName(name string) string {
+	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_", "?", "_", "\"", "_", "<", "_", ">", "_", "|", "_", " ", "_")
+	name = replacer.Replace(name)
+	if runes := []rune(name); len(runes) > 40 {
+		name = string(runes[:40])
+	}
+	return name
+}
@@ -0,0 +1,138 @@
+// backend/controllers/report_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// ReportController は不適切な内容の通報に関するAPIエンドポイントを管理する
+type ReportController struct {
+	reportService      services.ReportService
+	interactionService services.InteractionService
+}
+
+// NewReportController は新しいReportControllerを作成する
+func NewReportController(reportService services.ReportService, interactionService services.InteractionService) *ReportController {
+	return &ReportController{
+		reportService:      reportService,
+		interactionService: interactionService,
+	}
+}
+
+// CreateReport はピン・公開編集者・コメントを通報する
+// ログインユーザーまたはX-Visitor-Tokenヘッダーの来場者が通報できる
+func (c *ReportController) CreateReport(ctx *gin.Context) {
+	reporter, err := c.interactionService.ResolveAuthor(ctx, ctx.GetString("userID"), ctx.GetHeader("X-Visitor-Token"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	var req models.ContentReportCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	if err := c.reportService.Report(ctx, reporter, &req); err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	// 通報件数や対応状況は通報者に返さない
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "通報を受け付けました"})
+}
+
+// GetMapReports はマップの通報一覧を取得する（?status=&limit=&offset=）
+func (c *ReportController) GetMapReports(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	limit, offset, ok := pageParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := c.reportService.GetMapFlags(ctx, userID.(string), mapID, ctx.Query("status"), limit, offset)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// ResolveReport はマップの所有者として通報された内容に対応する
+func (c *ReportController) ResolveReport(ctx *gin.Context) {
+	c.resolve(ctx, false)
+}
+
+// GetAllReports はすべてのマップの通報一覧を取得する（管理者用）
+func (c *ReportController) GetAllReports(ctx *gin.Context) {
+	limit, offset, ok := pageParams(ctx)
+	if !ok {
+		return
+	}
+
+	page, err := c.reportService.GetAllFlags(ctx, ctx.Query("status"), limit, offset)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// AdminResolveReport は管理者として通報された内容に対応する
+func (c *ReportController) AdminResolveReport(ctx *gin.Context) {
+	c.resolve(ctx, true)
+}
+
+// resolve は通報された内容への対応を実行する
+func (c *ReportController) resolve(ctx *gin.Context, isAdmin bool) {
+	flagID := ctx.Param("reportId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.ContentFlagAction
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	flag, err := c.reportService.Resolve(ctx, userID.(string), isAdmin, flagID, &req)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// pageParams はlimit・offsetのクエリパラメータを取得する
+// 不正な値の場合はエラーを返してfalseとなる
+func pageParams(ctx *gin.Context) (int, int, bool) {
+	limit, err := queryInt(ctx, "limit")
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "無効なリクエストです")
+		return 0, 0, false
+	}
+	offset, err := queryInt(ctx, "offset")
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusBadRequest, "無効なリクエストです")
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
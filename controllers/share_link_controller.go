@@ -0,0 +1,88 @@
+// backend/controllers/share_link_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// ShareLinkController は共有リンクに関するAPIエンドポイントを管理する
+type ShareLinkController struct {
+	shareLinkService services.ShareLinkService
+}
+
+// NewShareLinkController は新しいShareLinkControllerを作成する
+func NewShareLinkController(shareLinkService services.ShareLinkService) *ShareLinkController {
+	return &ShareLinkController{
+		shareLinkService: shareLinkService,
+	}
+}
+
+// CreateShareLink は新しい共有リンクを作成する
+func (c *ShareLinkController) CreateShareLink(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	// すべて任意項目のため、ボディが空でも受け付ける
+	var req models.ShareLinkCreate
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			i18n.RespondBindingError(ctx, err)
+			return
+		}
+	}
+
+	link, err := c.shareLinkService.Create(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, link)
+}
+
+// GetShareLinks はマップの共有リンク一覧を取得する
+func (c *ShareLinkController) GetShareLinks(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	links, err := c.shareLinkService.GetByMapID(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if links == nil {
+		links = []*models.ShareLink{}
+	}
+
+	ctx.JSON(http.StatusOK, links)
+}
+
+// RevokeShareLink は共有リンクを無効化する
+func (c *ShareLinkController) RevokeShareLink(ctx *gin.Context) {
+	linkID := ctx.Param("linkId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.shareLinkService.Revoke(ctx, userID.(string), linkID); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "共有リンクを無効化しました", "id": linkID})
+}
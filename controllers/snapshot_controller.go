@@ -0,0 +1,150 @@
+// backend/controllers/snapshot_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// SnapshotController はマップの公開スナップショットに関するAPIエンドポイントを管理する
+type SnapshotController struct {
+	snapshotService services.SnapshotService
+}
+
+// NewSnapshotController は新しいSnapshotControllerを作成する
+func NewSnapshotController(snapshotService services.SnapshotService) *SnapshotController {
+	return &SnapshotController{
+		snapshotService: snapshotService,
+	}
+}
+
+// PublishSnapshot は編集中のマップをスナップショットとして公開する
+func (c *SnapshotController) PublishSnapshot(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	// メモは任意のため、ボディが空でも受け付ける
+	var req models.MapSnapshotCreate
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			i18n.RespondBindingError(ctx, err)
+			return
+		}
+	}
+
+	snapshot, err := c.snapshotService.Publish(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, snapshot)
+}
+
+// GetSnapshots はマップのスナップショット一覧を取得する
+func (c *SnapshotController) GetSnapshots(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	snapshots, err := c.snapshotService.GetByMapID(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if snapshots == nil {
+		snapshots = []*models.MapSnapshot{}
+	}
+
+	ctx.JSON(http.StatusOK, snapshots)
+}
+
+// UnpublishSnapshot はスナップショットの配信をやめ、編集中のデータを配信する
+func (c *SnapshotController) UnpublishSnapshot(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.snapshotService.Unpublish(ctx, userID.(string), mapID); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "スナップショットの公開を停止しました", "map_id": mapID})
+}
+
+// GetSnapshot はスナップショットを内容とともに取得する
+func (c *SnapshotController) GetSnapshot(ctx *gin.Context) {
+	snapshotID := ctx.Param("snapshotId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	snapshot, err := c.snapshotService.GetByID(ctx, userID.(string), snapshotID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, snapshot)
+}
+
+// GetDiff はスナップショットと編集中のデータとの差分を取得する
+func (c *SnapshotController) GetDiff(ctx *gin.Context) {
+	snapshotID := ctx.Param("snapshotId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	diff, err := c.snapshotService.Diff(ctx, userID.(string), snapshotID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, diff)
+}
+
+// Rollback は過去のスナップショットを再度公開する
+func (c *SnapshotController) Rollback(ctx *gin.Context) {
+	snapshotID := ctx.Param("snapshotId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.MapSnapshotRollback
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			i18n.RespondBindingError(ctx, err)
+			return
+		}
+	}
+
+	snapshot, err := c.snapshotService.Rollback(ctx, userID.(string), snapshotID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, snapshot)
+}
@@ -0,0 +1,210 @@
+// backend/controllers/stamp_rally_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// StampRallyController はスタンプラリーに関するAPIエンドポイントを管理する
+type StampRallyController struct {
+	rallyService services.StampRallyService
+}
+
+// NewStampRallyController は新しいStampRallyControllerを作成する
+func NewStampRallyController(rallyService services.StampRallyService) *StampRallyController {
+	return &StampRallyController{
+		rallyService: rallyService,
+	}
+}
+
+// CreateRally は新しいスタンプラリーを作成する
+func (c *StampRallyController) CreateRally(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.StampRallyCreate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	rally, err := c.rallyService.Create(ctx, userID.(string), mapID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rally)
+}
+
+// GetRallies はマップのスタンプラリー一覧を取得する
+func (c *StampRallyController) GetRallies(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	rallies, err := c.rallyService.GetByMapID(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rallies)
+}
+
+// GetRally は開催中のスタンプラリーを取得する（来場者向け）
+func (c *StampRallyController) GetRally(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+
+	rally, err := c.rallyService.GetByID(ctx, ctx.GetString("userID"), rallyID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if rally == nil || !rally.IsActive {
+		i18n.RespondError(ctx, http.StatusNotFound, "スタンプラリーが見つかりません")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rally)
+}
+
+// UpdateRally はスタンプラリーを更新する
+func (c *StampRallyController) UpdateRally(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.StampRallyUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	rally, err := c.rallyService.Update(ctx, userID.(string), rallyID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rally)
+}
+
+// DeleteRally はスタンプラリーを削除する
+func (c *StampRallyController) DeleteRally(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.rallyService.Delete(ctx, userID.(string), rallyID); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "スタンプラリーを削除しました", "id": rallyID})
+}
+
+// GetCheckpoints はチェックポイントごとのチェックインコードを取得する
+func (c *StampRallyController) GetCheckpoints(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	checkpoints, err := c.rallyService.GetCheckpoints(ctx, userID.(string), rallyID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, checkpoints)
+}
+
+// GetStats はスタンプラリーの達成状況を取得する
+func (c *StampRallyController) GetStats(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	stats, err := c.rallyService.GetStats(ctx, userID.(string), rallyID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// StartSession は来場者の匿名セッションを開始する
+func (c *StampRallyController) StartSession(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+
+	session, err := c.rallyService.StartSession(ctx, ctx.GetString("userID"), rallyID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, models.RallySessionResponse{
+		SessionID: session.ID,
+		Token:     session.Token,
+		RallyID:   session.RallyID,
+	})
+}
+
+// CheckIn はチェックポイントでスタンプを記録する
+func (c *StampRallyController) CheckIn(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+
+	var req models.RallyCheckIn
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	progress, err := c.rallyService.CheckIn(ctx, ctx.GetString("userID"), rallyID, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, progress)
+}
+
+// GetProgress はセッションの進捗を取得する
+func (c *StampRallyController) GetProgress(ctx *gin.Context) {
+	rallyID := ctx.Param("rallyId")
+	sessionID := ctx.Param("sessionId")
+
+	progress, err := c.rallyService.GetProgress(ctx, rallyID, sessionID, ctx.Query("token"))
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, progress)
+}
@@ -0,0 +1,103 @@
+// backend/controllers/translation_controller.go
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+)
+
+// TranslationController は多言語コンテンツに関するAPIエンドポイントを管理する
+type TranslationController struct {
+	translationService services.TranslationService
+}
+
+// NewTranslationController は新しいTranslationControllerを作成する
+func NewTranslationController(translationService services.TranslationService) *TranslationController {
+	return &TranslationController{
+		translationService: translationService,
+	}
+}
+
+// GetTranslation はマップの1言語分の翻訳を取得する
+func (c *TranslationController) GetTranslation(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	locale := ctx.Param("locale")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	translation, err := c.translationService.Get(ctx, userID.(string), mapID, locale)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, translation)
+}
+
+// UpdateTranslation はマップの1言語分の翻訳を保存する
+func (c *TranslationController) UpdateTranslation(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	locale := ctx.Param("locale")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.MapTranslation
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	translation, err := c.translationService.Save(ctx, userID.(string), mapID, locale, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, translation)
+}
+
+// DeleteTranslation はマップの1言語分の翻訳を削除する
+func (c *TranslationController) DeleteTranslation(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	locale := ctx.Param("locale")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	if err := c.translationService.Delete(ctx, userID.(string), mapID, locale); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "翻訳を削除しました", "locale": locale})
+}
+
+// GetReport はマップの翻訳の充足状況を取得する
+func (c *TranslationController) GetReport(ctx *gin.Context) {
+	mapID := ctx.Param("mapId")
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	report, err := c.translationService.Report(ctx, userID.(string), mapID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,230 @@
+// backend/controllers/two_factor_controller.go
+package controllers
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/services"
+	"github.com/shimaf4979/pamfree-backend/utils"
+	"github.com/skip2/go-qrcode"
+)
+
+// twoFactorQRSize は認証アプリ登録用QRコードの画像サイズ（ピクセル）
+const twoFactorQRSize = 256
+
+// TwoFactorController は二段階認証とセキュリティ設定に関するAPIエンドポイントを管理する
+type TwoFactorController struct {
+	twoFactorService services.TwoFactorService
+	authService      *services.AuthService
+	auditService     services.AuditService
+	jwtSecret        string
+}
+
+// NewTwoFactorController は新しいTwoFactorControllerを作成する
+func NewTwoFactorController(
+	twoFactorService services.TwoFactorService,
+	authService *services.AuthService,
+	auditService services.AuditService,
+	jwtSecret string,
+) *TwoFactorController {
+	return &TwoFactorController{
+		twoFactorService: twoFactorService,
+		authService:      authService,
+		auditService:     auditService,
+		jwtSecret:        jwtSecret,
+	}
+}
+
+// GetStatus はログインユーザーの二段階認証の状態を取得する
+func (c *TwoFactorController) GetStatus(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	status, err := c.twoFactorService.GetStatus(ctx, userID.(string))
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// Setup は認証アプリに登録する秘密鍵とQRコードを発行する
+func (c *TwoFactorController) Setup(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	setup, err := c.twoFactorService.Setup(ctx, userID.(string))
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+
+	png, err := utils.QRCodePNG(setup.OTPAuthURI, twoFactorQRSize, qrcode.Medium)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "QRコードの生成に失敗しました")
+		return
+	}
+	setup.QRCode = "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+
+	ctx.JSON(http.StatusOK, setup)
+}
+
+// Enable は確認コードを検証して二段階認証を有効にする
+// 回復コードと、二段階認証を完了した扱いの新しいトークンを返す
+func (c *TwoFactorController) Enable(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.TwoFactorCode
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	codes, err := c.twoFactorService.Enable(ctx, userID.(string), req.Code)
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionTwoFactorEnable,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.(string),
+	}, nil, nil)
+
+	user, err := c.authService.GetUserByID(ctx, userID.(string))
+	if err != nil || user == nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "サーバーエラーが発生しました")
+		return
+	}
+
+	token, err := utils.GenerateTwoFactorToken(user.ID, user.Email, user.Role, c.jwtSecret)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "認証トークンの生成に失敗しました")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "二段階認証を有効にしました",
+		"recovery_codes": codes,
+		"token":          token,
+	})
+}
+
+// Disable はパスワードと確認コードを検証して二段階認証を無効にする
+func (c *TwoFactorController) Disable(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.TwoFactorDisable
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	if err := c.twoFactorService.Disable(ctx, userID.(string), &req); err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionTwoFactorDisable,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID.(string),
+	}, nil, nil)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "二段階認証を無効にしました"})
+}
+
+// RegenerateRecoveryCodes は確認コードを検証して回復コードを発行し直す
+func (c *TwoFactorController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		i18n.RespondError(ctx, http.StatusUnauthorized, "認証が必要です")
+		return
+	}
+
+	var req models.TwoFactorCode
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	codes, err := c.twoFactorService.RegenerateRecoveryCodes(ctx, userID.(string), req.Code)
+	if err != nil {
+		respondTwoFactorError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// GetSecuritySettings はセキュリティ設定を取得する（管理者用）
+func (c *TwoFactorController) GetSecuritySettings(ctx *gin.Context) {
+	settings, err := c.twoFactorService.GetSettings(ctx)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateSecuritySettings はセキュリティ設定を更新する（管理者用）
+func (c *TwoFactorController) UpdateSecuritySettings(ctx *gin.Context) {
+	var req models.SecuritySettingsUpdate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		i18n.RespondBindingError(ctx, err)
+		return
+	}
+
+	before, err := c.twoFactorService.GetSettings(ctx)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	settings, err := c.twoFactorService.UpdateSettings(ctx, &req)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+		return
+	}
+	recordAudit(ctx, c.auditService, &models.AuditLog{
+		Action:     models.AuditActionSecuritySettings,
+		TargetType: models.AuditTargetSettings,
+		TargetID:   "security",
+	}, before, settings)
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// respondTwoFactorError は二段階認証のエラーを適切なステータスコードで返す
+func respondTwoFactorError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrTwoFactorInvalidCode, services.ErrTwoFactorWrongPassword,
+		services.ErrTwoFactorNotEnabled, services.ErrTwoFactorNotStarted:
+		i18n.RespondError(ctx, http.StatusBadRequest, err.Error())
+	case services.ErrTwoFactorAlreadyEnabled:
+		i18n.RespondError(ctx, http.StatusConflict, err.Error())
+	case services.ErrTwoFactorRequiredByAdmin:
+		i18n.RespondError(ctx, http.StatusForbidden, err.Error())
+	default:
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -2,18 +2,27 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/services"
 )
 
 // ViewerController はビューワー関連の操作を提供するコントローラー
 type ViewerController struct {
-	mapService   services.MapService
-	floorService services.FloorService
-	pinService   services.PinService
+	mapService          services.MapService
+	floorService        services.FloorService
+	pinService          services.PinService
+	georeferenceService services.GeoreferenceService
+	analyticsService    services.AnalyticsService
+	snapshotService     services.SnapshotService
+	shareLinkService    services.ShareLinkService
+	translationService  services.TranslationService
+	interactionService  services.InteractionService
+	reportService       services.ReportService
 }
 
 // NewViewerController は新しいViewerControllerを作成する
@@ -21,11 +30,25 @@ func NewViewerController(
 	mapService services.MapService,
 	floorService services.FloorService,
 	pinService services.PinService,
+	georeferenceService services.GeoreferenceService,
+	analyticsService services.AnalyticsService,
+	snapshotService services.SnapshotService,
+	shareLinkService services.ShareLinkService,
+	translationService services.TranslationService,
+	interactionService services.InteractionService,
+	reportService services.ReportService,
 ) *ViewerController {
 	return &ViewerController{
-		mapService:   mapService,
-		floorService: floorService,
-		pinService:   pinService,
+		mapService:          mapService,
+		floorService:        floorService,
+		pinService:          pinService,
+		georeferenceService: georeferenceService,
+		analyticsService:    analyticsService,
+		snapshotService:     snapshotService,
+		shareLinkService:    shareLinkService,
+		translationService:  translationService,
+		interactionService:  interactionService,
+		reportService:       reportService,
 	}
 }
 
@@ -33,25 +56,131 @@ func NewViewerController(
 func (c *ViewerController) GetMapData(ctx *gin.Context) {
 	mapID := ctx.Param("mapId")
 	if mapID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "マップIDが必要です"})
+		i18n.RespondError(ctx, http.StatusBadRequest, "マップIDが必要です")
 		return
 	}
 
 	// マップデータを取得
 	mapData, err := c.mapService.GetMapByID(ctx, mapID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "マップの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
 		return
 	}
+
+	c.serveMap(ctx, mapData)
+}
+
+// GetMapDataBySlug はスラッグによりマップの全データを取得する
+// 過去のスラッグの場合は現在のスラッグへリダイレクトする
+func (c *ViewerController) GetMapDataBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	mapData, redirected, err := c.mapService.GetMapBySlug(ctx, slug)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if mapData != nil && redirected {
+		canView, err := c.mapService.CanView(ctx, mapData, ctx.GetString("userID"))
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+			return
+		}
+		if canView {
+			ctx.Header("Location", "/api/viewer/by-slug/"+*mapData.Slug)
+			ctx.JSON(http.StatusMovedPermanently, gin.H{"slug": *mapData.Slug})
+			return
+		}
+	}
+
+	c.serveMap(ctx, mapData)
+}
+
+// serveMap は閲覧権限を確認してマップのデータを返す
+func (c *ViewerController) serveMap(ctx *gin.Context, mapData *models.Map) {
+	// 公開中でないマップはメンバー以外には存在しないものとして扱う
+	userID := ctx.GetString("userID")
 	if mapData == nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "マップが見つかりません"})
+		i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
 		return
 	}
+	isMember, err := c.mapService.CanEdit(ctx, mapData, userID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if !isMember {
+		canView, err := c.mapService.CanView(ctx, mapData, userID)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+			return
+		}
+		if !canView {
+			i18n.RespondError(ctx, http.StatusNotFound, "マップが見つかりません")
+			return
+		}
+
+		// マップの表示を記録（メンバーによる確認は含めない）
+		c.analyticsService.RecordMapView(mapData.ID)
+	}
+
+	// 公開スナップショットがあればその内容を配信する
+	// メンバーは?preview=workingで編集中のデータを確認できる
+	if !isMember || ctx.Query("preview") != "working" {
+		snapshot, err := c.snapshotService.GetPublished(ctx, mapData.ID)
+		if err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+			return
+		}
+		if snapshot != nil {
+			c.respondSnapshot(ctx, mapData, snapshot)
+			return
+		}
+	}
+
+	c.respondWorkingCopy(ctx, mapData)
+}
 
+// GetSharedMapData は共有リンクから未公開を含むマップの編集中のデータを取得する
+// パスワードはPOSTのボディで受け取る
+func (c *ViewerController) GetSharedMapData(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	var req models.ShareLinkAccess
+	if ctx.Request.Method == http.MethodPost && ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			i18n.RespondBindingError(ctx, err)
+			return
+		}
+	}
+
+	// 共有リンクの内容は検索エンジンやキャッシュに残さない
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("X-Robots-Tag", "noindex")
+
+	mapData, err := c.shareLinkService.Resolve(ctx, slug, req.Password)
+	if errors.Is(err, services.ErrShareLinkPasswordRequired) || errors.Is(err, services.ErrShareLinkWrongPassword) {
+		i18n.RespondError(ctx, http.StatusUnauthorized, err.Error(), gin.H{"password_required": true})
+		return
+	}
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "マップの取得に失敗しました")
+		return
+	}
+	if mapData == nil {
+		i18n.RespondError(ctx, http.StatusNotFound, "共有リンクが無効か、有効期限が切れています")
+		return
+	}
+
+	c.respondWorkingCopy(ctx, mapData)
+}
+
+// respondWorkingCopy は編集中のマップ・フロア・ピンをビューワーに返す
+func (c *ViewerController) respondWorkingCopy(ctx *gin.Context, mapData *models.Map) {
 	// フロアデータを取得
-	floors, err := c.floorService.GetFloorsByMapID(ctx, mapID)
+	floors, err := c.floorService.GetFloorsByMapID(ctx, mapData.ID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "フロアの取得に失敗しました"})
+		i18n.RespondError(ctx, http.StatusInternalServerError, "フロアの取得に失敗しました")
 		return
 	}
 	if floors == nil {
@@ -70,20 +199,116 @@ func (c *ViewerController) GetMapData(ctx *gin.Context) {
 		// 全フロアのピンを取得
 		pins, err = c.pinService.GetByFloorIDs(ctx, floorIDs)
 		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "ピンの取得に失敗しました"})
+			i18n.RespondError(ctx, http.StatusInternalServerError, "ピンの取得に失敗しました")
 			return
 		}
 		if pins == nil {
 			pins = []*models.Pin{}
 		}
+
+		// 地理参照済みフロアのピンに緯度経度を付与
+		if err := c.georeferenceService.AttachCoordinates(ctx, pins); err != nil {
+			i18n.RespondError(ctx, http.StatusInternalServerError, "位置情報の取得に失敗しました")
+			return
+		}
+	}
+
+	// 通報により非表示になったピンを除く
+	pins, err = c.reportService.FilterPins(ctx, mapData.ID, pins)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ピンの取得に失敗しました")
+		return
+	}
+	pins = models.WithoutEditorIDs(pins)
+
+	// 閲覧者の言語に翻訳
+	locale, ok := c.localize(ctx, mapData, floors, pins)
+	if !ok {
+		return
+	}
+
+	// ピンごとのコメント数・リアクション数を取得
+	interactions, err := c.interactionService.GetViewerInteractions(ctx, mapData.ID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "コメントの取得に失敗しました")
+		return
 	}
 
 	// レスポンスデータを構築
 	responseData := gin.H{
-		"map":    mapData,
-		"floors": floors,
-		"pins":   pins,
+		"map":          mapData,
+		"floors":       floors,
+		"pins":         pins,
+		"locale":       locale,
+		"interactions": interactions,
 	}
 
 	ctx.JSON(http.StatusOK, responseData)
 }
+
+// respondSnapshot は公開スナップショットの内容をビューワーに返す
+func (c *ViewerController) respondSnapshot(ctx *gin.Context, mapData *models.Map, snapshot *models.MapSnapshot) {
+	// 公開状態は常に最新のマップのものを使用する
+	snapshotMap := *snapshot.Data.Map
+	snapshotMap.Status = mapData.Status
+	snapshotMap.PublishAt = mapData.PublishAt
+	snapshotMap.UnpublishAt = mapData.UnpublishAt
+
+	pins := snapshot.Data.Pins
+	if err := c.georeferenceService.AttachCoordinates(ctx, pins); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "位置情報の取得に失敗しました")
+		return
+	}
+
+	pins, err := c.reportService.FilterPins(ctx, mapData.ID, pins)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "ピンの取得に失敗しました")
+		return
+	}
+	pins = models.WithoutEditorIDs(pins)
+
+	locale, ok := c.localize(ctx, &snapshotMap, snapshot.Data.Floors, pins)
+	if !ok {
+		return
+	}
+
+	interactions, err := c.interactionService.GetViewerInteractions(ctx, mapData.ID)
+	if err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "コメントの取得に失敗しました")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"map":              &snapshotMap,
+		"floors":           snapshot.Data.Floors,
+		"pins":             pins,
+		"snapshot_version": snapshot.Version,
+		"locale":           locale,
+		"interactions":     interactions,
+	})
+}
+
+// localize は?lang=またはAccept-Languageで選択した言語の翻訳を反映する
+// 翻訳がない項目はデフォルト言語のまま返す
+func (c *ViewerController) localize(ctx *gin.Context, mapData *models.Map, floors []*models.Floor, pins []*models.Pin) (string, bool) {
+	locale := c.translationService.Negotiate(ctx.Query("lang"), ctx.GetHeader("Accept-Language"))
+
+	if err := c.translationService.Apply(ctx, locale, mapData, floors, pins); err != nil {
+		i18n.RespondError(ctx, http.StatusInternalServerError, "翻訳の取得に失敗しました")
+		return "", false
+	}
+
+	ctx.Header("Content-Language", locale)
+	ctx.Header("Vary", "Accept-Language")
+	return locale, true
+}
+
+// respondViewerError は閲覧者向けのデータ取得のエラーを適切なステータスコードで返す
+func respondViewerError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrMapNotFound, services.ErrViewerFloorNotFound, services.ErrViewerPinNotFound:
+		i18n.RespondError(ctx, http.StatusNotFound, err.Error())
+	default:
+		i18n.RespondError(ctx, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,311 @@
+// backend/i18n/catalog.go
+package i18n
+
+// entry はメッセージキーと各言語の文言を表す
+// 日本語の文言はサービス層のエラーメッセージと一致させ、逆引きに使用する
+// 値を含むメッセージは書式文字列を登録し、RespondErrorfで翻訳してから値を埋め込む
+type entry struct {
+	key string
+	ja  string
+	en  string
+}
+
+// catalog はAPIが返すメッセージの一覧
+var catalog = []entry{
+	// 共通
+	{"request.invalid", "無効なリクエストです", "Invalid request"},
+	{"request.malformed", "リクエストが不正です", "Malformed request"},
+	{"request.invalid_input", "入力データが不正です", "Invalid input data"},
+	{"request.validation_failed", "入力内容に誤りがあります", "The request contains invalid fields"},
+	{"request.invalid_json", "リクエストの形式が不正です", "The request body is not valid JSON"},
+	{"server.error", "サーバーエラーが発生しました", "An internal server error occurred"},
+	{"update.failed", "更新に失敗しました", "Update failed"},
+
+	// 認証・アカウント
+	{"auth.required", "認証が必要です", "Authentication is required"},
+	{"auth.invalid_format", "認証形式が不正です", "Invalid authorization header format"},
+	{"auth.invalid_token", "無効なトークンです", "Invalid token"},
+	{"auth.token_invalid", "トークンが無効です", "The token is invalid"},
+	{"auth.token_failed", "認証トークンの生成に失敗しました", "Failed to generate the authentication token"},
+	{"auth.admin_required", "管理者権限が必要です", "Administrator privileges are required"},
+	{"auth.invalid_credentials", "メールアドレスまたはパスワードが正しくありません", "Incorrect email address or password"},
+	{"auth.locked", "ログインの失敗が続いたため、しばらくログインできません", "Too many failed login attempts. Please try again later"},
+	{"auth.challenge_expired", "ログインの有効期限が切れました。もう一度ログインしてください", "Your login session has expired. Please log in again"},
+	{"auth.two_factor_invalid_code", "確認コードが正しくありません", "The verification code is incorrect"},
+	{"auth.two_factor_not_enabled", "二段階認証が有効になっていません", "Two-factor authentication is not enabled"},
+	{"auth.two_factor_already_enabled", "二段階認証は既に有効です", "Two-factor authentication is already enabled"},
+	{"auth.two_factor_not_started", "先に二段階認証の設定を開始してください", "Start the two-factor authentication setup first"},
+	{"auth.two_factor_admin_login", "管理者は二段階認証を有効にしてログインする必要があります", "Administrators must enable two-factor authentication and log in with it"},
+	{"auth.two_factor_required", "管理者は二段階認証を無効にできません", "Administrators cannot disable two-factor authentication"},
+	{"auth.oidc_provider_not_found", "ログインプロバイダーが見つかりません", "Login provider not found"},
+	{"auth.oidc_provider_failed", "ログインプロバイダーに接続できません", "Could not connect to the login provider"},
+	{"auth.oidc_state_invalid", "認可リクエストが無効か、有効期限が切れています", "The authorization request is invalid or has expired"},
+	{"auth.oidc_verification_failed", "外部アカウントの認証に失敗しました", "Failed to authenticate the external account"},
+	{"auth.oidc_email_required", "外部アカウントのメールアドレスを取得できませんでした", "Could not obtain the email address of the external account"},
+	{"auth.oidc_email_unverified", "外部アカウントのメールアドレスが確認されていません", "The email address of the external account has not been verified"},
+	{"auth.identity_linked_to_other", "この外部アカウントは既に別のユーザーに連携されています", "This external account is already linked to another user"},
+	{"auth.identity_already_linked", "このプロバイダーのアカウントは既に連携されています", "An account from this provider is already linked"},
+	{"auth.identity_not_found", "連携されたアカウントが見つかりません", "Linked account not found"},
+	{"auth.identity_last_login", "パスワードが設定されていないため、最後の連携は解除できません", "The last linked account cannot be unlinked because no password is set"},
+	{"auth.access_token_not_allowed", "このAPIはアクセストークンでは利用できません", "This API cannot be used with an access token"},
+	{"auth.access_token_scope", "アクセストークンにこの操作の権限がありません", "The access token does not have permission for this operation"},
+	{"auth.access_token_name_required", "アクセストークンの名前を入力してください", "Enter a name for the access token"},
+	{"auth.access_token_not_found", "アクセストークンが見つかりません", "Access token not found"},
+	{"auth.access_token_limit", "作成できるアクセストークンの上限に達しています", "You have reached the maximum number of access tokens"},
+	{"auth.email_taken", "このメールアドレスは既に登録されています", "This email address is already registered"},
+	{"auth.register_failed", "ユーザーの登録に失敗しました", "Failed to register the user"},
+	{"auth.password_failed", "パスワードの処理に失敗しました", "Failed to process the password"},
+	{"auth.password_incorrect", "パスワードが正しくありません", "Incorrect password"},
+	{"auth.current_password_incorrect", "現在のパスワードが正しくありません", "The current password is incorrect"},
+	{"auth.password_update_failed", "パスワードの更新に失敗しました", "Failed to update the password"},
+	{"account.profile_update_failed", "プロフィールの更新に失敗しました", "Failed to update the profile"},
+	{"user.not_found", "ユーザーが見つかりません", "User not found"},
+	{"user.list_failed", "ユーザー一覧の取得に失敗しました", "Failed to fetch users"},
+	{"user.update_failed", "ユーザーの更新に失敗しました", "Failed to update the user"},
+	{"user.delete_failed", "ユーザーの削除に失敗しました", "Failed to delete the user"},
+	{"user.invalid_role", "有効な役割を指定してください", "Specify a valid role"},
+	{"user.cannot_change_own_role", "自分自身の役割は変更できません", "You cannot change your own role"},
+	{"user.cannot_delete_self", "自分自身を削除することはできません", "You cannot delete yourself"},
+
+	// マップ
+	{"map.not_found", "マップが見つかりません", "Map not found"},
+	{"map.id_required", "マップIDが必要です", "A map ID is required"},
+	{"map.id_taken", "このIDは既に使用されています", "This ID is already in use"},
+	{"map.fetch_failed", "マップの取得に失敗しました", "Failed to fetch the map"},
+	{"map.create_failed", "マップの作成に失敗しました", "Failed to create the map"},
+	{"map.update_failed", "マップの更新に失敗しました", "Failed to update the map"},
+	{"map.delete_failed", "マップの削除に失敗しました", "Failed to delete the map"},
+	{"map.forbidden", "このマップにアクセスする権限がありません", "You do not have access to this map"},
+	{"map.edit_forbidden", "このマップを編集する権限がありません", "You do not have permission to edit this map"},
+	{"map.delete_forbidden", "このマップを削除する権限がありません", "You do not have permission to delete this map"},
+	{"map.public_edit_disabled", "このマップは公開編集が許可されていません", "Public editing is not enabled for this map"},
+	{"map.schedule_draft_only", "公開予約は下書きのマップにのみ設定できます", "A publish time can only be set on draft maps"},
+	{"map.unpublish_archived", "アーカイブ済みのマップには公開終了日時を設定できません", "An unpublish time cannot be set on archived maps"},
+	{"map.unpublish_future", "公開終了日時は現在より後に設定してください", "The unpublish time must be in the future"},
+	{"map.unpublish_after_publish", "公開終了日時は公開日時より後に設定してください", "The unpublish time must be after the publish time"},
+	{"map.transfer_forbidden", "このマップを移管する権限がありません", "You do not have permission to transfer this map"},
+	{"map.transfer_target", "移管先のユーザーか組織のどちらか一方を指定してください", "Specify either a user or an organization to transfer to"},
+	{"map.transfer_user_not_found", "移管先のユーザーが見つかりません", "The user to transfer to was not found"},
+	{"map.transfer_organization", "移管先の組織でマップを管理する権限がありません", "You do not have permission to manage maps in the destination organization"},
+	{"map.transfer_same_owner", "移管先が現在の所有者と同じです", "The map is already owned by the specified owner"},
+
+	// 組織
+	{"organization.not_found", "組織が見つかりません", "Organization not found"},
+	{"organization.name_required", "組織名を入力してください", "Enter an organization name"},
+	{"organization.forbidden", "この組織を管理する権限がありません", "You do not have permission to manage this organization"},
+	{"organization.owner_required", "この操作は組織の所有者のみが行えます", "Only organization owners can perform this operation"},
+	{"organization.has_maps", "マップが残っている組織は削除できません。先にマップを移管または削除してください", "An organization that still owns maps cannot be deleted. Transfer or delete its maps first"},
+	{"organization.user_not_found", "指定したメールアドレスのユーザーが見つかりません", "No user was found with that email address"},
+	{"organization.member_exists", "このユーザーは既に組織のメンバーです", "This user is already a member of the organization"},
+	{"organization.member_not_found", "組織のメンバーが見つかりません", "Organization member not found"},
+	{"organization.last_owner", "組織には所有者が1人以上必要です", "An organization must have at least one owner"},
+
+	// スラッグ
+	{"slug.required", "スラッグが必要です", "A slug is required"},
+	{"slug.length", "スラッグは3文字以上50文字以内で指定してください", "Slugs must be between 3 and 50 characters"},
+	{"slug.format", "スラッグには英小文字・数字・ハイフンのみ使用でき、先頭と末尾は英数字にしてください", "Slugs may contain only lowercase letters, digits and hyphens, and must start and end with a letter or digit"},
+	{"slug.reserved", "このスラッグは予約されているため使用できません", "This slug is reserved"},
+	{"slug.taken", "このスラッグは既に使用されています", "This slug is already in use"},
+	{"slug.check_failed", "スラッグの確認に失敗しました", "Failed to check the slug"},
+
+	// フロア
+	{"floor.not_found", "フロアが見つかりません", "Floor not found"},
+	{"floor.fetch_failed", "フロアの取得に失敗しました", "Failed to fetch floors"},
+	{"floor.edit_forbidden", "このフロアを編集する権限がありません", "You do not have permission to edit this floor"},
+	{"floor.delete_forbidden", "このフロアを削除する権限がありません", "You do not have permission to delete this floor"},
+	{"floor.not_in_map", "このマップに存在しないフロアが含まれています", "Some floors do not belong to this map"},
+
+	// ピン
+	{"pin.not_found", "ピンが見つかりません", "Pin not found"},
+	{"pin.fetch_failed", "ピンの取得に失敗しました", "Failed to fetch pins"},
+	{"pin.edit_forbidden", "このピンを編集する権限がありません", "You do not have permission to edit this pin"},
+	{"pin.delete_forbidden", "このピンを削除する権限がありません", "You do not have permission to delete this pin"},
+	{"pin.not_in_map", "このマップに存在しないピンが含まれています", "Some pins do not belong to this map"},
+
+	// 公開編集
+	{"editor.not_found", "編集者が見つかりません", "Editor not found"},
+	{"editor.auth_required", "編集者の認証が必要です", "Editor authentication is required"},
+	{"editor.info_required", "編集者情報が必要です", "Editor information is required"},
+	{"editor.register_failed", "編集者の登録に失敗しました", "Failed to register the editor"},
+
+	// 画像アップロード
+	{"image.required", "画像ファイルが必要です", "An image file is required"},
+	{"image.url_required", "画像URLが必要です", "An image URL is required"},
+	{"image.too_large", "画像サイズが大きすぎます", "The image is too large"},
+	{"image.invalid_format", "無効な画像形式です", "Unsupported image format"},
+	{"image.upload_failed", "画像のアップロードに失敗しました", "Failed to upload the image"},
+	{"image.delete_failed", "画像の削除に失敗しました", "Failed to delete the image"},
+	{"upload.public_id_required", "公開IDが必要です", "A public ID is required"},
+	{"upload.signature_failed", "署名の生成に失敗しました", "Failed to generate the upload signature"},
+	{"upload.ticket_not_found", "アップロードチケットが見つかりません", "Upload ticket not found"},
+	{"upload.forbidden", "このアップロードを完了する権限がありません", "You do not have permission to complete this upload"},
+	{"upload.already_completed", "このアップロードは既に完了しています", "This upload has already been completed"},
+	{"upload.expired", "アップロードの有効期限が切れています", "The upload has expired"},
+	{"upload.invalid_target", "無効なアップロード対象です", "Invalid upload target"},
+	{"upload.asset_not_found", "アップロードされた画像が見つかりません", "The uploaded image could not be found"},
+
+	// 地理参照
+	{"geo.not_configured", "このフロアは位置情報が設定されていません", "This floor has no georeference"},
+	{"geo.min_points", "対応点は3点以上必要です", "At least three control points are required"},
+	{"geo.collinear", "対応点が一直線上に並んでいるため変換を計算できません", "The control points are collinear, so no transform can be computed"},
+	{"geo.invalid_transform", "変換係数が不正です", "Invalid transform coefficients"},
+	{"geo.not_invertible", "変換が逆変換を持ちません", "The transform is not invertible"},
+	{"geo.latlng_required", "緯度と経度が必要です", "Latitude and longitude are required"},
+	{"geo.invalid_latlng", "緯度経度の値が不正です", "Invalid latitude or longitude"},
+	{"geo.fetch_failed", "位置情報の取得に失敗しました", "Failed to fetch location data"},
+	{"geo.floor_not_configured", "位置情報が設定されていないフロアがあります", "Some floors have no georeference"},
+
+	// GeoJSON
+	{"geojson.invalid", "無効なGeoJSONです", "Invalid GeoJSON"},
+	{"geojson.feature_collection_required", "FeatureCollectionが必要です", "A FeatureCollection is required"},
+	{"geojson.point_required", "Pointジオメトリが必要です", "A Point geometry is required"},
+	{"geojson.title_required", "titleプロパティが必要です", "A title property is required"},
+	{"geojson.invalid_crs", "無効な座標系です", "Invalid coordinate system"},
+
+	// ファイル入出力
+	{"file.required", "ファイルが必要です", "A file is required"},
+	{"file.too_large", "ファイルサイズが大きすぎます", "The file is too large"},
+	{"file.read_failed", "ファイルの読み込みに失敗しました", "Failed to read the file"},
+	{"file.parse_failed", "ファイルの解析に失敗しました", "Failed to parse the file"},
+	{"file.generate_failed", "ファイルの生成に失敗しました", "Failed to generate the file"},
+	{"file.csv_or_xlsx", "CSVまたはXLSXファイルを指定してください", "Upload a CSV or XLSX file"},
+	{"file.format_csv_xlsx", "形式はcsvまたはxlsxを指定してください", "The format must be csv or xlsx"},
+	{"file.header_required", "ヘッダー行が必要です", "A header row is required"},
+	{"file.sheet_not_found", "シートが見つかりません", "No worksheet found"},
+	{"file.column_required", "必須の列がありません: %s", "Missing required column: %s"},
+
+	// PDF・QRコード
+	{"pdf.failed", "PDFの生成に失敗しました", "Failed to generate the PDF"},
+	{"pdf.not_ready", "PDFはまだ生成されていません", "The PDF has not been generated yet"},
+	{"pdf.busy", "PDF生成が混み合っています。しばらくしてから再度お試しください", "PDF generation is busy. Please try again later"},
+	{"pdf.font_missing", "日本語フォントが設定されていません", "No Japanese font is configured"},
+	{"pdf.floor_image_failed", "%sの画像の取得に失敗しました", "Failed to fetch the image of %s"},
+	{"pdf.image_fetch_failed", "画像を取得できませんでした: %s", "Could not fetch the image: %s"},
+	{"pdf.image_url_not_allowed", "許可されていない画像URLです", "The image URL is not allowed"},
+	{"pdf.address_not_allowed", "許可されていない接続先です: %s", "The destination address is not allowed: %s"},
+	{"job.not_found", "ジョブが見つかりません", "Job not found"},
+	{"qr.failed", "QRコードの生成に失敗しました", "Failed to generate the QR code"},
+	{"qr.zip_failed", "ZIPファイルの生成に失敗しました", "Failed to generate the ZIP file"},
+	{"qr.invalid_target", "無効なQRコードの対象です", "Invalid QR code target"},
+	{"qr.format_png_svg", "形式はpngまたはsvgを指定してください", "The format must be png or svg"},
+	{"qr.format_zip_pdf", "形式はzipまたはpdfを指定してください", "The format must be zip or pdf"},
+	{"qr.invalid_level", "誤り訂正レベルはL/M/Q/Hのいずれかを指定してください", "The error correction level must be L, M, Q or H"},
+	{"qr.invalid_size", "サイズは%d〜%dの範囲で指定してください", "The size must be between %d and %d"},
+
+	// スタンプラリー
+	{"rally.not_found", "スタンプラリーが見つかりません", "Stamp rally not found"},
+	{"rally.inactive", "このスタンプラリーは開催されていません", "This stamp rally is not active"},
+	{"rally.session_not_found", "セッションが見つかりません", "Session not found"},
+	{"rally.not_checkpoint", "このピンはチェックポイントではありません", "This pin is not a checkpoint"},
+	{"rally.invalid_code", "無効なチェックインコードです", "Invalid check-in code"},
+	{"rally.checkpoint_required", "チェックポイントを1つ以上指定してください", "Specify at least one checkpoint"},
+	{"rally.checkpoint_duplicate", "チェックポイントが重複しています", "Duplicate checkpoints"},
+	{"rally.checkpoint_not_in_map", "チェックポイントはこのマップのピンから選択してください", "Checkpoints must be pins on this map"},
+	{"rally.invalid_required_count", "必要なスタンプ数はチェックポイント数以下で指定してください", "The required stamp count must not exceed the number of checkpoints"},
+	{"rally.invalid_rule", "無効な達成条件です", "Invalid completion rule"},
+
+	// 利用状況
+	{"analytics.invalid_range", "集計期間が不正です", "Invalid date range"},
+	{"analytics.hourly_range", "1時間単位の集計期間は31日以内で指定してください", "Hourly ranges must be 31 days or shorter"},
+	{"analytics.daily_range", "集計期間は366日以内で指定してください", "Ranges must be 366 days or shorter"},
+	{"analytics.invalid_granularity", "無効な集計単位です", "Invalid granularity"},
+	{"analytics.invalid_from", "fromの形式が不正です", "Invalid from parameter"},
+	{"analytics.invalid_to", "toの形式が不正です", "Invalid to parameter"},
+
+	// スナップショット・共有リンク
+	{"snapshot.not_found", "スナップショットが見つかりません", "Snapshot not found"},
+	{"share.not_found", "共有リンクが見つかりません", "Share link not found"},
+	{"share.invalid", "共有リンクが無効か、有効期限が切れています", "The share link is invalid or has expired"},
+	{"share.password_required", "この共有リンクにはパスワードが必要です", "This share link requires a password"},
+	{"share.expiry_future", "有効期限は現在より後に設定してください", "The expiry must be in the future"},
+
+	// コメント・リアクション
+	{"comment.not_found", "コメントが見つかりません", "Comment not found"},
+	{"comment.empty", "コメントを入力してください", "Enter a comment"},
+	{"comment.delete_forbidden", "このコメントを削除する権限がありません", "You do not have permission to delete this comment"},
+	{"comment.invalid_status", "無効な表示状態です", "Invalid status"},
+	{"comment.invalid_cursor", "無効なカーソルです", "Invalid cursor"},
+	{"comment.fetch_failed", "コメントの取得に失敗しました", "Failed to fetch comments"},
+	{"comment.disabled", "このマップではコメントが無効になっています", "Comments are disabled for this map"},
+	{"reaction.disabled", "このマップではリアクションが無効になっています", "Reactions are disabled for this map"},
+	{"reaction.invalid_emoji", "使用できない絵文字です", "This emoji cannot be used"},
+	{"visitor.required", "コメント・リアクションには来場者登録またはログインが必要です", "Register as a visitor or log in to comment or react"},
+	{"visitor.invalid_token", "無効な来場者トークンです", "Invalid visitor token"},
+	{"rate.limited", "操作が多すぎます。しばらくしてから再度お試しください", "Too many requests. Please try again later"},
+
+	// 通報
+	{"report.target_not_found", "通報の対象が見つかりません", "The reported content could not be found"},
+	{"report.invalid_target", "無効な通報の対象です", "Invalid report target"},
+	{"report.not_found", "通報が見つかりません", "Report not found"},
+	{"report.forbidden", "この通報に対応する権限がありません", "You do not have permission to act on this report"},
+	{"report.already_removed", "この内容は既に削除されています", "This content has already been removed"},
+	{"report.invalid_action", "無効な対応です", "Invalid action"},
+	{"report.invalid_status", "無効な対応状況です", "Invalid report status"},
+	{"report.not_public_editor", "この内容の投稿者は公開編集者ではありません", "This content was not posted by a public editor"},
+	{"editor.banned", "この編集者は編集を禁止されています", "This editor has been banned from editing"},
+	{"editor.revoked", "この編集者の編集権限は取り消されています", "This editor's editing access has been revoked"},
+	{"editor.device_banned", "この端末からの編集は禁止されています", "Editing from this device has been banned"},
+	{"editor.create_forbidden", "このマップではピンを作成する権限がありません", "Public editors cannot create pins on this map"},
+	{"editor.invite_required", "このマップの編集には招待コードが必要です", "An invite code is required to edit this map"},
+	{"editor.invite_invalid", "招待コードが無効か、有効期限または利用回数の上限を過ぎています", "The invite code is invalid, expired or has reached its usage limit"},
+	{"editor.invite_not_found", "招待コードが見つかりません", "Invite code not found"},
+	{"editor.upload_forbidden", "このマップでは画像を追加する権限がありません", "Public editors cannot add images on this map"},
+
+	// 内容フィルター
+	{"filter.banned_word", "%sに使用できない言葉が含まれています", "%s contains words that are not allowed"},
+	{"filter.url", "%sにURLを含めることはできません", "%s must not contain URLs"},
+	{"filter.too_long", "%sは%d文字以内で入力してください", "%s must be at most %d characters"},
+	{"filter.word_exists", "この禁止語は既に登録されています", "This banned word is already registered"},
+	{"filter.word_not_found", "禁止語が見つかりません", "Banned word not found"},
+	{"filter.word_empty", "禁止語には文字を含めてください", "The banned word must contain letters"},
+
+	// 多言語コンテンツ
+	{"translation.unsupported_locale", "対応していない言語です", "Unsupported language"},
+	{"translation.default_locale", "デフォルト言語の内容はマップ・フロア・ピンを直接編集してください", "Edit the map, floors and pins directly for the default language"},
+	{"translation.fetch_failed", "翻訳の取得に失敗しました", "Failed to fetch translations"},
+}
+
+// validationMessages はバインディング検証エラーのタグごとの文言
+// %[1]sは項目名、%[2]sはタグのパラメータ
+var validationMessages = map[string]map[string]string{
+	"required": {
+		"ja": "%[1]sは必須です",
+		"en": "%[1]s is required",
+	},
+	"min": {
+		"ja": "%[1]sは%[2]s以上で指定してください",
+		"en": "%[1]s must be at least %[2]s",
+	},
+	"max": {
+		"ja": "%[1]sは%[2]s以下で指定してください",
+		"en": "%[1]s must be at most %[2]s",
+	},
+	"len": {
+		"ja": "%[1]sは%[2]sで指定してください",
+		"en": "%[1]s must have a length of %[2]s",
+	},
+	"gte": {
+		"ja": "%[1]sは%[2]s以上で指定してください",
+		"en": "%[1]s must be greater than or equal to %[2]s",
+	},
+	"lte": {
+		"ja": "%[1]sは%[2]s以下で指定してください",
+		"en": "%[1]s must be less than or equal to %[2]s",
+	},
+	"oneof": {
+		"ja": "%[1]sは次のいずれかを指定してください: %[2]s",
+		"en": "%[1]s must be one of: %[2]s",
+	},
+	"email": {
+		"ja": "%[1]sには有効なメールアドレスを指定してください",
+		"en": "%[1]s must be a valid email address",
+	},
+	"url": {
+		"ja": "%[1]sには有効なURLを指定してください",
+		"en": "%[1]s must be a valid URL",
+	},
+	"default": {
+		"ja": "%[1]sの値が不正です",
+		"en": "%[1]s is invalid",
+	},
+}
@@ -0,0 +1,191 @@
+// backend/i18n/i18n.go
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// 対応言語（先頭がデフォルト）
+var locales = []string{"ja", "en"}
+
+var (
+	matcher = utils.NewLocaleMatcher(locales)
+	byKey   = map[string]*entry{}
+	byText  = map[string]*entry{}
+)
+
+func init() {
+	for i := range catalog {
+		e := &catalog[i]
+		byKey[e.key] = e
+		byText[e.ja] = e
+	}
+}
+
+// FieldError は項目ごとの検証エラーを表す
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Setup はバインディング検証エラーの項目名にJSONのキー名を使用するよう設定する
+func Setup() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name := strings.Split(field.Tag.Get(tag), ",")[0]
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+}
+
+// Locale は?lang=またはAccept-Languageからレスポンスの言語を選択する
+func Locale(ctx *gin.Context) string {
+	return matcher.Match(ctx.Query("lang"), ctx.GetHeader("Accept-Language"))
+}
+
+// Message はメッセージを指定した言語に翻訳し、メッセージキーと共に返す
+// メッセージは日本語の文言またはキーで指定する（値を埋め込んだ後の文言は一致しないため、書式文字列で指定する）
+// カタログにないメッセージはそのまま返し、キーは空になる
+func Message(locale, message string) (string, string) {
+	e, ok := byText[message]
+	if !ok {
+		e, ok = byKey[message]
+	}
+	if !ok {
+		return message, ""
+	}
+
+	if locale == "en" {
+		return e.en, e.key
+	}
+	return e.ja, e.key
+}
+
+// RespondError はエラーメッセージを閲覧者の言語に翻訳して返す
+// extraに指定した項目はレスポンスに追加する
+func RespondError(ctx *gin.Context, status int, message string, extra ...gin.H) {
+	text, key := Message(Locale(ctx), message)
+
+	body := gin.H{"error": text}
+	if key != "" {
+		body["code"] = key
+	}
+	for _, h := range extra {
+		for k, v := range h {
+			body[k] = v
+		}
+	}
+
+	ctx.JSON(status, body)
+}
+
+// RespondErrorf は書式付きのエラーメッセージを翻訳して返す
+// formatには日本語の書式文字列を指定する
+func RespondErrorf(ctx *gin.Context, status int, format string, args ...interface{}) {
+	text, key := Message(Locale(ctx), format)
+
+	body := gin.H{"error": fmt.Sprintf(text, args...)}
+	if key != "" {
+		body["code"] = key
+	}
+
+	ctx.JSON(status, body)
+}
+
+// formattedError は書式と引数に分けて翻訳できるエラー
+type formattedError interface {
+	error
+	Format() string
+	Args() []interface{}
+}
+
+// RespondErrorOf はエラーを翻訳して返す
+// 書式と引数を持つエラーは書式を翻訳してから値を埋め込み、それ以外はエラーメッセージをそのまま翻訳する
+func RespondErrorOf(ctx *gin.Context, status int, err error) {
+	var fe formattedError
+	if errors.As(err, &fe) {
+		RespondErrorf(ctx, status, fe.Format(), fe.Args()...)
+		return
+	}
+	RespondError(ctx, status, err.Error())
+}
+
+// RespondBindingError はリクエストのバインディングエラーを項目ごとのメッセージにして返す
+func RespondBindingError(ctx *gin.Context, err error) {
+	locale := Locale(ctx)
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			text, _ := Message(locale, "request.validation_failed")
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": text,
+				"code":  "request.validation_failed",
+				"details": []FieldError{
+					{Field: typeErr.Field, Message: validationMessage(locale, "default", typeErr.Field, "")},
+				},
+			})
+		case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+			RespondError(ctx, http.StatusBadRequest, "request.invalid_json")
+		default:
+			RespondError(ctx, http.StatusBadRequest, "request.invalid")
+		}
+		return
+	}
+
+	details := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		details[i] = FieldError{
+			Field:   fe.Field(),
+			Message: validationMessage(locale, fe.Tag(), fe.Field(), fe.Param()),
+		}
+	}
+
+	ctx.JSON(http.StatusBadRequest, gin.H{
+		"error":   details[0].Message,
+		"code":    "request.validation_failed",
+		"details": details,
+	})
+}
+
+// validationMessage は検証タグに対応するメッセージを生成する
+func validationMessage(locale, tag, field, param string) string {
+	messages, ok := validationMessages[tag]
+	if !ok {
+		messages = validationMessages["default"]
+	}
+
+	if tag == "oneof" {
+		param = strings.ReplaceAll(param, " ", ", ")
+	}
+
+	format, ok := messages[locale]
+	if !ok {
+		format = messages[locales[0]]
+	}
+	return fmt.Sprintf(format, field, param)
+}
@@ -2,20 +2,34 @@
 package middlewares
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/utils"
 )
 
+// AccessTokenAuthenticator は個人用アクセストークンを検証する関数
+// 無効なトークンの場合はnilを返す
+type AccessTokenAuthenticator func(ctx context.Context, token string) (*models.AccessTokenPrincipal, error)
+
+// AccessTokenScopes は個人用アクセストークンで呼び出せるAPIと必要な権限の対応
+// キーは "GET /api/maps/:mapId" のようにメソッドとルートのパスを空白で区切ったもの
+// 値が空文字のAPIはどの権限でも呼び出せる。登録されていないAPIは呼び出せない
+type AccessTokenScopes map[string]string
+
 // AuthMiddleware は認証を検証するミドルウェア
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// JWTに加えて個人用アクセストークンも受け付け、アクセストークンの場合は権限を検証する
+func AuthMiddleware(jwtSecret string, accessTokens AccessTokenAuthenticator, scopes AccessTokenScopes) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Authorizationヘッダーを取得
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "認証が必要です"})
+			i18n.RespondError(c, http.StatusUnauthorized, "認証が必要です")
 			c.Abort()
 			return
 		}
@@ -23,15 +37,21 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		// Bearerトークンを取得
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "認証形式が不正です"})
+			i18n.RespondError(c, http.StatusUnauthorized, "認証形式が不正です")
 			c.Abort()
 			return
 		}
 
+		// 個人用アクセストークンは先頭の文字列でJWTと区別する
+		if strings.HasPrefix(parts[1], models.PersonalAccessTokenPrefix) {
+			authenticateAccessToken(c, parts[1], accessTokens, scopes)
+			return
+		}
+
 		// トークンを検証
 		claims, err := utils.ValidateToken(parts[1], jwtSecret)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "無効なトークンです"})
+			i18n.RespondError(c, http.StatusUnauthorized, "無効なトークンです")
 			c.Abort()
 			return
 		}
@@ -39,6 +59,66 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 		// ユーザー情報をコンテキストに保存
 		c.Set("userID", claims.UserID)
 		c.Set("userRole", claims.Role)
+		c.Set("twoFactor", claims.TwoFactor)
+		c.Next()
+	}
+}
+
+// authenticateAccessToken は個人用アクセストークンを検証し、呼び出すAPIに必要な権限があるか確認する
+func authenticateAccessToken(c *gin.Context, token string, accessTokens AccessTokenAuthenticator, scopes AccessTokenScopes) {
+	principal, err := accessTokens(c, token)
+	if err != nil {
+		log.Printf("アクセストークンの検証に失敗しました: %v", err)
+		i18n.RespondError(c, http.StatusInternalServerError, "サーバーエラーが発生しました")
+		c.Abort()
+		return
+	}
+	if principal == nil {
+		i18n.RespondError(c, http.StatusUnauthorized, "無効なトークンです")
+		c.Abort()
+		return
+	}
+
+	required, ok := scopes[c.Request.Method+" "+c.FullPath()]
+	if !ok {
+		i18n.RespondError(c, http.StatusForbidden, "このAPIはアクセストークンでは利用できません")
+		c.Abort()
+		return
+	}
+	if required != "" && !hasScope(principal.Scopes, required) {
+		i18n.RespondError(c, http.StatusForbidden, "アクセストークンにこの操作の権限がありません")
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", principal.UserID)
+	c.Set("userRole", principal.Role)
+	c.Set("twoFactor", false)
+	c.Set("accessTokenID", principal.TokenID)
+	c.Next()
+}
+
+// hasScope は権限の一覧に指定した権限が含まれるか判定する
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// OptionalAuthMiddleware は有効なトークンがあればユーザー情報を設定するミドルウェア
+// トークンがない、または無効な場合も処理を続行する
+func OptionalAuthMiddleware(jwtSecret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		parts := strings.Split(c.GetHeader("Authorization"), " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			if claims, err := utils.ValidateToken(parts[1], jwtSecret); err == nil {
+				c.Set("userID", claims.UserID)
+				c.Set("userRole", claims.Role)
+			}
+		}
 		c.Next()
 	}
 }
@@ -48,7 +128,32 @@ func AdminMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("userRole")
 		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{"error": "管理者権限が必要です"})
+			i18n.RespondError(c, http.StatusForbidden, "管理者権限が必要です")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// AdminTwoFactorMiddleware は管理者に二段階認証が必須の場合、二段階認証を完了したログインか検証するミドルウェア
+// AdminMiddlewareの後に使用する
+func AdminTwoFactorMiddleware(required func(ctx context.Context) (bool, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool("twoFactor") {
+			c.Next()
+			return
+		}
+
+		isRequired, err := required(c)
+		if err != nil {
+			log.Printf("セキュリティ設定の取得に失敗しました: %v", err)
+			i18n.RespondError(c, http.StatusInternalServerError, "サーバーエラーが発生しました")
+			c.Abort()
+			return
+		}
+		if isRequired {
+			i18n.RespondError(c, http.StatusForbidden, "管理者は二段階認証を有効にしてログインする必要があります")
 			c.Abort()
 			return
 		}
@@ -0,0 +1,48 @@
+// backend/middlewares/public_editor_middleware.go
+package middlewares
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// PublicEditorAuthenticator は公開編集者のIDとトークンを検証する関数
+// 編集者が存在しないかトークンが一致しない場合はnilを返す
+type PublicEditorAuthenticator func(ctx context.Context, editorID, token string) (*models.PublicEditor, error)
+
+// PublicEditorMiddleware は公開編集者を検証するミドルウェア
+// X-Editor-IdとX-Editor-Tokenヘッダーで編集者を特定し、編集者IDとニックネームをコンテキストに保存する
+// 編集禁止・権限の取り消しはサービスで確認する
+func PublicEditorMiddleware(authenticate PublicEditorAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		editorID := c.GetHeader("X-Editor-Id")
+		token := c.GetHeader("X-Editor-Token")
+		if editorID == "" || token == "" {
+			i18n.RespondError(c, http.StatusUnauthorized, "編集者の認証が必要です")
+			c.Abort()
+			return
+		}
+
+		editor, err := authenticate(c, editorID, token)
+		if err != nil {
+			log.Printf("公開編集者の検証に失敗しました: %v", err)
+			i18n.RespondError(c, http.StatusInternalServerError, "サーバーエラーが発生しました")
+			c.Abort()
+			return
+		}
+		if editor == nil {
+			i18n.RespondError(c, http.StatusUnauthorized, "無効なトークンです")
+			c.Abort()
+			return
+		}
+
+		c.Set("editorID", editor.ID)
+		c.Set("editorNickname", editor.Nickname)
+		c.Next()
+	}
+}
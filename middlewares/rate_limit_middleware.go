@@ -0,0 +1,67 @@
+// backend/middlewares/rate_limit_middleware.go
+package middlewares
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shimaf4979/pamfree-backend/i18n"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// RateLimitKeyFunc はリクエストから回数制限のキーを決める
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByIP はIPアドレスごとに回数を制限する
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUser はログインユーザーごとに回数を制限する（未ログインの場合はIPアドレス）
+func RateLimitByUser(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "user:" + userID
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByEditor は公開編集者ごとに回数を制限する（編集者を検証していない場合はIPアドレス）
+// PublicEditorMiddlewareで検証した編集者IDを使う
+func RateLimitByEditor(c *gin.Context) string {
+	if editorID := c.GetString("editorID"); editorID != "" {
+		return "editor:" + editorID
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitByParam はURLパラメータの値ごとに回数を制限する
+// 利用者を特定できないエンドポイントで、対象（マップ・共有リンクなど）ごとの上限として使う
+func RateLimitByParam(name string) RateLimitKeyFunc {
+	return func(c *gin.Context) string {
+		return name + ":" + c.Param(name)
+	}
+}
+
+// RateLimitMiddleware はトークンバケットによりリクエストの回数を制限するミドルウェア
+// nameごとに別のバケットを使い、制限を超えた場合はRetry-Afterヘッダーを付けて429を返す
+// ストアの障害時はリクエストを通す
+func RateLimitMiddleware(store utils.RateLimitStore, name string, rate utils.Rate, keyFunc RateLimitKeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, retryAfter, err := store.Take(c, name+":"+keyFunc(c), rate)
+		if err != nil {
+			log.Printf("回数制限の確認に失敗しました: %v", err)
+			c.Next()
+			return
+		}
+
+		if !allowed {
+			c.Header("Retry-After", utils.RetryAfterSeconds(retryAfter))
+			i18n.RespondError(c, http.StatusTooManyRequests, "操作が多すぎます。しばらくしてから再度お試しください")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
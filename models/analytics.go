@@ -0,0 +1,89 @@
+// backend/models/analytics.go
+package models
+
+import (
+	"time"
+)
+
+// ビューワーのイベント種別
+const (
+	AnalyticsEventMapView     = "map_view"
+	AnalyticsEventPinOpen     = "pin_open"
+	AnalyticsEventFloorSwitch = "floor_switch"
+	AnalyticsEventSearch      = "search"
+)
+
+// 集計の粒度
+const (
+	AnalyticsGranularityHour = "hour"
+	AnalyticsGranularityDay  = "day"
+)
+
+// AnalyticsEvent はビューワーから送信されるイベントを表す構造体
+// 個人を特定できる情報（IP・ユーザーエージェント等）は受け取らない
+type AnalyticsEvent struct {
+	Type       string     `json:"type" binding:"required,oneof=pin_open floor_switch search"`
+	FloorID    string     `json:"floor_id"`
+	PinID      string     `json:"pin_id"`
+	Query      string     `json:"query"`
+	OccurredAt *time.Time `json:"occurred_at"`
+}
+
+// AnalyticsBatch はまとめて送信されるイベントを表す構造体
+type AnalyticsBatch struct {
+	Events []AnalyticsEvent `json:"events" binding:"required,min=1,max=100,dive"`
+}
+
+// AnalyticsRollup は1時間単位の集計値を表す構造体
+type AnalyticsRollup struct {
+	MapID      string    `json:"map_id" db:"map_id"`
+	BucketHour time.Time `json:"bucket_hour" db:"bucket_hour"`
+	EventType  string    `json:"event_type" db:"event_type"`
+	Dimension  string    `json:"dimension" db:"dimension"` // ピンID・フロアID・検索語
+	Count      int       `json:"count" db:"count"`
+}
+
+// AnalyticsPoint は時系列の1点を表す構造体
+type AnalyticsPoint struct {
+	Bucket time.Time `json:"bucket"`
+	Count  int       `json:"count"`
+}
+
+// AnalyticsDimensionCount は集計軸ごとの件数を表す構造体
+type AnalyticsDimensionCount struct {
+	Dimension string `json:"dimension"`
+	Count     int    `json:"count"`
+}
+
+// AnalyticsTopPin はよく開かれたピンを表す構造体
+type AnalyticsTopPin struct {
+	PinID string `json:"pin_id"`
+	Title string `json:"title"`
+	Count int    `json:"count"`
+}
+
+// AnalyticsTopFloor はよく表示されたフロアを表す構造体
+type AnalyticsTopFloor struct {
+	FloorID string `json:"floor_id"`
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+}
+
+// AnalyticsTopSearch はよく検索された語を表す構造体
+type AnalyticsTopSearch struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+// AnalyticsDashboard はマップ所有者向けの利用状況を表す構造体
+type AnalyticsDashboard struct {
+	MapID       string                `json:"map_id"`
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	Granularity string                `json:"granularity"`
+	Totals      map[string]int        `json:"totals"`
+	Views       []*AnalyticsPoint     `json:"views"`
+	TopPins     []*AnalyticsTopPin    `json:"top_pins"`
+	TopFloors   []*AnalyticsTopFloor  `json:"top_floors"`
+	TopSearches []*AnalyticsTopSearch `json:"top_searches"`
+}
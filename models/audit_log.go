@@ -0,0 +1,87 @@
+// backend/models/audit_log.go
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 監査ログの操作
+const (
+	AuditActionLogin            = "auth.login"
+	AuditActionLoginFailed      = "auth.login_failed"
+	AuditActionPasswordChange   = "user.password_change"
+	AuditActionRoleChange       = "user.role_change"
+	AuditActionUserDelete       = "user.delete"
+	AuditActionMapDelete        = "map.delete"
+	AuditActionMapPublicEdit    = "map.public_edit"
+	AuditActionMapTransfer      = "map.transfer"
+	AuditActionImageDelete      = "image.delete"
+	AuditActionTwoFactorEnable  = "user.2fa_enable"
+	AuditActionTwoFactorDisable = "user.2fa_disable"
+	AuditActionIdentityLink     = "user.identity_link"
+	AuditActionIdentityUnlink   = "user.identity_unlink"
+	AuditActionTokenCreate      = "user.token_create"
+	AuditActionTokenRevoke      = "user.token_revoke"
+	AuditActionSecuritySettings = "settings.security"
+	AuditActionOrgDelete        = "organization.delete"
+	AuditActionOrgMemberAdd     = "organization.member_add"
+	AuditActionOrgMemberRole    = "organization.member_role"
+	AuditActionOrgMemberRemove  = "organization.member_remove"
+)
+
+// 監査ログの対象の種別
+const (
+	AuditTargetUser     = "user"
+	AuditTargetMap      = "map"
+	AuditTargetImage    = "image"
+	AuditTargetSettings = "settings"
+	AuditTargetOrg      = "organization"
+)
+
+// AuditLog は監査ログの1件を表す構造体
+// Before・Afterは変更前後の状態をJSONで保持する
+type AuditLog struct {
+	ID         string          `json:"id" db:"id"`
+	ActorID    string          `json:"actor_id" db:"actor_id"`
+	Action     string          `json:"action" db:"action"`
+	TargetType string          `json:"target_type" db:"target_type"`
+	TargetID   string          `json:"target_id" db:"target_id"`
+	IP         string          `json:"ip" db:"ip"`
+	UserAgent  string          `json:"user_agent" db:"user_agent"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before_data"`
+	After      json.RawMessage `json:"after,omitempty" db:"after_data"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogFilter は監査ログの検索条件を表す構造体
+// 空の条件では絞り込まない
+type AuditLogFilter struct {
+	ActorID string
+	Action  string
+	From    time.Time
+	To      time.Time
+	Limit   int
+	Offset  int
+}
+
+// AuditLogPage は監査ログ一覧の1ページを表す構造体
+type AuditLogPage struct {
+	Logs   []*AuditLog `json:"logs"`
+	Total  int         `json:"total"`
+	Limit  int         `json:"limit"`
+	Offset int         `json:"offset"`
+}
+
+// AuditLogColumns は監査ログのCSV出力の列（ヘッダー）
+var AuditLogColumns = []string{
+	"created_at",
+	"actor_id",
+	"action",
+	"target_type",
+	"target_id",
+	"ip",
+	"user_agent",
+	"before",
+	"after",
+}
@@ -0,0 +1,74 @@
+// backend/models/content_filter.go
+package models
+
+import (
+	"time"
+)
+
+// 内容フィルターに該当した場合の対応
+const (
+	FilterActionReject   = "reject"   // 投稿を拒否する
+	FilterActionModerate = "moderate" // 投稿を非表示にして所有者の確認を待つ
+)
+
+// 内容フィルターに該当した理由
+const (
+	FilterReasonBannedWord = "banned_word"
+	FilterReasonURL        = "url"
+	FilterReasonTooLong    = "too_long"
+)
+
+// 内容フィルターの対象の種別
+const (
+	FilterFieldNickname    = "nickname"
+	FilterFieldTitle       = "title"
+	FilterFieldDescription = "description"
+)
+
+// ContentFilterSettings はマップごとの内容フィルターの設定を表す構造体
+type ContentFilterSettings struct {
+	MapID                string `json:"map_id" db:"map_id"`
+	Action               string `json:"action" db:"action"`
+	BlockURLs            bool   `json:"block_urls" db:"block_urls"`
+	MaxNicknameLength    int    `json:"max_nickname_length" db:"max_nickname_length"`
+	MaxTitleLength       int    `json:"max_title_length" db:"max_title_length"`
+	MaxDescriptionLength int    `json:"max_description_length" db:"max_description_length"`
+}
+
+// ContentFilterSettingsUpdate は内容フィルターの設定の更新リクエストを表す構造体
+type ContentFilterSettingsUpdate struct {
+	Action               *string `json:"action" binding:"omitempty,oneof=reject moderate"`
+	BlockURLs            *bool   `json:"block_urls"`
+	MaxNicknameLength    *int    `json:"max_nickname_length" binding:"omitempty,min=1,max=255"`
+	MaxTitleLength       *int    `json:"max_title_length" binding:"omitempty,min=1,max=255"`
+	MaxDescriptionLength *int    `json:"max_description_length" binding:"omitempty,min=1,max=10000"`
+}
+
+// ContentFilterWord は禁止語を表す構造体
+type ContentFilterWord struct {
+	ID         string    `json:"id" db:"id"`
+	MapID      *string   `json:"map_id,omitempty" db:"map_id"` // NULLの場合はすべてのマップに適用する
+	Word       string    `json:"word" db:"word"`
+	Normalized string    `json:"-" db:"normalized"`
+	CreatedBy  string    `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ContentFilterWordCreate は禁止語の追加リクエストを表す構造体
+type ContentFilterWordCreate struct {
+	Word string `json:"word" binding:"required,max=100"`
+}
+
+// FilterField は内容フィルターで検査する項目を表す構造体
+type FilterField struct {
+	Name  string // エラーに表示する項目名（JSONのキー）
+	Kind  string // 最大文字数の判定に使う種別
+	Value string
+}
+
+// ContentFilterViolation は内容フィルターに該当した項目を表す構造体
+type ContentFilterViolation struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+	Limit  int    `json:"limit,omitempty"`
+}
@@ -0,0 +1,84 @@
+// backend/models/content_report.go
+package models
+
+import (
+	"time"
+)
+
+// 通報の対象の種別
+const (
+	ReportTargetPin     = "pin"
+	ReportTargetEditor  = "editor"
+	ReportTargetComment = "comment"
+)
+
+// 内容フィルターによる自動の通報
+const (
+	ReportReasonContentFilter = "content_filter"
+	ReporterTypeSystem        = "system"
+)
+
+// 通報された内容の対応状況
+const (
+	FlagStatusOpen      = "open"
+	FlagStatusHidden    = "hidden"
+	FlagStatusDismissed = "dismissed"
+	FlagStatusRemoved   = "removed"
+)
+
+// 通報された内容への対応
+const (
+	FlagActionDismiss = "dismiss"
+	FlagActionHide    = "hide"
+	FlagActionRemove  = "remove"
+	FlagActionBan     = "ban"
+)
+
+// ContentFlag は通報された内容ごとの対応状況を表す構造体
+type ContentFlag struct {
+	ID          string           `json:"id" db:"id"`
+	MapID       string           `json:"map_id" db:"map_id"`
+	TargetType  string           `json:"target_type" db:"target_type"`
+	TargetID    string           `json:"target_id" db:"target_id"`
+	ReportCount int              `json:"report_count" db:"report_count"`
+	Status      string           `json:"status" db:"status"`
+	HiddenAt    *time.Time       `json:"hidden_at,omitempty" db:"hidden_at"`
+	ResolvedBy  *string          `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolvedAt  *time.Time       `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+	Preview     string           `json:"preview" db:"-"`
+	Reports     []*ContentReport `json:"reports,omitempty" db:"-"`
+}
+
+// ContentReport は個別の通報を表す構造体
+type ContentReport struct {
+	ID           string    `json:"id" db:"id"`
+	FlagID       string    `json:"flag_id" db:"flag_id"`
+	Reason       string    `json:"reason" db:"reason"`
+	Details      string    `json:"details" db:"details"`
+	ReporterType string    `json:"reporter_type" db:"reporter_type"`
+	ReporterID   string    `json:"-" db:"reporter_id"` // 通報者の識別子はJSONに含めない
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ContentReportCreate は通報リクエストを表す構造体
+type ContentReportCreate struct {
+	TargetType string `json:"target_type" binding:"required,oneof=pin editor comment"`
+	TargetID   string `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required,oneof=spam offensive inappropriate other"`
+	Details    string `json:"details" binding:"max=500"`
+}
+
+// ContentFlagAction は通報された内容への対応リクエストを表す構造体
+type ContentFlagAction struct {
+	Action string `json:"action" binding:"required,oneof=dismiss hide remove ban"`
+}
+
+// ContentFlagPage は通報一覧の1ページを表す構造体
+type ContentFlagPage struct {
+	Flags  []*ContentFlag `json:"flags"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
@@ -0,0 +1,43 @@
+// backend/models/geojson.go
+package models
+
+// GeoJSONの座標系
+const (
+	CoordinateSystemPixel = "floor-pixel"
+	CoordinateSystemGeo   = "wgs84"
+)
+
+// GeoJSONFeatureCollection はGeoJSONのFeatureCollectionを表す構造体
+// CoordinateSystem はフロア画像座標で出力した場合にその旨を示す独自メンバー
+type GeoJSONFeatureCollection struct {
+	Type             string            `json:"type"`
+	CoordinateSystem string            `json:"coordinate_system,omitempty"`
+	Features         []*GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature はGeoJSONのFeatureを表す構造体
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   *GeoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry はGeoJSONのPointジオメトリを表す構造体
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+// GeoJSONImportResult はGeoJSONインポート結果を表す構造体
+type GeoJSONImportResult struct {
+	Created int                  `json:"created"`
+	Updated int                  `json:"updated"`
+	Errors  []GeoJSONImportError `json:"errors"`
+}
+
+// GeoJSONImportError はインポートできなかったFeatureを表す構造体
+type GeoJSONImportError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
+}
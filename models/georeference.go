@@ -0,0 +1,45 @@
+// backend/models/georeference.go
+package models
+
+import (
+	"time"
+)
+
+// ControlPoint は画像座標と緯度経度の対応点を表す構造体
+// 赤道・本初子午線上の0も正しい値のため、緯度経度はrequiredではなく範囲で検証する
+type ControlPoint struct {
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Latitude  float64 `json:"latitude" binding:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude" binding:"gte=-180,lte=180"`
+}
+
+// FloorGeoreference はフロア画像の地理参照情報を表す構造体
+// Transform は画像座標(x, y)から(経度, 緯度)へのアフィン変換係数 [a, b, c, d, e, f] で、
+// lng = a*x + b*y + c, lat = d*x + e*y + f となる
+type FloorGeoreference struct {
+	FloorID       string         `json:"floor_id" db:"floor_id"`
+	ControlPoints []ControlPoint `json:"control_points" db:"control_points"`
+	Transform     []float64      `json:"transform" db:"transform"`
+	RMSError      float64        `json:"rms_error" db:"rms_error"`
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// FloorGeoreferenceUpdate はフロアのキャリブレーションリクエストを表す構造体
+type FloorGeoreferenceUpdate struct {
+	ControlPoints []ControlPoint `json:"control_points" binding:"required,min=3,dive"`
+}
+
+// GeoLocate はGPS位置からフロア座標への変換リクエストを表す構造体
+type GeoLocate struct {
+	Latitude  float64 `json:"latitude" binding:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude" binding:"gte=-180,lte=180"`
+}
+
+// GeoLocateResponse はGPS位置に対応するフロア座標を表す構造体
+type GeoLocateResponse struct {
+	FloorID   string  `json:"floor_id"`
+	XPosition float64 `json:"x_position"`
+	YPosition float64 `json:"y_position"`
+}
@@ -5,23 +5,38 @@ import (
 	"time"
 )
 
+// マップの公開状態
+const (
+	MapStatusDraft     = "draft"
+	MapStatusPublished = "published"
+	MapStatusArchived  = "archived"
+)
+
 // Map はマップ情報を表す構造体
 type Map struct {
-	ID                 string    `json:"id" db:"id"`
-	Title              string    `json:"title" db:"title"`
-	Description        string    `json:"description" db:"description"`
-	UserID             string    `json:"user_id" db:"user_id"`
-	IsPubliclyEditable bool      `json:"is_publicly_editable" db:"is_publicly_editable"`
-	CreatedAt          time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	ID                 string     `json:"id" db:"id"`
+	Slug               *string    `json:"slug,omitempty" db:"slug"`
+	Title              string     `json:"title" db:"title"`
+	Description        string     `json:"description" db:"description"`
+	UserID             string     `json:"user_id,omitempty" db:"user_id"`                 // 組織のマップでは空
+	OrganizationID     *string    `json:"organization_id,omitempty" db:"organization_id"` // 個人のマップではnil
+	IsPubliclyEditable bool       `json:"is_publicly_editable" db:"is_publicly_editable"`
+	Status             string     `json:"status" db:"status"`
+	PublishAt          *time.Time `json:"publish_at,omitempty" db:"publish_at"`
+	UnpublishAt        *time.Time `json:"unpublish_at,omitempty" db:"unpublish_at"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 // MapCreate はマップ作成リクエストを表す構造体
 type MapCreate struct {
 	ID                 string `json:"id" binding:"required"`
+	Slug               string `json:"slug"`
 	Title              string `json:"title" binding:"required"`
 	Description        string `json:"description"`
 	IsPubliclyEditable bool   `json:"is_publicly_editable"`
+	Status             string `json:"status" binding:"omitempty,oneof=draft published"`
+	OrganizationID     string `json:"organization_id"` // 指定した場合は組織のマップとして作成する
 }
 
 // MapUpdate はマップ更新リクエストを表す構造体
@@ -30,3 +45,29 @@ type MapUpdate struct {
 	Description        string `json:"description"`
 	IsPubliclyEditable bool   `json:"is_publicly_editable"`
 }
+
+// MapPublicationUpdate はマップの公開状態と公開予約の更新リクエストを表す構造体
+type MapPublicationUpdate struct {
+	Status      string     `json:"status" binding:"required,oneof=draft published archived"`
+	PublishAt   *time.Time `json:"publish_at"`
+	UnpublishAt *time.Time `json:"unpublish_at"`
+}
+
+// MapSlugUpdate はマップのスラッグ更新リクエストを表す構造体
+type MapSlugUpdate struct {
+	Slug string `json:"slug" binding:"required"`
+}
+
+// SlugAvailability はスラッグの利用可否を表す構造体
+type SlugAvailability struct {
+	Slug      string `json:"slug"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// MapTransfer はマップの所有者の移管リクエストを表す構造体
+// 移管先のユーザーのメールアドレスか組織のIDのどちらか一方を指定する
+type MapTransfer struct {
+	UserEmail      string `json:"user_email" binding:"omitempty,email"`
+	OrganizationID string `json:"organization_id"`
+}
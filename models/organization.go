@@ -0,0 +1,65 @@
+// backend/models/organization.go
+package models
+
+import (
+	"time"
+)
+
+// 組織のメンバーの役割
+const (
+	OrganizationRoleOwner  = "owner"  // 組織の削除・所有者の変更ができる
+	OrganizationRoleAdmin  = "admin"  // メンバーの管理・マップの移管ができる
+	OrganizationRoleMember = "member" // 組織のマップを編集できる
+)
+
+// Organization は実行委員会などの組織を表す構造体
+// Roleは取得したユーザーの組織での役割
+type Organization struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Role      string    `json:"role,omitempty" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsOrganizationManager は組織のメンバーとマップを管理できる役割か判定する
+func IsOrganizationManager(role string) bool {
+	return role == OrganizationRoleOwner || role == OrganizationRoleAdmin
+}
+
+// OrganizationMember は組織のメンバーを表す構造体
+type OrganizationMember struct {
+	OrganizationID string    `json:"organization_id" db:"organization_id"`
+	UserID         string    `json:"user_id" db:"user_id"`
+	Name           string    `json:"name" db:"name"`
+	Email          string    `json:"email" db:"email"`
+	Role           string    `json:"role" db:"role"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// OrganizationDetail は組織とメンバーの一覧を表す構造体
+type OrganizationDetail struct {
+	*Organization
+	Members []*OrganizationMember `json:"members"`
+}
+
+// OrganizationCreate は組織作成リクエストを表す構造体
+type OrganizationCreate struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// OrganizationUpdate は組織更新リクエストを表す構造体
+type OrganizationUpdate struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// OrganizationMemberAdd はメンバー追加リクエストを表す構造体
+type OrganizationMemberAdd struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin member"`
+}
+
+// OrganizationMemberUpdate はメンバーの役割の変更リクエストを表す構造体
+type OrganizationMemberUpdate struct {
+	Role string `json:"role" binding:"required,oneof=owner admin member"`
+}
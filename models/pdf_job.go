@@ -0,0 +1,26 @@
+// backend/models/pdf_job.go
+package models
+
+import (
+	"time"
+)
+
+// PDF生成ジョブの状態
+const (
+	JobStatusQueued     = "queued"
+	JobStatusProcessing = "processing"
+	JobStatusCompleted  = "completed"
+	JobStatusFailed     = "failed"
+)
+
+// PDFJob は印刷用パンフレットPDFの生成ジョブを表す構造体
+type PDFJob struct {
+	ID          string     `json:"id" db:"id"`
+	MapID       string     `json:"map_id" db:"map_id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	Status      string     `json:"status" db:"status"`
+	Error       string     `json:"error,omitempty" db:"error"`
+	FilePath    string     `json:"-" db:"file_path"` // サーバー上の保存先はJSONに含めない
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
@@ -0,0 +1,57 @@
+// backend/models/personal_access_token.go
+package models
+
+import (
+	"time"
+)
+
+// PersonalAccessTokenPrefix は個人用アクセストークンの先頭に付ける文字列
+// AuthMiddlewareはこの文字列でJWTと区別する
+const PersonalAccessTokenPrefix = "pf_pat_"
+
+// 個人用アクセストークンの権限
+const (
+	ScopeMapsRead     = "maps:read"     // マップ・フロア・ピンの閲覧
+	ScopePinsWrite    = "pins:write"    // ピンの作成・更新・削除
+	ScopeFloorsManage = "floors:manage" // フロアの作成・更新・削除
+)
+
+// PersonalAccessTokenScopes は個人用アクセストークンに指定できる権限の一覧
+var PersonalAccessTokenScopes = []string{ScopeMapsRead, ScopePinsWrite, ScopeFloorsManage}
+
+// PersonalAccessToken はスクリプトなどの自動処理に使う個人用アクセストークンを表す構造体
+type PersonalAccessToken struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenHash   string     `json:"-" db:"token_hash"` // トークンのハッシュはJSONに含めない
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PersonalAccessTokenCreate は個人用アクセストークン作成リクエストを表す構造体
+// ExpiresInDaysを省略した場合は無期限
+type PersonalAccessTokenCreate struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,dive,oneof=maps:read pins:write floors:manage"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=365"`
+}
+
+// PersonalAccessTokenCreated は作成した個人用アクセストークンを表す構造体
+// トークン本体は作成時にだけ返す
+type PersonalAccessTokenCreated struct {
+	*PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// AccessTokenPrincipal は個人用アクセストークンで認証したユーザーを表す構造体
+type AccessTokenPrincipal struct {
+	TokenID string
+	UserID  string
+	Role    string
+	Scopes  []string
+}
@@ -11,13 +11,31 @@ type Pin struct {
 	FloorID        string    `json:"floor_id" db:"floor_id"`
 	Title          string    `json:"title" db:"title"`
 	Description    string    `json:"description" db:"description"`
+	Category       string    `json:"category" db:"category"`
 	XPosition      float64   `json:"x_position" db:"x_position"`
 	YPosition      float64   `json:"y_position" db:"y_position"`
 	ImageURL       string    `json:"image_url" db:"image_url"`
 	EditorID       string    `json:"editor_id" db:"editor_id"`
 	EditorNickname string    `json:"editor_nickname" db:"editor_nickname"`
+	ExternalKey    string    `json:"external_key" db:"external_key"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+
+	// 地理参照済みフロアのピンのみ設定される（DBには保存しない）
+	Latitude  *float64 `json:"latitude,omitempty" db:"-"`
+	Longitude *float64 `json:"longitude,omitempty" db:"-"`
+}
+
+// WithoutEditorIDs は編集者IDを除いたピンのコピーを返す
+// 編集者IDは公開編集の操作に使うため、ビューワーのレスポンスには含めない
+func WithoutEditorIDs(pins []*Pin) []*Pin {
+	result := make([]*Pin, len(pins))
+	for i, pin := range pins {
+		copied := *pin
+		copied.EditorID = ""
+		result[i] = &copied
+	}
+	return result
 }
 
 // PinCreate はピン作成リクエストを表す構造体
@@ -25,6 +43,7 @@ type PinCreate struct {
 	FloorID        string  `json:"floor_id" binding:"required"`
 	Title          string  `json:"title" binding:"required"`
 	Description    string  `json:"description"`
+	Category       string  `json:"category"`
 	XPosition      float64 `json:"x_position" binding:"required"`
 	YPosition      float64 `json:"y_position" binding:"required"`
 	ImageURL       string  `json:"image_url"`
@@ -36,5 +55,6 @@ type PinCreate struct {
 type PinUpdate struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
+	Category    string `json:"category"`
 	ImageURL    string `json:"image_url"`
 }
@@ -0,0 +1,104 @@
+// backend/models/pin_comment.go
+package models
+
+import (
+	"time"
+)
+
+// コメント・リアクションの投稿者の種別
+const (
+	AuthorTypeUser    = "user"
+	AuthorTypeVisitor = "visitor"
+)
+
+// コメントの表示状態
+const (
+	CommentStatusVisible = "visible"
+	CommentStatusHidden  = "hidden"
+)
+
+// ReactionEmojis はピンに付けられる絵文字の一覧
+var ReactionEmojis = []string{"👍", "❤️", "😂", "😮", "🎉", "👀"}
+
+// Visitor は匿名の来場者の識別情報を表す構造体
+type Visitor struct {
+	ID    string `json:"visitor_id"`
+	Token string `json:"token"`
+}
+
+// Author はコメント・リアクションの投稿者を表す構造体
+type Author struct {
+	Type string
+	ID   string
+	Name string
+}
+
+// PinComment はピンへのコメントを表す構造体
+type PinComment struct {
+	ID         string    `json:"id" db:"id"`
+	PinID      string    `json:"pin_id" db:"pin_id"`
+	MapID      string    `json:"map_id" db:"map_id"`
+	Body       string    `json:"body" db:"body"`
+	AuthorType string    `json:"author_type" db:"author_type"`
+	AuthorID   string    `json:"-" db:"author_id"` // 投稿者の識別子はJSONに含めない
+	AuthorName string    `json:"author_name" db:"author_name"`
+	Status     string    `json:"status" db:"status"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	IsMine     bool      `json:"is_mine" db:"-"`
+}
+
+// PinCommentCreate はコメント投稿リクエストを表す構造体
+type PinCommentCreate struct {
+	Body     string `json:"body" binding:"required,max=500"`
+	Nickname string `json:"nickname" binding:"max=50"`
+}
+
+// PinCommentModerate はコメントの表示状態の変更リクエストを表す構造体
+type PinCommentModerate struct {
+	Status string `json:"status" binding:"required,oneof=visible hidden"`
+}
+
+// PinCommentPage はコメント一覧の1ページを表す構造体
+type PinCommentPage struct {
+	Comments   []*PinComment `json:"comments"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// PinReactionRequest はリアクション追加リクエストを表す構造体
+type PinReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// PinReactionSummary はピンのリアクションの集計を表す構造体
+type PinReactionSummary struct {
+	PinID     string         `json:"pin_id"`
+	Reactions map[string]int `json:"reactions"`
+	Mine      []string       `json:"mine"`
+}
+
+// PinInteractionCount はピンごとのコメント数・リアクション数を表す構造体
+type PinInteractionCount struct {
+	Comments  int            `json:"comments"`
+	Reactions map[string]int `json:"reactions"`
+}
+
+// MapInteractionSettings はマップのコメント・リアクションの設定を表す構造体
+type MapInteractionSettings struct {
+	MapID            string `json:"map_id" db:"map_id"`
+	CommentsEnabled  bool   `json:"comments_enabled" db:"comments_enabled"`
+	ReactionsEnabled bool   `json:"reactions_enabled" db:"reactions_enabled"`
+}
+
+// MapInteractionSettingsUpdate はコメント・リアクションの設定の更新リクエストを表す構造体
+type MapInteractionSettingsUpdate struct {
+	CommentsEnabled  *bool `json:"comments_enabled"`
+	ReactionsEnabled *bool `json:"reactions_enabled"`
+}
+
+// ViewerInteractions はビューワーに返すコメント・リアクションの情報を表す構造体
+type ViewerInteractions struct {
+	CommentsEnabled  bool                            `json:"comments_enabled"`
+	ReactionsEnabled bool                            `json:"reactions_enabled"`
+	Pins             map[string]*PinInteractionCount `json:"pins"`
+}
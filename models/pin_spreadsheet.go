@@ -0,0 +1,38 @@
+// backend/models/pin_spreadsheet.go
+package models
+
+// PinSpreadsheetColumns はピンの表形式入出力の列（ヘッダー）
+var PinSpreadsheetColumns = []string{
+	"external_key",
+	"floor",
+	"title",
+	"description",
+	"category",
+	"x_position",
+	"y_position",
+	"image_url",
+}
+
+// PinImportRow はインポート対象として検証済みの1行を表す構造体
+type PinImportRow struct {
+	Row    int    `json:"row"`
+	Action string `json:"action"` // create または update
+	PinID  string `json:"pin_id,omitempty"`
+	Pin    *Pin   `json:"pin"`
+}
+
+// PinImportRowError は検証に失敗した行を表す構造体
+type PinImportRowError struct {
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// PinImportResult はピン一括インポートの結果を表す構造体
+type PinImportResult struct {
+	DryRun  bool                `json:"dry_run"`
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Rows    []PinImportRow      `json:"rows"`
+	Errors  []PinImportRowError `json:"errors"`
+}
@@ -0,0 +1,30 @@
+// backend/models/public_edit_invite.go
+package models
+
+import (
+	"time"
+)
+
+// PublicEditInvite は公開編集者の登録に使う招待コードを表す構造体
+type PublicEditInvite struct {
+	ID          string                        `json:"id" db:"id"`
+	MapID       string                        `json:"map_id" db:"map_id"`
+	Code        string                        `json:"code" db:"code"`
+	Label       string                        `json:"label" db:"label"`
+	ExpiresAt   *time.Time                    `json:"expires_at,omitempty" db:"expires_at"`
+	MaxUses     *int                          `json:"max_uses,omitempty" db:"max_uses"`
+	UseCount    int                           `json:"use_count" db:"use_count"`
+	Permissions PublicEditPermissionOverrides `json:"permissions"` // 招待された編集者の権限（未指定はマップの権限に従う）
+	EditorCount int                           `json:"editor_count" db:"-"`
+	RevokedAt   *time.Time                    `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy   string                        `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time                     `json:"created_at" db:"created_at"`
+}
+
+// PublicEditInviteCreate は招待コード作成リクエストを表す構造体
+type PublicEditInviteCreate struct {
+	Label       string                        `json:"label" binding:"max=100"`
+	ExpiresAt   *time.Time                    `json:"expires_at"`
+	MaxUses     *int                          `json:"max_uses" binding:"omitempty,min=1"`
+	Permissions PublicEditPermissionOverrides `json:"permissions"`
+}
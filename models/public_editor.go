@@ -5,20 +5,101 @@ import (
 	"time"
 )
 
+// 公開編集者の状態
+const (
+	PublicEditorStatusActive  = "active"
+	PublicEditorStatusRevoked = "revoked"
+	PublicEditorStatusBanned  = "banned"
+)
+
 // PublicEditor は公開編集者情報を表す構造体
 type PublicEditor struct {
-	ID          string    `json:"id" db:"id"`
-	MapID       string    `json:"map_id" db:"map_id"`
-	Nickname    string    `json:"nickname" db:"nickname"`
-	EditorToken string    `json:"-" db:"editor_token"` // トークンはJSONに含めない
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	LastActive  time.Time `json:"last_active" db:"last_active"`
+	ID                 string     `json:"id" db:"id"`
+	MapID              string     `json:"map_id" db:"map_id"`
+	Nickname           string     `json:"nickname" db:"nickname"`
+	EditorToken        string     `json:"-" db:"editor_token"` // トークンはJSONに含めない
+	NetworkFingerprint string     `json:"-" db:"network_fingerprint"`
+	DeviceFingerprint  string     `json:"-" db:"device_fingerprint"`
+	CreatedAt          time.Time  `json:"created_at" db:"created_at"`
+	LastActive         time.Time  `json:"last_active" db:"last_active"`
+	BannedAt           *time.Time `json:"banned_at,omitempty" db:"banned_at"`
+	RevokedAt          *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	InviteID           *string    `json:"invite_id,omitempty" db:"invite_id"` // 登録に使った招待コード
+}
+
+// Status は公開編集者の状態を返す
+func (e *PublicEditor) Status() string {
+	switch {
+	case e.BannedAt != nil:
+		return PublicEditorStatusBanned
+	case e.RevokedAt != nil:
+		return PublicEditorStatusRevoked
+	default:
+		return PublicEditorStatusActive
+	}
+}
+
+// PublicEditorSummary は所有者向けの公開編集者の一覧項目を表す構造体
+type PublicEditorSummary struct {
+	*PublicEditor
+	Status      string     `json:"status"`
+	PinCount    int        `json:"pin_count"`
+	LastPinAt   *time.Time `json:"last_pin_at,omitempty"`
+	ReportCount int        `json:"report_count"`
+}
+
+// ClientFingerprint は公開編集者の端末を識別する情報を表す構造体
+type ClientFingerprint struct {
+	Network string // IPアドレス（IPv6は/64のネットワーク）
+	Device  string // クライアントが生成した端末ID（任意。詐称できるため補助的に使う）
 }
 
 // PublicEditorRegister は公開編集者登録リクエストを表す構造体
 type PublicEditorRegister struct {
-	MapID    string `json:"mapId" binding:"required"`
-	Nickname string `json:"nickname" binding:"required"`
+	MapID       string `json:"mapId" binding:"required"`
+	Nickname    string `json:"nickname" binding:"required"`
+	Fingerprint string `json:"fingerprint" binding:"max=128"`
+	InviteCode  string `json:"inviteCode" binding:"max=16"`
+}
+
+// PublicEditPermissions はマップごとの公開編集者の権限を表す構造体
+type PublicEditPermissions struct {
+	MapID           string `json:"map_id" db:"map_id"`
+	CanCreate       bool   `json:"can_create" db:"can_create"`
+	CanEditOthers   bool   `json:"can_edit_others" db:"can_edit_others"`
+	CanDelete       bool   `json:"can_delete" db:"can_delete"`
+	CanUploadImages bool   `json:"can_upload_images" db:"can_upload_images"`
+	InviteOnly      bool   `json:"invite_only" db:"invite_only"` // 登録に招待コードを必須にする
+}
+
+// PublicEditPermissionOverrides は公開編集者の権限のうち指定された項目だけを表す構造体
+type PublicEditPermissionOverrides struct {
+	CanCreate       *bool `json:"can_create" db:"can_create"`
+	CanEditOthers   *bool `json:"can_edit_others" db:"can_edit_others"`
+	CanDelete       *bool `json:"can_delete" db:"can_delete"`
+	CanUploadImages *bool `json:"can_upload_images" db:"can_upload_images"`
+}
+
+// ApplyTo は指定された項目で権限を上書きする
+func (o *PublicEditPermissionOverrides) ApplyTo(permissions *PublicEditPermissions) {
+	if o.CanCreate != nil {
+		permissions.CanCreate = *o.CanCreate
+	}
+	if o.CanEditOthers != nil {
+		permissions.CanEditOthers = *o.CanEditOthers
+	}
+	if o.CanDelete != nil {
+		permissions.CanDelete = *o.CanDelete
+	}
+	if o.CanUploadImages != nil {
+		permissions.CanUploadImages = *o.CanUploadImages
+	}
+}
+
+// PublicEditPermissionsUpdate は公開編集者の権限の更新リクエストを表す構造体
+type PublicEditPermissionsUpdate struct {
+	PublicEditPermissionOverrides
+	InviteOnly *bool `json:"invite_only"`
 }
 
 // PublicEditorVerify は公開編集者検証リクエストを表す構造体
@@ -29,11 +110,12 @@ type PublicEditorVerify struct {
 
 // PublicEditorResponse は公開編集者登録レスポンスを表す構造体
 type PublicEditorResponse struct {
-	EditorID string `json:"editorId"`
-	Nickname string `json:"nickname"`
-	Token    string `json:"token,omitempty"` // 登録時のみトークンを含める
-	MapID    string `json:"mapId"`
-	Verified bool   `json:"verified"`
+	EditorID    string                 `json:"editorId"`
+	Nickname    string                 `json:"nickname"`
+	Token       string                 `json:"token,omitempty"` // 登録時のみトークンを含める
+	MapID       string                 `json:"mapId"`
+	Verified    bool                   `json:"verified"`
+	Permissions *PublicEditPermissions `json:"permissions,omitempty"`
 }
 
 // ToResponse は公開編集者モデルからレスポンスモデルに変換する
@@ -0,0 +1,17 @@
+// backend/models/qr.go
+package models
+
+// QRコードの対象種類
+const (
+	QRTargetMap   = "map"
+	QRTargetFloor = "floor"
+	QRTargetPin   = "pin"
+)
+
+// QRTarget はQRコードに埋め込むビューワーへのリンクを表す構造体
+type QRTarget struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
@@ -0,0 +1,34 @@
+// backend/models/share_link.go
+package models
+
+import (
+	"time"
+)
+
+// ShareLink は未公開のマップを閲覧するための共有リンクを表す構造体
+type ShareLink struct {
+	ID           string     `json:"id" db:"id"`
+	MapID        string     `json:"map_id" db:"map_id"`
+	Slug         string     `json:"slug" db:"slug"`
+	PasswordHash string     `json:"-" db:"password_hash"` // パスワードはJSONに含めない
+	HasPassword  bool       `json:"has_password" db:"-"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	MaxUses      *int       `json:"max_uses,omitempty" db:"max_uses"`
+	UseCount     int        `json:"use_count" db:"use_count"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedBy    string     `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	URL          string     `json:"url,omitempty" db:"-"`
+}
+
+// ShareLinkCreate は共有リンク作成リクエストを表す構造体
+type ShareLinkCreate struct {
+	Password  string     `json:"password" binding:"omitempty,min=4"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	MaxUses   *int       `json:"max_uses" binding:"omitempty,min=1"`
+}
+
+// ShareLinkAccess は共有リンクでの閲覧リクエストを表す構造体
+type ShareLinkAccess struct {
+	Password string `json:"password"`
+}
@@ -0,0 +1,61 @@
+// backend/models/snapshot.go
+package models
+
+import (
+	"time"
+)
+
+// 差分の種類
+const (
+	DiffAdded    = "added"
+	DiffRemoved  = "removed"
+	DiffModified = "modified"
+)
+
+// MapSnapshot はマップ全体の公開スナップショットを表す構造体
+// 作成後は変更しない
+type MapSnapshot struct {
+	ID          string           `json:"id" db:"id"`
+	MapID       string           `json:"map_id" db:"map_id"`
+	Version     int              `json:"version" db:"version"`
+	Note        string           `json:"note" db:"note"`
+	CreatedBy   string           `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	IsPublished bool             `json:"is_published" db:"-"`
+	Data        *MapSnapshotData `json:"data,omitempty" db:"data"`
+}
+
+// MapSnapshotData はスナップショットに含まれるマップ・フロア・ピンを表す構造体
+type MapSnapshotData struct {
+	Map    *Map     `json:"map"`
+	Floors []*Floor `json:"floors"`
+	Pins   []*Pin   `json:"pins"`
+}
+
+// MapSnapshotCreate はスナップショット公開リクエストを表す構造体
+type MapSnapshotCreate struct {
+	Note string `json:"note"`
+}
+
+// MapSnapshotRollback はロールバックリクエストを表す構造体
+type MapSnapshotRollback struct {
+	RestoreWorkingCopy bool `json:"restore_working_copy"` // 編集中のデータもスナップショットの内容に戻す
+}
+
+// SnapshotDiffEntry はフロアまたはピン1件の差分を表す構造体
+type SnapshotDiffEntry struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Change string   `json:"change"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// SnapshotDiff はスナップショットと編集中のデータとの差分を表す構造体
+type SnapshotDiff struct {
+	SnapshotID string               `json:"snapshot_id"`
+	Version    int                  `json:"version"`
+	MapFields  []string             `json:"map_fields"`
+	Floors     []*SnapshotDiffEntry `json:"floors"`
+	Pins       []*SnapshotDiffEntry `json:"pins"`
+	HasChanges bool                 `json:"has_changes"`
+}
@@ -0,0 +1,97 @@
+// backend/models/stamp_rally.go
+package models
+
+import (
+	"time"
+)
+
+// スタンプラリーの達成条件
+const (
+	RallyRuleAll   = "all"   // 全チェックポイント
+	RallyRuleCount = "count" // M個中N個
+)
+
+// StampRally はマップごとのスタンプラリー定義を表す構造体
+type StampRally struct {
+	ID               string    `json:"id" db:"id"`
+	MapID            string    `json:"map_id" db:"map_id"`
+	Title            string    `json:"title" db:"title"`
+	Description      string    `json:"description" db:"description"`
+	CompletionRule   string    `json:"completion_rule" db:"completion_rule"`
+	RequiredCount    int       `json:"required_count" db:"required_count"`
+	IsActive         bool      `json:"is_active" db:"is_active"`
+	CheckpointPinIDs []string  `json:"checkpoint_pin_ids" db:"-"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// StampRallyCreate はスタンプラリー作成リクエストを表す構造体
+type StampRallyCreate struct {
+	Title            string   `json:"title" binding:"required"`
+	Description      string   `json:"description"`
+	CompletionRule   string   `json:"completion_rule" binding:"required,oneof=all count"`
+	RequiredCount    int      `json:"required_count"`
+	CheckpointPinIDs []string `json:"checkpoint_pin_ids" binding:"required,min=1"`
+}
+
+// StampRallyUpdate はスタンプラリー更新リクエストを表す構造体
+type StampRallyUpdate struct {
+	Title            string   `json:"title"`
+	Description      string   `json:"description"`
+	CompletionRule   string   `json:"completion_rule" binding:"omitempty,oneof=all count"`
+	RequiredCount    *int     `json:"required_count"`
+	IsActive         *bool    `json:"is_active"`
+	CheckpointPinIDs []string `json:"checkpoint_pin_ids"`
+}
+
+// RallyCheckpoint はチェックポイントのチェックインコードを表す構造体（所有者向け）
+type RallyCheckpoint struct {
+	PinID      string `json:"pin_id"`
+	PinTitle   string `json:"pin_title"`
+	Code       string `json:"code"`
+	CheckInURL string `json:"check_in_url"`
+}
+
+// RallySession は来場者の匿名セッションを表す構造体
+type RallySession struct {
+	ID          string     `json:"id" db:"id"`
+	RallyID     string     `json:"rally_id" db:"rally_id"`
+	Token       string     `json:"-" db:"token"` // トークンはJSONに含めない
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// RallySessionResponse はセッション発行レスポンスを表す構造体
+type RallySessionResponse struct {
+	SessionID string `json:"sessionId"`
+	Token     string `json:"token"`
+	RallyID   string `json:"rallyId"`
+}
+
+// RallyCheckIn はチェックインリクエストを表す構造体
+type RallyCheckIn struct {
+	SessionID string `json:"sessionId" binding:"required"`
+	Token     string `json:"token" binding:"required"`
+	PinID     string `json:"pinId" binding:"required"`
+	Code      string `json:"code" binding:"required"`
+}
+
+// RallyProgress はセッションの進捗を表す構造体
+type RallyProgress struct {
+	SessionID       string     `json:"session_id"`
+	RallyID         string     `json:"rally_id"`
+	CollectedPinIDs []string   `json:"collected_pin_ids"`
+	CollectedCount  int        `json:"collected_count"`
+	RequiredCount   int        `json:"required_count"`
+	Completed       bool       `json:"completed"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// RallyStats はスタンプラリーの達成状況の統計を表す構造体
+type RallyStats struct {
+	RallyID        string         `json:"rally_id"`
+	Sessions       int            `json:"sessions"`
+	Completed      int            `json:"completed"`
+	CompletionRate float64        `json:"completion_rate"`
+	CheckIns       map[string]int `json:"check_ins"` // ピンIDごとのチェックイン数
+}
@@ -0,0 +1,80 @@
+// backend/models/translation.go
+package models
+
+import (
+	"time"
+)
+
+// 翻訳対象の種類
+const (
+	TranslationEntityMap   = "map"
+	TranslationEntityFloor = "floor"
+	TranslationEntityPin   = "pin"
+)
+
+// 翻訳対象の項目
+const (
+	TranslationFieldTitle       = "title"
+	TranslationFieldDescription = "description"
+	TranslationFieldName        = "name"
+)
+
+// Translation は翻訳された項目1件を表す構造体
+type Translation struct {
+	MapID      string    `json:"map_id" db:"map_id"`
+	EntityType string    `json:"entity_type" db:"entity_type"`
+	EntityID   string    `json:"entity_id" db:"entity_id"`
+	Locale     string    `json:"locale" db:"locale"`
+	Field      string    `json:"field" db:"field"`
+	Value      string    `json:"value" db:"value"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MapTranslationFields はマップの翻訳項目を表す構造体
+// 省略した項目は変更せず、空文字の項目は翻訳を削除する
+type MapTranslationFields struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// FloorTranslationFields はフロアの翻訳項目を表す構造体
+type FloorTranslationFields struct {
+	Name *string `json:"name,omitempty"`
+}
+
+// PinTranslationFields はピンの翻訳項目を表す構造体
+type PinTranslationFields struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// MapTranslation はマップ全体の1言語分の翻訳を表す構造体
+type MapTranslation struct {
+	Locale string                             `json:"locale"`
+	Map    *MapTranslationFields              `json:"map,omitempty"`
+	Floors map[string]*FloorTranslationFields `json:"floors,omitempty"`
+	Pins   map[string]*PinTranslationFields   `json:"pins,omitempty"`
+}
+
+// TranslationKey は翻訳対象の項目を表す構造体
+type TranslationKey struct {
+	EntityType string `json:"entity_type"`
+	EntityID   string `json:"entity_id"`
+	Field      string `json:"field"`
+}
+
+// LocaleCompleteness は1言語分の翻訳の充足状況を表す構造体
+type LocaleCompleteness struct {
+	Locale     string            `json:"locale"`
+	Total      int               `json:"total"`
+	Translated int               `json:"translated"`
+	Percent    float64           `json:"percent"`
+	Missing    []*TranslationKey `json:"missing"`
+}
+
+// TranslationReport はマップの翻訳の充足状況を表す構造体
+type TranslationReport struct {
+	MapID         string                `json:"map_id"`
+	DefaultLocale string                `json:"default_locale"`
+	Locales       []*LocaleCompleteness `json:"locales"`
+}
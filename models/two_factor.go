@@ -0,0 +1,65 @@
+// backend/models/two_factor.go
+package models
+
+import (
+	"time"
+)
+
+// UserTwoFactor はユーザーのTOTPによる二段階認証の設定を表す構造体
+// EnabledAtがnilの場合は設定途中（確認コードの検証前）
+type UserTwoFactor struct {
+	UserID       string     `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"` // 秘密鍵はJSONに含めない
+	EnabledAt    *time.Time `json:"enabled_at,omitempty" db:"enabled_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Enabled は二段階認証が有効になっているか判定する
+func (t *UserTwoFactor) Enabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// TwoFactorStatus はユーザーの二段階認証の状態を表す構造体
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	Required               bool       `json:"required"` // 管理者として二段階認証が必須かどうか
+}
+
+// TwoFactorSetup は認証アプリへの登録情報を表す構造体
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // otpauth URIのQRコード（PNGのdata URL）
+}
+
+// TwoFactorCode は確認コードを送信するリクエストを表す構造体
+// 認証アプリの確認コードまたは回復コードを受け付ける
+type TwoFactorCode struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// TwoFactorDisable は二段階認証を無効にするリクエストを表す構造体
+// パスワードを持たないユーザー（外部アカウントのみで登録）は確認コードだけで無効にできる
+type TwoFactorDisable struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+// LoginTwoFactor はログインの二段階目のリクエストを表す構造体
+type LoginTwoFactor struct {
+	ChallengeToken string `json:"challengeToken" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+// SecuritySettings はサービス全体のセキュリティ設定を表す構造体
+type SecuritySettings struct {
+	RequireAdminTwoFactor bool `json:"require_admin_two_factor" db:"require_admin_two_factor"`
+}
+
+// SecuritySettingsUpdate はセキュリティ設定の更新リクエストを表す構造体
+type SecuritySettingsUpdate struct {
+	RequireAdminTwoFactor *bool `json:"require_admin_two_factor"`
+}
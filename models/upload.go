@@ -0,0 +1,52 @@
+// backend/models/upload.go
+package models
+
+import (
+	"time"
+)
+
+// アップロード対象の種類
+const (
+	UploadTargetFloor = "floor"
+	UploadTargetPin   = "pin"
+)
+
+// アップロードチケットの状態
+const (
+	UploadStatusPending   = "pending"
+	UploadStatusCompleted = "completed"
+)
+
+// UploadTicket は署名付き直接アップロードの発行情報を表す構造体
+type UploadTicket struct {
+	ID         string    `json:"id" db:"id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	TargetType string    `json:"target_type" db:"target_type"`
+	TargetID   string    `json:"target_id" db:"target_id"`
+	PublicID   string    `json:"public_id" db:"public_id"`
+	Status     string    `json:"status" db:"status"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// UploadSignRequest は署名付きアップロードパラメータの発行リクエストを表す構造体
+type UploadSignRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=floor pin"`
+	TargetID   string `json:"target_id" binding:"required"`
+}
+
+// UploadSignResponse はクライアントがCloudinaryへ直接アップロードするためのパラメータを表す構造体
+type UploadSignResponse struct {
+	UploadURL string    `json:"upload_url"`
+	APIKey    string    `json:"api_key"`
+	CloudName string    `json:"cloud_name"`
+	PublicID  string    `json:"public_id"`
+	Timestamp int64     `json:"timestamp"`
+	Signature string    `json:"signature"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UploadComplete はアップロード完了通知リクエストを表す構造体
+type UploadComplete struct {
+	PublicID string `json:"public_id" binding:"required"`
+}
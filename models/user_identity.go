@@ -0,0 +1,54 @@
+// backend/models/user_identity.go
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity は外部のOpenID Connectプロバイダーのアカウントとの連携を表す構造体
+type UserIdentity struct {
+	ID          string     `json:"id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Subject     string     `json:"-" db:"subject"` // プロバイダー側の識別子はJSONに含めない
+	Email       string     `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+}
+
+// OIDCProviderInfo はログインに使えるプロバイダーを表す構造体
+type OIDCProviderInfo struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// OIDCLoginState は認可リクエスト中の状態を表す構造体
+// UserIDがある場合はログイン中のユーザーへの連携を表す
+type OIDCLoginState struct {
+	State        string    `db:"state"`
+	Provider     string    `db:"provider"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	UserID       *string   `db:"user_id"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+// OIDCAuthorization は認可リクエストの開始結果を表す構造体
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+// OIDCCallback は認可後のコールバックのリクエストを表す構造体
+type OIDCCallback struct {
+	State string `json:"state" binding:"required,max=64"`
+	Code  string `json:"code" binding:"required"`
+}
+
+// OIDCResult はコールバックの処理結果を表す構造体
+type OIDCResult struct {
+	User     *User
+	Identity *UserIdentity
+	Linked   bool // ログイン中のユーザーへの連携の場合はtrue
+	Created  bool // 新しいユーザーを登録した場合はtrue
+}
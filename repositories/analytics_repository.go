@@ -0,0 +1,229 @@
+// backend/repositories/analytics_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// AnalyticsRepository はビューワー利用状況の集計データへのアクセスを提供するインターフェース
+type AnalyticsRepository interface {
+	IncrementRollups(ctx context.Context, rollups []*models.AnalyticsRollup) error
+	GetSeries(ctx context.Context, mapID, eventType string, from, to time.Time) ([]*models.AnalyticsPoint, error)
+	GetTotals(ctx context.Context, mapID string, from, to time.Time) (map[string]int, error)
+	GetTopDimensions(ctx context.Context, mapID, eventType string, from, to time.Time, minCount, limit int) ([]*models.AnalyticsDimensionCount, error)
+	FindMapTargets(ctx context.Context, mapID string, floorIDs, pinIDs []string) (floors, pins map[string]bool, err error)
+}
+
+// MySQLAnalyticsRepository はMySQLデータベースを使用したAnalyticsRepositoryの実装
+type MySQLAnalyticsRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAnalyticsRepository は新しいMySQLAnalyticsRepositoryを作成する
+func NewMySQLAnalyticsRepository(db *sql.DB) AnalyticsRepository {
+	return &MySQLAnalyticsRepository{db: db}
+}
+
+// IncrementRollups は1時間集計の件数を加算する
+func (r *MySQLAnalyticsRepository) IncrementRollups(ctx context.Context, rollups []*models.AnalyticsRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO analytics_hourly (map_id, bucket_hour, event_type, dimension, count)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE count = count + VALUES(count)
+	`
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, rollup := range rollups {
+		if _, err := stmt.ExecContext(
+			ctx,
+			rollup.MapID,
+			rollup.BucketHour,
+			rollup.EventType,
+			rollup.Dimension,
+			rollup.Count,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetSeries は指定期間の1時間ごとの件数を取得する
+func (r *MySQLAnalyticsRepository) GetSeries(ctx context.Context, mapID, eventType string, from, to time.Time) ([]*models.AnalyticsPoint, error) {
+	query := `
+		SELECT bucket_hour, SUM(count)
+		FROM analytics_hourly
+		WHERE map_id = ? AND event_type = ? AND bucket_hour >= ? AND bucket_hour < ?
+		GROUP BY bucket_hour
+		ORDER BY bucket_hour ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID, eventType, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []*models.AnalyticsPoint
+	for rows.Next() {
+		var point models.AnalyticsPoint
+		if err := rows.Scan(&point.Bucket, &point.Count); err != nil {
+			return nil, err
+		}
+		points = append(points, &point)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// GetTotals は指定期間のイベント種別ごとの合計件数を取得する
+func (r *MySQLAnalyticsRepository) GetTotals(ctx context.Context, mapID string, from, to time.Time) (map[string]int, error) {
+	query := `
+		SELECT event_type, SUM(count)
+		FROM analytics_hourly
+		WHERE map_id = ? AND bucket_hour >= ? AND bucket_hour < ?
+		GROUP BY event_type
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := make(map[string]int)
+	for rows.Next() {
+		var eventType string
+		var count int
+		if err := rows.Scan(&eventType, &count); err != nil {
+			return nil, err
+		}
+		totals[eventType] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// GetTopDimensions は指定期間で件数の多い集計軸を取得する
+// minCount未満の集計軸は返さない
+func (r *MySQLAnalyticsRepository) GetTopDimensions(ctx context.Context, mapID, eventType string, from, to time.Time, minCount, limit int) ([]*models.AnalyticsDimensionCount, error) {
+	query := `
+		SELECT dimension, SUM(count) AS total
+		FROM analytics_hourly
+		WHERE map_id = ? AND event_type = ? AND bucket_hour >= ? AND bucket_hour < ? AND dimension <> ''
+		GROUP BY dimension
+		HAVING total >= ?
+		ORDER BY total DESC, dimension ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID, eventType, from, to, minCount, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []*models.AnalyticsDimensionCount
+	for rows.Next() {
+		var count models.AnalyticsDimensionCount
+		if err := rows.Scan(&count.Dimension, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// FindMapTargets は指定したフロア・ピンのうちマップに属するものを1回のクエリで取得する
+func (r *MySQLAnalyticsRepository) FindMapTargets(ctx context.Context, mapID string, floorIDs, pinIDs []string) (map[string]bool, map[string]bool, error) {
+	floors := make(map[string]bool)
+	pins := make(map[string]bool)
+
+	// フロアとピンの検索をUNION ALLでまとめる (IN句用のプレースホルダーを作成)
+	var queries []string
+	var args []interface{}
+	if len(floorIDs) > 0 {
+		placeholders := make([]string, len(floorIDs))
+		args = append(args, mapID)
+		for i, id := range floorIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		queries = append(queries, `
+			SELECT 'floor', id FROM floors
+			WHERE map_id = ? AND id IN (`+strings.Join(placeholders, ",")+`)`)
+	}
+	if len(pinIDs) > 0 {
+		placeholders := make([]string, len(pinIDs))
+		args = append(args, mapID)
+		for i, id := range pinIDs {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		queries = append(queries, `
+			SELECT 'pin', p.id FROM pins p
+			JOIN floors f ON f.id = p.floor_id
+			WHERE f.map_id = ? AND p.id IN (`+strings.Join(placeholders, ",")+`)`)
+	}
+	if len(queries) == 0 {
+		return floors, pins, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, strings.Join(queries, " UNION ALL "), args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind, id string
+		if err := rows.Scan(&kind, &id); err != nil {
+			return nil, nil, err
+		}
+		if kind == "floor" {
+			floors[id] = true
+		} else {
+			pins[id] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return floors, pins, nil
+}
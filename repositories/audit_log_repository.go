@@ -0,0 +1,142 @@
+// backend/repositories/audit_log_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// AuditLogRepository は監査ログへのアクセスを提供するインターフェース
+// 監査ログは追記専用のため、更新・削除の操作は提供しない
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLog) error
+	Find(ctx context.Context, filter *models.AuditLogFilter) ([]*models.AuditLog, int, error)
+}
+
+// MySQLAuditLogRepository はMySQLデータベースを使用したAuditLogRepositoryの実装
+type MySQLAuditLogRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLAuditLogRepository は新しいMySQLAuditLogRepositoryを作成する
+func NewMySQLAuditLogRepository(db *sql.DB) AuditLogRepository {
+	return &MySQLAuditLogRepository{db: db}
+}
+
+// Create は監査ログを追加する
+func (r *MySQLAuditLogRepository) Create(ctx context.Context, entry *models.AuditLog) error {
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	entry.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO audit_logs (id, actor_id, action, target_type, target_id, ip, user_agent, before_data, after_data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		entry.ID,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.IP,
+		entry.UserAgent,
+		nullableJSON(entry.Before),
+		nullableJSON(entry.After),
+		entry.CreatedAt,
+	)
+
+	return err
+}
+
+// Find は条件に一致する監査ログを新しい順に取得し、総件数と共に返す
+func (r *MySQLAuditLogRepository) Find(ctx context.Context, filter *models.AuditLogFilter) ([]*models.AuditLog, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	if filter.ActorID != "" {
+		conditions = append(conditions, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM audit_logs `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, actor_id, action, target_type, target_id, ip, user_agent, before_data, after_data, created_at
+		FROM audit_logs
+		` + where + `
+		ORDER BY created_at DESC, id
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var logs []*models.AuditLog
+	for rows.Next() {
+		var entry models.AuditLog
+		var before, after []byte
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.IP,
+			&entry.UserAgent,
+			&before,
+			&after,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		entry.Before = before
+		entry.After = after
+		logs = append(logs, &entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
+
+// nullableJSON は空のJSONをNULLとして保存するための値に変換する
+func nullableJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
@@ -0,0 +1,215 @@
+// backend/repositories/content_filter_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// ContentFilterRepository は内容フィルターの設定と禁止語へのアクセスを提供するインターフェース
+type ContentFilterRepository interface {
+	GetSettings(ctx context.Context, mapID string) (*models.ContentFilterSettings, error)
+	SaveSettings(ctx context.Context, settings *models.ContentFilterSettings) error
+	GetWords(ctx context.Context, mapID string) ([]*models.ContentFilterWord, error)
+	GetApplicableWords(ctx context.Context, mapID string) ([]*models.ContentFilterWord, error)
+	GetWordByID(ctx context.Context, id string) (*models.ContentFilterWord, error)
+	CreateWord(ctx context.Context, word *models.ContentFilterWord) error
+	DeleteWord(ctx context.Context, id string) error
+}
+
+// MySQLContentFilterRepository はMySQLデータベースを使用したContentFilterRepositoryの実装
+type MySQLContentFilterRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLContentFilterRepository は新しいMySQLContentFilterRepositoryを作成する
+func NewMySQLContentFilterRepository(db *sql.DB) ContentFilterRepository {
+	return &MySQLContentFilterRepository{db: db}
+}
+
+// GetSettings はマップの内容フィルターの設定を取得する
+// 設定がない場合は既定の設定を返す
+func (r *MySQLContentFilterRepository) GetSettings(ctx context.Context, mapID string) (*models.ContentFilterSettings, error) {
+	query := `
+		SELECT map_id, action, block_urls, max_nickname_length, max_title_length, max_description_length
+		FROM content_filter_settings
+		WHERE map_id = ?
+	`
+
+	settings := models.ContentFilterSettings{
+		MapID:                mapID,
+		Action:               models.FilterActionReject,
+		BlockURLs:            true,
+		MaxNicknameLength:    30,
+		MaxTitleLength:       100,
+		MaxDescriptionLength: 2000,
+	}
+	err := r.db.QueryRowContext(ctx, query, mapID).Scan(
+		&settings.MapID,
+		&settings.Action,
+		&settings.BlockURLs,
+		&settings.MaxNicknameLength,
+		&settings.MaxTitleLength,
+		&settings.MaxDescriptionLength,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// SaveSettings はマップの内容フィルターの設定を作成または更新する
+func (r *MySQLContentFilterRepository) SaveSettings(ctx context.Context, settings *models.ContentFilterSettings) error {
+	query := `
+		INSERT INTO content_filter_settings (map_id, action, block_urls, max_nickname_length, max_title_length, max_description_length, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE action = VALUES(action), block_urls = VALUES(block_urls),
+		    max_nickname_length = VALUES(max_nickname_length), max_title_length = VALUES(max_title_length),
+		    max_description_length = VALUES(max_description_length), updated_at = VALUES(updated_at)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		settings.MapID,
+		settings.Action,
+		settings.BlockURLs,
+		settings.MaxNicknameLength,
+		settings.MaxTitleLength,
+		settings.MaxDescriptionLength,
+		time.Now(),
+	)
+
+	return err
+}
+
+// GetWords はマップの禁止語を取得する
+// mapIDが空の場合はすべてのマップに適用する禁止語を取得する
+func (r *MySQLContentFilterRepository) GetWords(ctx context.Context, mapID string) ([]*models.ContentFilterWord, error) {
+	if mapID == "" {
+		query := `
+			SELECT id, map_id, word, normalized, created_by, created_at
+			FROM content_filter_words
+			WHERE map_id IS NULL
+			ORDER BY created_at
+		`
+		return r.queryWords(ctx, query)
+	}
+
+	query := `
+		SELECT id, map_id, word, normalized, created_by, created_at
+		FROM content_filter_words
+		WHERE map_id = ?
+		ORDER BY created_at
+	`
+	return r.queryWords(ctx, query, mapID)
+}
+
+// GetApplicableWords はマップに適用する禁止語（全体とマップ固有）を取得する
+func (r *MySQLContentFilterRepository) GetApplicableWords(ctx context.Context, mapID string) ([]*models.ContentFilterWord, error) {
+	query := `
+		SELECT id, map_id, word, normalized, created_by, created_at
+		FROM content_filter_words
+		WHERE map_id IS NULL OR map_id = ?
+	`
+	return r.queryWords(ctx, query, mapID)
+}
+
+// GetWordByID はIDにより禁止語を取得する
+func (r *MySQLContentFilterRepository) GetWordByID(ctx context.Context, id string) (*models.ContentFilterWord, error) {
+	query := `
+		SELECT id, map_id, word, normalized, created_by, created_at
+		FROM content_filter_words
+		WHERE id = ?
+	`
+
+	var word models.ContentFilterWord
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&word.ID,
+		&word.MapID,
+		&word.Word,
+		&word.Normalized,
+		&word.CreatedBy,
+		&word.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &word, nil
+}
+
+// CreateWord は禁止語を追加する
+func (r *MySQLContentFilterRepository) CreateWord(ctx context.Context, word *models.ContentFilterWord) error {
+	if word.ID == "" {
+		word.ID = uuid.New().String()
+	}
+	word.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO content_filter_words (id, map_id, word, normalized, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		word.ID,
+		word.MapID,
+		word.Word,
+		word.Normalized,
+		word.CreatedBy,
+		word.CreatedAt,
+	)
+
+	return err
+}
+
+// DeleteWord は禁止語を削除する
+func (r *MySQLContentFilterRepository) DeleteWord(ctx context.Context, id string) error {
+	query := `DELETE FROM content_filter_words WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// queryWords は禁止語の一覧を読み込む
+func (r *MySQLContentFilterRepository) queryWords(ctx context.Context, query string, args ...interface{}) ([]*models.ContentFilterWord, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var words []*models.ContentFilterWord
+	for rows.Next() {
+		var word models.ContentFilterWord
+		if err := rows.Scan(
+			&word.ID,
+			&word.MapID,
+			&word.Word,
+			&word.Normalized,
+			&word.CreatedBy,
+			&word.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		words = append(words, &word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
@@ -0,0 +1,318 @@
+// backend/repositories/content_report_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// ContentReportRepository は通報データへのアクセスを提供するインターフェース
+type ContentReportRepository interface {
+	AddReport(ctx context.Context, mapID, targetType, targetID string, report *models.ContentReport) (*models.ContentFlag, bool, error)
+	GetFlagByID(ctx context.Context, id string) (*models.ContentFlag, error)
+	GetFlags(ctx context.Context, mapID, status string, limit, offset int) ([]*models.ContentFlag, int, error)
+	GetReportsByFlagIDs(ctx context.Context, flagIDs []string) (map[string][]*models.ContentReport, error)
+	UpdateFlagStatus(ctx context.Context, flag *models.ContentFlag) error
+	GetHiddenTargetIDs(ctx context.Context, mapID, targetType string) ([]string, error)
+}
+
+// MySQLContentReportRepository はMySQLデータベースを使用したContentReportRepositoryの実装
+type MySQLContentReportRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLContentReportRepository は新しいMySQLContentReportRepositoryを作成する
+func NewMySQLContentReportRepository(db *sql.DB) ContentReportRepository {
+	return &MySQLContentReportRepository{db: db}
+}
+
+// AddReport は通報を追加し、対象の通報件数を加算する
+// 同じ通報者が既に通報している場合は件数を加算せず、falseを返す
+func (r *MySQLContentReportRepository) AddReport(ctx context.Context, mapID, targetType, targetID string, report *models.ContentReport) (*models.ContentFlag, bool, error) {
+	now := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	// 対象の対応状況がなければ作成する
+	query := `
+		INSERT IGNORE INTO content_flags (id, map_id, target_type, target_id, report_count, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?)
+	`
+	if _, err := tx.ExecContext(ctx, query, uuid.New().String(), mapID, targetType, targetID, models.FlagStatusOpen, now, now); err != nil {
+		return nil, false, err
+	}
+
+	query = `
+		SELECT id, map_id, target_type, target_id, report_count, status, hidden_at, resolved_by, resolved_at, created_at, updated_at
+		FROM content_flags
+		WHERE target_type = ? AND target_id = ?
+		FOR UPDATE
+	`
+	flag, err := scanContentFlag(tx.QueryRowContext(ctx, query, targetType, targetID))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if report.ID == "" {
+		report.ID = uuid.New().String()
+	}
+	report.FlagID = flag.ID
+	report.CreatedAt = now
+
+	query = `
+		INSERT IGNORE INTO content_reports (id, flag_id, reason, details, reporter_type, reporter_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		report.ID,
+		report.FlagID,
+		report.Reason,
+		report.Details,
+		report.ReporterType,
+		report.ReporterID,
+		report.CreatedAt,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, false, err
+	}
+	if affected == 0 {
+		return flag, false, tx.Commit()
+	}
+
+	query = `UPDATE content_flags SET report_count = report_count + 1, updated_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, now, flag.ID); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	flag.ReportCount++
+	flag.UpdatedAt = now
+	return flag, true, nil
+}
+
+// GetFlagByID はIDにより通報された内容の対応状況を取得する
+func (r *MySQLContentReportRepository) GetFlagByID(ctx context.Context, id string) (*models.ContentFlag, error) {
+	query := `
+		SELECT id, map_id, target_type, target_id, report_count, status, hidden_at, resolved_by, resolved_at, created_at, updated_at
+		FROM content_flags
+		WHERE id = ?
+	`
+
+	flag, err := scanContentFlag(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return flag, err
+}
+
+// GetFlags は通報された内容を新しく通報された順に取得し、総件数と共に返す
+// mapID・statusが空の場合は絞り込まない
+func (r *MySQLContentReportRepository) GetFlags(ctx context.Context, mapID, status string, limit, offset int) ([]*models.ContentFlag, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	if mapID != "" {
+		conditions = append(conditions, "map_id = ?")
+		args = append(args, mapID)
+	}
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, status)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM content_flags `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, map_id, target_type, target_id, report_count, status, hidden_at, resolved_by, resolved_at, created_at, updated_at
+		FROM content_flags
+		` + where + `
+		ORDER BY updated_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var flags []*models.ContentFlag
+	for rows.Next() {
+		var flag models.ContentFlag
+		if err := rows.Scan(
+			&flag.ID,
+			&flag.MapID,
+			&flag.TargetType,
+			&flag.TargetID,
+			&flag.ReportCount,
+			&flag.Status,
+			&flag.HiddenAt,
+			&flag.ResolvedBy,
+			&flag.ResolvedAt,
+			&flag.CreatedAt,
+			&flag.UpdatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		flags = append(flags, &flag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return flags, total, nil
+}
+
+// GetReportsByFlagIDs は複数の対象に対する個別の通報を取得する
+func (r *MySQLContentReportRepository) GetReportsByFlagIDs(ctx context.Context, flagIDs []string) (map[string][]*models.ContentReport, error) {
+	reports := make(map[string][]*models.ContentReport)
+	if len(flagIDs) == 0 {
+		return reports, nil
+	}
+
+	// プレースホルダーを作成 (IN句用)
+	placeholders := make([]string, len(flagIDs))
+	args := make([]interface{}, len(flagIDs))
+	for i, id := range flagIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `
+		SELECT id, flag_id, reason, details, reporter_type, reporter_id, created_at
+		FROM content_reports
+		WHERE flag_id IN (` + strings.Join(placeholders, ",") + `)
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var report models.ContentReport
+		if err := rows.Scan(
+			&report.ID,
+			&report.FlagID,
+			&report.Reason,
+			&report.Details,
+			&report.ReporterType,
+			&report.ReporterID,
+			&report.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		reports[report.FlagID] = append(reports[report.FlagID], &report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// UpdateFlagStatus は通報された内容の対応状況を更新する
+func (r *MySQLContentReportRepository) UpdateFlagStatus(ctx context.Context, flag *models.ContentFlag) error {
+	flag.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE content_flags
+		SET status = ?, hidden_at = ?, resolved_by = ?, resolved_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		flag.Status,
+		flag.HiddenAt,
+		flag.ResolvedBy,
+		flag.ResolvedAt,
+		flag.UpdatedAt,
+		flag.ID,
+	)
+
+	return err
+}
+
+// GetHiddenTargetIDs はマップ内で非表示・削除済みになっている対象のIDを取得する
+func (r *MySQLContentReportRepository) GetHiddenTargetIDs(ctx context.Context, mapID, targetType string) ([]string, error) {
+	query := `
+		SELECT target_id
+		FROM content_flags
+		WHERE map_id = ? AND target_type = ? AND status IN (?, ?)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID, targetType, models.FlagStatusHidden, models.FlagStatusRemoved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// scanContentFlag は通報された内容の対応状況を1件読み込む
+// 見つからない場合はsql.ErrNoRowsを返す
+func scanContentFlag(row *sql.Row) (*models.ContentFlag, error) {
+	var flag models.ContentFlag
+	if err := row.Scan(
+		&flag.ID,
+		&flag.MapID,
+		&flag.TargetType,
+		&flag.TargetID,
+		&flag.ReportCount,
+		&flag.Status,
+		&flag.HiddenAt,
+		&flag.ResolvedBy,
+		&flag.ResolvedAt,
+		&flag.CreatedAt,
+		&flag.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
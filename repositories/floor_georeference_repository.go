@@ -0,0 +1,147 @@
+// backend/repositories/floor_georeference_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// FloorGeoreferenceRepository はフロアの地理参照データへのアクセスを提供するインターフェース
+type FloorGeoreferenceRepository interface {
+	Save(ctx context.Context, geo *models.FloorGeoreference) error
+	GetByFloorID(ctx context.Context, floorID string) (*models.FloorGeoreference, error)
+	GetByFloorIDs(ctx context.Context, floorIDs []string) ([]*models.FloorGeoreference, error)
+	Delete(ctx context.Context, floorID string) error
+}
+
+// MySQLFloorGeoreferenceRepository はMySQLデータベースを使用したFloorGeoreferenceRepositoryの実装
+type MySQLFloorGeoreferenceRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLFloorGeoreferenceRepository は新しいMySQLFloorGeoreferenceRepositoryを作成する
+func NewMySQLFloorGeoreferenceRepository(db *sql.DB) FloorGeoreferenceRepository {
+	return &MySQLFloorGeoreferenceRepository{db: db}
+}
+
+// Save はフロアの地理参照情報を作成または更新する
+func (r *MySQLFloorGeoreferenceRepository) Save(ctx context.Context, geo *models.FloorGeoreference) error {
+	now := time.Now()
+	if geo.CreatedAt.IsZero() {
+		geo.CreatedAt = now
+	}
+	geo.UpdatedAt = now
+
+	// 対応点と変換係数はJSONとして保存
+	points, err := json.Marshal(geo.ControlPoints)
+	if err != nil {
+		return err
+	}
+	transform, err := json.Marshal(geo.Transform)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO floor_georeferences (floor_id, control_points, transform, rms_error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE control_points = VALUES(control_points), transform = VALUES(transform),
+		    rms_error = VALUES(rms_error), updated_at = VALUES(updated_at)
+	`
+
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		geo.FloorID,
+		string(points),
+		string(transform),
+		geo.RMSError,
+		geo.CreatedAt,
+		geo.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetByFloorID はフロアIDにより地理参照情報を取得する
+func (r *MySQLFloorGeoreferenceRepository) GetByFloorID(ctx context.Context, floorID string) (*models.FloorGeoreference, error) {
+	geos, err := r.GetByFloorIDs(ctx, []string{floorID})
+	if err != nil {
+		return nil, err
+	}
+	if len(geos) == 0 {
+		return nil, nil
+	}
+	return geos[0], nil
+}
+
+// GetByFloorIDs は複数のフロアIDに対応する地理参照情報を取得する
+func (r *MySQLFloorGeoreferenceRepository) GetByFloorIDs(ctx context.Context, floorIDs []string) ([]*models.FloorGeoreference, error) {
+	if len(floorIDs) == 0 {
+		return []*models.FloorGeoreference{}, nil
+	}
+
+	// プレースホルダーを作成 (IN句用)
+	placeholders := make([]string, len(floorIDs))
+	args := make([]interface{}, len(floorIDs))
+	for i, id := range floorIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	query := `
+		SELECT floor_id, control_points, transform, rms_error, created_at, updated_at
+		FROM floor_georeferences
+		WHERE floor_id IN (` + strings.Join(placeholders, ",") + `)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var geos []*models.FloorGeoreference
+	for rows.Next() {
+		var geo models.FloorGeoreference
+		var points, transform string
+
+		if err := rows.Scan(
+			&geo.FloorID,
+			&points,
+			&transform,
+			&geo.RMSError,
+			&geo.CreatedAt,
+			&geo.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(points), &geo.ControlPoints); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(transform), &geo.Transform); err != nil {
+			return nil, err
+		}
+
+		geos = append(geos, &geo)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return geos, nil
+}
+
+// Delete はフロアの地理参照情報を削除する
+func (r *MySQLFloorGeoreferenceRepository) Delete(ctx context.Context, floorID string) error {
+	query := `DELETE FROM floor_georeferences WHERE floor_id = ?`
+	_, err := r.db.ExecContext(ctx, query, floorID)
+	return err
+}
@@ -0,0 +1,74 @@
+// backend/repositories/map_interaction_settings_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// MapInteractionSettingsRepository はマップのコメント・リアクションの設定へのアクセスを提供するインターフェース
+type MapInteractionSettingsRepository interface {
+	Get(ctx context.Context, mapID string) (*models.MapInteractionSettings, error)
+	Save(ctx context.Context, settings *models.MapInteractionSettings) error
+}
+
+// MySQLMapInteractionSettingsRepository はMySQLデータベースを使用したMapInteractionSettingsRepositoryの実装
+type MySQLMapInteractionSettingsRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLMapInteractionSettingsRepository は新しいMySQLMapInteractionSettingsRepositoryを作成する
+func NewMySQLMapInteractionSettingsRepository(db *sql.DB) MapInteractionSettingsRepository {
+	return &MySQLMapInteractionSettingsRepository{db: db}
+}
+
+// Get はマップの設定を取得する
+// 設定がない場合はコメント・リアクションともに有効とする
+func (r *MySQLMapInteractionSettingsRepository) Get(ctx context.Context, mapID string) (*models.MapInteractionSettings, error) {
+	query := `
+		SELECT map_id, comments_enabled, reactions_enabled
+		FROM map_interaction_settings
+		WHERE map_id = ?
+	`
+
+	settings := models.MapInteractionSettings{
+		MapID:            mapID,
+		CommentsEnabled:  true,
+		ReactionsEnabled: true,
+	}
+	err := r.db.QueryRowContext(ctx, query, mapID).Scan(
+		&settings.MapID,
+		&settings.CommentsEnabled,
+		&settings.ReactionsEnabled,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// Save はマップの設定を作成または更新する
+func (r *MySQLMapInteractionSettingsRepository) Save(ctx context.Context, settings *models.MapInteractionSettings) error {
+	query := `
+		INSERT INTO map_interaction_settings (map_id, comments_enabled, reactions_enabled, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE comments_enabled = VALUES(comments_enabled),
+		    reactions_enabled = VALUES(reactions_enabled), updated_at = VALUES(updated_at)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		settings.MapID,
+		settings.CommentsEnabled,
+		settings.ReactionsEnabled,
+		time.Now(),
+	)
+
+	return err
+}
@@ -14,8 +14,17 @@ type MapRepository interface {
 	Create(ctx context.Context, m *models.Map) error
 	GetByID(ctx context.Context, id string) (*models.Map, error)
 	GetByUserID(ctx context.Context, userID string) ([]*models.Map, error)
+	GetAccessibleByUserID(ctx context.Context, userID string) ([]*models.Map, error)
+	GetByOrganizationID(ctx context.Context, organizationID string) ([]*models.Map, error)
+	GetOrganizationRole(ctx context.Context, organizationID, userID string) (string, error)
 	Update(ctx context.Context, m *models.Map) error
+	UpdateOwner(ctx context.Context, m *models.Map) error
 	Delete(ctx context.Context, id string) error
+	PublishDue(ctx context.Context, now time.Time) (int64, error)
+	ArchiveDue(ctx context.Context, now time.Time) (int64, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Map, error)
+	GetBySlugHistory(ctx context.Context, slug string) (*models.Map, error)
+	UpdateSlug(ctx context.Context, m *models.Map, slug string) error
 }
 
 // MySQLMapRepository はMySQLデータベースを使用したMapRepositoryの実装
@@ -35,18 +44,23 @@ func (r *MySQLMapRepository) Create(ctx context.Context, m *models.Map) error {
 	m.UpdatedAt = now
 
 	query := `
-		INSERT INTO maps (id, title, description, user_id, is_publicly_editable, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO maps (id, slug, title, description, user_id, organization_id, is_publicly_editable, status, publish_at, unpublish_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.ExecContext(
 		ctx,
 		query,
 		m.ID,
+		m.Slug,
 		m.Title,
 		m.Description,
-		m.UserID,
+		nullableUserID(m.UserID),
+		m.OrganizationID,
 		m.IsPubliclyEditable,
+		m.Status,
+		m.PublishAt,
+		m.UnpublishAt,
 		m.CreatedAt,
 		m.UpdatedAt,
 	)
@@ -57,18 +71,217 @@ func (r *MySQLMapRepository) Create(ctx context.Context, m *models.Map) error {
 // GetByID はIDによりマップを取得する
 func (r *MySQLMapRepository) GetByID(ctx context.Context, id string) (*models.Map, error) {
 	query := `
-		SELECT id, title, description, user_id, is_publicly_editable, created_at, updated_at
+		SELECT id, slug, title, description, COALESCE(user_id, ''), organization_id, is_publicly_editable, status, publish_at, unpublish_at, created_at, updated_at
 		FROM maps
 		WHERE id = ?
 	`
 
+	return scanMap(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByUserID はユーザーIDによりマップ一覧を取得する
+func (r *MySQLMapRepository) GetByUserID(ctx context.Context, userID string) ([]*models.Map, error) {
+	query := `
+		SELECT id, slug, title, description, COALESCE(user_id, ''), organization_id, is_publicly_editable, status, publish_at, unpublish_at, created_at, updated_at
+		FROM maps
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+	`
+
+	return r.queryMaps(ctx, query, userID)
+}
+
+// GetAccessibleByUserID はユーザーが所有するマップと、メンバーである組織のマップの一覧を取得する
+func (r *MySQLMapRepository) GetAccessibleByUserID(ctx context.Context, userID string) ([]*models.Map, error) {
+	query := `
+		SELECT id, slug, title, description, COALESCE(user_id, ''), organization_id, is_publicly_editable, status, publish_at, unpublish_at, created_at, updated_at
+		FROM maps
+		WHERE user_id = ?
+		   OR organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = ?)
+		ORDER BY created_at DESC
+	`
+
+	return r.queryMaps(ctx, query, userID, userID)
+}
+
+// GetByOrganizationID は組織のマップ一覧を取得する
+func (r *MySQLMapRepository) GetByOrganizationID(ctx context.Context, organizationID string) ([]*models.Map, error) {
+	query := `
+		SELECT id, slug, title, description, COALESCE(user_id, ''), organization_id, is_publicly_editable, status, publish_at, unpublish_at, created_at, updated_at
+		FROM maps
+		WHERE organization_id = ?
+		ORDER BY created_at DESC
+	`
+
+	return r.queryMaps(ctx, query, organizationID)
+}
+
+// GetOrganizationRole はユーザーの組織での役割を取得する（メンバーでない場合は空文字）
+// 組織のマップの編集権限の確認に使う
+func (r *MySQLMapRepository) GetOrganizationRole(ctx context.Context, organizationID, userID string) (string, error) {
+	query := `SELECT role FROM organization_members WHERE organization_id = ? AND user_id = ?`
+
+	var role string
+	err := r.db.QueryRowContext(ctx, query, organizationID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return role, err
+}
+
+// Update はマップ情報を更新する
+func (r *MySQLMapRepository) Update(ctx context.Context, m *models.Map) error {
+	m.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE maps
+		SET title = ?, description = ?, is_publicly_editable = ?, status = ?, publish_at = ?, unpublish_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		m.Title,
+		m.Description,
+		m.IsPubliclyEditable,
+		m.Status,
+		m.PublishAt,
+		m.UnpublishAt,
+		m.UpdatedAt,
+		m.ID,
+	)
+
+	return err
+}
+
+// UpdateOwner はマップの所有者（ユーザーまたは組織）を変更する
+func (r *MySQLMapRepository) UpdateOwner(ctx context.Context, m *models.Map) error {
+	m.UpdatedAt = time.Now()
+
+	query := `UPDATE maps SET user_id = ?, organization_id = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, nullableUserID(m.UserID), m.OrganizationID, m.UpdatedAt, m.ID)
+	return err
+}
+
+// Delete はマップを削除する
+func (r *MySQLMapRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM maps WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// PublishDue は公開予約時刻を過ぎた下書きのマップを公開する
+func (r *MySQLMapRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE maps
+		SET status = ?, publish_at = NULL, updated_at = ?
+		WHERE status = ? AND publish_at IS NOT NULL AND publish_at <= ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, models.MapStatusPublished, now, models.MapStatusDraft, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// ArchiveDue は公開終了時刻を過ぎた公開中のマップをアーカイブする
+func (r *MySQLMapRepository) ArchiveDue(ctx context.Context, now time.Time) (int64, error) {
+	query := `
+		UPDATE maps
+		SET status = ?, unpublish_at = NULL, updated_at = ?
+		WHERE status = ? AND unpublish_at IS NOT NULL AND unpublish_at <= ?
+	`
+
+	result, err := r.db.ExecContext(ctx, query, models.MapStatusArchived, now, models.MapStatusPublished, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetBySlug はスラッグによりマップを取得する
+func (r *MySQLMapRepository) GetBySlug(ctx context.Context, slug string) (*models.Map, error) {
+	query := `
+		SELECT id, slug, title, description, COALESCE(user_id, ''), organization_id, is_publicly_editable, status, publish_at, unpublish_at, created_at, updated_at
+		FROM maps
+		WHERE slug = ?
+	`
+
+	return scanMap(r.db.QueryRowContext(ctx, query, slug))
+}
+
+// GetBySlugHistory は過去に使用されていたスラッグによりマップを取得する
+func (r *MySQLMapRepository) GetBySlugHistory(ctx context.Context, slug string) (*models.Map, error) {
+	query := `
+		SELECT m.id, m.slug, m.title, m.description, COALESCE(m.user_id, ''), m.organization_id, m.is_publicly_editable, m.status, m.publish_at, m.unpublish_at, m.created_at, m.updated_at
+		FROM map_slug_history h
+		JOIN maps m ON m.id = h.map_id
+		WHERE h.slug = ?
+	`
+
+	return scanMap(r.db.QueryRowContext(ctx, query, slug))
+}
+
+// UpdateSlug はマップのスラッグを変更し、以前のスラッグを履歴に残す
+func (r *MySQLMapRepository) UpdateSlug(ctx context.Context, m *models.Map, slug string) error {
+	now := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if m.Slug != nil && *m.Slug != "" && *m.Slug != slug {
+		query := `
+			INSERT INTO map_slug_history (slug, map_id, created_at)
+			VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE map_id = VALUES(map_id), created_at = VALUES(created_at)
+		`
+		if _, err := tx.ExecContext(ctx, query, *m.Slug, m.ID, now); err != nil {
+			return err
+		}
+	}
+
+	// 自分の過去のスラッグに戻す場合は履歴から取り除く
+	query := `DELETE FROM map_slug_history WHERE slug = ? AND map_id = ?`
+	if _, err := tx.ExecContext(ctx, query, slug, m.ID); err != nil {
+		return err
+	}
+
+	query = `UPDATE maps SET slug = ?, updated_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, slug, now, m.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	m.Slug = &slug
+	m.UpdatedAt = now
+	return nil
+}
+
+// scanMap はマップを1件読み込む
+func scanMap(row *sql.Row) (*models.Map, error) {
 	var m models.Map
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := row.Scan(
 		&m.ID,
+		&m.Slug,
 		&m.Title,
 		&m.Description,
 		&m.UserID,
+		&m.OrganizationID,
 		&m.IsPubliclyEditable,
+		&m.Status,
+		&m.PublishAt,
+		&m.UnpublishAt,
 		&m.CreatedAt,
 		&m.UpdatedAt,
 	)
@@ -84,16 +297,9 @@ func (r *MySQLMapRepository) GetByID(ctx context.Context, id string) (*models.Ma
 	return &m, nil
 }
 
-// GetByUserID はユーザーIDによりマップ一覧を取得する
-func (r *MySQLMapRepository) GetByUserID(ctx context.Context, userID string) ([]*models.Map, error) {
-	query := `
-		SELECT id, title, description, user_id, is_publicly_editable, created_at, updated_at
-		FROM maps
-		WHERE user_id = ?
-		ORDER BY created_at DESC
-	`
-
-	rows, err := r.db.QueryContext(ctx, query, userID)
+// queryMaps はマップの一覧を読み込む
+func (r *MySQLMapRepository) queryMaps(ctx context.Context, query string, args ...interface{}) ([]*models.Map, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -104,10 +310,15 @@ func (r *MySQLMapRepository) GetByUserID(ctx context.Context, userID string) ([]
 		var m models.Map
 		if err := rows.Scan(
 			&m.ID,
+			&m.Slug,
 			&m.Title,
 			&m.Description,
 			&m.UserID,
+			&m.OrganizationID,
 			&m.IsPubliclyEditable,
+			&m.Status,
+			&m.PublishAt,
+			&m.UnpublishAt,
 			&m.CreatedAt,
 			&m.UpdatedAt,
 		); err != nil {
@@ -123,32 +334,10 @@ func (r *MySQLMapRepository) GetByUserID(ctx context.Context, userID string) ([]
 	return maps, nil
 }
 
-// Update はマップ情報を更新する
-func (r *MySQLMapRepository) Update(ctx context.Context, m *models.Map) error {
-	m.UpdatedAt = time.Now()
-
-	query := `
-		UPDATE maps
-		SET title = ?, description = ?, is_publicly_editable = ?, updated_at = ?
-		WHERE id = ?
-	`
-
-	_, err := r.db.ExecContext(
-		ctx,
-		query,
-		m.Title,
-		m.Description,
-		m.IsPubliclyEditable,
-		m.UpdatedAt,
-		m.ID,
-	)
-
-	return err
-}
-
-// Delete はマップを削除する
-func (r *MySQLMapRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM maps WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+// nullableUserID は組織のマップの所有ユーザーをNULLとして保存する
+func nullableUserID(userID string) interface{} {
+	if userID == "" {
+		return nil
+	}
+	return userID
 }
@@ -0,0 +1,281 @@
+// backend/repositories/map_snapshot_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// MapSnapshotRepository はマップの公開スナップショットへのアクセスを提供するインターフェース
+type MapSnapshotRepository interface {
+	Create(ctx context.Context, snapshot *models.MapSnapshot) error
+	GetByID(ctx context.Context, id string) (*models.MapSnapshot, error)
+	GetByMapID(ctx context.Context, mapID string) ([]*models.MapSnapshot, error)
+	GetPublished(ctx context.Context, mapID string) (*models.MapSnapshot, error)
+	SetPublished(ctx context.Context, mapID, snapshotID string) error
+	ClearPublished(ctx context.Context, mapID string) error
+	RestoreWorkingCopy(ctx context.Context, data *models.MapSnapshotData) error
+}
+
+// MySQLMapSnapshotRepository はMySQLデータベースを使用したMapSnapshotRepositoryの実装
+type MySQLMapSnapshotRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLMapSnapshotRepository は新しいMySQLMapSnapshotRepositoryを作成する
+func NewMySQLMapSnapshotRepository(db *sql.DB) MapSnapshotRepository {
+	return &MySQLMapSnapshotRepository{db: db}
+}
+
+// Create は新しいスナップショットを作成する
+// バージョンはマップごとの連番で採番する
+func (r *MySQLMapSnapshotRepository) Create(ctx context.Context, snapshot *models.MapSnapshot) error {
+	if snapshot.ID == "" {
+		snapshot.ID = uuid.New().String()
+	}
+	snapshot.CreatedAt = time.Now()
+
+	data, err := json.Marshal(snapshot.Data)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT COALESCE(MAX(version), 0) + 1 FROM map_snapshots WHERE map_id = ? FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, snapshot.MapID).Scan(&snapshot.Version); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO map_snapshots (id, map_id, version, note, data, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if _, err := tx.ExecContext(
+		ctx,
+		query,
+		snapshot.ID,
+		snapshot.MapID,
+		snapshot.Version,
+		snapshot.Note,
+		string(data),
+		snapshot.CreatedBy,
+		snapshot.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID はIDによりスナップショットを内容とともに取得する
+func (r *MySQLMapSnapshotRepository) GetByID(ctx context.Context, id string) (*models.MapSnapshot, error) {
+	query := `
+		SELECT s.id, s.map_id, s.version, s.note, s.data, s.created_by, s.created_at, p.snapshot_id IS NOT NULL
+		FROM map_snapshots s
+		LEFT JOIN map_published_snapshots p ON p.snapshot_id = s.id
+		WHERE s.id = ?
+	`
+
+	return r.scanWithData(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByMapID はマップのスナップショット一覧を新しい順に取得する（内容は含まない）
+func (r *MySQLMapSnapshotRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.MapSnapshot, error) {
+	query := `
+		SELECT s.id, s.map_id, s.version, s.note, s.created_by, s.created_at, p.snapshot_id IS NOT NULL
+		FROM map_snapshots s
+		LEFT JOIN map_published_snapshots p ON p.snapshot_id = s.id
+		WHERE s.map_id = ?
+		ORDER BY s.version DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []*models.MapSnapshot
+	for rows.Next() {
+		var snapshot models.MapSnapshot
+		if err := rows.Scan(
+			&snapshot.ID,
+			&snapshot.MapID,
+			&snapshot.Version,
+			&snapshot.Note,
+			&snapshot.CreatedBy,
+			&snapshot.CreatedAt,
+			&snapshot.IsPublished,
+		); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// GetPublished はマップの公開中のスナップショットを内容とともに取得する
+func (r *MySQLMapSnapshotRepository) GetPublished(ctx context.Context, mapID string) (*models.MapSnapshot, error) {
+	query := `
+		SELECT s.id, s.map_id, s.version, s.note, s.data, s.created_by, s.created_at, TRUE
+		FROM map_published_snapshots p
+		JOIN map_snapshots s ON s.id = p.snapshot_id
+		WHERE p.map_id = ?
+	`
+
+	return r.scanWithData(r.db.QueryRowContext(ctx, query, mapID))
+}
+
+// SetPublished はビューワーが配信するスナップショットを設定する
+func (r *MySQLMapSnapshotRepository) SetPublished(ctx context.Context, mapID, snapshotID string) error {
+	query := `
+		INSERT INTO map_published_snapshots (map_id, snapshot_id, published_at)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE snapshot_id = VALUES(snapshot_id), published_at = VALUES(published_at)
+	`
+	_, err := r.db.ExecContext(ctx, query, mapID, snapshotID, time.Now())
+	return err
+}
+
+// ClearPublished はスナップショットの配信をやめ、編集中のデータを配信する状態に戻す
+func (r *MySQLMapSnapshotRepository) ClearPublished(ctx context.Context, mapID string) error {
+	query := `DELETE FROM map_published_snapshots WHERE map_id = ?`
+	_, err := r.db.ExecContext(ctx, query, mapID)
+	return err
+}
+
+// RestoreWorkingCopy は編集中のマップ・フロア・ピンをスナップショットの内容に置き換える
+// スナップショットにないフロア・ピンは削除する
+func (r *MySQLMapSnapshotRepository) RestoreWorkingCopy(ctx context.Context, data *models.MapSnapshotData) error {
+	now := time.Now()
+	mapID := data.Map.ID
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE maps SET title = ?, description = ?, updated_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, data.Map.Title, data.Map.Description, now, mapID); err != nil {
+		return err
+	}
+
+	// フロアの復元
+	floorIDs := []interface{}{mapID}
+	floorPlaceholders := []string{}
+	for _, floor := range data.Floors {
+		query := `
+			INSERT INTO floors (id, map_id, floor_number, name, image_url, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE floor_number = VALUES(floor_number), name = VALUES(name),
+			    image_url = VALUES(image_url), updated_at = VALUES(updated_at)
+		`
+		if _, err := tx.ExecContext(ctx, query, floor.ID, mapID, floor.FloorNumber, floor.Name, floor.ImageURL, floor.CreatedAt, now); err != nil {
+			return err
+		}
+		floorIDs = append(floorIDs, floor.ID)
+		floorPlaceholders = append(floorPlaceholders, "?")
+	}
+
+	query = `DELETE FROM floors WHERE map_id = ?`
+	if len(floorPlaceholders) > 0 {
+		query += ` AND id NOT IN (` + strings.Join(floorPlaceholders, ",") + `)`
+	}
+	if _, err := tx.ExecContext(ctx, query, floorIDs...); err != nil {
+		return err
+	}
+
+	// ピンの復元
+	pinIDs := []interface{}{mapID}
+	pinPlaceholders := []string{}
+	for _, pin := range data.Pins {
+		query := `
+			INSERT INTO pins (id, floor_id, title, description, category, x_position, y_position, image_url, editor_id, editor_nickname, external_key, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE floor_id = VALUES(floor_id), title = VALUES(title), description = VALUES(description),
+			    category = VALUES(category), x_position = VALUES(x_position), y_position = VALUES(y_position),
+			    image_url = VALUES(image_url), editor_id = VALUES(editor_id), editor_nickname = VALUES(editor_nickname),
+			    external_key = VALUES(external_key), updated_at = VALUES(updated_at)
+		`
+		if _, err := tx.ExecContext(
+			ctx,
+			query,
+			pin.ID,
+			pin.FloorID,
+			pin.Title,
+			pin.Description,
+			pin.Category,
+			pin.XPosition,
+			pin.YPosition,
+			pin.ImageURL,
+			pin.EditorID,
+			pin.EditorNickname,
+			pin.ExternalKey,
+			pin.CreatedAt,
+			now,
+		); err != nil {
+			return err
+		}
+		pinIDs = append(pinIDs, pin.ID)
+		pinPlaceholders = append(pinPlaceholders, "?")
+	}
+
+	query = `DELETE FROM pins WHERE floor_id IN (SELECT id FROM floors WHERE map_id = ?)`
+	if len(pinPlaceholders) > 0 {
+		query += ` AND id NOT IN (` + strings.Join(pinPlaceholders, ",") + `)`
+	}
+	if _, err := tx.ExecContext(ctx, query, pinIDs...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// scanWithData はスナップショットを内容とともに読み込む
+func (r *MySQLMapSnapshotRepository) scanWithData(row *sql.Row) (*models.MapSnapshot, error) {
+	var snapshot models.MapSnapshot
+	var data string
+	err := row.Scan(
+		&snapshot.ID,
+		&snapshot.MapID,
+		&snapshot.Version,
+		&snapshot.Note,
+		&data,
+		&snapshot.CreatedBy,
+		&snapshot.CreatedAt,
+		&snapshot.IsPublished,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Data = &models.MapSnapshotData{}
+	if err := json.Unmarshal([]byte(data), snapshot.Data); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
@@ -0,0 +1,102 @@
+// backend/repositories/oidc_state_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// OIDCStateRepository は認可リクエスト中の状態へのアクセスを提供するインターフェース
+type OIDCStateRepository interface {
+	Create(ctx context.Context, state *models.OIDCLoginState) error
+	Consume(ctx context.Context, state string, now time.Time) (*models.OIDCLoginState, error)
+}
+
+// MySQLOIDCStateRepository はMySQLデータベースを使用したOIDCStateRepositoryの実装
+type MySQLOIDCStateRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLOIDCStateRepository は新しいMySQLOIDCStateRepositoryを作成する
+func NewMySQLOIDCStateRepository(db *sql.DB) OIDCStateRepository {
+	return &MySQLOIDCStateRepository{db: db}
+}
+
+// Create は認可リクエストの状態を保存する
+// 期限切れの状態はこのときにまとめて削除する
+func (r *MySQLOIDCStateRepository) Create(ctx context.Context, state *models.OIDCLoginState) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE expires_at < ?`, time.Now()); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO oidc_login_states (state, provider, nonce, code_verifier, user_id, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		state.State,
+		state.Provider,
+		state.Nonce,
+		state.CodeVerifier,
+		state.UserID,
+		state.ExpiresAt,
+		time.Now(),
+	)
+
+	return err
+}
+
+// Consume は認可リクエストの状態を取得して削除する
+// 存在しないか期限切れの場合はnilを返す
+func (r *MySQLOIDCStateRepository) Consume(ctx context.Context, state string, now time.Time) (*models.OIDCLoginState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT state, provider, nonce, code_verifier, user_id, expires_at
+		FROM oidc_login_states
+		WHERE state = ?
+		FOR UPDATE
+	`
+
+	var loginState models.OIDCLoginState
+	err = tx.QueryRowContext(ctx, query, state).Scan(
+		&loginState.State,
+		&loginState.Provider,
+		&loginState.Nonce,
+		&loginState.CodeVerifier,
+		&loginState.UserID,
+		&loginState.ExpiresAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM oidc_login_states WHERE state = ?`, state); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if !now.Before(loginState.ExpiresAt) {
+		return nil, nil
+	}
+
+	return &loginState, nil
+}
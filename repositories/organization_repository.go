@@ -0,0 +1,257 @@
+// backend/repositories/organization_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// OrganizationRepository は組織とメンバーのデータへのアクセスを提供するインターフェース
+type OrganizationRepository interface {
+	Create(ctx context.Context, org *models.Organization, ownerID string) error
+	GetByID(ctx context.Context, id string) (*models.Organization, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.Organization, error)
+	Update(ctx context.Context, org *models.Organization) error
+	Delete(ctx context.Context, id string) error
+	CountMaps(ctx context.Context, id string) (int, error)
+	GetMembers(ctx context.Context, id string) ([]*models.OrganizationMember, error)
+	GetMember(ctx context.Context, id, userID string) (*models.OrganizationMember, error)
+	AddMember(ctx context.Context, member *models.OrganizationMember) error
+	UpdateMemberRole(ctx context.Context, id, userID, role string) error
+	RemoveMember(ctx context.Context, id, userID string) error
+	CountOwners(ctx context.Context, id string) (int, error)
+}
+
+// MySQLOrganizationRepository はMySQLデータベースを使用したOrganizationRepositoryの実装
+type MySQLOrganizationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLOrganizationRepository は新しいMySQLOrganizationRepositoryを作成する
+func NewMySQLOrganizationRepository(db *sql.DB) OrganizationRepository {
+	return &MySQLOrganizationRepository{db: db}
+}
+
+// Create は新しい組織を作成し、作成したユーザーを所有者として追加する
+func (r *MySQLOrganizationRepository) Create(ctx context.Context, org *models.Organization, ownerID string) error {
+	if org.ID == "" {
+		org.ID = uuid.New().String()
+	}
+	now := time.Now()
+	org.CreatedAt = now
+	org.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO organizations (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, org.ID, org.Name, org.CreatedAt, org.UpdatedAt); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, org.ID, ownerID, models.OrganizationRoleOwner, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	org.Role = models.OrganizationRoleOwner
+	return nil
+}
+
+// GetByID はIDにより組織を取得する
+func (r *MySQLOrganizationRepository) GetByID(ctx context.Context, id string) (*models.Organization, error) {
+	query := `SELECT id, name, created_at, updated_at FROM organizations WHERE id = ?`
+
+	var org models.Organization
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&org.ID,
+		&org.Name,
+		&org.CreatedAt,
+		&org.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &org, nil
+}
+
+// GetByUserID はユーザーが所属する組織の一覧を、ユーザーの役割とともに取得する
+func (r *MySQLOrganizationRepository) GetByUserID(ctx context.Context, userID string) ([]*models.Organization, error) {
+	query := `
+		SELECT o.id, o.name, m.role, o.created_at, o.updated_at
+		FROM organization_members m
+		JOIN organizations o ON o.id = m.organization_id
+		WHERE m.user_id = ?
+		ORDER BY o.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orgs []*models.Organization
+	for rows.Next() {
+		var org models.Organization
+		if err := rows.Scan(
+			&org.ID,
+			&org.Name,
+			&org.Role,
+			&org.CreatedAt,
+			&org.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, &org)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// Update は組織の情報を更新する
+func (r *MySQLOrganizationRepository) Update(ctx context.Context, org *models.Organization) error {
+	org.UpdatedAt = time.Now()
+
+	query := `UPDATE organizations SET name = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, org.Name, org.UpdatedAt, org.ID)
+	return err
+}
+
+// Delete は組織を削除する（メンバーも削除される）
+func (r *MySQLOrganizationRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM organizations WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// CountMaps は組織が所有するマップの数を取得する
+func (r *MySQLOrganizationRepository) CountMaps(ctx context.Context, id string) (int, error) {
+	query := `SELECT COUNT(*) FROM maps WHERE organization_id = ?`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&count)
+	return count, err
+}
+
+// GetMembers は組織のメンバー一覧を取得する
+func (r *MySQLOrganizationRepository) GetMembers(ctx context.Context, id string) ([]*models.OrganizationMember, error) {
+	query := `
+		SELECT m.organization_id, m.user_id, u.name, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = ?
+		ORDER BY m.created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []*models.OrganizationMember
+	for rows.Next() {
+		var member models.OrganizationMember
+		if err := rows.Scan(
+			&member.OrganizationID,
+			&member.UserID,
+			&member.Name,
+			&member.Email,
+			&member.Role,
+			&member.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// GetMember は組織のメンバーを1人取得する
+func (r *MySQLOrganizationRepository) GetMember(ctx context.Context, id, userID string) (*models.OrganizationMember, error) {
+	query := `
+		SELECT m.organization_id, m.user_id, u.name, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.organization_id = ? AND m.user_id = ?
+	`
+
+	var member models.OrganizationMember
+	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
+		&member.OrganizationID,
+		&member.UserID,
+		&member.Name,
+		&member.Email,
+		&member.Role,
+		&member.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// AddMember は組織にメンバーを追加する
+func (r *MySQLOrganizationRepository) AddMember(ctx context.Context, member *models.OrganizationMember) error {
+	member.CreatedAt = time.Now()
+
+	query := `INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query, member.OrganizationID, member.UserID, member.Role, member.CreatedAt)
+	return err
+}
+
+// UpdateMemberRole はメンバーの役割を変更する
+func (r *MySQLOrganizationRepository) UpdateMemberRole(ctx context.Context, id, userID, role string) error {
+	query := `UPDATE organization_members SET role = ? WHERE organization_id = ? AND user_id = ?`
+	_, err := r.db.ExecContext(ctx, query, role, id, userID)
+	return err
+}
+
+// RemoveMember は組織からメンバーを外す
+func (r *MySQLOrganizationRepository) RemoveMember(ctx context.Context, id, userID string) error {
+	query := `DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?`
+	_, err := r.db.ExecContext(ctx, query, id, userID)
+	return err
+}
+
+// CountOwners は組織の所有者の数を取得する
+func (r *MySQLOrganizationRepository) CountOwners(ctx context.Context, id string) (int, error) {
+	query := `SELECT COUNT(*) FROM organization_members WHERE organization_id = ? AND role = ?`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, id, models.OrganizationRoleOwner).Scan(&count)
+	return count, err
+}
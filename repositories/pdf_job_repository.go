@@ -0,0 +1,127 @@
+// backend/repositories/pdf_job_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// PDFJobRepository はPDF生成ジョブデータへのアクセスを提供するインターフェース
+type PDFJobRepository interface {
+	Create(ctx context.Context, job *models.PDFJob) error
+	GetByID(ctx context.Context, id string) (*models.PDFJob, error)
+	Update(ctx context.Context, job *models.PDFJob) error
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// MySQLPDFJobRepository はMySQLデータベースを使用したPDFJobRepositoryの実装
+type MySQLPDFJobRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPDFJobRepository は新しいMySQLPDFJobRepositoryを作成する
+func NewMySQLPDFJobRepository(db *sql.DB) PDFJobRepository {
+	return &MySQLPDFJobRepository{db: db}
+}
+
+// Create は新しいPDF生成ジョブを作成する
+func (r *MySQLPDFJobRepository) Create(ctx context.Context, job *models.PDFJob) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	job.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO pdf_jobs (id, map_id, user_id, status, error, file_path, created_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		job.ID,
+		job.MapID,
+		job.UserID,
+		job.Status,
+		job.Error,
+		job.FilePath,
+		job.CreatedAt,
+		job.CompletedAt,
+	)
+
+	return err
+}
+
+// GetByID はIDによりPDF生成ジョブを取得する
+func (r *MySQLPDFJobRepository) GetByID(ctx context.Context, id string) (*models.PDFJob, error) {
+	query := `
+		SELECT id, map_id, user_id, status, error, file_path, created_at, completed_at
+		FROM pdf_jobs
+		WHERE id = ?
+	`
+
+	var job models.PDFJob
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&job.ID,
+		&job.MapID,
+		&job.UserID,
+		&job.Status,
+		&job.Error,
+		&job.FilePath,
+		&job.CreatedAt,
+		&completedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// NULL値の処理
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+
+	return &job, nil
+}
+
+// Update はPDF生成ジョブの状態を更新する
+func (r *MySQLPDFJobRepository) Update(ctx context.Context, job *models.PDFJob) error {
+	query := `
+		UPDATE pdf_jobs
+		SET status = ?, error = ?, file_path = ?, completed_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		job.Status,
+		job.Error,
+		job.FilePath,
+		job.CompletedAt,
+		job.ID,
+	)
+
+	return err
+}
+
+// DeleteBefore は指定した時刻より前に作成された処理済みのジョブを削除し、削除した件数を返す
+func (r *MySQLPDFJobRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM pdf_jobs WHERE created_at < ? AND status IN (?, ?)`
+
+	result, err := r.db.ExecContext(ctx, query, before, models.JobStatusCompleted, models.JobStatusFailed)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
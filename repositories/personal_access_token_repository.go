@@ -0,0 +1,195 @@
+// backend/repositories/personal_access_token_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// PersonalAccessTokenRepository は個人用アクセストークンデータへのアクセスを提供するインターフェース
+type PersonalAccessTokenRepository interface {
+	Create(ctx context.Context, token *models.PersonalAccessToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error)
+	CountActive(ctx context.Context, userID string, now time.Time) (int, error)
+	Revoke(ctx context.Context, userID, id string, revokedAt time.Time) (bool, error)
+	Touch(ctx context.Context, id string, now time.Time, interval time.Duration) error
+}
+
+// MySQLPersonalAccessTokenRepository はMySQLデータベースを使用したPersonalAccessTokenRepositoryの実装
+type MySQLPersonalAccessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPersonalAccessTokenRepository は新しいMySQLPersonalAccessTokenRepositoryを作成する
+func NewMySQLPersonalAccessTokenRepository(db *sql.DB) PersonalAccessTokenRepository {
+	return &MySQLPersonalAccessTokenRepository{db: db}
+}
+
+// Create は新しい個人用アクセストークンを作成する
+func (r *MySQLPersonalAccessTokenRepository) Create(ctx context.Context, token *models.PersonalAccessToken) error {
+	if token.ID == "" {
+		token.ID = uuid.New().String()
+	}
+	token.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO personal_access_tokens (id, user_id, name, token_hash, token_prefix, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		token.ID,
+		token.UserID,
+		token.Name,
+		token.TokenHash,
+		token.TokenPrefix,
+		strings.Join(token.Scopes, ","),
+		token.ExpiresAt,
+		token.CreatedAt,
+	)
+
+	return err
+}
+
+// GetByHash はトークンのハッシュにより個人用アクセストークンを取得する
+func (r *MySQLPersonalAccessTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = ?
+	`
+
+	return scanPersonalAccessToken(r.db.QueryRowContext(ctx, query, tokenHash))
+}
+
+// GetByUserID はユーザーの個人用アクセストークン一覧を取得する
+func (r *MySQLPersonalAccessTokenRepository) GetByUserID(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = ?
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*models.PersonalAccessToken
+	for rows.Next() {
+		var token models.PersonalAccessToken
+		var scopes string
+		if err := rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.Name,
+			&token.TokenHash,
+			&token.TokenPrefix,
+			&scopes,
+			&token.ExpiresAt,
+			&token.LastUsedAt,
+			&token.RevokedAt,
+			&token.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		token.Scopes = splitScopes(scopes)
+		tokens = append(tokens, &token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CountActive はユーザーの有効な個人用アクセストークンの数を取得する
+func (r *MySQLPersonalAccessTokenRepository) CountActive(ctx context.Context, userID string, now time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM personal_access_tokens
+		WHERE user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, userID, now).Scan(&count)
+	return count, err
+}
+
+// Revoke はユーザーの個人用アクセストークンを無効化する
+// 該当するトークンがない、または既に無効化されている場合はfalseを返す
+func (r *MySQLPersonalAccessTokenRepository) Revoke(ctx context.Context, userID, id string, revokedAt time.Time) (bool, error) {
+	query := `UPDATE personal_access_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, revokedAt, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Touch は個人用アクセストークンの最終使用日時を更新する
+// リクエストのたびに書き込まないよう、前回の更新からintervalが経過している場合だけ更新する
+func (r *MySQLPersonalAccessTokenRepository) Touch(ctx context.Context, id string, now time.Time, interval time.Duration) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = ?
+		WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, now, id, now.Add(-interval))
+	return err
+}
+
+// scanPersonalAccessToken は個人用アクセストークンを1件読み込む
+func scanPersonalAccessToken(row *sql.Row) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	var scopes string
+	err := row.Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		&token.TokenHash,
+		&token.TokenPrefix,
+		&scopes,
+		&token.ExpiresAt,
+		&token.LastUsedAt,
+		&token.RevokedAt,
+		&token.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	token.Scopes = splitScopes(scopes)
+	return &token, nil
+}
+
+// splitScopes はカンマ区切りの権限を分割する
+func splitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
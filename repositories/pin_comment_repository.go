@@ -0,0 +1,221 @@
+// backend/repositories/pin_comment_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// PinCommentRepository はピンへのコメントデータへのアクセスを提供するインターフェース
+type PinCommentRepository interface {
+	Create(ctx context.Context, comment *models.PinComment) error
+	GetByID(ctx context.Context, id string) (*models.PinComment, error)
+	GetByPinID(ctx context.Context, pinID string, includeHidden bool, before time.Time, beforeID string, limit int) ([]*models.PinComment, error)
+	GetByMapID(ctx context.Context, mapID, status string, before time.Time, beforeID string, limit int) ([]*models.PinComment, error)
+	UpdateStatus(ctx context.Context, id, status string) error
+	Delete(ctx context.Context, id string) error
+	CountVisibleByMapID(ctx context.Context, mapID string) (map[string]int, error)
+}
+
+// MySQLPinCommentRepository はMySQLデータベースを使用したPinCommentRepositoryの実装
+type MySQLPinCommentRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPinCommentRepository は新しいMySQLPinCommentRepositoryを作成する
+func NewMySQLPinCommentRepository(db *sql.DB) PinCommentRepository {
+	return &MySQLPinCommentRepository{db: db}
+}
+
+// Create は新しいコメントを作成する
+func (r *MySQLPinCommentRepository) Create(ctx context.Context, comment *models.PinComment) error {
+	if comment.ID == "" {
+		comment.ID = uuid.New().String()
+	}
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	query := `
+		INSERT INTO pin_comments (id, pin_id, map_id, body, author_type, author_id, author_name, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		comment.ID,
+		comment.PinID,
+		comment.MapID,
+		comment.Body,
+		comment.AuthorType,
+		comment.AuthorID,
+		comment.AuthorName,
+		comment.Status,
+		comment.CreatedAt,
+		comment.UpdatedAt,
+	)
+
+	return err
+}
+
+// GetByID はIDによりコメントを取得する
+func (r *MySQLPinCommentRepository) GetByID(ctx context.Context, id string) (*models.PinComment, error) {
+	query := `
+		SELECT id, pin_id, map_id, body, author_type, author_id, author_name, status, created_at, updated_at
+		FROM pin_comments
+		WHERE id = ?
+	`
+
+	var c models.PinComment
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&c.ID,
+		&c.PinID,
+		&c.MapID,
+		&c.Body,
+		&c.AuthorType,
+		&c.AuthorID,
+		&c.AuthorName,
+		&c.Status,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// GetByPinID はピンのコメントを新しい順に取得する
+// beforeがゼロ値でない場合は、その位置より古いコメントを取得する
+func (r *MySQLPinCommentRepository) GetByPinID(ctx context.Context, pinID string, includeHidden bool, before time.Time, beforeID string, limit int) ([]*models.PinComment, error) {
+	query := `
+		SELECT id, pin_id, map_id, body, author_type, author_id, author_name, status, created_at, updated_at
+		FROM pin_comments
+		WHERE pin_id = ?
+	`
+	args := []interface{}{pinID}
+
+	if !includeHidden {
+		query += ` AND status = ?`
+		args = append(args, models.CommentStatusVisible)
+	}
+
+	return r.queryPage(ctx, query, args, before, beforeID, limit)
+}
+
+// GetByMapID はマップのコメントを新しい順に取得する
+// statusが空の場合はすべての表示状態のコメントを取得する
+func (r *MySQLPinCommentRepository) GetByMapID(ctx context.Context, mapID, status string, before time.Time, beforeID string, limit int) ([]*models.PinComment, error) {
+	query := `
+		SELECT id, pin_id, map_id, body, author_type, author_id, author_name, status, created_at, updated_at
+		FROM pin_comments
+		WHERE map_id = ?
+	`
+	args := []interface{}{mapID}
+
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+
+	return r.queryPage(ctx, query, args, before, beforeID, limit)
+}
+
+// UpdateStatus はコメントの表示状態を更新する
+func (r *MySQLPinCommentRepository) UpdateStatus(ctx context.Context, id, status string) error {
+	query := `UPDATE pin_comments SET status = ?, updated_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, status, time.Now(), id)
+	return err
+}
+
+// Delete はコメントを削除する
+func (r *MySQLPinCommentRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM pin_comments WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// CountVisibleByMapID はマップの表示中のコメント数をピンごとに集計する
+func (r *MySQLPinCommentRepository) CountVisibleByMapID(ctx context.Context, mapID string) (map[string]int, error) {
+	query := `
+		SELECT pin_id, COUNT(*)
+		FROM pin_comments
+		WHERE map_id = ? AND status = ?
+		GROUP BY pin_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID, models.CommentStatusVisible)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var pinID string
+		var count int
+		if err := rows.Scan(&pinID, &count); err != nil {
+			return nil, err
+		}
+		counts[pinID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// queryPage は条件に続けてページ位置と件数の指定を加え、コメントを取得する
+func (r *MySQLPinCommentRepository) queryPage(ctx context.Context, query string, args []interface{}, before time.Time, beforeID string, limit int) ([]*models.PinComment, error) {
+	if !before.IsZero() {
+		query += ` AND (created_at < ? OR (created_at = ? AND id < ?))`
+		args = append(args, before, before, beforeID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*models.PinComment
+	for rows.Next() {
+		var c models.PinComment
+		if err := rows.Scan(
+			&c.ID,
+			&c.PinID,
+			&c.MapID,
+			&c.Body,
+			&c.AuthorType,
+			&c.AuthorID,
+			&c.AuthorName,
+			&c.Status,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
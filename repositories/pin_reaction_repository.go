@@ -0,0 +1,142 @@
+// backend/repositories/pin_reaction_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PinReactionRepository はピンへのリアクションデータへのアクセスを提供するインターフェース
+type PinReactionRepository interface {
+	Add(ctx context.Context, pinID, mapID, emoji, authorType, authorID string) error
+	Remove(ctx context.Context, pinID, emoji, authorType, authorID string) error
+	CountByPinID(ctx context.Context, pinID string) (map[string]int, error)
+	CountByMapID(ctx context.Context, mapID string) (map[string]map[string]int, error)
+	GetByAuthor(ctx context.Context, pinID, authorType, authorID string) ([]string, error)
+}
+
+// MySQLPinReactionRepository はMySQLデータベースを使用したPinReactionRepositoryの実装
+type MySQLPinReactionRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPinReactionRepository は新しいMySQLPinReactionRepositoryを作成する
+func NewMySQLPinReactionRepository(db *sql.DB) PinReactionRepository {
+	return &MySQLPinReactionRepository{db: db}
+}
+
+// Add はリアクションを追加する（既に付けている場合は何もしない）
+func (r *MySQLPinReactionRepository) Add(ctx context.Context, pinID, mapID, emoji, authorType, authorID string) error {
+	query := `
+		INSERT IGNORE INTO pin_reactions (pin_id, map_id, emoji, author_type, author_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, pinID, mapID, emoji, authorType, authorID, time.Now())
+	return err
+}
+
+// Remove はリアクションを取り消す
+func (r *MySQLPinReactionRepository) Remove(ctx context.Context, pinID, emoji, authorType, authorID string) error {
+	query := `DELETE FROM pin_reactions WHERE pin_id = ? AND emoji = ? AND author_type = ? AND author_id = ?`
+	_, err := r.db.ExecContext(ctx, query, pinID, emoji, authorType, authorID)
+	return err
+}
+
+// CountByPinID はピンのリアクション数を絵文字ごとに集計する
+func (r *MySQLPinReactionRepository) CountByPinID(ctx context.Context, pinID string) (map[string]int, error) {
+	query := `
+		SELECT emoji, COUNT(*)
+		FROM pin_reactions
+		WHERE pin_id = ?
+		GROUP BY emoji
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pinID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var emoji string
+		var count int
+		if err := rows.Scan(&emoji, &count); err != nil {
+			return nil, err
+		}
+		counts[emoji] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// CountByMapID はマップのリアクション数をピン・絵文字ごとに集計する
+func (r *MySQLPinReactionRepository) CountByMapID(ctx context.Context, mapID string) (map[string]map[string]int, error) {
+	query := `
+		SELECT pin_id, emoji, COUNT(*)
+		FROM pin_reactions
+		WHERE map_id = ?
+		GROUP BY pin_id, emoji
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]map[string]int)
+	for rows.Next() {
+		var pinID, emoji string
+		var count int
+		if err := rows.Scan(&pinID, &emoji, &count); err != nil {
+			return nil, err
+		}
+		if counts[pinID] == nil {
+			counts[pinID] = make(map[string]int)
+		}
+		counts[pinID][emoji] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// GetByAuthor は利用者がピンに付けているリアクションの絵文字を取得する
+func (r *MySQLPinReactionRepository) GetByAuthor(ctx context.Context, pinID, authorType, authorID string) ([]string, error) {
+	query := `
+		SELECT emoji
+		FROM pin_reactions
+		WHERE pin_id = ? AND author_type = ? AND author_id = ?
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, pinID, authorType, authorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emojis []string
+	for rows.Next() {
+		var emoji string
+		if err := rows.Scan(&emoji); err != nil {
+			return nil, err
+		}
+		emojis = append(emojis, emoji)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emojis, nil
+}
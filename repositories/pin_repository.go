@@ -18,9 +18,15 @@ type PinRepository interface {
 	GetByFloorID(ctx context.Context, floorID string) ([]*models.Pin, error)
 	GetByFloorIDs(ctx context.Context, floorIDs []string) ([]*models.Pin, error)
 	Update(ctx context.Context, pin *models.Pin) error
+	SaveAll(ctx context.Context, created, updated []*models.Pin) error
 	Delete(ctx context.Context, id string) error
 }
 
+// pinExecer はピンの書き込みをDBとトランザクションで共通化するためのインターフェース
+type pinExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // MySQLPinRepository はMySQLデータベースを使用したPinRepositoryの実装
 type MySQLPinRepository struct {
 	db *sql.DB
@@ -33,6 +39,11 @@ func NewMySQLPinRepository(db *sql.DB) PinRepository {
 
 // Create は新しいピンを作成する
 func (r *MySQLPinRepository) Create(ctx context.Context, pin *models.Pin) error {
+	return insertPin(ctx, r.db, pin)
+}
+
+// insertPin はピンを挿入する
+func insertPin(ctx context.Context, db pinExecer, pin *models.Pin) error {
 	if pin.ID == "" {
 		pin.ID = uuid.New().String()
 	}
@@ -40,22 +51,24 @@ func (r *MySQLPinRepository) Create(ctx context.Context, pin *models.Pin) error
 	pin.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO pins (id, floor_id, title, description, x_position, y_position, image_url, editor_id, editor_nickname, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO pins (id, floor_id, title, description, category, x_position, y_position, image_url, editor_id, editor_nickname, external_key, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err := r.db.ExecContext(
+	_, err := db.ExecContext(
 		ctx,
 		query,
 		pin.ID,
 		pin.FloorID,
 		pin.Title,
 		pin.Description,
+		pin.Category,
 		pin.XPosition,
 		pin.YPosition,
 		pin.ImageURL,
 		pin.EditorID,
 		pin.EditorNickname,
+		pin.ExternalKey,
 		pin.CreatedAt,
 		pin.UpdatedAt,
 	)
@@ -66,7 +79,7 @@ func (r *MySQLPinRepository) Create(ctx context.Context, pin *models.Pin) error
 // GetByID はIDによりピンを取得する
 func (r *MySQLPinRepository) GetByID(ctx context.Context, id string) (*models.Pin, error) {
 	query := `
-		SELECT id, floor_id, title, description, x_position, y_position, image_url, editor_id, editor_nickname, created_at, updated_at
+		SELECT id, floor_id, title, description, category, x_position, y_position, image_url, editor_id, editor_nickname, external_key, created_at, updated_at
 		FROM pins
 		WHERE id = ?
 	`
@@ -79,11 +92,13 @@ func (r *MySQLPinRepository) GetByID(ctx context.Context, id string) (*models.Pi
 		&pin.FloorID,
 		&pin.Title,
 		&pin.Description,
+		&pin.Category,
 		&pin.XPosition,
 		&pin.YPosition,
 		&imageURL,
 		&editorID,
 		&editorNickname,
+		&pin.ExternalKey,
 		&pin.CreatedAt,
 		&pin.UpdatedAt,
 	)
@@ -113,7 +128,7 @@ func (r *MySQLPinRepository) GetByID(ctx context.Context, id string) (*models.Pi
 // GetByFloorID はフロアIDによりピンを取得する
 func (r *MySQLPinRepository) GetByFloorID(ctx context.Context, floorID string) ([]*models.Pin, error) {
 	query := `
-		SELECT id, floor_id, title, description, x_position, y_position, image_url, editor_id, editor_nickname, created_at, updated_at
+		SELECT id, floor_id, title, description, category, x_position, y_position, image_url, editor_id, editor_nickname, external_key, created_at, updated_at
 		FROM pins
 		WHERE floor_id = ?
 		ORDER BY created_at ASC
@@ -135,11 +150,13 @@ func (r *MySQLPinRepository) GetByFloorID(ctx context.Context, floorID string) (
 			&pin.FloorID,
 			&pin.Title,
 			&pin.Description,
+			&pin.Category,
 			&pin.XPosition,
 			&pin.YPosition,
 			&imageURL,
 			&editorID,
 			&editorNickname,
+			&pin.ExternalKey,
 			&pin.CreatedAt,
 			&pin.UpdatedAt,
 		); err != nil {
@@ -184,7 +201,7 @@ func (r *MySQLPinRepository) GetByFloorIDs(ctx context.Context, floorIDs []strin
 
 	// SQLクエリを構築
 	query := `
-		SELECT id, floor_id, title, description, x_position, y_position, image_url, editor_id, editor_nickname, created_at, updated_at
+		SELECT id, floor_id, title, description, category, x_position, y_position, image_url, editor_id, editor_nickname, external_key, created_at, updated_at
 		FROM pins
 		WHERE floor_id IN (` + strings.Join(placeholders, ",") + `)
 		ORDER BY created_at ASC
@@ -208,11 +225,13 @@ func (r *MySQLPinRepository) GetByFloorIDs(ctx context.Context, floorIDs []strin
 			&pin.FloorID,
 			&pin.Title,
 			&pin.Description,
+			&pin.Category,
 			&pin.XPosition,
 			&pin.YPosition,
 			&imageURL,
 			&editorID,
 			&editorNickname,
+			&pin.ExternalKey,
 			&pin.CreatedAt,
 			&pin.UpdatedAt,
 		); err != nil {
@@ -242,25 +261,32 @@ func (r *MySQLPinRepository) GetByFloorIDs(ctx context.Context, floorIDs []strin
 
 // Update はピン情報を更新する
 func (r *MySQLPinRepository) Update(ctx context.Context, pin *models.Pin) error {
+	return updatePin(ctx, r.db, pin)
+}
+
+// updatePin はピンを更新する
+func updatePin(ctx context.Context, db pinExecer, pin *models.Pin) error {
 	pin.UpdatedAt = time.Now()
 
 	query := `
 		UPDATE pins
-		SET title = ?, description = ?, x_position = ?, y_position = ?, image_url = ?, 
-		    editor_id = ?, editor_nickname = ?, updated_at = ?
+		SET title = ?, description = ?, category = ?, x_position = ?, y_position = ?, image_url = ?, 
+		    editor_id = ?, editor_nickname = ?, external_key = ?, updated_at = ?
 		WHERE id = ?
 	`
 
-	_, err := r.db.ExecContext(
+	_, err := db.ExecContext(
 		ctx,
 		query,
 		pin.Title,
 		pin.Description,
+		pin.Category,
 		pin.XPosition,
 		pin.YPosition,
 		pin.ImageURL,
 		pin.EditorID,
 		pin.EditorNickname,
+		pin.ExternalKey,
 		pin.UpdatedAt,
 		pin.ID,
 	)
@@ -268,6 +294,29 @@ func (r *MySQLPinRepository) Update(ctx context.Context, pin *models.Pin) error
 	return err
 }
 
+// SaveAll はピンの作成と更新を1つのトランザクションで行う
+// いずれかが失敗した場合はすべて取り消す
+func (r *MySQLPinRepository) SaveAll(ctx context.Context, created, updated []*models.Pin) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, pin := range created {
+		if err := insertPin(ctx, tx, pin); err != nil {
+			return err
+		}
+	}
+	for _, pin := range updated {
+		if err := updatePin(ctx, tx, pin); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 // Delete はピンを削除する
 func (r *MySQLPinRepository) Delete(ctx context.Context, id string) error {
 	query := `DELETE FROM pins WHERE id = ?`
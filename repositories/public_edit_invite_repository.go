@@ -0,0 +1,222 @@
+// backend/repositories/public_edit_invite_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// PublicEditInviteRepository は公開編集の招待コードへのアクセスを提供するインターフェース
+type PublicEditInviteRepository interface {
+	Create(ctx context.Context, invite *models.PublicEditInvite) error
+	GetByID(ctx context.Context, id string) (*models.PublicEditInvite, error)
+	GetByCode(ctx context.Context, code string) (*models.PublicEditInvite, error)
+	GetByMapID(ctx context.Context, mapID string) ([]*models.PublicEditInvite, error)
+	Use(ctx context.Context, id string, now time.Time) (bool, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) (int64, error)
+}
+
+// MySQLPublicEditInviteRepository はMySQLデータベースを使用したPublicEditInviteRepositoryの実装
+type MySQLPublicEditInviteRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPublicEditInviteRepository は新しいMySQLPublicEditInviteRepositoryを作成する
+func NewMySQLPublicEditInviteRepository(db *sql.DB) PublicEditInviteRepository {
+	return &MySQLPublicEditInviteRepository{db: db}
+}
+
+// Create は新しい招待コードを作成する
+func (r *MySQLPublicEditInviteRepository) Create(ctx context.Context, invite *models.PublicEditInvite) error {
+	if invite.ID == "" {
+		invite.ID = uuid.New().String()
+	}
+	invite.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO public_edit_invites (id, map_id, code, label, expires_at, max_uses, use_count,
+		    can_create, can_edit_others, can_delete, can_upload_images, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		invite.ID,
+		invite.MapID,
+		invite.Code,
+		invite.Label,
+		invite.ExpiresAt,
+		invite.MaxUses,
+		invite.Permissions.CanCreate,
+		invite.Permissions.CanEditOthers,
+		invite.Permissions.CanDelete,
+		invite.Permissions.CanUploadImages,
+		invite.CreatedBy,
+		invite.CreatedAt,
+	)
+
+	return err
+}
+
+// GetByID はIDにより招待コードを取得する
+func (r *MySQLPublicEditInviteRepository) GetByID(ctx context.Context, id string) (*models.PublicEditInvite, error) {
+	query := `
+		SELECT id, map_id, code, label, expires_at, max_uses, use_count,
+		       can_create, can_edit_others, can_delete, can_upload_images, revoked_at, created_by, created_at
+		FROM public_edit_invites
+		WHERE id = ?
+	`
+	return scanPublicEditInvite(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetByCode はコードにより招待コードを取得する
+func (r *MySQLPublicEditInviteRepository) GetByCode(ctx context.Context, code string) (*models.PublicEditInvite, error) {
+	query := `
+		SELECT id, map_id, code, label, expires_at, max_uses, use_count,
+		       can_create, can_edit_others, can_delete, can_upload_images, revoked_at, created_by, created_at
+		FROM public_edit_invites
+		WHERE code = ?
+	`
+	return scanPublicEditInvite(r.db.QueryRowContext(ctx, query, code))
+}
+
+// GetByMapID はマップの招待コード一覧を登録した編集者の人数と共に取得する
+func (r *MySQLPublicEditInviteRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.PublicEditInvite, error) {
+	query := `
+		SELECT i.id, i.map_id, i.code, i.label, i.expires_at, i.max_uses, i.use_count,
+		       i.can_create, i.can_edit_others, i.can_delete, i.can_upload_images, i.revoked_at, i.created_by, i.created_at,
+		       (SELECT COUNT(*) FROM public_editors e WHERE e.invite_id = i.id)
+		FROM public_edit_invites i
+		WHERE i.map_id = ?
+		ORDER BY i.created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var invites []*models.PublicEditInvite
+	for rows.Next() {
+		var invite models.PublicEditInvite
+		if err := rows.Scan(
+			&invite.ID,
+			&invite.MapID,
+			&invite.Code,
+			&invite.Label,
+			&invite.ExpiresAt,
+			&invite.MaxUses,
+			&invite.UseCount,
+			&invite.Permissions.CanCreate,
+			&invite.Permissions.CanEditOthers,
+			&invite.Permissions.CanDelete,
+			&invite.Permissions.CanUploadImages,
+			&invite.RevokedAt,
+			&invite.CreatedBy,
+			&invite.CreatedAt,
+			&invite.EditorCount,
+		); err != nil {
+			return nil, err
+		}
+		invites = append(invites, &invite)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return invites, nil
+}
+
+// Use は招待コードの利用回数を加算する
+// 無効化・期限切れ・利用上限に達している場合はfalseを返す
+func (r *MySQLPublicEditInviteRepository) Use(ctx context.Context, id string, now time.Time) (bool, error) {
+	query := `
+		UPDATE public_edit_invites
+		SET use_count = use_count + 1
+		WHERE id = ? AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > ?)
+		  AND (max_uses IS NULL OR use_count < max_uses)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Revoke は招待コードを無効化し、そのコードで登録した編集者の編集権限を取り消す
+// 取り消した編集者の人数を返す
+func (r *MySQLPublicEditInviteRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE public_edit_invites SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	if _, err := tx.ExecContext(ctx, query, revokedAt, id); err != nil {
+		return 0, err
+	}
+
+	query = `UPDATE public_editors SET revoked_at = ? WHERE invite_id = ? AND revoked_at IS NULL`
+	result, err := tx.ExecContext(ctx, query, revokedAt, id)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return revoked, nil
+}
+
+// scanPublicEditInvite は招待コードを1件読み込む
+func scanPublicEditInvite(row *sql.Row) (*models.PublicEditInvite, error) {
+	var invite models.PublicEditInvite
+	err := row.Scan(
+		&invite.ID,
+		&invite.MapID,
+		&invite.Code,
+		&invite.Label,
+		&invite.ExpiresAt,
+		&invite.MaxUses,
+		&invite.UseCount,
+		&invite.Permissions.CanCreate,
+		&invite.Permissions.CanEditOthers,
+		&invite.Permissions.CanDelete,
+		&invite.Permissions.CanUploadImages,
+		&invite.RevokedAt,
+		&invite.CreatedBy,
+		&invite.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &invite, nil
+}
@@ -0,0 +1,83 @@
+// backend/repositories/public_edit_permissions_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// PublicEditPermissionsRepository は公開編集者の権限へのアクセスを提供するインターフェース
+type PublicEditPermissionsRepository interface {
+	Get(ctx context.Context, mapID string) (*models.PublicEditPermissions, error)
+	Save(ctx context.Context, permissions *models.PublicEditPermissions) error
+}
+
+// MySQLPublicEditPermissionsRepository はMySQLデータベースを使用したPublicEditPermissionsRepositoryの実装
+type MySQLPublicEditPermissionsRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLPublicEditPermissionsRepository は新しいMySQLPublicEditPermissionsRepositoryを作成する
+func NewMySQLPublicEditPermissionsRepository(db *sql.DB) PublicEditPermissionsRepository {
+	return &MySQLPublicEditPermissionsRepository{db: db}
+}
+
+// Get はマップの公開編集者の権限を取得する
+// 設定がない場合は他の編集者のピンの編集以外を許可し、招待コードなしで登録できる
+func (r *MySQLPublicEditPermissionsRepository) Get(ctx context.Context, mapID string) (*models.PublicEditPermissions, error) {
+	query := `
+		SELECT map_id, can_create, can_edit_others, can_delete, can_upload_images, invite_only
+		FROM public_edit_permissions
+		WHERE map_id = ?
+	`
+
+	permissions := models.PublicEditPermissions{
+		MapID:           mapID,
+		CanCreate:       true,
+		CanEditOthers:   false,
+		CanDelete:       true,
+		CanUploadImages: true,
+	}
+	err := r.db.QueryRowContext(ctx, query, mapID).Scan(
+		&permissions.MapID,
+		&permissions.CanCreate,
+		&permissions.CanEditOthers,
+		&permissions.CanDelete,
+		&permissions.CanUploadImages,
+		&permissions.InviteOnly,
+	)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &permissions, nil
+}
+
+// Save はマップの公開編集者の権限を作成または更新する
+func (r *MySQLPublicEditPermissionsRepository) Save(ctx context.Context, permissions *models.PublicEditPermissions) error {
+	query := `
+		INSERT INTO public_edit_permissions (map_id, can_create, can_edit_others, can_delete, can_upload_images, invite_only, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE can_create = VALUES(can_create), can_edit_others = VALUES(can_edit_others),
+		    can_delete = VALUES(can_delete), can_upload_images = VALUES(can_upload_images),
+		    invite_only = VALUES(invite_only), updated_at = VALUES(updated_at)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		permissions.MapID,
+		permissions.CanCreate,
+		permissions.CanEditOthers,
+		permissions.CanDelete,
+		permissions.CanUploadImages,
+		permissions.InviteOnly,
+		time.Now(),
+	)
+
+	return err
+}
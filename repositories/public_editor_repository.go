@@ -4,6 +4,7 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +19,11 @@ type PublicEditorRepository interface {
 	GetByMapID(ctx context.Context, mapID string) ([]*models.PublicEditor, error)
 	Update(ctx context.Context, editor *models.PublicEditor) error
 	UpdateLastActive(ctx context.Context, id string) error
+	GetSummariesByMapID(ctx context.Context, mapID string) ([]*models.PublicEditorSummary, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	Ban(ctx context.Context, editor *models.PublicEditor, bannedAt time.Time) error
+	Unban(ctx context.Context, editor *models.PublicEditor) error
+	IsBanned(ctx context.Context, mapID string, fingerprints []string) (bool, error)
 }
 
 // MySQLPublicEditorRepository はMySQLデータベースを使用したPublicEditorRepositoryの実装
@@ -39,8 +45,8 @@ func (r *MySQLPublicEditorRepository) Create(ctx context.Context, editor *models
 	editor.LastActive = time.Now()
 
 	query := `
-		INSERT INTO public_editors (id, map_id, nickname, editor_token, created_at, last_active)
-		VALUES (?, ?, ?, ?, ?, ?)
+		INSERT INTO public_editors (id, map_id, nickname, editor_token, network_fingerprint, device_fingerprint, invite_id, created_at, last_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	_, err := r.db.ExecContext(
@@ -50,6 +56,9 @@ func (r *MySQLPublicEditorRepository) Create(ctx context.Context, editor *models
 		editor.MapID,
 		editor.Nickname,
 		editor.EditorToken,
+		editor.NetworkFingerprint,
+		editor.DeviceFingerprint,
+		editor.InviteID,
 		editor.CreatedAt,
 		editor.LastActive,
 	)
@@ -60,7 +69,7 @@ func (r *MySQLPublicEditorRepository) Create(ctx context.Context, editor *models
 // GetByID はIDにより公開編集者を取得する
 func (r *MySQLPublicEditorRepository) GetByID(ctx context.Context, id string) (*models.PublicEditor, error) {
 	query := `
-		SELECT id, map_id, nickname, editor_token, created_at, last_active
+		SELECT id, map_id, nickname, editor_token, network_fingerprint, device_fingerprint, created_at, last_active, banned_at, revoked_at, invite_id
 		FROM public_editors
 		WHERE id = ?
 	`
@@ -71,8 +80,13 @@ func (r *MySQLPublicEditorRepository) GetByID(ctx context.Context, id string) (*
 		&editor.MapID,
 		&editor.Nickname,
 		&editor.EditorToken,
+		&editor.NetworkFingerprint,
+		&editor.DeviceFingerprint,
 		&editor.CreatedAt,
 		&editor.LastActive,
+		&editor.BannedAt,
+		&editor.RevokedAt,
+		&editor.InviteID,
 	)
 
 	if err == sql.ErrNoRows {
@@ -89,7 +103,7 @@ func (r *MySQLPublicEditorRepository) GetByID(ctx context.Context, id string) (*
 // GetByToken はトークンにより公開編集者を取得する
 func (r *MySQLPublicEditorRepository) GetByToken(ctx context.Context, token string) (*models.PublicEditor, error) {
 	query := `
-		SELECT id, map_id, nickname, editor_token, created_at, last_active
+		SELECT id, map_id, nickname, editor_token, network_fingerprint, device_fingerprint, created_at, last_active, banned_at, revoked_at, invite_id
 		FROM public_editors
 		WHERE editor_token = ?
 	`
@@ -100,8 +114,13 @@ func (r *MySQLPublicEditorRepository) GetByToken(ctx context.Context, token stri
 		&editor.MapID,
 		&editor.Nickname,
 		&editor.EditorToken,
+		&editor.NetworkFingerprint,
+		&editor.DeviceFingerprint,
 		&editor.CreatedAt,
 		&editor.LastActive,
+		&editor.BannedAt,
+		&editor.RevokedAt,
+		&editor.InviteID,
 	)
 
 	if err == sql.ErrNoRows {
@@ -118,7 +137,7 @@ func (r *MySQLPublicEditorRepository) GetByToken(ctx context.Context, token stri
 // GetByMapID はマップIDにより公開編集者を取得する
 func (r *MySQLPublicEditorRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.PublicEditor, error) {
 	query := `
-		SELECT id, map_id, nickname, editor_token, created_at, last_active
+		SELECT id, map_id, nickname, editor_token, network_fingerprint, device_fingerprint, created_at, last_active, banned_at, revoked_at, invite_id
 		FROM public_editors
 		WHERE map_id = ?
 		ORDER BY last_active DESC
@@ -138,8 +157,13 @@ func (r *MySQLPublicEditorRepository) GetByMapID(ctx context.Context, mapID stri
 			&editor.MapID,
 			&editor.Nickname,
 			&editor.EditorToken,
+			&editor.NetworkFingerprint,
+			&editor.DeviceFingerprint,
 			&editor.CreatedAt,
 			&editor.LastActive,
+			&editor.BannedAt,
+			&editor.RevokedAt,
+			&editor.InviteID,
 		); err != nil {
 			return nil, err
 		}
@@ -190,3 +214,152 @@ func (r *MySQLPublicEditorRepository) UpdateLastActive(ctx context.Context, id s
 
 	return err
 }
+
+// GetSummariesByMapID はマップの公開編集者を作成したピンの件数・通報件数と共に取得する
+func (r *MySQLPublicEditorRepository) GetSummariesByMapID(ctx context.Context, mapID string) ([]*models.PublicEditorSummary, error) {
+	query := `
+		SELECT e.id, e.map_id, e.nickname, e.created_at, e.last_active, e.banned_at, e.revoked_at, e.invite_id,
+		       COUNT(p.id), MAX(p.created_at),
+		       COALESCE((SELECT f.report_count FROM content_flags f WHERE f.target_type = ? AND f.target_id = e.id), 0)
+		FROM public_editors e
+		LEFT JOIN pins p ON p.editor_id = e.id
+		WHERE e.map_id = ?
+		GROUP BY e.id, e.map_id, e.nickname, e.created_at, e.last_active, e.banned_at, e.revoked_at, e.invite_id
+		ORDER BY e.last_active DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, models.ReportTargetEditor, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*models.PublicEditorSummary
+	for rows.Next() {
+		var editor models.PublicEditor
+		var lastPinAt sql.NullTime
+		summary := &models.PublicEditorSummary{PublicEditor: &editor}
+		if err := rows.Scan(
+			&editor.ID,
+			&editor.MapID,
+			&editor.Nickname,
+			&editor.CreatedAt,
+			&editor.LastActive,
+			&editor.BannedAt,
+			&editor.RevokedAt,
+			&editor.InviteID,
+			&summary.PinCount,
+			&lastPinAt,
+			&summary.ReportCount,
+		); err != nil {
+			return nil, err
+		}
+		if lastPinAt.Valid {
+			summary.LastPinAt = &lastPinAt.Time
+		}
+		summary.Status = editor.Status()
+		summaries = append(summaries, summary)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// Revoke は公開編集者の編集権限を取り消す（再登録は可能）
+func (r *MySQLPublicEditorRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	query := `UPDATE public_editors SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, revokedAt, id)
+	return err
+}
+
+// Ban は公開編集者の編集を禁止し、同じ端末からのマップへの再登録を禁止する
+func (r *MySQLPublicEditorRepository) Ban(ctx context.Context, editor *models.PublicEditor, bannedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE public_editors SET banned_at = ? WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, bannedAt, editor.ID); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT IGNORE INTO public_editor_bans (map_id, fingerprint, editor_id, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	for _, fingerprint := range []string{editor.NetworkFingerprint, editor.DeviceFingerprint} {
+		if fingerprint == "" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, query, editor.MapID, fingerprint, editor.ID, bannedAt); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	editor.BannedAt = &bannedAt
+	return nil
+}
+
+// Unban は公開編集者の編集禁止と端末の再登録禁止を解除する
+func (r *MySQLPublicEditorRepository) Unban(ctx context.Context, editor *models.PublicEditor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE public_editors SET banned_at = NULL WHERE id = ?`
+	if _, err := tx.ExecContext(ctx, query, editor.ID); err != nil {
+		return err
+	}
+
+	query = `DELETE FROM public_editor_bans WHERE editor_id = ?`
+	if _, err := tx.ExecContext(ctx, query, editor.ID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	editor.BannedAt = nil
+	return nil
+}
+
+// IsBanned はいずれかの端末識別子がマップで登録禁止になっているか判定する
+func (r *MySQLPublicEditorRepository) IsBanned(ctx context.Context, mapID string, fingerprints []string) (bool, error) {
+	placeholders := []string{}
+	args := []interface{}{mapID}
+	for _, fingerprint := range fingerprints {
+		if fingerprint == "" {
+			continue
+		}
+		placeholders = append(placeholders, "?")
+		args = append(args, fingerprint)
+	}
+	if len(placeholders) == 0 {
+		return false, nil
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM public_editor_bans
+		WHERE map_id = ? AND fingerprint IN (` + strings.Join(placeholders, ",") + `)
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,77 @@
+// backend/repositories/rate_limit_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// MySQLRateLimitRepository はMySQLデータベースにトークンバケットを保持するRateLimitStoreの実装
+// 複数のサーバーで回数制限を共有する場合に使用する
+type MySQLRateLimitRepository struct {
+	db    *sql.DB
+	mu    sync.Mutex
+	swept time.Time
+}
+
+// NewMySQLRateLimitRepository は新しいMySQLRateLimitRepositoryを作成する
+func NewMySQLRateLimitRepository(db *sql.DB) utils.RateLimitStore {
+	return &MySQLRateLimitRepository{db: db, swept: time.Now()}
+}
+
+// Take はキーのバケットからトークンを1回分消費する
+// 消費できない場合は次に消費できるまでの時間を返す
+func (r *MySQLRateLimitRepository) Take(ctx context.Context, key string, rate utils.Rate) (bool, time.Duration, error) {
+	now := time.Now()
+	r.sweep(ctx, now)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	// バケットがなければ満杯の状態で作成する
+	query := `INSERT IGNORE INTO rate_limit_buckets (bucket_key, tokens, updated_at) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, key, rate.Burst, now); err != nil {
+		return false, 0, err
+	}
+
+	var tokens float64
+	var updatedAt time.Time
+	query = `SELECT tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ? FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, key).Scan(&tokens, &updatedAt); err != nil {
+		return false, 0, err
+	}
+
+	tokens, allowed, retryAfter := rate.Refill(tokens, now.Sub(updatedAt))
+
+	query = `UPDATE rate_limit_buckets SET tokens = ?, updated_at = ? WHERE bucket_key = ?`
+	if _, err := tx.ExecContext(ctx, query, tokens, now, key); err != nil {
+		return false, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, 0, err
+	}
+
+	return allowed, retryAfter, nil
+}
+
+// sweep は長く使われていないバケットを定期的に削除する
+func (r *MySQLRateLimitRepository) sweep(ctx context.Context, now time.Time) {
+	r.mu.Lock()
+	if now.Sub(r.swept) < 10*time.Minute {
+		r.mu.Unlock()
+		return
+	}
+	r.swept = now
+	r.mu.Unlock()
+
+	query := `DELETE FROM rate_limit_buckets WHERE updated_at < ?`
+	r.db.ExecContext(ctx, query, now.Add(-time.Hour))
+}
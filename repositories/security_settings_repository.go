@@ -0,0 +1,53 @@
+// backend/repositories/security_settings_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// SecuritySettingsRepository はサービス全体のセキュリティ設定へのアクセスを提供するインターフェース
+type SecuritySettingsRepository interface {
+	Get(ctx context.Context) (*models.SecuritySettings, error)
+	Save(ctx context.Context, settings *models.SecuritySettings) error
+}
+
+// MySQLSecuritySettingsRepository はMySQLデータベースを使用したSecuritySettingsRepositoryの実装
+type MySQLSecuritySettingsRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLSecuritySettingsRepository は新しいMySQLSecuritySettingsRepositoryを作成する
+func NewMySQLSecuritySettingsRepository(db *sql.DB) SecuritySettingsRepository {
+	return &MySQLSecuritySettingsRepository{db: db}
+}
+
+// Get はセキュリティ設定を取得する
+// 設定がない場合は管理者の二段階認証を必須にしない
+func (r *MySQLSecuritySettingsRepository) Get(ctx context.Context) (*models.SecuritySettings, error) {
+	query := `SELECT require_admin_two_factor FROM security_settings WHERE id = 1`
+
+	var settings models.SecuritySettings
+	err := r.db.QueryRowContext(ctx, query).Scan(&settings.RequireAdminTwoFactor)
+
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// Save はセキュリティ設定を作成または更新する
+func (r *MySQLSecuritySettingsRepository) Save(ctx context.Context, settings *models.SecuritySettings) error {
+	query := `
+		INSERT INTO security_settings (id, require_admin_two_factor, updated_at)
+		VALUES (1, ?, ?)
+		ON DUPLICATE KEY UPDATE require_admin_two_factor = VALUES(require_admin_two_factor), updated_at = VALUES(updated_at)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, settings.RequireAdminTwoFactor, time.Now())
+	return err
+}
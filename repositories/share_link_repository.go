@@ -0,0 +1,181 @@
+// backend/repositories/share_link_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// ShareLinkRepository は共有リンクデータへのアクセスを提供するインターフェース
+type ShareLinkRepository interface {
+	Create(ctx context.Context, link *models.ShareLink) error
+	GetByID(ctx context.Context, id string) (*models.ShareLink, error)
+	GetBySlug(ctx context.Context, slug string) (*models.ShareLink, error)
+	GetByMapID(ctx context.Context, mapID string) ([]*models.ShareLink, error)
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+	Use(ctx context.Context, id string, now time.Time) (bool, error)
+}
+
+// MySQLShareLinkRepository はMySQLデータベースを使用したShareLinkRepositoryの実装
+type MySQLShareLinkRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLShareLinkRepository は新しいMySQLShareLinkRepositoryを作成する
+func NewMySQLShareLinkRepository(db *sql.DB) ShareLinkRepository {
+	return &MySQLShareLinkRepository{db: db}
+}
+
+// Create は新しい共有リンクを作成する
+func (r *MySQLShareLinkRepository) Create(ctx context.Context, link *models.ShareLink) error {
+	if link.ID == "" {
+		link.ID = uuid.New().String()
+	}
+	link.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO share_links (id, map_id, slug, password_hash, expires_at, max_uses, use_count, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		link.ID,
+		link.MapID,
+		link.Slug,
+		link.PasswordHash,
+		link.ExpiresAt,
+		link.MaxUses,
+		link.CreatedBy,
+		link.CreatedAt,
+	)
+
+	return err
+}
+
+// GetByID はIDにより共有リンクを取得する
+func (r *MySQLShareLinkRepository) GetByID(ctx context.Context, id string) (*models.ShareLink, error) {
+	query := `
+		SELECT id, map_id, slug, password_hash, expires_at, max_uses, use_count, revoked_at, created_by, created_at
+		FROM share_links
+		WHERE id = ?
+	`
+	return scanShareLink(r.db.QueryRowContext(ctx, query, id))
+}
+
+// GetBySlug はスラッグにより共有リンクを取得する
+func (r *MySQLShareLinkRepository) GetBySlug(ctx context.Context, slug string) (*models.ShareLink, error) {
+	query := `
+		SELECT id, map_id, slug, password_hash, expires_at, max_uses, use_count, revoked_at, created_by, created_at
+		FROM share_links
+		WHERE slug = ?
+	`
+	return scanShareLink(r.db.QueryRowContext(ctx, query, slug))
+}
+
+// GetByMapID はマップの共有リンク一覧を取得する
+func (r *MySQLShareLinkRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.ShareLink, error) {
+	query := `
+		SELECT id, map_id, slug, password_hash, expires_at, max_uses, use_count, revoked_at, created_by, created_at
+		FROM share_links
+		WHERE map_id = ?
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*models.ShareLink
+	for rows.Next() {
+		var link models.ShareLink
+		if err := rows.Scan(
+			&link.ID,
+			&link.MapID,
+			&link.Slug,
+			&link.PasswordHash,
+			&link.ExpiresAt,
+			&link.MaxUses,
+			&link.UseCount,
+			&link.RevokedAt,
+			&link.CreatedBy,
+			&link.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		link.HasPassword = link.PasswordHash != ""
+		links = append(links, &link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// Revoke は共有リンクを無効化する
+func (r *MySQLShareLinkRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	query := `UPDATE share_links SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, revokedAt, id)
+	return err
+}
+
+// Use は共有リンクの利用回数を加算する
+// 無効化・期限切れ・利用上限に達している場合はfalseを返す
+func (r *MySQLShareLinkRepository) Use(ctx context.Context, id string, now time.Time) (bool, error) {
+	query := `
+		UPDATE share_links
+		SET use_count = use_count + 1
+		WHERE id = ? AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > ?)
+		  AND (max_uses IS NULL OR use_count < max_uses)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, id, now)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// scanShareLink は共有リンクを1件読み込む
+func scanShareLink(row *sql.Row) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := row.Scan(
+		&link.ID,
+		&link.MapID,
+		&link.Slug,
+		&link.PasswordHash,
+		&link.ExpiresAt,
+		&link.MaxUses,
+		&link.UseCount,
+		&link.RevokedAt,
+		&link.CreatedBy,
+		&link.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	link.HasPassword = link.PasswordHash != ""
+	return &link, nil
+}
@@ -0,0 +1,405 @@
+// backend/repositories/stamp_rally_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// StampRallyRepository はスタンプラリーデータへのアクセスを提供するインターフェース
+type StampRallyRepository interface {
+	Create(ctx context.Context, rally *models.StampRally) error
+	GetByID(ctx context.Context, id string) (*models.StampRally, error)
+	GetByMapID(ctx context.Context, mapID string) ([]*models.StampRally, error)
+	Update(ctx context.Context, rally *models.StampRally) error
+	Delete(ctx context.Context, id string) error
+
+	CreateSession(ctx context.Context, session *models.RallySession) error
+	GetSession(ctx context.Context, id string) (*models.RallySession, error)
+	CompleteSession(ctx context.Context, id string, completedAt time.Time) error
+	CreateCheckIn(ctx context.Context, sessionID, pinID string) error
+	GetCheckedInPinIDs(ctx context.Context, sessionID string) ([]string, error)
+	GetStats(ctx context.Context, rallyID string) (*models.RallyStats, error)
+}
+
+// MySQLStampRallyRepository はMySQLデータベースを使用したStampRallyRepositoryの実装
+type MySQLStampRallyRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLStampRallyRepository は新しいMySQLStampRallyRepositoryを作成する
+func NewMySQLStampRallyRepository(db *sql.DB) StampRallyRepository {
+	return &MySQLStampRallyRepository{db: db}
+}
+
+// Create は新しいスタンプラリーとチェックポイントを作成する
+func (r *MySQLStampRallyRepository) Create(ctx context.Context, rally *models.StampRally) error {
+	if rally.ID == "" {
+		rally.ID = uuid.New().String()
+	}
+	now := time.Now()
+	rally.CreatedAt = now
+	rally.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO stamp_rallies (id, map_id, title, description, completion_rule, required_count, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if _, err := tx.ExecContext(
+		ctx,
+		query,
+		rally.ID,
+		rally.MapID,
+		rally.Title,
+		rally.Description,
+		rally.CompletionRule,
+		rally.RequiredCount,
+		rally.IsActive,
+		rally.CreatedAt,
+		rally.UpdatedAt,
+	); err != nil {
+		return err
+	}
+
+	if err := replaceCheckpoints(ctx, tx, rally); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetByID はIDによりスタンプラリーを取得する
+func (r *MySQLStampRallyRepository) GetByID(ctx context.Context, id string) (*models.StampRally, error) {
+	query := `
+		SELECT id, map_id, title, description, completion_rule, required_count, is_active, created_at, updated_at
+		FROM stamp_rallies
+		WHERE id = ?
+	`
+
+	var rally models.StampRally
+	var description sql.NullString
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&rally.ID,
+		&rally.MapID,
+		&rally.Title,
+		&description,
+		&rally.CompletionRule,
+		&rally.RequiredCount,
+		&rally.IsActive,
+		&rally.CreatedAt,
+		&rally.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if description.Valid {
+		rally.Description = description.String
+	}
+
+	if rally.CheckpointPinIDs, err = r.getCheckpoints(ctx, rally.ID); err != nil {
+		return nil, err
+	}
+
+	return &rally, nil
+}
+
+// GetByMapID はマップIDによりスタンプラリー一覧を取得する
+func (r *MySQLStampRallyRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.StampRally, error) {
+	query := `
+		SELECT id, map_id, title, description, completion_rule, required_count, is_active, created_at, updated_at
+		FROM stamp_rallies
+		WHERE map_id = ?
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, mapID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rallies []*models.StampRally
+	for rows.Next() {
+		var rally models.StampRally
+		var description sql.NullString
+
+		if err := rows.Scan(
+			&rally.ID,
+			&rally.MapID,
+			&rally.Title,
+			&description,
+			&rally.CompletionRule,
+			&rally.RequiredCount,
+			&rally.IsActive,
+			&rally.CreatedAt,
+			&rally.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if description.Valid {
+			rally.Description = description.String
+		}
+
+		rallies = append(rallies, &rally)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, rally := range rallies {
+		if rally.CheckpointPinIDs, err = r.getCheckpoints(ctx, rally.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return rallies, nil
+}
+
+// Update はスタンプラリーとチェックポイントを更新する
+func (r *MySQLStampRallyRepository) Update(ctx context.Context, rally *models.StampRally) error {
+	rally.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE stamp_rallies
+		SET title = ?, description = ?, completion_rule = ?, required_count = ?, is_active = ?, updated_at = ?
+		WHERE id = ?
+	`
+
+	if _, err := tx.ExecContext(
+		ctx,
+		query,
+		rally.Title,
+		rally.Description,
+		rally.CompletionRule,
+		rally.RequiredCount,
+		rally.IsActive,
+		rally.UpdatedAt,
+		rally.ID,
+	); err != nil {
+		return err
+	}
+
+	if err := replaceCheckpoints(ctx, tx, rally); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete はスタンプラリーを削除する
+func (r *MySQLStampRallyRepository) Delete(ctx context.Context, id string) error {
+	query := `DELETE FROM stamp_rallies WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// CreateSession は来場者セッションを作成する
+func (r *MySQLStampRallyRepository) CreateSession(ctx context.Context, session *models.RallySession) error {
+	if session.ID == "" {
+		session.ID = uuid.New().String()
+	}
+	session.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO rally_sessions (id, rally_id, token, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, session.ID, session.RallyID, session.Token, session.CreatedAt)
+	return err
+}
+
+// GetSession はIDにより来場者セッションを取得する
+func (r *MySQLStampRallyRepository) GetSession(ctx context.Context, id string) (*models.RallySession, error) {
+	query := `
+		SELECT id, rally_id, token, created_at, completed_at
+		FROM rally_sessions
+		WHERE id = ?
+	`
+
+	var session models.RallySession
+	var completedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&session.ID,
+		&session.RallyID,
+		&session.Token,
+		&session.CreatedAt,
+		&completedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if completedAt.Valid {
+		session.CompletedAt = &completedAt.Time
+	}
+
+	return &session, nil
+}
+
+// CompleteSession はセッションを達成済みにする
+func (r *MySQLStampRallyRepository) CompleteSession(ctx context.Context, id string, completedAt time.Time) error {
+	query := `UPDATE rally_sessions SET completed_at = ? WHERE id = ? AND completed_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, completedAt, id)
+	return err
+}
+
+// CreateCheckIn はチェックインを記録する（同じピンへの再チェックインは無視する）
+func (r *MySQLStampRallyRepository) CreateCheckIn(ctx context.Context, sessionID, pinID string) error {
+	query := `
+		INSERT IGNORE INTO rally_check_ins (session_id, pin_id, created_at)
+		VALUES (?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, sessionID, pinID, time.Now())
+	return err
+}
+
+// GetCheckedInPinIDs はセッションでチェックイン済みのピンIDを取得する
+func (r *MySQLStampRallyRepository) GetCheckedInPinIDs(ctx context.Context, sessionID string) ([]string, error) {
+	query := `
+		SELECT pin_id
+		FROM rally_check_ins
+		WHERE session_id = ?
+		ORDER BY created_at ASC
+	`
+
+	return queryStrings(ctx, r.db, query, sessionID)
+}
+
+// GetStats はスタンプラリーの達成状況を集計する
+func (r *MySQLStampRallyRepository) GetStats(ctx context.Context, rallyID string) (*models.RallyStats, error) {
+	stats := &models.RallyStats{
+		RallyID:  rallyID,
+		CheckIns: make(map[string]int),
+	}
+
+	query := `
+		SELECT COUNT(*), COUNT(completed_at)
+		FROM rally_sessions
+		WHERE rally_id = ?
+	`
+	if err := r.db.QueryRowContext(ctx, query, rallyID).Scan(&stats.Sessions, &stats.Completed); err != nil {
+		return nil, err
+	}
+
+	if stats.Sessions > 0 {
+		stats.CompletionRate = float64(stats.Completed) / float64(stats.Sessions)
+	}
+
+	query = `
+		SELECT c.pin_id, COUNT(*)
+		FROM rally_check_ins c
+		JOIN rally_sessions s ON s.id = c.session_id
+		WHERE s.rally_id = ?
+		GROUP BY c.pin_id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, rallyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pinID string
+		var count int
+		if err := rows.Scan(&pinID, &count); err != nil {
+			return nil, err
+		}
+		stats.CheckIns[pinID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// getCheckpoints はスタンプラリーのチェックポイントのピンIDを順番に取得する
+func (r *MySQLStampRallyRepository) getCheckpoints(ctx context.Context, rallyID string) ([]string, error) {
+	query := `
+		SELECT pin_id
+		FROM stamp_rally_checkpoints
+		WHERE rally_id = ?
+		ORDER BY position ASC
+	`
+
+	return queryStrings(ctx, r.db, query, rallyID)
+}
+
+// replaceCheckpoints はトランザクション内でチェックポイントを置き換える
+func replaceCheckpoints(ctx context.Context, tx *sql.Tx, rally *models.StampRally) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM stamp_rally_checkpoints WHERE rally_id = ?`, rally.ID); err != nil {
+		return err
+	}
+
+	for i, pinID := range rally.CheckpointPinIDs {
+		query := `
+			INSERT INTO stamp_rally_checkpoints (rally_id, pin_id, position)
+			VALUES (?, ?, ?)
+		`
+		if _, err := tx.ExecContext(ctx, query, rally.ID, pinID, i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// queryStrings は1列の文字列を返すクエリを実行する
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
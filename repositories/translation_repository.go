@@ -0,0 +1,116 @@
+// backend/repositories/translation_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// TranslationRepository は多言語コンテンツへのアクセスを提供するインターフェース
+type TranslationRepository interface {
+	Save(ctx context.Context, translations []*models.Translation) error
+	GetByMapID(ctx context.Context, mapID, locale string) ([]*models.Translation, error)
+	DeleteByLocale(ctx context.Context, mapID, locale string) error
+}
+
+// MySQLTranslationRepository はMySQLデータベースを使用したTranslationRepositoryの実装
+type MySQLTranslationRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLTranslationRepository は新しいMySQLTranslationRepositoryを作成する
+func NewMySQLTranslationRepository(db *sql.DB) TranslationRepository {
+	return &MySQLTranslationRepository{db: db}
+}
+
+// Save は翻訳を作成または更新する
+// 値が空の翻訳は削除する
+func (r *MySQLTranslationRepository) Save(ctx context.Context, translations []*models.Translation) error {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, t := range translations {
+		if t.Value == "" {
+			query := `DELETE FROM translations WHERE entity_type = ? AND entity_id = ? AND locale = ? AND field = ?`
+			if _, err := tx.ExecContext(ctx, query, t.EntityType, t.EntityID, t.Locale, t.Field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		t.UpdatedAt = now
+		query := `
+			INSERT INTO translations (map_id, entity_type, entity_id, locale, field, value, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE value = VALUES(value), updated_at = VALUES(updated_at)
+		`
+		if _, err := tx.ExecContext(ctx, query, t.MapID, t.EntityType, t.EntityID, t.Locale, t.Field, t.Value, t.UpdatedAt); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByMapID はマップの翻訳を取得する
+// localeが空の場合は全言語の翻訳を取得する
+func (r *MySQLTranslationRepository) GetByMapID(ctx context.Context, mapID, locale string) ([]*models.Translation, error) {
+	query := `
+		SELECT map_id, entity_type, entity_id, locale, field, value, updated_at
+		FROM translations
+		WHERE map_id = ?
+	`
+	args := []interface{}{mapID}
+	if locale != "" {
+		query += ` AND locale = ?`
+		args = append(args, locale)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var translations []*models.Translation
+	for rows.Next() {
+		var t models.Translation
+		if err := rows.Scan(
+			&t.MapID,
+			&t.EntityType,
+			&t.EntityID,
+			&t.Locale,
+			&t.Field,
+			&t.Value,
+			&t.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		translations = append(translations, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+// DeleteByLocale はマップの指定言語の翻訳をすべて削除する
+func (r *MySQLTranslationRepository) DeleteByLocale(ctx context.Context, mapID, locale string) error {
+	query := `DELETE FROM translations WHERE map_id = ? AND locale = ?`
+	_, err := r.db.ExecContext(ctx, query, mapID, locale)
+	return err
+}
@@ -0,0 +1,198 @@
+// backend/repositories/two_factor_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// TwoFactorRepository は二段階認証の設定と回復コードへのアクセスを提供するインターフェース
+type TwoFactorRepository interface {
+	Get(ctx context.Context, userID string) (*models.UserTwoFactor, error)
+	SavePending(ctx context.Context, userID, secret string) error
+	Enable(ctx context.Context, userID string, step int64, enabledAt time.Time, codeHashes []string) error
+	Delete(ctx context.Context, userID string) error
+	UseStep(ctx context.Context, userID string, step int64) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+}
+
+// MySQLTwoFactorRepository はMySQLデータベースを使用したTwoFactorRepositoryの実装
+type MySQLTwoFactorRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLTwoFactorRepository は新しいMySQLTwoFactorRepositoryを作成する
+func NewMySQLTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &MySQLTwoFactorRepository{db: db}
+}
+
+// Get はユーザーの二段階認証の設定を取得する
+func (r *MySQLTwoFactorRepository) Get(ctx context.Context, userID string) (*models.UserTwoFactor, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at
+		FROM user_two_factor
+		WHERE user_id = ?
+	`
+
+	var twoFactor models.UserTwoFactor
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(
+		&twoFactor.UserID,
+		&twoFactor.Secret,
+		&twoFactor.EnabledAt,
+		&twoFactor.LastUsedStep,
+		&twoFactor.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &twoFactor, nil
+}
+
+// SavePending は確認前の秘密鍵を保存する
+// 既に設定途中の秘密鍵がある場合は置き換える
+func (r *MySQLTwoFactorRepository) SavePending(ctx context.Context, userID, secret string) error {
+	query := `
+		INSERT INTO user_two_factor (user_id, secret, enabled_at, last_used_step, created_at)
+		VALUES (?, ?, NULL, 0, ?)
+		ON DUPLICATE KEY UPDATE secret = VALUES(secret), enabled_at = NULL, last_used_step = 0, created_at = VALUES(created_at)
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, secret, time.Now())
+	return err
+}
+
+// Enable は二段階認証を有効にし、回復コードを登録する
+func (r *MySQLTwoFactorRepository) Enable(ctx context.Context, userID string, step int64, enabledAt time.Time, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE user_two_factor SET enabled_at = ?, last_used_step = ? WHERE user_id = ?`
+	if _, err := tx.ExecContext(ctx, query, enabledAt, step, userID); err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Delete は二段階認証の設定と回復コードを削除する
+func (r *MySQLTwoFactorRepository) Delete(ctx context.Context, userID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_two_factor WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseStep は使用した確認コードの間隔番号を記録する
+// 同じか以前の間隔番号が既に使われている場合はfalseを返す
+func (r *MySQLTwoFactorRepository) UseStep(ctx context.Context, userID string, step int64) (bool, error) {
+	query := `UPDATE user_two_factor SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+
+	result, err := r.db.ExecContext(ctx, query, step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// ReplaceRecoveryCodes はユーザーの回復コードをすべて置き換える
+func (r *MySQLTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UseRecoveryCode は未使用の回復コードを使用済みにする
+// 一致する未使用のコードがない場合はfalseを返す
+func (r *MySQLTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID, codeHash string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = ?
+		WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, usedAt, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CountRecoveryCodes は未使用の回復コードの件数を取得する
+func (r *MySQLTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = ? AND used_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// replaceRecoveryCodes はトランザクション内で回復コードを置き換える
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID string, codeHashes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO user_recovery_codes (id, user_id, code_hash, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	now := time.Now()
+	for _, codeHash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query, uuid.New().String(), userID, codeHash, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
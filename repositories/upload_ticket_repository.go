@@ -0,0 +1,102 @@
+// backend/repositories/upload_ticket_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// UploadTicketRepository はアップロードチケットデータへのアクセスを提供するインターフェース
+type UploadTicketRepository interface {
+	Create(ctx context.Context, ticket *models.UploadTicket) error
+	GetByPublicID(ctx context.Context, publicID string) (*models.UploadTicket, error)
+	UpdateStatus(ctx context.Context, id, status string) error
+	DeleteExpired(ctx context.Context, before time.Time) error
+}
+
+// MySQLUploadTicketRepository はMySQLデータベースを使用したUploadTicketRepositoryの実装
+type MySQLUploadTicketRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLUploadTicketRepository は新しいMySQLUploadTicketRepositoryを作成する
+func NewMySQLUploadTicketRepository(db *sql.DB) UploadTicketRepository {
+	return &MySQLUploadTicketRepository{db: db}
+}
+
+// Create は新しいアップロードチケットを作成する
+func (r *MySQLUploadTicketRepository) Create(ctx context.Context, ticket *models.UploadTicket) error {
+	if ticket.ID == "" {
+		ticket.ID = uuid.New().String()
+	}
+	ticket.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO upload_tickets (id, user_id, target_type, target_id, public_id, status, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		ticket.ID,
+		ticket.UserID,
+		ticket.TargetType,
+		ticket.TargetID,
+		ticket.PublicID,
+		ticket.Status,
+		ticket.ExpiresAt,
+		ticket.CreatedAt,
+	)
+
+	return err
+}
+
+// GetByPublicID はCloudinaryの公開IDによりアップロードチケットを取得する
+func (r *MySQLUploadTicketRepository) GetByPublicID(ctx context.Context, publicID string) (*models.UploadTicket, error) {
+	query := `
+		SELECT id, user_id, target_type, target_id, public_id, status, expires_at, created_at
+		FROM upload_tickets
+		WHERE public_id = ?
+	`
+
+	var ticket models.UploadTicket
+	err := r.db.QueryRowContext(ctx, query, publicID).Scan(
+		&ticket.ID,
+		&ticket.UserID,
+		&ticket.TargetType,
+		&ticket.TargetID,
+		&ticket.PublicID,
+		&ticket.Status,
+		&ticket.ExpiresAt,
+		&ticket.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ticket, nil
+}
+
+// UpdateStatus はアップロードチケットの状態を更新する
+func (r *MySQLUploadTicketRepository) UpdateStatus(ctx context.Context, id, status string) error {
+	query := `UPDATE upload_tickets SET status = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, status, id)
+	return err
+}
+
+// DeleteExpired は有効期限切れの未完了チケットを削除する
+func (r *MySQLUploadTicketRepository) DeleteExpired(ctx context.Context, before time.Time) error {
+	query := `DELETE FROM upload_tickets WHERE status = ? AND expires_at < ?`
+	_, err := r.db.ExecContext(ctx, query, models.UploadStatusPending, before)
+	return err
+}
@@ -0,0 +1,151 @@
+// backend/repositories/user_identity_repository.go
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+)
+
+// UserIdentityRepository は外部アカウントとの連携へのアクセスを提供するインターフェース
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.UserIdentity, error)
+	UpdateLastLogin(ctx context.Context, id string, t time.Time) error
+	Delete(ctx context.Context, userID, provider string) (bool, error)
+}
+
+// MySQLUserIdentityRepository はMySQLデータベースを使用したUserIdentityRepositoryの実装
+type MySQLUserIdentityRepository struct {
+	db *sql.DB
+}
+
+// NewMySQLUserIdentityRepository は新しいMySQLUserIdentityRepositoryを作成する
+func NewMySQLUserIdentityRepository(db *sql.DB) UserIdentityRepository {
+	return &MySQLUserIdentityRepository{db: db}
+}
+
+// Create は外部アカウントとの連携を作成する
+func (r *MySQLUserIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	if identity.ID == "" {
+		identity.ID = uuid.New().String()
+	}
+	identity.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, created_at, last_login_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		identity.ID,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+		identity.CreatedAt,
+		identity.LastLoginAt,
+	)
+
+	return err
+}
+
+// GetBySubject はプロバイダーとプロバイダー側の識別子により連携を取得する
+func (r *MySQLUserIdentityRepository) GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE provider = ? AND subject = ?
+	`
+
+	var identity models.UserIdentity
+	err := r.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+		&identity.LastLoginAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// GetByUserID はユーザーの連携の一覧を取得する
+func (r *MySQLUserIdentityRepository) GetByUserID(ctx context.Context, userID string) ([]*models.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE user_id = ?
+		ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*models.UserIdentity
+	for rows.Next() {
+		var identity models.UserIdentity
+		if err := rows.Scan(
+			&identity.ID,
+			&identity.UserID,
+			&identity.Provider,
+			&identity.Subject,
+			&identity.Email,
+			&identity.CreatedAt,
+			&identity.LastLoginAt,
+		); err != nil {
+			return nil, err
+		}
+		identities = append(identities, &identity)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// UpdateLastLogin は連携したアカウントで最後にログインした日時を更新する
+func (r *MySQLUserIdentityRepository) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
+	query := `UPDATE user_identities SET last_login_at = ? WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, t, id)
+	return err
+}
+
+// Delete はユーザーとプロバイダーの連携を削除する
+// 連携がない場合はfalseを返す
+func (r *MySQLUserIdentityRepository) Delete(ctx context.Context, userID, provider string) (bool, error) {
+	query := `DELETE FROM user_identities WHERE user_id = ? AND provider = ?`
+
+	result, err := r.db.ExecContext(ctx, query, userID, provider)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/shimaf4979/pamfree-backend/config"
 	"github.com/shimaf4979/pamfree-backend/controllers"
 	"github.com/shimaf4979/pamfree-backend/middlewares"
+	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/repositories"
 	"github.com/shimaf4979/pamfree-backend/services"
+	"github.com/shimaf4979/pamfree-backend/utils"
 )
 
 // SetupRoutes はアプリケーションのルートを設定する
@@ -31,24 +33,90 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 	floorRepo := repositories.NewMySQLFloorRepository(db)
 	pinRepo := repositories.NewMySQLPinRepository(db)
 	publicEditorRepo := repositories.NewMySQLPublicEditorRepository(db)
+	publicEditPermissionsRepo := repositories.NewMySQLPublicEditPermissionsRepository(db)
+	publicEditInviteRepo := repositories.NewMySQLPublicEditInviteRepository(db)
+	uploadTicketRepo := repositories.NewMySQLUploadTicketRepository(db)
+	floorGeoRepo := repositories.NewMySQLFloorGeoreferenceRepository(db)
+	pdfJobRepo := repositories.NewMySQLPDFJobRepository(db)
+	stampRallyRepo := repositories.NewMySQLStampRallyRepository(db)
+	analyticsRepo := repositories.NewMySQLAnalyticsRepository(db)
+	mapSnapshotRepo := repositories.NewMySQLMapSnapshotRepository(db)
+	shareLinkRepo := repositories.NewMySQLShareLinkRepository(db)
+	translationRepo := repositories.NewMySQLTranslationRepository(db)
+	pinCommentRepo := repositories.NewMySQLPinCommentRepository(db)
+	pinReactionRepo := repositories.NewMySQLPinReactionRepository(db)
+	interactionSettingsRepo := repositories.NewMySQLMapInteractionSettingsRepository(db)
+	contentReportRepo := repositories.NewMySQLContentReportRepository(db)
+	contentFilterRepo := repositories.NewMySQLContentFilterRepository(db)
+	auditLogRepo := repositories.NewMySQLAuditLogRepository(db)
+	twoFactorRepo := repositories.NewMySQLTwoFactorRepository(db)
+	securitySettingsRepo := repositories.NewMySQLSecuritySettingsRepository(db)
+	userIdentityRepo := repositories.NewMySQLUserIdentityRepository(db)
+	oidcStateRepo := repositories.NewMySQLOIDCStateRepository(db)
+	accessTokenRepo := repositories.NewMySQLPersonalAccessTokenRepository(db)
+	organizationRepo := repositories.NewMySQLOrganizationRepository(db)
 
 	// サービスの初期化
 	authService := services.NewAuthService(userRepo)
-	mapService := services.NewMapService(mapRepo)
+	mapService := services.NewMapService(mapRepo, userRepo)
 	floorService := services.NewFloorService(floorRepo, mapRepo)
-	pinService := services.NewPinService(pinRepo, floorRepo, mapRepo)
-	publicEditorService := services.NewPublicEditorService(publicEditorRepo, mapRepo)
+	pinService := services.NewPinService(pinRepo, floorRepo, mapRepo, publicEditorRepo, publicEditPermissionsRepo, publicEditInviteRepo, contentFilterRepo, contentReportRepo)
+	publicEditorService := services.NewPublicEditorService(publicEditorRepo, mapRepo, publicEditPermissionsRepo, publicEditInviteRepo, contentFilterRepo, contentReportRepo)
+	uploadService := services.NewUploadService(uploadTicketRepo, floorRepo, pinRepo, mapRepo)
+	georeferenceService := services.NewGeoreferenceService(floorGeoRepo, floorRepo, mapRepo)
+	geoJSONService := services.NewGeoJSONService(pinRepo, floorRepo, mapRepo, floorGeoRepo)
+	viewerService := services.NewViewerService(mapRepo, floorRepo, pinRepo, mapSnapshotRepo, contentReportRepo)
+	pinSpreadsheetService := services.NewPinSpreadsheetService(pinRepo, floorRepo, mapRepo)
+	pdfService := services.NewPDFService(pdfJobRepo, mapRepo, floorRepo, pinRepo, cfg.CloudinaryName, cfg.PDFFontPath, cfg.ExportDir, time.Duration(cfg.PDFRetention)*time.Hour)
+	qrService := services.NewQRService(mapRepo, floorRepo, pinRepo, cfg.ViewerBaseURL)
+	stampRallyService := services.NewStampRallyService(stampRallyRepo, mapRepo, floorRepo, pinRepo, cfg.RallySecret, cfg.ViewerBaseURL)
+	snapshotService := services.NewSnapshotService(mapSnapshotRepo, mapRepo, floorRepo, pinRepo)
+	shareLinkService := services.NewShareLinkService(shareLinkRepo, mapRepo, cfg.ViewerBaseURL)
+	translationService := services.NewTranslationService(translationRepo, mapRepo, floorRepo, pinRepo, cfg.Locales)
+	interactionService := services.NewInteractionService(pinCommentRepo, pinReactionRepo, interactionSettingsRepo, mapRepo, floorRepo, pinRepo, userRepo, cfg.VisitorSecret, cfg.CommentLimit, cfg.ReactionLimit)
+	reportService := services.NewReportService(contentReportRepo, mapRepo, floorRepo, pinRepo, publicEditorRepo, pinCommentRepo, cfg.ReportThreshold)
+	contentFilterService := services.NewContentFilterService(contentFilterRepo, mapRepo)
+	auditService := services.NewAuditService(auditLogRepo)
+	twoFactorService := services.NewTwoFactorService(twoFactorRepo, securitySettingsRepo, userRepo, cfg.TOTPIssuer)
+	oidcService := services.NewOIDCService(userIdentityRepo, oidcStateRepo, userRepo, oidcProviders(cfg), cfg.OIDCRedirectURL)
+	accessTokenService := services.NewPersonalAccessTokenService(accessTokenRepo, userRepo)
+	organizationService := services.NewOrganizationService(organizationRepo, userRepo)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, mapRepo, floorRepo, pinRepo, time.Duration(cfg.AnalyticsFlush)*time.Second)
+
+	// 公開予約スケジューラーの起動
+	services.NewPublicationScheduler(mapRepo, time.Duration(cfg.PublishInterval)*time.Second)
 
 	// コントローラーの初期化
-	authController := controllers.NewAuthController(authService, cfg.JWTSecret)
-	mapController := controllers.NewMapController(mapService)
-	floorController := controllers.NewFloorController(floorService)
-	pinController := controllers.NewPinController(pinService)
+	authController := controllers.NewAuthController(authService, auditService, twoFactorService, cfg.JWTSecret,
+		utils.NewLoginLockout(cfg.LoginMaxFailures, time.Duration(cfg.LoginLockout)*time.Second),
+		utils.NewLoginLockout(cfg.AccountFailures, time.Duration(cfg.LoginLockout)*time.Second),
+	)
+	mapController := controllers.NewMapController(mapService, auditService)
+	floorController := controllers.NewFloorController(floorService, viewerService)
+	pinController := controllers.NewPinController(pinService, viewerService)
 	publicEditorController := controllers.NewPublicEditorController(publicEditorService, mapService)
-	viewerController := controllers.NewViewerController(mapService, floorService, pinService)
+	viewerController := controllers.NewViewerController(mapService, floorService, pinService, georeferenceService, analyticsService, snapshotService, shareLinkService, translationService, interactionService, reportService)
+	georeferenceController := controllers.NewGeoreferenceController(georeferenceService, viewerService)
+	geoJSONController := controllers.NewGeoJSONController(geoJSONService, floorService, viewerService)
+	pinSpreadsheetController := controllers.NewPinSpreadsheetController(pinSpreadsheetService)
+	pdfController := controllers.NewPDFController(pdfService)
+	qrController := controllers.NewQRController(qrService, cfg.PDFFontPath)
+	stampRallyController := controllers.NewStampRallyController(stampRallyService)
+	analyticsController := controllers.NewAnalyticsController(analyticsService)
+	snapshotController := controllers.NewSnapshotController(snapshotService)
+	shareLinkController := controllers.NewShareLinkController(shareLinkService)
+	translationController := controllers.NewTranslationController(translationService)
+	interactionController := controllers.NewInteractionController(interactionService)
+	reportController := controllers.NewReportController(reportService, interactionService)
+	contentFilterController := controllers.NewContentFilterController(contentFilterService)
+	auditLogController := controllers.NewAuditLogController(auditService)
+	twoFactorController := controllers.NewTwoFactorController(twoFactorService, authService, auditService, cfg.JWTSecret)
+	oidcController := controllers.NewOIDCController(oidcService, twoFactorService, auditService, cfg.JWTSecret)
+	accessTokenController := controllers.NewPersonalAccessTokenController(accessTokenService, auditService)
+	organizationController := controllers.NewOrganizationController(organizationService, auditService)
 
 	// Cloudinaryコントローラー
-	cloudinaryController, err := controllers.NewCloudinaryController(cfg)
+	cloudinaryController, err := controllers.NewCloudinaryController(cfg, uploadService, auditService)
 	if err != nil {
 		log.Fatalf("Cloudinaryコントローラーの初期化に失敗しました: %v", err)
 	}
@@ -65,8 +133,27 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 	router.Use(cors.New(corsConfig))
 
 	// 認証ミドルウェア
-	authMiddleware := middlewares.AuthMiddleware(cfg.JWTSecret)
+	authMiddleware := middlewares.AuthMiddleware(cfg.JWTSecret, accessTokenService.Authenticate, accessTokenScopes)
+	optionalAuthMiddleware := middlewares.OptionalAuthMiddleware(cfg.JWTSecret)
 	adminMiddleware := middlewares.AdminMiddleware()
+	adminTwoFactorMiddleware := middlewares.AdminTwoFactorMiddleware(twoFactorService.AdminTwoFactorRequired)
+	publicEditorMiddleware := middlewares.PublicEditorMiddleware(publicEditorService.Authenticate)
+
+	// 回数制限ミドルウェア（mysqlの場合は複数のサーバーで回数を共有する）
+	var rateLimitStore utils.RateLimitStore = utils.NewMemoryRateLimitStore()
+	if cfg.RateLimitBackend == "mysql" {
+		rateLimitStore = repositories.NewMySQLRateLimitRepository(db)
+	}
+	loginLimit := middlewares.RateLimitMiddleware(rateLimitStore, "login", utils.Rate{Burst: 10, Every: 6 * time.Second}, middlewares.RateLimitByIP)
+	registerLimit := middlewares.RateLimitMiddleware(rateLimitStore, "register", utils.Rate{Burst: 5, Every: 12 * time.Minute}, middlewares.RateLimitByIP)
+	editorRegisterLimit := middlewares.RateLimitMiddleware(rateLimitStore, "public-editor", utils.Rate{Burst: 5, Every: time.Minute}, middlewares.RateLimitByIP)
+	twoFactorLimit := middlewares.RateLimitMiddleware(rateLimitStore, "two-factor", utils.Rate{Burst: 10, Every: 6 * time.Second}, middlewares.RateLimitByIP)
+	publicPinIPLimit := middlewares.RateLimitMiddleware(rateLimitStore, "public-pin-ip", utils.Rate{Burst: 60, Every: time.Second}, middlewares.RateLimitByIP)
+	publicPinLimit := middlewares.RateLimitMiddleware(rateLimitStore, "public-pin", utils.Rate{Burst: 20, Every: 3 * time.Second}, middlewares.RateLimitByEditor)
+	analyticsIPLimit := middlewares.RateLimitMiddleware(rateLimitStore, "analytics-ip", utils.Rate{Burst: 20, Every: 3 * time.Second}, middlewares.RateLimitByIP)
+	analyticsMapLimit := middlewares.RateLimitMiddleware(rateLimitStore, "analytics-map", utils.Rate{Burst: 600, Every: 50 * time.Millisecond}, middlewares.RateLimitByParam("mapId"))
+	shareIPLimit := middlewares.RateLimitMiddleware(rateLimitStore, "share-ip", utils.Rate{Burst: 10, Every: 6 * time.Second}, middlewares.RateLimitByIP)
+	sharePasswordLimit := middlewares.RateLimitMiddleware(rateLimitStore, "share-password", utils.Rate{Burst: 20, Every: 6 * time.Second}, middlewares.RateLimitByParam("slug"))
 
 	// ヘルスチェック
 	router.GET("/health", func(c *gin.Context) {
@@ -80,8 +167,14 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 	// 認証ルート
 	auth := router.Group("/api/auth")
 	{
-		auth.POST("/register", authController.Register)
-		auth.POST("/login", authController.Login)
+		auth.POST("/register", registerLimit, authController.Register)
+		auth.POST("/login", loginLimit, authController.Login)
+		auth.POST("/login/2fa", twoFactorLimit, authController.LoginTwoFactor)
+
+		// OpenID Connectによる外部アカウントでのログイン
+		auth.GET("/oidc/providers", oidcController.GetProviders)
+		auth.POST("/oidc/:provider/start", loginLimit, oidcController.StartLogin)
+		auth.POST("/oidc/callback", loginLimit, oidcController.Callback)
 		auth.GET("/me", authMiddleware, authController.GetMe)
 	}
 
@@ -90,57 +183,227 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 	{
 		maps.GET("", authMiddleware, mapController.GetMaps)
 		maps.POST("", authMiddleware, mapController.CreateMap)
+		maps.GET("/slug-availability", authMiddleware, mapController.CheckSlugAvailability)
 		maps.GET("/:mapId", authMiddleware, mapController.GetMapByID)
 		maps.PATCH("/:mapId", authMiddleware, mapController.UpdateMap)
 		maps.DELETE("/:mapId", authMiddleware, mapController.DeleteMap)
+		maps.PUT("/:mapId/publication", authMiddleware, mapController.UpdatePublication)
+		maps.PUT("/:mapId/slug", authMiddleware, mapController.UpdateSlug)
+		maps.POST("/:mapId/transfer", authMiddleware, mapController.TransferMap)
+
+		// 公開スナップショット
+		maps.GET("/:mapId/snapshots", authMiddleware, snapshotController.GetSnapshots)
+		maps.POST("/:mapId/snapshots", authMiddleware, snapshotController.PublishSnapshot)
+		maps.DELETE("/:mapId/snapshots/published", authMiddleware, snapshotController.UnpublishSnapshot)
+
+		// 共有リンク
+		maps.GET("/:mapId/share-links", authMiddleware, shareLinkController.GetShareLinks)
+		maps.POST("/:mapId/share-links", authMiddleware, shareLinkController.CreateShareLink)
+
+		// 多言語コンテンツ
+		maps.GET("/:mapId/translations/report", authMiddleware, translationController.GetReport)
+		maps.GET("/:mapId/translations/:locale", authMiddleware, translationController.GetTranslation)
+		maps.PUT("/:mapId/translations/:locale", authMiddleware, translationController.UpdateTranslation)
+		maps.DELETE("/:mapId/translations/:locale", authMiddleware, translationController.DeleteTranslation)
+
+		// コメント・リアクションの管理
+		maps.GET("/:mapId/comments", authMiddleware, interactionController.GetMapComments)
+		maps.GET("/:mapId/interaction-settings", authMiddleware, interactionController.GetSettings)
+		maps.PUT("/:mapId/interaction-settings", authMiddleware, interactionController.UpdateSettings)
+
+		// 通報の受信箱
+		maps.GET("/:mapId/reports", authMiddleware, reportController.GetMapReports)
+
+		// 公開編集者の管理と権限
+		maps.GET("/:mapId/public-editors", authMiddleware, publicEditorController.GetEditors)
+		maps.POST("/:mapId/public-editors/:editorId/revoke", authMiddleware, publicEditorController.RevokeEditor)
+		maps.POST("/:mapId/public-editors/:editorId/ban", authMiddleware, publicEditorController.BanEditor)
+		maps.DELETE("/:mapId/public-editors/:editorId/ban", authMiddleware, publicEditorController.UnbanEditor)
+		maps.GET("/:mapId/public-edit-permissions", authMiddleware, publicEditorController.GetPermissions)
+		maps.PUT("/:mapId/public-edit-permissions", authMiddleware, publicEditorController.UpdatePermissions)
+		maps.GET("/:mapId/public-edit-invites", authMiddleware, publicEditorController.GetInvites)
+		maps.POST("/:mapId/public-edit-invites", authMiddleware, publicEditorController.CreateInvite)
+
+		// 公開編集の内容フィルター
+		maps.GET("/:mapId/content-filter", authMiddleware, contentFilterController.GetSettings)
+		maps.PUT("/:mapId/content-filter", authMiddleware, contentFilterController.UpdateSettings)
+		maps.GET("/:mapId/content-filter/words", authMiddleware, contentFilterController.GetMapWords)
+		maps.POST("/:mapId/content-filter/words", authMiddleware, contentFilterController.AddMapWord)
+		maps.DELETE("/:mapId/content-filter/words/:wordId", authMiddleware, contentFilterController.DeleteMapWord)
 
 		// フロアルート (マップIDによる)
-		maps.GET("/:mapId/floors", floorController.GetFloors)
+		maps.GET("/:mapId/floors", optionalAuthMiddleware, floorController.GetFloors)
 		maps.POST("/:mapId/floors", authMiddleware, floorController.CreateFloor)
+
+		// GeoJSON入出力
+		maps.GET("/:mapId/geojson", optionalAuthMiddleware, geoJSONController.ExportMap)
+		maps.POST("/:mapId/geojson", authMiddleware, geoJSONController.ImportMap)
+
+		// ピンのCSV/XLSX入出力
+		maps.GET("/:mapId/pins/export", authMiddleware, pinSpreadsheetController.ExportPins)
+		maps.POST("/:mapId/pins/import", authMiddleware, pinSpreadsheetController.ImportPins)
+
+		// 印刷用PDF
+		maps.GET("/:mapId/pdf", authMiddleware, pdfController.GenerateMapPDF)
+
+		// 全ピンのQRコード一括生成
+		maps.GET("/:mapId/pins/qr", authMiddleware, qrController.GetMapPinQRBatch)
+
+		// スタンプラリー
+		maps.GET("/:mapId/rallies", authMiddleware, stampRallyController.GetRallies)
+		maps.POST("/:mapId/rallies", authMiddleware, stampRallyController.CreateRally)
+
+		// 利用状況
+		maps.GET("/:mapId/analytics", authMiddleware, analyticsController.GetDashboard)
+	}
+
+	// 組織ルート
+	organizations := router.Group("/api/organizations", authMiddleware)
+	{
+		organizations.GET("", organizationController.GetOrganizations)
+		organizations.POST("", organizationController.CreateOrganization)
+		organizations.GET("/:orgId", organizationController.GetOrganization)
+		organizations.PATCH("/:orgId", organizationController.UpdateOrganization)
+		organizations.DELETE("/:orgId", organizationController.DeleteOrganization)
+
+		// メンバー管理
+		organizations.POST("/:orgId/members", organizationController.AddMember)
+		organizations.PATCH("/:orgId/members/:userId", organizationController.UpdateMember)
+		organizations.DELETE("/:orgId/members/:userId", organizationController.RemoveMember)
 	}
 
 	// フロアルート
 	floors := router.Group("/api/floors")
 	{
-		floors.GET("/:floorId", floorController.GetFloorByID)
+		floors.GET("/:floorId", optionalAuthMiddleware, floorController.GetFloorByID)
 		floors.PATCH("/:floorId", authMiddleware, floorController.UpdateFloor)
 		floors.DELETE("/:floorId", authMiddleware, floorController.DeleteFloor)
 
 		// ピンルート (フロアIDによる)
-		floors.GET("/:floorId/pins", pinController.GetPinsByFloorID)
+		floors.GET("/:floorId/pins", optionalAuthMiddleware, pinController.GetPinsByFloorID)
 		floors.POST("/:floorId/pins", authMiddleware, pinController.CreatePin)
 
 		// フロア画像アップロード
 		floors.POST("/:floorId/image", authMiddleware, floorController.UpdateFloorImage)
+
+		// 地理参照（画像座標と緯度経度の対応付け）
+		floors.GET("/:floorId/georeference", optionalAuthMiddleware, georeferenceController.GetGeoreference)
+		floors.PUT("/:floorId/georeference", authMiddleware, georeferenceController.UpdateGeoreference)
+		floors.DELETE("/:floorId/georeference", authMiddleware, georeferenceController.DeleteGeoreference)
+		floors.POST("/:floorId/locate", optionalAuthMiddleware, georeferenceController.Locate)
+
+		// GeoJSON入出力
+		floors.GET("/:floorId/geojson", optionalAuthMiddleware, geoJSONController.ExportFloor)
+		floors.POST("/:floorId/geojson", authMiddleware, geoJSONController.ImportFloor)
 	}
 
 	// ピンルート
 	pins := router.Group("/api/pins")
 	{
-		pins.GET("/:pinId", pinController.GetPinByID)
+		pins.GET("/:pinId", optionalAuthMiddleware, pinController.GetPinByID)
 		pins.PATCH("/:pinId", authMiddleware, pinController.UpdatePin)
 		pins.DELETE("/:pinId", authMiddleware, pinController.DeletePin)
 
 		// ピン画像アップロード
 		pins.POST("/:pinId/image", authMiddleware, pinController.UpdatePinImage)
+
+		// 来場者のコメント・リアクション
+		pins.GET("/:pinId/comments", optionalAuthMiddleware, interactionController.GetComments)
+		pins.POST("/:pinId/comments", optionalAuthMiddleware, interactionController.CreateComment)
+		pins.GET("/:pinId/reactions", optionalAuthMiddleware, interactionController.GetReactions)
+		pins.POST("/:pinId/reactions", optionalAuthMiddleware, interactionController.AddReaction)
+		pins.DELETE("/:pinId/reactions/:emoji", optionalAuthMiddleware, interactionController.RemoveReaction)
+	}
+
+	// コメントルート
+	comments := router.Group("/api/comments")
+	{
+		comments.PATCH("/:commentId", authMiddleware, interactionController.ModerateComment)
+		comments.DELETE("/:commentId", optionalAuthMiddleware, interactionController.DeleteComment)
+	}
+
+	// 通報ルート
+	reports := router.Group("/api/reports")
+	{
+		reports.POST("", optionalAuthMiddleware, reportController.CreateReport)
+		reports.POST("/:reportId/actions", authMiddleware, reportController.ResolveReport)
+	}
+
+	// 匿名の来場者トークンの発行
+	router.POST("/api/visitors", interactionController.IssueVisitor)
+
+	// PDF生成ジョブルート
+	pdfJobs := router.Group("/api/pdf-jobs", authMiddleware)
+	{
+		pdfJobs.GET("/:jobId", pdfController.GetJob)
+		pdfJobs.GET("/:jobId/download", pdfController.Download)
+	}
+
+	// QRコードルート
+	qr := router.Group("/api/qr")
+	{
+		qr.GET("/maps/:mapId", optionalAuthMiddleware, qrController.GetMapQR)
+		qr.GET("/floors/:floorId", optionalAuthMiddleware, qrController.GetFloorQR)
+		qr.GET("/pins/:pinId", optionalAuthMiddleware, qrController.GetPinQR)
+	}
+
+	// 共有リンクルート
+	shareLinks := router.Group("/api/share-links", authMiddleware)
+	{
+		shareLinks.DELETE("/:linkId", shareLinkController.RevokeShareLink)
+	}
+
+	// スナップショットルート
+	snapshots := router.Group("/api/snapshots", authMiddleware)
+	{
+		snapshots.GET("/:snapshotId", snapshotController.GetSnapshot)
+		snapshots.GET("/:snapshotId/diff", snapshotController.GetDiff)
+		snapshots.POST("/:snapshotId/rollback", snapshotController.Rollback)
+	}
+
+	// スタンプラリールート
+	rallies := router.Group("/api/rallies")
+	{
+		rallies.GET("/:rallyId", optionalAuthMiddleware, stampRallyController.GetRally)
+		rallies.PATCH("/:rallyId", authMiddleware, stampRallyController.UpdateRally)
+		rallies.DELETE("/:rallyId", authMiddleware, stampRallyController.DeleteRally)
+		rallies.GET("/:rallyId/checkpoints", authMiddleware, stampRallyController.GetCheckpoints)
+		rallies.GET("/:rallyId/stats", authMiddleware, stampRallyController.GetStats)
+
+		// 来場者の匿名セッションとチェックイン
+		rallies.POST("/:rallyId/sessions", optionalAuthMiddleware, stampRallyController.StartSession)
+		rallies.GET("/:rallyId/sessions/:sessionId", stampRallyController.GetProgress)
+		rallies.POST("/:rallyId/check-in", optionalAuthMiddleware, stampRallyController.CheckIn)
+	}
+
+	// 公開編集の招待コードルート
+	publicEditInvites := router.Group("/api/public-edit-invites", authMiddleware)
+	{
+		publicEditInvites.DELETE("/:inviteId", publicEditorController.RevokeInvite)
 	}
 
 	// 公開編集ルート
 	publicEdit := router.Group("/api/public-edit")
 	{
-		publicEdit.POST("/register", publicEditorController.Register)
+		publicEdit.POST("/register", editorRegisterLimit, publicEditorController.Register)
 		publicEdit.POST("/verify", publicEditorController.Verify)
 
-		// 公開編集用のピン操作
-		publicEdit.POST("/pins", pinController.CreatePublicPin)
-		publicEdit.PATCH("/pins/:pinId", pinController.UpdatePublicPin)
-		publicEdit.DELETE("/pins/:pinId", pinController.DeletePublicPin)
+		// 公開編集用のピン操作（編集者のトークンを検証し、編集者ごと・IPアドレスごとに制限する）
+		publicEdit.POST("/pins", publicPinIPLimit, publicEditorMiddleware, publicPinLimit, pinController.CreatePublicPin)
+		publicEdit.PATCH("/pins/:pinId", publicPinIPLimit, publicEditorMiddleware, publicPinLimit, pinController.UpdatePublicPin)
+		publicEdit.DELETE("/pins/:pinId", publicPinIPLimit, publicEditorMiddleware, publicPinLimit, pinController.DeletePublicPin)
 	}
 
 	// ビューワールート
 	viewer := router.Group("/api/viewer")
 	{
-		viewer.GET("/:mapId", viewerController.GetMapData)
+		viewer.GET("/:mapId", optionalAuthMiddleware, viewerController.GetMapData)
+		viewer.POST("/:mapId/events", analyticsIPLimit, analyticsMapLimit, analyticsController.IngestEvents)
+		viewer.GET("/by-slug/:slug", optionalAuthMiddleware, viewerController.GetMapDataBySlug)
+
+		// 共有リンクによる未公開マップの閲覧（パスワードの総当たりを防ぐため、IPアドレスごと・共有リンクごとに制限する）
+		viewer.GET("/share/:slug", shareIPLimit, viewerController.GetSharedMapData)
+		viewer.POST("/share/:slug", shareIPLimit, sharePasswordLimit, viewerController.GetSharedMapData)
 	}
 
 	// Cloudinaryルート
@@ -148,22 +411,95 @@ func SetupRoutes(router *gin.Engine, cfg *config.Config) {
 	{
 		cloudinary.POST("/upload", cloudinaryController.UploadImage)
 		cloudinary.POST("/delete", cloudinaryController.DeleteImage)
+
+		// 署名付き直接アップロード
+		cloudinary.POST("/sign", cloudinaryController.SignUpload)
+		cloudinary.POST("/complete", cloudinaryController.CompleteUpload)
 	}
 
 	// アカウント管理ルート
 	account := router.Group("/api/account", authMiddleware)
 	{
 		account.PATCH("/update-profile", authController.UpdateProfile)
-		account.POST("/change-password", authController.ChangePassword)
+		account.POST("/change-password", twoFactorLimit, authController.ChangePassword)
+
+		// TOTPによる二段階認証
+		account.GET("/2fa", twoFactorController.GetStatus)
+		account.POST("/2fa/setup", twoFactorController.Setup)
+		account.POST("/2fa/enable", twoFactorLimit, twoFactorController.Enable)
+		account.POST("/2fa/disable", twoFactorLimit, twoFactorController.Disable)
+		account.POST("/2fa/recovery-codes", twoFactorLimit, twoFactorController.RegenerateRecoveryCodes)
+
+		// 外部アカウントの連携
+		account.GET("/identities", oidcController.GetIdentities)
+		account.POST("/identities/:provider/link", oidcController.StartLink)
+		account.DELETE("/identities/:provider", oidcController.Unlink)
+
+		// 個人用アクセストークン
+		account.GET("/tokens", accessTokenController.GetTokens)
+		account.POST("/tokens", accessTokenController.CreateToken)
+		account.DELETE("/tokens/:tokenId", accessTokenController.RevokeToken)
 	}
 
 	// 管理者ルート
-	admin := router.Group("/api/admin", authMiddleware, adminMiddleware)
+	admin := router.Group("/api/admin", authMiddleware, adminMiddleware, adminTwoFactorMiddleware)
 	{
 		admin.GET("/users", authController.GetAllUsers)
 		admin.PATCH("/users/:userId", authController.UpdateUser)
 		admin.DELETE("/users/:userId", authController.DeleteUser)
+
+		// 全マップの通報の確認と対応
+		admin.GET("/reports", reportController.GetAllReports)
+		admin.POST("/reports/:reportId/actions", reportController.AdminResolveReport)
+
+		// すべてのマップに適用する禁止語
+		admin.GET("/content-filter/words", contentFilterController.GetGlobalWords)
+		admin.POST("/content-filter/words", contentFilterController.AddGlobalWord)
+		admin.DELETE("/content-filter/words/:wordId", contentFilterController.DeleteGlobalWord)
+
+		// 監査ログの検索とCSV出力
+		admin.GET("/audit-logs", auditLogController.GetLogs)
+		admin.GET("/audit-logs/export", auditLogController.ExportLogs)
+
+		// 管理者に二段階認証を必須にする設定
+		admin.GET("/security-settings", twoFactorController.GetSecuritySettings)
+		admin.PUT("/security-settings", twoFactorController.UpdateSecuritySettings)
+	}
+}
+
+// accessTokenScopes は個人用アクセストークンで呼び出せるAPIと必要な権限
+// ここにないAPI（アカウント・管理者向けのAPIなど）はアクセストークンでは呼び出せない
+var accessTokenScopes = middlewares.AccessTokenScopes{
+	"GET /api/auth/me": "",
+
+	// マップの閲覧
+	"GET /api/maps":                    models.ScopeMapsRead,
+	"GET /api/maps/:mapId":             models.ScopeMapsRead,
+	"GET /api/maps/:mapId/pins/export": models.ScopeMapsRead,
+
+	// ピンの編集
+	"POST /api/floors/:floorId/pins":    models.ScopePinsWrite,
+	"PATCH /api/pins/:pinId":            models.ScopePinsWrite,
+	"DELETE /api/pins/:pinId":           models.ScopePinsWrite,
+	"POST /api/pins/:pinId/image":       models.ScopePinsWrite,
+	"POST /api/maps/:mapId/pins/import": models.ScopePinsWrite,
+
+	// フロアの管理
+	"POST /api/maps/:mapId/floors":             models.ScopeFloorsManage,
+	"PATCH /api/floors/:floorId":               models.ScopeFloorsManage,
+	"DELETE /api/floors/:floorId":              models.ScopeFloorsManage,
+	"POST /api/floors/:floorId/image":          models.ScopeFloorsManage,
+	"PUT /api/floors/:floorId/georeference":    models.ScopeFloorsManage,
+	"DELETE /api/floors/:floorId/georeference": models.ScopeFloorsManage,
+}
+
+// oidcProviders は設定からOpenID Connectのプロバイダーを作成する
+func oidcProviders(cfg *config.Config) []*utils.OIDCProvider {
+	providers := make([]*utils.OIDCProvider, len(cfg.OIDCProviders))
+	for i, provider := range cfg.OIDCProviders {
+		providers[i] = utils.NewOIDCProvider(provider.ID, provider.Name, provider.Issuer, provider.ClientID, provider.ClientSecret, provider.Scopes)
 	}
+	return providers
 }
 
 // setupDatabase はデータベース接続を設定する
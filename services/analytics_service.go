@@ -0,0 +1,392 @@
+// backend/services/analytics_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"golang.org/x/text/width"
+)
+
+// 利用状況集計に関する上限値
+const (
+	analyticsMaxBufferKeys   = 1000
+	analyticsMaxQueryRunes   = 100
+	analyticsMaxEventAge     = 24 * time.Hour
+	analyticsMaxClockSkew    = 5 * time.Minute
+	analyticsTopLimit        = 10
+	analyticsMinSearchCount  = 3 // 個人の検索語が特定されないよう、少数の検索語は表示しない
+	analyticsMaxHourlyRange  = 31 * 24 * time.Hour
+	analyticsMaxDailyRange   = 366 * 24 * time.Hour
+	analyticsDefaultDuration = 7 * 24 * time.Hour
+)
+
+// AnalyticsService はビューワーの利用状況の記録と集計を提供するインターフェース
+type AnalyticsService interface {
+	RecordMapView(mapID string)
+	Ingest(ctx context.Context, mapID string, batch *models.AnalyticsBatch) (int, error)
+	GetDashboard(ctx context.Context, userID, mapID string, from, to time.Time, granularity string) (*models.AnalyticsDashboard, error)
+}
+
+// analyticsKey は集計バッファのキー
+type analyticsKey struct {
+	mapID      string
+	bucketHour time.Time
+	eventType  string
+	dimension  string
+}
+
+// DefaultAnalyticsService はAnalyticsServiceの実装
+// イベントはメモリ上で1時間単位に集計し、一定間隔でまとめてデータベースへ書き込む
+type DefaultAnalyticsService struct {
+	analyticsRepo repositories.AnalyticsRepository
+	mapRepo       repositories.MapRepository
+	floorRepo     repositories.FloorRepository
+	pinRepo       repositories.PinRepository
+
+	mu     sync.Mutex
+	buffer map[analyticsKey]int
+	flush  chan struct{}
+}
+
+// NewAnalyticsService は新しいAnalyticsServiceを作成し、定期書き込みを開始する
+func NewAnalyticsService(
+	analyticsRepo repositories.AnalyticsRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	flushInterval time.Duration,
+) AnalyticsService {
+	s := &DefaultAnalyticsService{
+		analyticsRepo: analyticsRepo,
+		mapRepo:       mapRepo,
+		floorRepo:     floorRepo,
+		pinRepo:       pinRepo,
+		buffer:        make(map[analyticsKey]int),
+		flush:         make(chan struct{}, 1),
+	}
+
+	go s.flusher(flushInterval)
+
+	return s
+}
+
+// RecordMapView はマップの表示を記録する
+func (s *DefaultAnalyticsService) RecordMapView(mapID string) {
+	s.add(analyticsKey{
+		mapID:      mapID,
+		bucketHour: time.Now().Truncate(time.Hour),
+		eventType:  models.AnalyticsEventMapView,
+	})
+}
+
+// Ingest はビューワーから送信されたイベントを検証して記録する
+// 公開中でないマップはErrMapNotFoundを返す
+// マップに属さないピン・フロアを指すイベントは破棄し、受け付けた件数を返す
+func (s *DefaultAnalyticsService) Ingest(ctx context.Context, mapID string, batch *models.AnalyticsBatch) (int, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return 0, err
+	}
+	if map_ == nil || !isPublished(map_, time.Now()) {
+		return 0, ErrMapNotFound
+	}
+
+	// イベントが指すピン・フロアのうちマップに属するものだけをまとめて確認する
+	var floorIDs, pinIDs []string
+	seen := make(map[string]bool)
+	for _, event := range batch.Events {
+		switch {
+		case event.Type == models.AnalyticsEventPinOpen && event.PinID != "" && !seen["pin:"+event.PinID]:
+			seen["pin:"+event.PinID] = true
+			pinIDs = append(pinIDs, event.PinID)
+		case event.Type == models.AnalyticsEventFloorSwitch && event.FloorID != "" && !seen["floor:"+event.FloorID]:
+			seen["floor:"+event.FloorID] = true
+			floorIDs = append(floorIDs, event.FloorID)
+		}
+	}
+
+	floorSet, pinSet, err := s.analyticsRepo.FindMapTargets(ctx, mapID, floorIDs, pinIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	accepted := 0
+	for _, event := range batch.Events {
+		key := analyticsKey{
+			mapID:      mapID,
+			bucketHour: eventTime(event.OccurredAt, now).Truncate(time.Hour),
+			eventType:  event.Type,
+		}
+
+		switch event.Type {
+		case models.AnalyticsEventPinOpen:
+			if !pinSet[event.PinID] {
+				continue
+			}
+			key.dimension = event.PinID
+		case models.AnalyticsEventFloorSwitch:
+			if !floorSet[event.FloorID] {
+				continue
+			}
+			key.dimension = event.FloorID
+		case models.AnalyticsEventSearch:
+			query := normalizeSearchQuery(event.Query)
+			if query == "" {
+				continue
+			}
+			key.dimension = query
+		default:
+			continue
+		}
+
+		s.add(key)
+		accepted++
+	}
+
+	return accepted, nil
+}
+
+// GetDashboard はマップ所有者向けの利用状況を取得する
+func (s *DefaultAnalyticsService) GetDashboard(ctx context.Context, userID, mapID string, from, to time.Time, granularity string) (*models.AnalyticsDashboard, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップにアクセスする権限がありません")
+	}
+
+	if granularity == "" {
+		granularity = models.AnalyticsGranularityDay
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-analyticsDefaultDuration)
+	}
+	from = from.Truncate(time.Hour)
+	if !from.Before(to) {
+		return nil, errors.New("集計期間が不正です")
+	}
+
+	switch granularity {
+	case models.AnalyticsGranularityHour:
+		if to.Sub(from) > analyticsMaxHourlyRange {
+			return nil, errors.New("1時間単位の集計期間は31日以内で指定してください")
+		}
+	case models.AnalyticsGranularityDay:
+		if to.Sub(from) > analyticsMaxDailyRange {
+			return nil, errors.New("集計期間は366日以内で指定してください")
+		}
+	default:
+		return nil, errors.New("無効な集計単位です")
+	}
+
+	// 直近のイベントも集計に含める
+	s.flushBuffer(ctx)
+
+	dashboard := &models.AnalyticsDashboard{
+		MapID:       mapID,
+		From:        from,
+		To:          to,
+		Granularity: granularity,
+		Views:       []*models.AnalyticsPoint{},
+		TopPins:     []*models.AnalyticsTopPin{},
+		TopFloors:   []*models.AnalyticsTopFloor{},
+		TopSearches: []*models.AnalyticsTopSearch{},
+	}
+
+	dashboard.Totals, err = s.analyticsRepo.GetTotals(ctx, mapID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := s.analyticsRepo.GetSeries(ctx, mapID, models.AnalyticsEventMapView, from, to)
+	if err != nil {
+		return nil, err
+	}
+	dashboard.Views = bucketSeries(series, from, to, granularity)
+
+	topPins, err := s.analyticsRepo.GetTopDimensions(ctx, mapID, models.AnalyticsEventPinOpen, from, to, 1, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, top := range topPins {
+		pin, err := s.pinRepo.GetByID(ctx, top.Dimension)
+		if err != nil {
+			return nil, err
+		}
+		// 削除済みのピンは表示しない
+		if pin == nil {
+			continue
+		}
+		dashboard.TopPins = append(dashboard.TopPins, &models.AnalyticsTopPin{
+			PinID: pin.ID,
+			Title: pin.Title,
+			Count: top.Count,
+		})
+	}
+
+	topFloors, err := s.analyticsRepo.GetTopDimensions(ctx, mapID, models.AnalyticsEventFloorSwitch, from, to, 1, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, top := range topFloors {
+		floor, err := s.floorRepo.GetByID(ctx, top.Dimension)
+		if err != nil {
+			return nil, err
+		}
+		if floor == nil {
+			continue
+		}
+		dashboard.TopFloors = append(dashboard.TopFloors, &models.AnalyticsTopFloor{
+			FloorID: floor.ID,
+			Name:    floor.Name,
+			Count:   top.Count,
+		})
+	}
+
+	topSearches, err := s.analyticsRepo.GetTopDimensions(ctx, mapID, models.AnalyticsEventSearch, from, to, analyticsMinSearchCount, analyticsTopLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, top := range topSearches {
+		dashboard.TopSearches = append(dashboard.TopSearches, &models.AnalyticsTopSearch{
+			Query: top.Dimension,
+			Count: top.Count,
+		})
+	}
+
+	return dashboard, nil
+}
+
+// add は集計バッファの件数を加算する
+func (s *DefaultAnalyticsService) add(key analyticsKey) {
+	s.mu.Lock()
+	s.buffer[key]++
+	full := len(s.buffer) >= analyticsMaxBufferKeys
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// flusher は一定間隔、またはバッファが溜まった時点で集計値を書き込む
+func (s *DefaultAnalyticsService) flusher(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.flush:
+		}
+		s.flushBuffer(context.Background())
+	}
+}
+
+// flushBuffer はバッファの集計値をデータベースへ書き込む
+func (s *DefaultAnalyticsService) flushBuffer(ctx context.Context) {
+	s.mu.Lock()
+	buffer := s.buffer
+	s.buffer = make(map[analyticsKey]int)
+	s.mu.Unlock()
+
+	if len(buffer) == 0 {
+		return
+	}
+
+	rollups := make([]*models.AnalyticsRollup, 0, len(buffer))
+	for key, count := range buffer {
+		rollups = append(rollups, &models.AnalyticsRollup{
+			MapID:      key.mapID,
+			BucketHour: key.bucketHour,
+			EventType:  key.eventType,
+			Dimension:  key.dimension,
+			Count:      count,
+		})
+	}
+
+	if err := s.analyticsRepo.IncrementRollups(ctx, rollups); err != nil {
+		// 集計値は失われても致命的ではないため、ログのみ出力する
+		log.Printf("利用状況の書き込みに失敗しました: %v", err)
+	}
+}
+
+// eventTime はイベントの発生時刻を返す
+// 未指定または極端にずれた時刻はサーバーの受信時刻とする
+func eventTime(occurredAt *time.Time, now time.Time) time.Time {
+	if occurredAt == nil {
+		return now
+	}
+	if occurredAt.Before(now.Add(-analyticsMaxEventAge)) || occurredAt.After(now.Add(analyticsMaxClockSkew)) {
+		return now
+	}
+	return occurredAt.Local()
+}
+
+// normalizeSearchQuery は検索語を集計用に正規化する
+// 全角英数を半角にし、小文字化・空白の統一を行う
+func normalizeSearchQuery(query string) string {
+	query = strings.ToLower(width.Fold.String(query))
+	query = strings.Join(strings.Fields(query), " ")
+
+	if utf8.RuneCountInString(query) > analyticsMaxQueryRunes {
+		query = string([]rune(query)[:analyticsMaxQueryRunes])
+	}
+
+	return query
+}
+
+// bucketSeries は1時間ごとの件数を指定された粒度の連続した時系列に変換する
+func bucketSeries(series []*models.AnalyticsPoint, from, to time.Time, granularity string) []*models.AnalyticsPoint {
+	truncate := func(t time.Time) time.Time {
+		t = t.Local()
+		if granularity == models.AnalyticsGranularityDay {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		}
+		return t.Truncate(time.Hour)
+	}
+	next := func(t time.Time) time.Time {
+		if granularity == models.AnalyticsGranularityDay {
+			return t.AddDate(0, 0, 1)
+		}
+		return t.Add(time.Hour)
+	}
+
+	counts := make(map[int64]int)
+	for _, point := range series {
+		counts[truncate(point.Bucket).Unix()] += point.Count
+	}
+
+	points := []*models.AnalyticsPoint{}
+	for bucket := truncate(from); bucket.Before(to); bucket = next(bucket) {
+		points = append(points, &models.AnalyticsPoint{
+			Bucket: bucket,
+			Count:  counts[bucket.Unix()],
+		})
+	}
+
+	return points
+}
@@ -0,0 +1,134 @@
+// backend/services/audit_service.go
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+const (
+	defaultAuditLogPageSize = 50
+	maxAuditLogPageSize     = 200
+	// maxAuditLogExportRows はCSVで一度に出力する監査ログの上限
+	maxAuditLogExportRows = 10000
+	// maxAuditUserAgentLength は記録するユーザーエージェントの最大文字数
+	maxAuditUserAgentLength = 255
+)
+
+// AuditService は監査ログの記録と検索を提供するインターフェース
+type AuditService interface {
+	Record(ctx context.Context, entry *models.AuditLog, before, after interface{}) error
+	Query(ctx context.Context, filter *models.AuditLogFilter) (*models.AuditLogPage, error)
+	Export(ctx context.Context, filter *models.AuditLogFilter) ([][]string, error)
+}
+
+// DefaultAuditService はAuditServiceの実装
+type DefaultAuditService struct {
+	auditLogRepo repositories.AuditLogRepository
+}
+
+// NewAuditService は新しいAuditServiceを作成する
+func NewAuditService(auditLogRepo repositories.AuditLogRepository) AuditService {
+	return &DefaultAuditService{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+// Record は監査ログを記録する
+// before・afterは変更前後の状態で、nilの場合は記録しない
+func (s *DefaultAuditService) Record(ctx context.Context, entry *models.AuditLog, before, after interface{}) error {
+	var err error
+	if entry.Before, err = marshalAuditData(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshalAuditData(after); err != nil {
+		return err
+	}
+
+	if runes := []rune(entry.UserAgent); len(runes) > maxAuditUserAgentLength {
+		entry.UserAgent = string(runes[:maxAuditUserAgentLength])
+	}
+
+	return s.auditLogRepo.Create(ctx, entry)
+}
+
+// Query は条件に一致する監査ログを新しい順に取得する
+func (s *DefaultAuditService) Query(ctx context.Context, filter *models.AuditLogFilter) (*models.AuditLogPage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLogPageSize
+	}
+	if filter.Limit > maxAuditLogPageSize {
+		filter.Limit = maxAuditLogPageSize
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	logs, total, err := s.auditLogRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if logs == nil {
+		logs = []*models.AuditLog{}
+	}
+
+	return &models.AuditLogPage{
+		Logs:   logs,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// Export は条件に一致する監査ログを表形式で取得する
+// 件数が多い場合は新しいものから上限件数までを出力する
+func (s *DefaultAuditService) Export(ctx context.Context, filter *models.AuditLogFilter) ([][]string, error) {
+	filter.Limit = maxAuditLogExportRows
+	filter.Offset = 0
+
+	logs, _, err := s.auditLogRepo.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{models.AuditLogColumns}
+	for _, entry := range logs {
+		row := []string{
+			entry.CreatedAt.Format(time.RFC3339),
+			entry.ActorID,
+			entry.Action,
+			entry.TargetType,
+			entry.TargetID,
+			entry.IP,
+			entry.UserAgent,
+			string(entry.Before),
+			string(entry.After),
+		}
+		// User-Agentなどは利用者が自由に送れるため、数式として実行されないようにする
+		for i, cell := range row {
+			row[i] = utils.EscapeFormula(cell)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// marshalAuditData は変更前後の状態をJSONに変換する
+// nilのマップやポインタを渡した場合も記録しない
+func marshalAuditData(data interface{}) (json.RawMessage, error) {
+	if data == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil || string(b) == "null" {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,336 @@
+// backend/services/content_filter_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// ContentFilterError は公開編集者の入力が内容フィルターにより拒否されたことを表すエラー
+type ContentFilterError struct {
+	models.ContentFilterViolation
+}
+
+// Format はエラーメッセージの書式を返す（引数はArgsで取得する）
+func (e *ContentFilterError) Format() string {
+	switch e.Reason {
+	case models.FilterReasonURL:
+		return "%sにURLを含めることはできません"
+	case models.FilterReasonTooLong:
+		return "%sは%d文字以内で入力してください"
+	default:
+		return "%sに使用できない言葉が含まれています"
+	}
+}
+
+// Args はエラーメッセージの書式に渡す引数を返す
+func (e *ContentFilterError) Args() []interface{} {
+	if e.Reason == models.FilterReasonTooLong {
+		return []interface{}{e.Field, e.Limit}
+	}
+	return []interface{}{e.Field}
+}
+
+// Error はエラーメッセージを返す
+func (e *ContentFilterError) Error() string {
+	return fmt.Sprintf(e.Format(), e.Args()...)
+}
+
+// contentFilter は公開編集者の入力を検査し、必要に応じて確認待ちにする
+type contentFilter struct {
+	filterRepo repositories.ContentFilterRepository
+	reportRepo repositories.ContentReportRepository
+}
+
+// check はマップの設定に従って入力を検査する
+// 拒否する場合はContentFilterErrorを返し、確認待ちにする場合は該当した項目を返す
+// 最大文字数を超えた入力は設定にかかわらず拒否する
+func (f *contentFilter) check(ctx context.Context, mapID string, fields ...models.FilterField) (*models.ContentFilterViolation, error) {
+	settings, err := f.filterRepo.GetSettings(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range fields {
+		limit := 0
+		switch field.Kind {
+		case models.FilterFieldNickname:
+			limit = settings.MaxNicknameLength
+		case models.FilterFieldTitle:
+			limit = settings.MaxTitleLength
+		case models.FilterFieldDescription:
+			limit = settings.MaxDescriptionLength
+		}
+		if limit > 0 && utf8.RuneCountInString(field.Value) > limit {
+			return nil, &ContentFilterError{models.ContentFilterViolation{
+				Field:  field.Name,
+				Reason: models.FilterReasonTooLong,
+				Limit:  limit,
+			}}
+		}
+	}
+
+	var words []*models.ContentFilterWord
+	for _, field := range fields {
+		violation := models.ContentFilterViolation{Field: field.Name}
+		if settings.BlockURLs && utils.ContainsURL(field.Value) {
+			violation.Reason = models.FilterReasonURL
+		} else {
+			if words == nil {
+				if words, err = f.filterRepo.GetApplicableWords(ctx, mapID); err != nil {
+					return nil, err
+				}
+			}
+			normalized := utils.NormalizeText(field.Value)
+			for _, word := range words {
+				if word.Normalized != "" && strings.Contains(normalized, word.Normalized) {
+					violation.Reason = models.FilterReasonBannedWord
+					break
+				}
+			}
+		}
+
+		if violation.Reason == "" {
+			continue
+		}
+		if settings.Action == models.FilterActionModerate {
+			return &violation, nil
+		}
+		return nil, &ContentFilterError{violation}
+	}
+
+	return nil, nil
+}
+
+// moderate は内容フィルターに該当した対象を非表示にし、所有者の通報一覧に載せる
+func (f *contentFilter) moderate(ctx context.Context, mapID, targetType, targetID string, violation *models.ContentFilterViolation) error {
+	report := &models.ContentReport{
+		Reason:       models.ReportReasonContentFilter,
+		Details:      violation.Field + ": " + violation.Reason,
+		ReporterType: models.ReporterTypeSystem,
+		ReporterID:   models.ReportReasonContentFilter,
+	}
+
+	flag, _, err := f.reportRepo.AddReport(ctx, mapID, targetType, targetID, report)
+	if err != nil {
+		return err
+	}
+
+	// 所有者が一度再表示した内容でも、再び該当した場合は非表示に戻す
+	if flag.Status != models.FlagStatusHidden {
+		now := time.Now()
+		flag.Status = models.FlagStatusHidden
+		flag.HiddenAt = &now
+		flag.ResolvedBy = nil
+		flag.ResolvedAt = nil
+		return f.reportRepo.UpdateFlagStatus(ctx, flag)
+	}
+
+	return nil
+}
+
+// ContentFilterService は内容フィルターの設定と禁止語の管理を提供するインターフェース
+type ContentFilterService interface {
+	GetSettings(ctx context.Context, userID, mapID string) (*models.ContentFilterSettings, error)
+	UpdateSettings(ctx context.Context, userID, mapID string, input *models.ContentFilterSettingsUpdate) (*models.ContentFilterSettings, error)
+	GetMapWords(ctx context.Context, userID, mapID string) ([]*models.ContentFilterWord, error)
+	AddMapWord(ctx context.Context, userID, mapID string, input *models.ContentFilterWordCreate) (*models.ContentFilterWord, error)
+	DeleteMapWord(ctx context.Context, userID, mapID, wordID string) error
+	GetGlobalWords(ctx context.Context) ([]*models.ContentFilterWord, error)
+	AddGlobalWord(ctx context.Context, userID string, input *models.ContentFilterWordCreate) (*models.ContentFilterWord, error)
+	DeleteGlobalWord(ctx context.Context, wordID string) error
+}
+
+// DefaultContentFilterService はContentFilterServiceの実装
+type DefaultContentFilterService struct {
+	filterRepo repositories.ContentFilterRepository
+	mapRepo    repositories.MapRepository
+}
+
+// NewContentFilterService は新しいContentFilterServiceを作成する
+func NewContentFilterService(
+	filterRepo repositories.ContentFilterRepository,
+	mapRepo repositories.MapRepository,
+) ContentFilterService {
+	return &DefaultContentFilterService{
+		filterRepo: filterRepo,
+		mapRepo:    mapRepo,
+	}
+}
+
+// GetSettings はマップの内容フィルターの設定を取得する
+func (s *DefaultContentFilterService) GetSettings(ctx context.Context, userID, mapID string) (*models.ContentFilterSettings, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	return s.filterRepo.GetSettings(ctx, mapID)
+}
+
+// UpdateSettings はマップの内容フィルターの設定を更新する
+func (s *DefaultContentFilterService) UpdateSettings(ctx context.Context, userID, mapID string, input *models.ContentFilterSettingsUpdate) (*models.ContentFilterSettings, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.filterRepo.GetSettings(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Action != nil {
+		settings.Action = *input.Action
+	}
+	if input.BlockURLs != nil {
+		settings.BlockURLs = *input.BlockURLs
+	}
+	if input.MaxNicknameLength != nil {
+		settings.MaxNicknameLength = *input.MaxNicknameLength
+	}
+	if input.MaxTitleLength != nil {
+		settings.MaxTitleLength = *input.MaxTitleLength
+	}
+	if input.MaxDescriptionLength != nil {
+		settings.MaxDescriptionLength = *input.MaxDescriptionLength
+	}
+
+	if err := s.filterRepo.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// GetMapWords はマップ固有の禁止語を取得する
+func (s *DefaultContentFilterService) GetMapWords(ctx context.Context, userID, mapID string) ([]*models.ContentFilterWord, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	return s.words(ctx, mapID)
+}
+
+// AddMapWord はマップ固有の禁止語を追加する
+func (s *DefaultContentFilterService) AddMapWord(ctx context.Context, userID, mapID string, input *models.ContentFilterWordCreate) (*models.ContentFilterWord, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	return s.addWord(ctx, userID, mapID, input.Word)
+}
+
+// DeleteMapWord はマップ固有の禁止語を削除する
+func (s *DefaultContentFilterService) DeleteMapWord(ctx context.Context, userID, mapID, wordID string) error {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return err
+	}
+
+	word, err := s.filterRepo.GetWordByID(ctx, wordID)
+	if err != nil {
+		return err
+	}
+	if word == nil || word.MapID == nil || *word.MapID != mapID {
+		return errors.New("禁止語が見つかりません")
+	}
+
+	return s.filterRepo.DeleteWord(ctx, word.ID)
+}
+
+// GetGlobalWords はすべてのマップに適用する禁止語を取得する（管理者用）
+func (s *DefaultContentFilterService) GetGlobalWords(ctx context.Context) ([]*models.ContentFilterWord, error) {
+	return s.words(ctx, "")
+}
+
+// AddGlobalWord はすべてのマップに適用する禁止語を追加する（管理者用）
+func (s *DefaultContentFilterService) AddGlobalWord(ctx context.Context, userID string, input *models.ContentFilterWordCreate) (*models.ContentFilterWord, error) {
+	return s.addWord(ctx, userID, "", input.Word)
+}
+
+// DeleteGlobalWord はすべてのマップに適用する禁止語を削除する（管理者用）
+func (s *DefaultContentFilterService) DeleteGlobalWord(ctx context.Context, wordID string) error {
+	word, err := s.filterRepo.GetWordByID(ctx, wordID)
+	if err != nil {
+		return err
+	}
+	if word == nil || word.MapID != nil {
+		return errors.New("禁止語が見つかりません")
+	}
+
+	return s.filterRepo.DeleteWord(ctx, word.ID)
+}
+
+// words は禁止語の一覧を取得する（mapIDが空の場合は全体の禁止語）
+func (s *DefaultContentFilterService) words(ctx context.Context, mapID string) ([]*models.ContentFilterWord, error) {
+	words, err := s.filterRepo.GetWords(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if words == nil {
+		words = []*models.ContentFilterWord{}
+	}
+
+	return words, nil
+}
+
+// addWord は正規化した禁止語を追加する（mapIDが空の場合は全体の禁止語）
+// 正規化後に同じになる語が既にある場合は追加しない
+func (s *DefaultContentFilterService) addWord(ctx context.Context, userID, mapID, value string) (*models.ContentFilterWord, error) {
+	normalized := utils.NormalizeText(value)
+	if normalized == "" {
+		return nil, errors.New("禁止語には文字を含めてください")
+	}
+
+	existing, err := s.filterRepo.GetWords(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	for _, word := range existing {
+		if word.Normalized == normalized {
+			return nil, errors.New("この禁止語は既に登録されています")
+		}
+	}
+
+	word := &models.ContentFilterWord{
+		ID:         uuid.New().String(),
+		Word:       strings.TrimSpace(value),
+		Normalized: normalized,
+		CreatedBy:  userID,
+	}
+	if mapID != "" {
+		word.MapID = &mapID
+	}
+
+	if err := s.filterRepo.CreateWord(ctx, word); err != nil {
+		return nil, err
+	}
+
+	return word, nil
+}
+
+// checkMapOwner はユーザーがマップの所有者か確認する
+func (s *DefaultContentFilterService) checkMapOwner(ctx context.Context, userID, mapID string) error {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このマップを編集する権限がありません")
+	}
+	return nil
+}
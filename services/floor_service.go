@@ -48,7 +48,11 @@ func (s *FloorServiceImpl) CreateFloor(ctx context.Context, req models.FloorCrea
 		return nil, errors.New("マップが見つかりません")
 	}
 
-	if mapObj.UserID != userID {
+	canEdit, err := canEditMap(ctx, s.mapRepo, mapObj, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
 		return nil, errors.New("このマップを編集する権限がありません")
 	}
 
@@ -110,7 +114,11 @@ func (s *FloorServiceImpl) UpdateFloor(ctx context.Context, id string, req model
 		return nil, errors.New("マップが見つかりません")
 	}
 
-	if mapObj.UserID != userID {
+	canEdit, err := canEditMap(ctx, s.mapRepo, mapObj, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
 		return nil, errors.New("このフロアを編集する権限がありません")
 	}
 
@@ -152,10 +160,12 @@ func (s *FloorServiceImpl) DeleteFloor(ctx context.Context, id string, userID st
 		return errors.New("マップが見つかりません")
 	}
 
-	// 所有者または管理者の場合のみ削除可能
-	if mapObj.UserID != userID {
-		// userのロールを取得（この部分は必要に応じて実装）
-		// ここではシンプルにするため、所有者チェックのみ行う
+	// マップを編集できるユーザー（所有者・組織のメンバー）のみ削除可能
+	canEdit, err := canEditMap(ctx, s.mapRepo, mapObj, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
 		return errors.New("このフロアを削除する権限がありません")
 	}
 
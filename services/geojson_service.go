@@ -0,0 +1,301 @@
+// backend/services/geojson_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// GeoJSONService はピンのGeoJSON入出力を提供するインターフェース
+type GeoJSONService interface {
+	Export(ctx context.Context, floors []*models.Floor, pins []*models.Pin, coordinateSystem string) (*models.GeoJSONFeatureCollection, error)
+	Import(ctx context.Context, userID, mapID, floorID, coordinateSystem string, fc *models.GeoJSONFeatureCollection) (*models.GeoJSONImportResult, error)
+}
+
+// DefaultGeoJSONService はGeoJSONServiceの実装
+type DefaultGeoJSONService struct {
+	pinRepo   repositories.PinRepository
+	floorRepo repositories.FloorRepository
+	mapRepo   repositories.MapRepository
+	geoRepo   repositories.FloorGeoreferenceRepository
+}
+
+// NewGeoJSONService は新しいGeoJSONServiceを作成する
+func NewGeoJSONService(
+	pinRepo repositories.PinRepository,
+	floorRepo repositories.FloorRepository,
+	mapRepo repositories.MapRepository,
+	geoRepo repositories.FloorGeoreferenceRepository,
+) GeoJSONService {
+	return &DefaultGeoJSONService{
+		pinRepo:   pinRepo,
+		floorRepo: floorRepo,
+		mapRepo:   mapRepo,
+		geoRepo:   geoRepo,
+	}
+}
+
+// Import はFeatureCollectionからピンを作成または更新する
+// floorIDが空の場合は各Featureのfloor_idプロパティを使用する
+func (s *DefaultGeoJSONService) Import(ctx context.Context, userID, mapID, floorID, coordinateSystem string, fc *models.GeoJSONFeatureCollection) (*models.GeoJSONImportResult, error) {
+	if fc.Type != "FeatureCollection" {
+		return nil, errors.New("FeatureCollectionが必要です")
+	}
+
+	// マップの所有者を確認
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+
+	floors, err := s.floorRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	floorMap := make(map[string]*models.Floor)
+	for _, floor := range floors {
+		floorMap[floor.ID] = floor
+	}
+	if floorID != "" && floorMap[floorID] == nil {
+		return nil, errors.New("フロアが見つかりません")
+	}
+
+	// 座標系の決定（指定がなければGeoJSON標準のWGS84とみなす）
+	if coordinateSystem == "" {
+		coordinateSystem = fc.CoordinateSystem
+	}
+	if coordinateSystem == "" {
+		coordinateSystem = models.CoordinateSystemGeo
+	}
+	if coordinateSystem != models.CoordinateSystemGeo && coordinateSystem != models.CoordinateSystemPixel {
+		return nil, errors.New("無効な座標系です")
+	}
+
+	inverses, err := s.loadTransforms(ctx, floors, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.GeoJSONImportResult{Errors: []models.GeoJSONImportError{}}
+	for i, feature := range fc.Features {
+		created, err := s.importFeature(ctx, userID, floorID, coordinateSystem, floorMap, inverses, feature)
+		if err != nil {
+			result.Errors = append(result.Errors, models.GeoJSONImportError{Index: i, Error: err.Error()})
+			continue
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+	}
+
+	return result, nil
+}
+
+// importFeature は1件のFeatureをピンとして保存し、新規作成であればtrueを返す
+func (s *DefaultGeoJSONService) importFeature(
+	ctx context.Context,
+	userID, floorID, coordinateSystem string,
+	floorMap map[string]*models.Floor,
+	inverses map[string]utils.AffineTransform,
+	feature *models.GeoJSONFeature,
+) (bool, error) {
+	if feature == nil || feature.Geometry == nil || feature.Geometry.Type != "Point" || len(feature.Geometry.Coordinates) < 2 {
+		return false, errors.New("Pointジオメトリが必要です")
+	}
+
+	props := feature.Properties
+	title := propString(props, "title")
+	if title == "" {
+		return false, errors.New("titleプロパティが必要です")
+	}
+
+	// 対象フロアを決定
+	targetFloorID := floorID
+	if targetFloorID == "" {
+		targetFloorID = propString(props, "floor_id")
+	}
+	if floorMap[targetFloorID] == nil {
+		return false, errors.New("フロアが見つかりません")
+	}
+
+	// 座標をフロア画像座標に変換
+	x, y := feature.Geometry.Coordinates[0], feature.Geometry.Coordinates[1]
+	if coordinateSystem == models.CoordinateSystemGeo {
+		inverse, ok := inverses[targetFloorID]
+		if !ok {
+			return false, errors.New("このフロアは位置情報が設定されていません")
+		}
+		x, y = inverse.Apply(x, y)
+	}
+
+	// 同じマップ内の既存ピンであれば更新
+	pinID := feature.ID
+	if pinID == "" {
+		pinID = propString(props, "id")
+	}
+	if pinID != "" {
+		existing, err := s.pinRepo.GetByID(ctx, pinID)
+		if err != nil {
+			return false, err
+		}
+		if existing != nil && floorMap[existing.FloorID] != nil {
+			existing.FloorID = targetFloorID
+			existing.Title = title
+			existing.Description = propString(props, "description")
+			existing.Category = propString(props, "category")
+			existing.ImageURL = propString(props, "image")
+			existing.XPosition = x
+			existing.YPosition = y
+			return false, s.pinRepo.Update(ctx, existing)
+		}
+	}
+
+	pin := &models.Pin{
+		ID:             uuid.New().String(),
+		FloorID:        targetFloorID,
+		Title:          title,
+		Description:    propString(props, "description"),
+		Category:       propString(props, "category"),
+		XPosition:      x,
+		YPosition:      y,
+		ImageURL:       propString(props, "image"),
+		EditorID:       userID,
+		EditorNickname: "管理者",
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	return true, s.pinRepo.Create(ctx, pin)
+}
+
+// Export はフロア群のピンをFeatureCollectionに変換する
+// 閲覧者に返すフロアとピンは呼び出し元で選ぶ（フロアに含まれないピンは出力しない）
+func (s *DefaultGeoJSONService) Export(ctx context.Context, floors []*models.Floor, pins []*models.Pin, coordinateSystem string) (*models.GeoJSONFeatureCollection, error) {
+	transforms, err := s.loadTransforms(ctx, floors, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 座標系の決定（指定がなければ全フロアが地理参照済みの場合のみWGS84）
+	switch coordinateSystem {
+	case "":
+		coordinateSystem = models.CoordinateSystemGeo
+		if len(floors) == 0 || len(transforms) < len(floors) {
+			coordinateSystem = models.CoordinateSystemPixel
+		}
+	case models.CoordinateSystemGeo:
+		if len(transforms) < len(floors) {
+			return nil, errors.New("位置情報が設定されていないフロアがあります")
+		}
+	case models.CoordinateSystemPixel:
+	default:
+		return nil, errors.New("無効な座標系です")
+	}
+
+	floorNames := make(map[string]string)
+	for _, floor := range floors {
+		floorNames[floor.ID] = floor.Name
+	}
+
+	fc := &models.GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []*models.GeoJSONFeature{},
+	}
+	if coordinateSystem == models.CoordinateSystemPixel {
+		fc.CoordinateSystem = models.CoordinateSystemPixel
+	}
+
+	for _, pin := range pins {
+		if _, ok := floorNames[pin.FloorID]; !ok {
+			continue
+		}
+
+		x, y := pin.XPosition, pin.YPosition
+		if coordinateSystem == models.CoordinateSystemGeo {
+			x, y = transforms[pin.FloorID].Apply(x, y)
+		}
+
+		fc.Features = append(fc.Features, &models.GeoJSONFeature{
+			Type: "Feature",
+			ID:   pin.ID,
+			Geometry: &models.GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{x, y},
+			},
+			Properties: map[string]interface{}{
+				"id":          pin.ID,
+				"floor_id":    pin.FloorID,
+				"floor_name":  floorNames[pin.FloorID],
+				"title":       pin.Title,
+				"description": pin.Description,
+				"category":    pin.Category,
+				"editor":      pin.EditorNickname,
+				"image":       pin.ImageURL,
+				"x_position":  pin.XPosition,
+				"y_position":  pin.YPosition,
+			},
+		})
+	}
+
+	return fc, nil
+}
+
+// loadTransforms はフロアごとの変換（inverseがtrueの場合は経度緯度→画像座標）を取得する
+func (s *DefaultGeoJSONService) loadTransforms(ctx context.Context, floors []*models.Floor, inverse bool) (map[string]utils.AffineTransform, error) {
+	floorIDs := make([]string, len(floors))
+	for i, floor := range floors {
+		floorIDs[i] = floor.ID
+	}
+
+	geos, err := s.geoRepo.GetByFloorIDs(ctx, floorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	transforms := make(map[string]utils.AffineTransform)
+	for _, geo := range geos {
+		transform, err := toAffine(geo.Transform)
+		if err != nil {
+			return nil, err
+		}
+		if inverse {
+			if transform, err = transform.Inverse(); err != nil {
+				return nil, err
+			}
+		}
+		transforms[geo.FloorID] = transform
+	}
+
+	return transforms, nil
+}
+
+// propString はプロパティを文字列として取得する
+func propString(props map[string]interface{}, key string) string {
+	value, ok := props[key]
+	if !ok || value == nil {
+		return ""
+	}
+	if str, ok := value.(string); ok {
+		return str
+	}
+	return fmt.Sprint(value)
+}
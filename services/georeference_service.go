@@ -0,0 +1,210 @@
+// backend/services/georeference_service.go
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// GeoreferenceService はフロアの地理参照に関する操作を提供するインターフェース
+type GeoreferenceService interface {
+	Calibrate(ctx context.Context, userID, floorID string, req *models.FloorGeoreferenceUpdate) (*models.FloorGeoreference, error)
+	GetByFloorID(ctx context.Context, floorID string) (*models.FloorGeoreference, error)
+	Delete(ctx context.Context, userID, floorID string) error
+	Locate(ctx context.Context, floorID string, latitude, longitude float64) (*models.GeoLocateResponse, error)
+	AttachCoordinates(ctx context.Context, pins []*models.Pin) error
+}
+
+// DefaultGeoreferenceService はGeoreferenceServiceの実装
+type DefaultGeoreferenceService struct {
+	geoRepo   repositories.FloorGeoreferenceRepository
+	floorRepo repositories.FloorRepository
+	mapRepo   repositories.MapRepository
+}
+
+// NewGeoreferenceService は新しいGeoreferenceServiceを作成する
+func NewGeoreferenceService(
+	geoRepo repositories.FloorGeoreferenceRepository,
+	floorRepo repositories.FloorRepository,
+	mapRepo repositories.MapRepository,
+) GeoreferenceService {
+	return &DefaultGeoreferenceService{
+		geoRepo:   geoRepo,
+		floorRepo: floorRepo,
+		mapRepo:   mapRepo,
+	}
+}
+
+// Calibrate は対応点からフロアの変換を計算して保存する
+func (s *DefaultGeoreferenceService) Calibrate(ctx context.Context, userID, floorID string, req *models.FloorGeoreferenceUpdate) (*models.FloorGeoreference, error) {
+	if err := s.checkFloorOwner(ctx, userID, floorID); err != nil {
+		return nil, err
+	}
+
+	// 画像座標 → (経度, 緯度) の対応を作成
+	src := make([][2]float64, len(req.ControlPoints))
+	dst := make([][2]float64, len(req.ControlPoints))
+	for i, p := range req.ControlPoints {
+		if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+			return nil, errors.New("緯度経度の値が不正です")
+		}
+		src[i] = [2]float64{p.X, p.Y}
+		dst[i] = [2]float64{p.Longitude, p.Latitude}
+	}
+
+	transform, err := utils.FitAffine(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := transform.Inverse(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.geoRepo.GetByFloorID(ctx, floorID)
+	if err != nil {
+		return nil, err
+	}
+
+	geo := &models.FloorGeoreference{
+		FloorID:       floorID,
+		ControlPoints: req.ControlPoints,
+		Transform:     transform[:],
+		RMSError:      transform.RMSError(src, dst),
+	}
+	if existing != nil {
+		geo.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.geoRepo.Save(ctx, geo); err != nil {
+		return nil, err
+	}
+
+	return geo, nil
+}
+
+// GetByFloorID はフロアの地理参照情報を取得する
+func (s *DefaultGeoreferenceService) GetByFloorID(ctx context.Context, floorID string) (*models.FloorGeoreference, error) {
+	return s.geoRepo.GetByFloorID(ctx, floorID)
+}
+
+// Delete はフロアの地理参照情報を削除する
+func (s *DefaultGeoreferenceService) Delete(ctx context.Context, userID, floorID string) error {
+	if err := s.checkFloorOwner(ctx, userID, floorID); err != nil {
+		return err
+	}
+	return s.geoRepo.Delete(ctx, floorID)
+}
+
+// Locate はGPS位置をフロアの画像座標に変換する
+func (s *DefaultGeoreferenceService) Locate(ctx context.Context, floorID string, latitude, longitude float64) (*models.GeoLocateResponse, error) {
+	geo, err := s.geoRepo.GetByFloorID(ctx, floorID)
+	if err != nil {
+		return nil, err
+	}
+	if geo == nil {
+		return nil, errors.New("このフロアは位置情報が設定されていません")
+	}
+
+	transform, err := toAffine(geo.Transform)
+	if err != nil {
+		return nil, err
+	}
+	inverse, err := transform.Inverse()
+	if err != nil {
+		return nil, err
+	}
+
+	x, y := inverse.Apply(longitude, latitude)
+
+	return &models.GeoLocateResponse{
+		FloorID:   floorID,
+		XPosition: x,
+		YPosition: y,
+	}, nil
+}
+
+// AttachCoordinates は地理参照済みフロアのピンに緯度経度を設定する
+func (s *DefaultGeoreferenceService) AttachCoordinates(ctx context.Context, pins []*models.Pin) error {
+	if len(pins) == 0 {
+		return nil
+	}
+
+	// 対象フロアIDを重複なく収集
+	seen := make(map[string]bool)
+	var floorIDs []string
+	for _, pin := range pins {
+		if !seen[pin.FloorID] {
+			seen[pin.FloorID] = true
+			floorIDs = append(floorIDs, pin.FloorID)
+		}
+	}
+
+	geos, err := s.geoRepo.GetByFloorIDs(ctx, floorIDs)
+	if err != nil {
+		return err
+	}
+
+	transforms := make(map[string]utils.AffineTransform)
+	for _, geo := range geos {
+		transform, err := toAffine(geo.Transform)
+		if err != nil {
+			return err
+		}
+		transforms[geo.FloorID] = transform
+	}
+
+	for _, pin := range pins {
+		transform, ok := transforms[pin.FloorID]
+		if !ok {
+			continue
+		}
+		lng, lat := transform.Apply(pin.XPosition, pin.YPosition)
+		pin.Latitude = &lat
+		pin.Longitude = &lng
+	}
+
+	return nil
+}
+
+// checkFloorOwner はフロアが属するマップの所有者であるか確認する
+func (s *DefaultGeoreferenceService) checkFloorOwner(ctx context.Context, userID, floorID string) error {
+	floor, err := s.floorRepo.GetByID(ctx, floorID)
+	if err != nil {
+		return err
+	}
+	if floor == nil {
+		return errors.New("フロアが見つかりません")
+	}
+
+	map_, err := s.mapRepo.GetByID(ctx, floor.MapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このフロアを編集する権限がありません")
+	}
+
+	return nil
+}
+
+// toAffine は保存された係数をアフィン変換に変換する
+func toAffine(values []float64) (utils.AffineTransform, error) {
+	var t utils.AffineTransform
+	if len(values) != len(t) {
+		return t, errors.New("変換係数が不正です")
+	}
+	copy(t[:], values)
+	return t, nil
+}
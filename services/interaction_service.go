@@ -0,0 +1,619 @@
+// backend/services/interaction_service.go
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// コメント一覧の1ページあたりの件数
+const (
+	defaultCommentPageSize = 20
+	maxCommentPageSize     = 50
+)
+
+// 来場者のコメント・リアクションで呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrAuthorRequired    = errors.New("コメント・リアクションには来場者登録またはログインが必要です")
+	ErrCommentsDisabled  = errors.New("このマップではコメントが無効になっています")
+	ErrReactionsDisabled = errors.New("このマップではリアクションが無効になっています")
+)
+
+// RateLimitError は操作の回数制限を超えたことを表すエラー
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+// Error はエラーメッセージを返す
+func (e *RateLimitError) Error() string {
+	return "操作が多すぎます。しばらくしてから再度お試しください"
+}
+
+// InteractionService はピンへのコメント・リアクションに関する操作を提供するインターフェース
+type InteractionService interface {
+	IssueVisitor(clientIP string) (*models.Visitor, error)
+	ResolveAuthor(ctx context.Context, userID, visitorToken string) (*models.Author, error)
+	GetComments(ctx context.Context, viewerID string, author *models.Author, pinID, cursor string, limit int) (*models.PinCommentPage, error)
+	CreateComment(ctx context.Context, author *models.Author, pinID string, req *models.PinCommentCreate) (*models.PinComment, error)
+	DeleteComment(ctx context.Context, userID string, author *models.Author, id string) error
+	ModerateComment(ctx context.Context, userID, id string, req *models.PinCommentModerate) (*models.PinComment, error)
+	GetMapComments(ctx context.Context, userID, mapID, status, cursor string, limit int) (*models.PinCommentPage, error)
+	GetReactions(ctx context.Context, author *models.Author, pinID string) (*models.PinReactionSummary, error)
+	AddReaction(ctx context.Context, author *models.Author, pinID, emoji string) (*models.PinReactionSummary, error)
+	RemoveReaction(ctx context.Context, author *models.Author, pinID, emoji string) (*models.PinReactionSummary, error)
+	GetSettings(ctx context.Context, userID, mapID string) (*models.MapInteractionSettings, error)
+	UpdateSettings(ctx context.Context, userID, mapID string, req *models.MapInteractionSettingsUpdate) (*models.MapInteractionSettings, error)
+	GetViewerInteractions(ctx context.Context, mapID string) (*models.ViewerInteractions, error)
+}
+
+// DefaultInteractionService はInteractionServiceの実装
+type DefaultInteractionService struct {
+	commentRepo     repositories.PinCommentRepository
+	reactionRepo    repositories.PinReactionRepository
+	settingsRepo    repositories.MapInteractionSettingsRepository
+	mapRepo         repositories.MapRepository
+	floorRepo       repositories.FloorRepository
+	pinRepo         repositories.PinRepository
+	userRepo        repositories.UserRepository
+	secret          []byte
+	visitorLimiter  *utils.RateLimiter
+	commentLimiter  *utils.RateLimiter
+	reactionLimiter *utils.RateLimiter
+}
+
+// NewInteractionService は新しいInteractionServiceを作成する
+// commentLimit・reactionLimitは1人あたり1分間に許可する回数
+func NewInteractionService(
+	commentRepo repositories.PinCommentRepository,
+	reactionRepo repositories.PinReactionRepository,
+	settingsRepo repositories.MapInteractionSettingsRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	userRepo repositories.UserRepository,
+	secret string,
+	commentLimit int,
+	reactionLimit int,
+) InteractionService {
+	return &DefaultInteractionService{
+		commentRepo:     commentRepo,
+		reactionRepo:    reactionRepo,
+		settingsRepo:    settingsRepo,
+		mapRepo:         mapRepo,
+		floorRepo:       floorRepo,
+		pinRepo:         pinRepo,
+		userRepo:        userRepo,
+		secret:          []byte(secret),
+		visitorLimiter:  utils.NewRateLimiter(10, time.Minute),
+		commentLimiter:  utils.NewRateLimiter(commentLimit, time.Minute),
+		reactionLimiter: utils.NewRateLimiter(reactionLimit, time.Minute),
+	}
+}
+
+// IssueVisitor は匿名の来場者の識別子と署名付きトークンを発行する
+func (s *DefaultInteractionService) IssueVisitor(clientIP string) (*models.Visitor, error) {
+	if ok, retryAfter := s.visitorLimiter.Allow(clientIP); !ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	id := uuid.New().String()
+	return &models.Visitor{
+		ID:    id,
+		Token: id + "." + s.visitorSignature(id),
+	}, nil
+}
+
+// ResolveAuthor はログインユーザーまたは来場者トークンから投稿者を特定する
+// どちらもない場合はnilを返す
+func (s *DefaultInteractionService) ResolveAuthor(ctx context.Context, userID, visitorToken string) (*models.Author, error) {
+	if userID != "" {
+		user, err := s.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, errors.New("ユーザーが見つかりません")
+		}
+		return &models.Author{Type: models.AuthorTypeUser, ID: user.ID, Name: user.Name}, nil
+	}
+
+	if visitorToken == "" {
+		return nil, nil
+	}
+
+	i := strings.LastIndex(visitorToken, ".")
+	if i <= 0 {
+		return nil, errors.New("無効な来場者トークンです")
+	}
+	id, signature := visitorToken[:i], visitorToken[i+1:]
+	if !hmac.Equal([]byte(s.visitorSignature(id)), []byte(signature)) {
+		return nil, errors.New("無効な来場者トークンです")
+	}
+
+	return &models.Author{Type: models.AuthorTypeVisitor, ID: id}, nil
+}
+
+// GetComments はピンのコメントを新しい順に取得する
+// マップを編集できるユーザー（所有者・組織のメンバー）には非表示にしたコメントも返す
+func (s *DefaultInteractionService) GetComments(ctx context.Context, viewerID string, author *models.Author, pinID, cursor string, limit int) (*models.PinCommentPage, error) {
+	_, map_, err := s.getViewablePin(ctx, viewerID, pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	isOwner, err := canEditMap(ctx, s.mapRepo, map_, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !isOwner {
+		settings, err := s.settingsRepo.Get(ctx, map_.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !settings.CommentsEnabled {
+			return nil, ErrCommentsDisabled
+		}
+	}
+
+	before, beforeID, err := decodeCommentCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit = commentPageSize(limit)
+
+	// 次のページの有無を判定するため1件多く取得する
+	comments, err := s.commentRepo.GetByPinID(ctx, pinID, isOwner, before, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCommentPage(comments, author, limit), nil
+}
+
+// CreateComment はピンにコメントを投稿する
+func (s *DefaultInteractionService) CreateComment(ctx context.Context, author *models.Author, pinID string, req *models.PinCommentCreate) (*models.PinComment, error) {
+	if author == nil {
+		return nil, ErrAuthorRequired
+	}
+
+	pin, map_, err := s.getViewablePin(ctx, viewerIDOf(author), pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.settingsRepo.Get(ctx, map_.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.CommentsEnabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, errors.New("コメントを入力してください")
+	}
+
+	if ok, retryAfter := s.commentLimiter.Allow(authorKey(author)); !ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	// 来場者はニックネームを名乗れる（未指定の場合はゲスト）
+	name := author.Name
+	if author.Type == models.AuthorTypeVisitor {
+		name = strings.TrimSpace(req.Nickname)
+		if name == "" {
+			name = "ゲスト"
+		}
+	}
+
+	comment := &models.PinComment{
+		PinID:      pin.ID,
+		MapID:      map_.ID,
+		Body:       body,
+		AuthorType: author.Type,
+		AuthorID:   author.ID,
+		AuthorName: name,
+		Status:     models.CommentStatusVisible,
+		IsMine:     true,
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment はコメントを削除する
+// 投稿者本人またはマップの所有者のみ削除できる
+func (s *DefaultInteractionService) DeleteComment(ctx context.Context, userID string, author *models.Author, id string) error {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment == nil {
+		return errors.New("コメントが見つかりません")
+	}
+
+	if !isCommentAuthor(comment, author) {
+		if err := s.checkMapOwner(ctx, userID, comment.MapID); err != nil {
+			return errors.New("このコメントを削除する権限がありません")
+		}
+	}
+
+	return s.commentRepo.Delete(ctx, comment.ID)
+}
+
+// ModerateComment はコメントを表示・非表示に切り替える
+func (s *DefaultInteractionService) ModerateComment(ctx context.Context, userID, id string, req *models.PinCommentModerate) (*models.PinComment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil {
+		return nil, errors.New("コメントが見つかりません")
+	}
+
+	if err := s.checkMapOwner(ctx, userID, comment.MapID); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.UpdateStatus(ctx, comment.ID, req.Status); err != nil {
+		return nil, err
+	}
+
+	comment.Status = req.Status
+	comment.UpdatedAt = time.Now()
+	return comment, nil
+}
+
+// GetMapComments はマップ全体のコメントを新しい順に取得する（所有者によるモデレーション用）
+func (s *DefaultInteractionService) GetMapComments(ctx context.Context, userID, mapID, status, cursor string, limit int) (*models.PinCommentPage, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	if status != "" && status != models.CommentStatusVisible && status != models.CommentStatusHidden {
+		return nil, errors.New("無効な表示状態です")
+	}
+
+	before, beforeID, err := decodeCommentCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	limit = commentPageSize(limit)
+
+	comments, err := s.commentRepo.GetByMapID(ctx, mapID, status, before, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	return newCommentPage(comments, nil, limit), nil
+}
+
+// GetReactions はピンのリアクション数と自分が付けたリアクションを取得する
+func (s *DefaultInteractionService) GetReactions(ctx context.Context, author *models.Author, pinID string) (*models.PinReactionSummary, error) {
+	if _, _, err := s.getViewablePin(ctx, viewerIDOf(author), pinID); err != nil {
+		return nil, err
+	}
+
+	return s.reactionSummary(ctx, author, pinID)
+}
+
+// AddReaction はピンにリアクションを付ける
+func (s *DefaultInteractionService) AddReaction(ctx context.Context, author *models.Author, pinID, emoji string) (*models.PinReactionSummary, error) {
+	map_, err := s.checkReactable(ctx, author, pinID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Add(ctx, pinID, map_.ID, emoji, author.Type, author.ID); err != nil {
+		return nil, err
+	}
+
+	return s.reactionSummary(ctx, author, pinID)
+}
+
+// RemoveReaction はピンに付けたリアクションを取り消す
+func (s *DefaultInteractionService) RemoveReaction(ctx context.Context, author *models.Author, pinID, emoji string) (*models.PinReactionSummary, error) {
+	if _, err := s.checkReactable(ctx, author, pinID, emoji); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Remove(ctx, pinID, emoji, author.Type, author.ID); err != nil {
+		return nil, err
+	}
+
+	return s.reactionSummary(ctx, author, pinID)
+}
+
+// GetSettings はマップのコメント・リアクションの設定を取得する
+func (s *DefaultInteractionService) GetSettings(ctx context.Context, userID, mapID string) (*models.MapInteractionSettings, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	return s.settingsRepo.Get(ctx, mapID)
+}
+
+// UpdateSettings はマップのコメント・リアクションの設定を更新する
+func (s *DefaultInteractionService) UpdateSettings(ctx context.Context, userID, mapID string, req *models.MapInteractionSettingsUpdate) (*models.MapInteractionSettings, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.settingsRepo.Get(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CommentsEnabled != nil {
+		settings.CommentsEnabled = *req.CommentsEnabled
+	}
+	if req.ReactionsEnabled != nil {
+		settings.ReactionsEnabled = *req.ReactionsEnabled
+	}
+
+	if err := s.settingsRepo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// GetViewerInteractions はビューワーに返すピンごとのコメント数・リアクション数を集計する
+// 無効にしている機能の件数は含めない
+func (s *DefaultInteractionService) GetViewerInteractions(ctx context.Context, mapID string) (*models.ViewerInteractions, error) {
+	settings, err := s.settingsRepo.Get(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	interactions := &models.ViewerInteractions{
+		CommentsEnabled:  settings.CommentsEnabled,
+		ReactionsEnabled: settings.ReactionsEnabled,
+		Pins:             make(map[string]*models.PinInteractionCount),
+	}
+
+	entry := func(pinID string) *models.PinInteractionCount {
+		count, ok := interactions.Pins[pinID]
+		if !ok {
+			count = &models.PinInteractionCount{Reactions: map[string]int{}}
+			interactions.Pins[pinID] = count
+		}
+		return count
+	}
+
+	if settings.CommentsEnabled {
+		counts, err := s.commentRepo.CountVisibleByMapID(ctx, mapID)
+		if err != nil {
+			return nil, err
+		}
+		for pinID, count := range counts {
+			entry(pinID).Comments = count
+		}
+	}
+
+	if settings.ReactionsEnabled {
+		counts, err := s.reactionRepo.CountByMapID(ctx, mapID)
+		if err != nil {
+			return nil, err
+		}
+		for pinID, reactions := range counts {
+			entry(pinID).Reactions = reactions
+		}
+	}
+
+	return interactions, nil
+}
+
+// checkReactable はリアクションを付け外しできるか確認し、ピンのマップを返す
+func (s *DefaultInteractionService) checkReactable(ctx context.Context, author *models.Author, pinID, emoji string) (*models.Map, error) {
+	if author == nil {
+		return nil, ErrAuthorRequired
+	}
+
+	if !containsString(models.ReactionEmojis, emoji) {
+		return nil, errors.New("使用できない絵文字です")
+	}
+
+	_, map_, err := s.getViewablePin(ctx, viewerIDOf(author), pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.settingsRepo.Get(ctx, map_.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.ReactionsEnabled {
+		return nil, ErrReactionsDisabled
+	}
+
+	if ok, retryAfter := s.reactionLimiter.Allow(authorKey(author)); !ok {
+		return nil, &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	return map_, nil
+}
+
+// reactionSummary はピンのリアクション数と投稿者が付けたリアクションをまとめる
+func (s *DefaultInteractionService) reactionSummary(ctx context.Context, author *models.Author, pinID string) (*models.PinReactionSummary, error) {
+	counts, err := s.reactionRepo.CountByPinID(ctx, pinID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.PinReactionSummary{
+		PinID:     pinID,
+		Reactions: counts,
+		Mine:      []string{},
+	}
+
+	if author != nil {
+		mine, err := s.reactionRepo.GetByAuthor(ctx, pinID, author.Type, author.ID)
+		if err != nil {
+			return nil, err
+		}
+		if mine != nil {
+			summary.Mine = mine
+		}
+	}
+
+	return summary, nil
+}
+
+// getViewablePin はピンとそのマップを取得し、閲覧できるか確認する
+// 公開中でないマップのピンは所有者以外には存在しないものとして扱う
+func (s *DefaultInteractionService) getViewablePin(ctx context.Context, viewerID, pinID string) (*models.Pin, *models.Map, error) {
+	pin, err := s.pinRepo.GetByID(ctx, pinID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pin == nil {
+		return nil, nil, errors.New("ピンが見つかりません")
+	}
+
+	floor, err := s.floorRepo.GetByID(ctx, pin.FloorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if floor == nil {
+		return nil, nil, errors.New("フロアが見つかりません")
+	}
+
+	map_, err := s.mapRepo.GetByID(ctx, floor.MapID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if map_ == nil {
+		return nil, nil, errors.New("ピンが見つかりません")
+	}
+	if !isPublished(map_, time.Now()) {
+		canEdit, err := canEditMap(ctx, s.mapRepo, map_, viewerID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !canEdit {
+			return nil, nil, errors.New("ピンが見つかりません")
+		}
+	}
+
+	return pin, map_, nil
+}
+
+// checkMapOwner はマップの所有者であるか確認する
+func (s *DefaultInteractionService) checkMapOwner(ctx context.Context, userID, mapID string) error {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このマップを編集する権限がありません")
+	}
+	return nil
+}
+
+// visitorSignature は来場者IDの署名を生成する
+func (s *DefaultInteractionService) visitorSignature(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("visitor:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newCommentPage は1件多く取得したコメントから1ページ分と次のページの位置を作成する
+func newCommentPage(comments []*models.PinComment, author *models.Author, limit int) *models.PinCommentPage {
+	page := &models.PinCommentPage{Comments: []*models.PinComment{}}
+
+	if len(comments) > limit {
+		comments = comments[:limit]
+		last := comments[len(comments)-1]
+		page.NextCursor = encodeCommentCursor(last.CreatedAt, last.ID)
+	}
+
+	for _, comment := range comments {
+		comment.IsMine = isCommentAuthor(comment, author)
+		page.Comments = append(page.Comments, comment)
+	}
+
+	return page
+}
+
+// encodeCommentCursor はコメント一覧の続きを取得するための位置を文字列にする
+func encodeCommentCursor(createdAt time.Time, id string) string {
+	raw := strconv.FormatInt(createdAt.UnixNano(), 10) + ":" + id
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCommentCursor はコメント一覧の位置の文字列を読み取る
+// 空の場合は先頭のページとしてゼロ値を返す
+func decodeCommentCursor(cursor string) (time.Time, string, error) {
+	if cursor == "" {
+		return time.Time{}, "", nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", errors.New("無効なカーソルです")
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return time.Time{}, "", errors.New("無効なカーソルです")
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, "", errors.New("無効なカーソルです")
+	}
+
+	return time.Unix(0, nanos), parts[1], nil
+}
+
+// commentPageSize は1ページの件数を許容範囲に収める
+func commentPageSize(limit int) int {
+	if limit <= 0 {
+		return defaultCommentPageSize
+	}
+	if limit > maxCommentPageSize {
+		return maxCommentPageSize
+	}
+	return limit
+}
+
+// isCommentAuthor は投稿者本人のコメントか判定する
+func isCommentAuthor(comment *models.PinComment, author *models.Author) bool {
+	return author != nil && comment.AuthorType == author.Type && comment.AuthorID == author.ID
+}
+
+// authorKey は回数制限に使用する投稿者のキーを返す
+func authorKey(author *models.Author) string {
+	return author.Type + ":" + author.ID
+}
+
+// viewerIDOf はマップの所有者判定に使用するユーザーIDを返す（来場者の場合は空）
+func viewerIDOf(author *models.Author) string {
+	if author == nil || author.Type != models.AuthorTypeUser {
+		return ""
+	}
+	return author.ID
+}
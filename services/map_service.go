@@ -4,35 +4,71 @@ package services
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/shimaf4979/pamfree-backend/models"
 	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
 )
 
 // MapService マップに関する操作を提供するインターフェース
 type MapService interface {
-	GetMapsByUserID(ctx context.Context, userID string) ([]*models.Map, error)
+	GetMaps(ctx context.Context, userID, organizationID string) ([]*models.Map, error)
 	GetMapByID(ctx context.Context, id string) (*models.Map, error)
 	CreateMap(ctx context.Context, m *models.Map) error
 	UpdateMap(ctx context.Context, m *models.Map) error
 	DeleteMap(ctx context.Context, id string) error
+	UpdatePublication(ctx context.Context, userID, id string, req *models.MapPublicationUpdate) (*models.Map, error)
+	CanView(ctx context.Context, m *models.Map, userID string) (bool, error)
+	CanEdit(ctx context.Context, m *models.Map, userID string) (bool, error)
+	CanManage(ctx context.Context, m *models.Map, userID string) (bool, error)
+	TransferMap(ctx context.Context, userID, id string, req *models.MapTransfer) (*models.Map, error)
+	CheckSlugAvailability(ctx context.Context, mapID, slug string) (*models.SlugAvailability, error)
+	UpdateSlug(ctx context.Context, userID, id, slug string) (*models.Map, error)
+	GetMapBySlug(ctx context.Context, slug string) (*models.Map, bool, error)
 }
 
+// マップの作成・移管で呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrMapNotFound             = errors.New("マップが見つかりません")
+	ErrMapOrganizationNotFound = errors.New("組織が見つかりません")
+	ErrMapTransferForbidden    = errors.New("このマップを移管する権限がありません")
+	ErrMapTransferTarget       = errors.New("移管先のユーザーか組織のどちらか一方を指定してください")
+	ErrMapTransferUserNotFound = errors.New("移管先のユーザーが見つかりません")
+	ErrMapTransferOrganization = errors.New("移管先の組織でマップを管理する権限がありません")
+	ErrMapTransferSameOwner    = errors.New("移管先が現在の所有者と同じです")
+	ErrSlugTaken               = errors.New("このスラッグは既に使用されています")
+)
+
 // DefaultMapService はMapServiceの実装
 type DefaultMapService struct {
-	mapRepo repositories.MapRepository
+	mapRepo  repositories.MapRepository
+	userRepo repositories.UserRepository
 }
 
 // NewMapService 新しいMapServiceを作成
-func NewMapService(mapRepo repositories.MapRepository) MapService {
+func NewMapService(mapRepo repositories.MapRepository, userRepo repositories.UserRepository) MapService {
 	return &DefaultMapService{
-		mapRepo: mapRepo,
+		mapRepo:  mapRepo,
+		userRepo: userRepo,
 	}
 }
 
-// GetMapsByUserID ユーザーIDによるマップ一覧の取得
-func (s *DefaultMapService) GetMapsByUserID(ctx context.Context, userID string) ([]*models.Map, error) {
-	return s.mapRepo.GetByUserID(ctx, userID)
+// GetMaps ユーザーが編集できるマップ一覧の取得
+// 個人のマップと、メンバーである組織のマップを返す。organizationIDを指定した場合はその組織のマップのみ返す
+func (s *DefaultMapService) GetMaps(ctx context.Context, userID, organizationID string) ([]*models.Map, error) {
+	if organizationID == "" {
+		return s.mapRepo.GetAccessibleByUserID(ctx, userID)
+	}
+
+	role, err := s.mapRepo.GetOrganizationRole(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrMapOrganizationNotFound
+	}
+	return s.mapRepo.GetByOrganizationID(ctx, organizationID)
 }
 
 // GetMapByID IDによるマップの取得
@@ -41,7 +77,19 @@ func (s *DefaultMapService) GetMapByID(ctx context.Context, id string) (*models.
 }
 
 // CreateMap マップの作成
+// OrganizationIDを指定した場合は、作成するユーザー（UserID）がメンバーである組織のマップとして作成する
 func (s *DefaultMapService) CreateMap(ctx context.Context, m *models.Map) error {
+	if m.OrganizationID != nil {
+		role, err := s.mapRepo.GetOrganizationRole(ctx, *m.OrganizationID, m.UserID)
+		if err != nil {
+			return err
+		}
+		if role == "" {
+			return ErrMapOrganizationNotFound
+		}
+		m.UserID = ""
+	}
+
 	// IDの重複チェック
 	existingMap, err := s.mapRepo.GetByID(ctx, m.ID)
 	if err != nil {
@@ -51,6 +99,24 @@ func (s *DefaultMapService) CreateMap(ctx context.Context, m *models.Map) error
 		return errors.New("このIDは既に使用されています")
 	}
 
+	// スラッグの検証
+	if m.Slug != nil {
+		slug := utils.NormalizeSlug(*m.Slug)
+		reason, err := s.checkSlug(ctx, m.ID, slug)
+		if err != nil {
+			return err
+		}
+		if reason != nil {
+			return reason
+		}
+		m.Slug = &slug
+	}
+
+	// 作成直後は下書きとし、明示的に公開されるまでビューワーには表示しない
+	if m.Status == "" {
+		m.Status = models.MapStatusDraft
+	}
+
 	return s.mapRepo.Create(ctx, m)
 }
 
@@ -81,3 +147,277 @@ func (s *DefaultMapService) DeleteMap(ctx context.Context, id string) error {
 
 	return s.mapRepo.Delete(ctx, id)
 }
+
+// UpdatePublication マップの公開状態と公開予約の更新
+func (s *DefaultMapService) UpdatePublication(ctx context.Context, userID, id string, req *models.MapPublicationUpdate) (*models.Map, error) {
+	m, err := s.mapRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, m, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+
+	now := time.Now()
+	if req.PublishAt != nil && req.Status != models.MapStatusDraft {
+		return nil, errors.New("公開予約は下書きのマップにのみ設定できます")
+	}
+	if req.UnpublishAt != nil {
+		if req.Status == models.MapStatusArchived {
+			return nil, errors.New("アーカイブ済みのマップには公開終了日時を設定できません")
+		}
+		if !req.UnpublishAt.After(now) {
+			return nil, errors.New("公開終了日時は現在より後に設定してください")
+		}
+		if req.PublishAt != nil && !req.UnpublishAt.After(*req.PublishAt) {
+			return nil, errors.New("公開終了日時は公開日時より後に設定してください")
+		}
+	}
+
+	m.Status = req.Status
+	m.PublishAt = req.PublishAt
+	m.UnpublishAt = req.UnpublishAt
+
+	if err := s.mapRepo.Update(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// CanView ビューワーでマップを閲覧できるか判定する
+// 公開中でないマップはメンバー（所有者・組織のメンバー）のみ閲覧できる
+func (s *DefaultMapService) CanView(ctx context.Context, m *models.Map, userID string) (bool, error) {
+	return canViewMap(ctx, s.mapRepo, m, userID)
+}
+
+// CanEdit ユーザーがマップを編集できるか判定する
+func (s *DefaultMapService) CanEdit(ctx context.Context, m *models.Map, userID string) (bool, error) {
+	return canEditMap(ctx, s.mapRepo, m, userID)
+}
+
+// CanManage ユーザーがマップを削除・移管できるか判定する
+func (s *DefaultMapService) CanManage(ctx context.Context, m *models.Map, userID string) (bool, error) {
+	return canManageMap(ctx, s.mapRepo, m, userID)
+}
+
+// TransferMap マップの所有者を別のユーザーまたは組織に移管する
+// 移管できるのは個人のマップの所有者と組織の所有者・管理者で、組織に移管する場合は移管先の組織の所有者・管理者である必要がある
+func (s *DefaultMapService) TransferMap(ctx context.Context, userID, id string, req *models.MapTransfer) (*models.Map, error) {
+	if (req.UserEmail == "") == (req.OrganizationID == "") {
+		return nil, ErrMapTransferTarget
+	}
+
+	m, err := s.mapRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, ErrMapNotFound
+	}
+	canManage, err := canManageMap(ctx, s.mapRepo, m, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage {
+		return nil, ErrMapTransferForbidden
+	}
+
+	if req.OrganizationID != "" {
+		if m.OrganizationID != nil && *m.OrganizationID == req.OrganizationID {
+			return nil, ErrMapTransferSameOwner
+		}
+		role, err := s.mapRepo.GetOrganizationRole(ctx, req.OrganizationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !models.IsOrganizationManager(role) {
+			return nil, ErrMapTransferOrganization
+		}
+		organizationID := req.OrganizationID
+		m.UserID = ""
+		m.OrganizationID = &organizationID
+	} else {
+		user, err := s.userRepo.GetByEmail(ctx, req.UserEmail)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrMapTransferUserNotFound
+		}
+		if m.OrganizationID == nil && m.UserID == user.ID {
+			return nil, ErrMapTransferSameOwner
+		}
+		m.UserID = user.ID
+		m.OrganizationID = nil
+	}
+
+	if err := s.mapRepo.UpdateOwner(ctx, m); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// CheckSlugAvailability スラッグが使用可能か確認する
+// mapIDには変更対象のマップを指定する（自分が使用中・使用していたスラッグは使用可能とする）
+func (s *DefaultMapService) CheckSlugAvailability(ctx context.Context, mapID, slug string) (*models.SlugAvailability, error) {
+	slug = utils.NormalizeSlug(slug)
+	availability := &models.SlugAvailability{Slug: slug}
+
+	reason, err := s.checkSlug(ctx, mapID, slug)
+	if err != nil {
+		return nil, err
+	}
+	if reason != nil {
+		availability.Reason = reason.Error()
+		return availability, nil
+	}
+
+	availability.Available = true
+	return availability, nil
+}
+
+// checkSlug は正規化済みのスラッグが使用可能か確認し、使用できない場合はその理由をreasonに返す
+func (s *DefaultMapService) checkSlug(ctx context.Context, mapID, slug string) (reason error, err error) {
+	if err := utils.ValidateSlug(slug); err != nil {
+		return err, nil
+	}
+
+	existing, err := s.mapRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != mapID {
+		return ErrSlugTaken, nil
+	}
+
+	// 過去のスラッグは古いURLからのリダイレクトに使われるため、他のマップには割り当てない
+	previous, err := s.mapRepo.GetBySlugHistory(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.ID != mapID {
+		return ErrSlugTaken, nil
+	}
+
+	return nil, nil
+}
+
+// UpdateSlug マップのスラッグの更新
+func (s *DefaultMapService) UpdateSlug(ctx context.Context, userID, id, slug string) (*models.Map, error) {
+	m, err := s.mapRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, m, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+
+	slug = utils.NormalizeSlug(slug)
+	reason, err := s.checkSlug(ctx, m.ID, slug)
+	if err != nil {
+		return nil, err
+	}
+	if reason != nil {
+		return nil, reason
+	}
+
+	if err := s.mapRepo.UpdateSlug(ctx, m, slug); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMapBySlug スラッグによるマップの取得
+// 過去のスラッグで見つかった場合はredirectedにtrueを返す
+func (s *DefaultMapService) GetMapBySlug(ctx context.Context, slug string) (*models.Map, bool, error) {
+	slug = utils.NormalizeSlug(slug)
+
+	m, err := s.mapRepo.GetBySlug(ctx, slug)
+	if err != nil || m != nil {
+		return m, false, err
+	}
+
+	m, err = s.mapRepo.GetBySlugHistory(ctx, slug)
+	if err != nil || m == nil || m.Slug == nil {
+		return nil, false, err
+	}
+
+	return m, true, nil
+}
+
+// isPublished は指定時刻にマップが公開されているか判定する
+// スケジューラーの実行を待たずに公開予約・公開終了を反映する
+func isPublished(m *models.Map, now time.Time) bool {
+	if m.UnpublishAt != nil && !now.Before(*m.UnpublishAt) {
+		return false
+	}
+
+	switch m.Status {
+	case models.MapStatusPublished:
+		return true
+	case models.MapStatusDraft:
+		return m.PublishAt != nil && !now.Before(*m.PublishAt)
+	default:
+		return false
+	}
+}
+
+// canViewMap はユーザーがマップを閲覧できるか判定する
+// 公開中のマップは誰でも、公開中でないマップは編集できるユーザーのみが閲覧できる
+func canViewMap(ctx context.Context, mapRepo repositories.MapRepository, m *models.Map, userID string) (bool, error) {
+	if isPublished(m, time.Now()) {
+		return true, nil
+	}
+	return canEditMap(ctx, mapRepo, m, userID)
+}
+
+// canEditMap はユーザーがマップを編集できるか判定する
+// 個人のマップは所有者、組織のマップは組織のメンバーが編集できる
+func canEditMap(ctx context.Context, mapRepo repositories.MapRepository, m *models.Map, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	if m.OrganizationID == nil {
+		return m.UserID == userID, nil
+	}
+
+	role, err := mapRepo.GetOrganizationRole(ctx, *m.OrganizationID, userID)
+	if err != nil {
+		return false, err
+	}
+	return role != "", nil
+}
+
+// canManageMap はユーザーがマップを削除・移管できるか判定する
+// 個人のマップは所有者、組織のマップは組織の所有者・管理者が管理できる
+func canManageMap(ctx context.Context, mapRepo repositories.MapRepository, m *models.Map, userID string) (bool, error) {
+	if userID == "" {
+		return false, nil
+	}
+	if m.OrganizationID == nil {
+		return m.UserID == userID, nil
+	}
+
+	role, err := mapRepo.GetOrganizationRole(ctx, *m.OrganizationID, userID)
+	if err != nil {
+		return false, err
+	}
+	return models.IsOrganizationManager(role), nil
+}
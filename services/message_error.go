@@ -0,0 +1,32 @@
+// backend/services/message_error.go
+package services
+
+import "fmt"
+
+// MessageError は書式と引数からなるエラー
+// 値を含むメッセージは文字列を連結せずにこのエラーで返し、コントローラーで書式を翻訳してから値を埋め込む
+type MessageError struct {
+	format string
+	args   []interface{}
+}
+
+// newMessageError は新しいMessageErrorを作成する
+// formatには翻訳カタログに登録した日本語の書式文字列を指定する
+func newMessageError(format string, args ...interface{}) *MessageError {
+	return &MessageError{format: format, args: args}
+}
+
+// Format はエラーメッセージの書式を返す（引数はArgsで取得する）
+func (e *MessageError) Format() string {
+	return e.format
+}
+
+// Args はエラーメッセージの書式に渡す引数を返す
+func (e *MessageError) Args() []interface{} {
+	return e.args
+}
+
+// Error はエラーメッセージを返す
+func (e *MessageError) Error() string {
+	return fmt.Sprintf(e.format, e.args...)
+}
@@ -0,0 +1,71 @@
+// backend/services/messages_test.go
+package services
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shimaf4979/pamfree-backend/i18n"
+)
+
+// errorConstructors はメッセージを第1引数に取るエラーの生成関数
+var errorConstructors = map[string]bool{
+	"errors.New":      true,
+	"newMessageError": true,
+}
+
+// TestErrorMessagesInCatalog はサービスのエラーメッセージがすべて翻訳カタログにあることを確認する
+// 文字列の連結で作ったメッセージは翻訳できないため、書式と引数に分けたnewMessageErrorを使う
+func TestErrorMessagesInCatalog(t *testing.T) {
+	fset := token.NewFileSet()
+	notTest := func(info fs.FileInfo) bool { return !strings.HasSuffix(info.Name(), "_test.go") }
+	pkgs, err := parser.ParseDir(fset, ".", notTest, 0)
+	if err != nil {
+		t.Fatalf("ソースの解析に失敗しました: %v", err)
+	}
+
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) == 0 || !errorConstructors[callName(call)] {
+					return true
+				}
+
+				pos := fset.Position(call.Pos())
+				lit, ok := call.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					t.Errorf("%s: エラーメッセージは文字列リテラルで指定してください", pos)
+					return true
+				}
+				message, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Errorf("%s: 文字列を解釈できません: %v", pos, err)
+					return true
+				}
+				if _, key := i18n.Message("ja", message); key == "" {
+					t.Errorf("%s: 翻訳カタログにないメッセージです: %q", pos, message)
+				}
+				return true
+			})
+		}
+	}
+}
+
+// callName は関数呼び出しの名前を「パッケージ名.関数名」または「関数名」で返す
+func callName(call *ast.CallExpr) string {
+	switch fn := call.Fun.(type) {
+	case *ast.Ident:
+		return fn.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := fn.X.(*ast.Ident); ok {
+			return pkg.Name + "." + fn.Sel.Name
+		}
+	}
+	return ""
+}
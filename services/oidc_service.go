@@ -0,0 +1,323 @@
+// backend/services/oidc_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// oidcStateTTL は認可リクエストを開始してからコールバックまでの有効期間
+const oidcStateTTL = 10 * time.Minute
+
+// 外部アカウントでのログイン・連携で呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrOIDCProviderNotFound   = errors.New("ログインプロバイダーが見つかりません")
+	ErrOIDCProviderFailed     = errors.New("ログインプロバイダーに接続できません")
+	ErrOIDCStateInvalid       = errors.New("認可リクエストが無効か、有効期限が切れています")
+	ErrOIDCVerificationFailed = errors.New("外部アカウントの認証に失敗しました")
+	ErrOIDCEmailRequired      = errors.New("外部アカウントのメールアドレスを取得できませんでした")
+	ErrOIDCEmailUnverified    = errors.New("外部アカウントのメールアドレスが確認されていません")
+	ErrIdentityLinkedToOther  = errors.New("この外部アカウントは既に別のユーザーに連携されています")
+	ErrIdentityAlreadyLinked  = errors.New("このプロバイダーのアカウントは既に連携されています")
+	ErrIdentityNotFound       = errors.New("連携されたアカウントが見つかりません")
+	ErrIdentityLastLogin      = errors.New("パスワードが設定されていないため、最後の連携は解除できません")
+)
+
+// OIDCService はOpenID Connectによる外部アカウントでのログインと連携を提供するインターフェース
+type OIDCService interface {
+	Providers() []models.OIDCProviderInfo
+	Start(ctx context.Context, providerID, userID string) (*models.OIDCAuthorization, error)
+	Callback(ctx context.Context, req *models.OIDCCallback) (*models.OIDCResult, error)
+	GetIdentities(ctx context.Context, userID string) ([]*models.UserIdentity, error)
+	Unlink(ctx context.Context, userID, providerID string) error
+}
+
+// DefaultOIDCService はOIDCServiceの実装
+type DefaultOIDCService struct {
+	identityRepo repositories.UserIdentityRepository
+	stateRepo    repositories.OIDCStateRepository
+	userRepo     repositories.UserRepository
+	providers    []*utils.OIDCProvider
+	redirectURL  string
+}
+
+// NewOIDCService は新しいOIDCServiceを作成する
+func NewOIDCService(
+	identityRepo repositories.UserIdentityRepository,
+	stateRepo repositories.OIDCStateRepository,
+	userRepo repositories.UserRepository,
+	providers []*utils.OIDCProvider,
+	redirectURL string,
+) OIDCService {
+	return &DefaultOIDCService{
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		userRepo:     userRepo,
+		providers:    providers,
+		redirectURL:  redirectURL,
+	}
+}
+
+// Providers はログインに使えるプロバイダーの一覧を返す
+func (s *DefaultOIDCService) Providers() []models.OIDCProviderInfo {
+	providers := make([]models.OIDCProviderInfo, len(s.providers))
+	for i, provider := range s.providers {
+		providers[i] = models.OIDCProviderInfo{ID: provider.ID, Name: provider.Name}
+	}
+	return providers
+}
+
+// Start は認可リクエストを開始し、プロバイダーの認可画面のURLを返す
+// userIDを指定した場合はログイン中のユーザーへの連携として扱う
+func (s *DefaultOIDCService) Start(ctx context.Context, providerID, userID string) (*models.OIDCAuthorization, error) {
+	provider := s.provider(providerID)
+	if provider == nil {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	state, err := generateToken(16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := generateToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, challenge, err := utils.GeneratePKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, s.redirectURL, state, nonce, challenge)
+	if err != nil {
+		log.Printf("ログインプロバイダー %s の情報の取得に失敗しました: %v", provider.ID, err)
+		return nil, ErrOIDCProviderFailed
+	}
+
+	loginState := &models.OIDCLoginState{
+		State:        state,
+		Provider:     provider.ID,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}
+	if userID != "" {
+		loginState.UserID = &userID
+	}
+	if err := s.stateRepo.Create(ctx, loginState); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorization{
+		AuthorizationURL: authorizationURL,
+		State:            state,
+	}, nil
+}
+
+// Callback は認可コードをIDトークンに交換して検証し、ログインまたは連携を行う
+// 連携されていないアカウントは、確認済みのメールアドレスが一致するユーザーに連携するか新しいユーザーを登録する
+func (s *DefaultOIDCService) Callback(ctx context.Context, req *models.OIDCCallback) (*models.OIDCResult, error) {
+	// stateは一度だけ使える
+	loginState, err := s.stateRepo.Consume(ctx, req.State, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if loginState == nil {
+		return nil, ErrOIDCStateInvalid
+	}
+
+	provider := s.provider(loginState.Provider)
+	if provider == nil {
+		return nil, ErrOIDCProviderNotFound
+	}
+
+	rawIDToken, err := provider.Exchange(ctx, req.Code, s.redirectURL, loginState.CodeVerifier)
+	if err != nil {
+		log.Printf("ログインプロバイダー %s の認可コードの交換に失敗しました: %v", provider.ID, err)
+		return nil, ErrOIDCVerificationFailed
+	}
+	claims, err := provider.VerifyIDToken(ctx, rawIDToken, loginState.Nonce)
+	if err != nil {
+		log.Printf("ログインプロバイダー %s のIDトークンの検証に失敗しました: %v", provider.ID, err)
+		return nil, ErrOIDCVerificationFailed
+	}
+
+	identity, err := s.identityRepo.GetBySubject(ctx, provider.ID, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if loginState.UserID != nil {
+		return s.link(ctx, *loginState.UserID, provider.ID, claims, identity)
+	}
+	return s.login(ctx, provider.ID, claims, identity)
+}
+
+// GetIdentities はユーザーが連携している外部アカウントの一覧を取得する
+func (s *DefaultOIDCService) GetIdentities(ctx context.Context, userID string) ([]*models.UserIdentity, error) {
+	identities, err := s.identityRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if identities == nil {
+		identities = []*models.UserIdentity{}
+	}
+	return identities, nil
+}
+
+// Unlink は外部アカウントとの連携を解除する
+// パスワードがないユーザーはログインできなくなるため、最後の連携は解除できない
+func (s *DefaultOIDCService) Unlink(ctx context.Context, userID, providerID string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("ユーザーが見つかりません")
+	}
+
+	identities, err := s.identityRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Password == "" && len(identities) <= 1 {
+		return ErrIdentityLastLogin
+	}
+
+	deleted, err := s.identityRepo.Delete(ctx, userID, providerID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+// link はログイン中のユーザーに外部アカウントを連携する
+func (s *DefaultOIDCService) link(ctx context.Context, userID, providerID string, claims *utils.OIDCClaims, identity *models.UserIdentity) (*models.OIDCResult, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("ユーザーが見つかりません")
+	}
+
+	if identity != nil {
+		if identity.UserID != userID {
+			return nil, ErrIdentityLinkedToOther
+		}
+		// 既に連携済みの場合はそのまま返す
+		return &models.OIDCResult{User: user, Identity: identity, Linked: true}, nil
+	}
+
+	if identity, err = s.createIdentity(ctx, user.ID, providerID, claims, nil); err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCResult{User: user, Identity: identity, Linked: true}, nil
+}
+
+// login は外部アカウントでログインするユーザーを決める
+func (s *DefaultOIDCService) login(ctx context.Context, providerID string, claims *utils.OIDCClaims, identity *models.UserIdentity) (*models.OIDCResult, error) {
+	now := time.Now()
+
+	if identity != nil {
+		user, err := s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user == nil {
+			return nil, ErrOIDCVerificationFailed
+		}
+		if err := s.identityRepo.UpdateLastLogin(ctx, identity.ID, now); err != nil {
+			return nil, err
+		}
+		identity.LastLoginAt = &now
+		return &models.OIDCResult{User: user, Identity: identity}, nil
+	}
+
+	// メールアドレスでユーザーを結び付けるため、プロバイダーが確認済みのものに限る
+	if claims.Email == "" {
+		return nil, ErrOIDCEmailRequired
+	}
+	if !claims.EmailVerified {
+		return nil, ErrOIDCEmailUnverified
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, claims.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	created := false
+	if user == nil {
+		name := strings.TrimSpace(claims.Name)
+		if name == "" {
+			name = strings.SplitN(claims.Email, "@", 2)[0]
+		}
+		// 外部アカウントのみで登録したユーザーはパスワードを持たない
+		user = &models.User{
+			Email: claims.Email,
+			Name:  name,
+			Role:  "user",
+		}
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		created = true
+	}
+
+	identity, err = s.createIdentity(ctx, user.ID, providerID, claims, &now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCResult{User: user, Identity: identity, Created: created}, nil
+}
+
+// createIdentity は外部アカウントとの連携を作成する
+// ユーザーが同じプロバイダーの別のアカウントを連携済みの場合はエラーを返す
+func (s *DefaultOIDCService) createIdentity(ctx context.Context, userID, providerID string, claims *utils.OIDCClaims, lastLoginAt *time.Time) (*models.UserIdentity, error) {
+	identities, err := s.identityRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, linked := range identities {
+		if linked.Provider == providerID {
+			return nil, ErrIdentityAlreadyLinked
+		}
+	}
+
+	identity := &models.UserIdentity{
+		UserID:      userID,
+		Provider:    providerID,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: lastLoginAt,
+	}
+	if err := s.identityRepo.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	return identity, nil
+}
+
+// provider はIDによりプロバイダーを取得する
+func (s *DefaultOIDCService) provider(id string) *utils.OIDCProvider {
+	for _, provider := range s.providers {
+		if provider.ID == id {
+			return provider
+		}
+	}
+	return nil
+}
@@ -0,0 +1,463 @@
+// backend/services/oidc_service_test.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+const (
+	testOIDCClientID = "pamfree-test"
+	testOIDCKeyID    = "test-key"
+)
+
+// mockOIDCProvider はディスカバリー・JWKS・トークンエンドポイントを提供するテスト用のプロバイダー
+type mockOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// challenge は認可リクエストで受け取ったPKCEのチャレンジ
+	challenge string
+	// verifier はトークンエンドポイントで受け取ったコード検証値
+	verifier string
+	// nonce は認可リクエストで受け取ったnonce
+	nonce string
+	// kid はIDトークンのヘッダーに設定する鍵ID
+	kid string
+	// claims はIDトークンのクレームを書き換える
+	claims func(claims jwt.MapClaims)
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("鍵の生成に失敗しました: %v", err)
+	}
+
+	m := &mockOIDCProvider{t: t, key: key, kid: testOIDCKeyID}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.HandleFunc("/jwks", m.jwks)
+	mux.HandleFunc("/token", m.token)
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockOIDCProvider) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]string{
+		"issuer":                 m.server.URL,
+		"authorization_endpoint": m.server.URL + "/authorize",
+		"token_endpoint":         m.server.URL + "/token",
+		"jwks_uri":               m.server.URL + "/jwks",
+	})
+}
+
+func (m *mockOIDCProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": testOIDCKeyID,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+// token はPKCEのコード検証値を確認してIDトークンを発行する
+func (m *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	m.verifier = r.PostForm.Get("code_verifier")
+	sum := sha256.Sum256([]byte(m.verifier))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            testOIDCClientID,
+		"sub":            "subject-1",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "テストユーザー",
+		"nonce":          m.nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(5 * time.Minute).Unix(),
+	}
+	if m.claims != nil {
+		m.claims(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	idToken, err := token.SignedString(m.key)
+	if err != nil {
+		m.t.Errorf("IDトークンの署名に失敗しました: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"id_token": idToken, "token_type": "Bearer"})
+}
+
+// fakeOIDCStateRepo はメモリ上のOIDCStateRepository
+type fakeOIDCStateRepo struct {
+	states map[string]*models.OIDCLoginState
+}
+
+func (r *fakeOIDCStateRepo) Create(ctx context.Context, state *models.OIDCLoginState) error {
+	r.states[state.State] = state
+	return nil
+}
+
+func (r *fakeOIDCStateRepo) Consume(ctx context.Context, state string, now time.Time) (*models.OIDCLoginState, error) {
+	loginState, ok := r.states[state]
+	if !ok {
+		return nil, nil
+	}
+	delete(r.states, state)
+	if !now.Before(loginState.ExpiresAt) {
+		return nil, nil
+	}
+	return loginState, nil
+}
+
+// fakeUserIdentityRepo はメモリ上のUserIdentityRepository
+type fakeUserIdentityRepo struct {
+	identities []*models.UserIdentity
+}
+
+func (r *fakeUserIdentityRepo) Create(ctx context.Context, identity *models.UserIdentity) error {
+	identity.ID = uuid.New().String()
+	identity.CreatedAt = time.Now()
+	r.identities = append(r.identities, identity)
+	return nil
+}
+
+func (r *fakeUserIdentityRepo) GetBySubject(ctx context.Context, provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserIdentityRepo) GetByUserID(ctx context.Context, userID string) ([]*models.UserIdentity, error) {
+	var identities []*models.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *fakeUserIdentityRepo) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
+	return nil
+}
+
+func (r *fakeUserIdentityRepo) Delete(ctx context.Context, userID, provider string) (bool, error) {
+	for i, identity := range r.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			r.identities = append(r.identities[:i], r.identities[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeUserRepo はメモリ上のUserRepository
+type fakeUserRepo struct {
+	users []*models.User
+}
+
+func (r *fakeUserRepo) Create(ctx context.Context, user *models.User) error {
+	user.ID = uuid.New().String()
+	r.users = append(r.users, user)
+	return nil
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *models.User) error {
+	return nil
+}
+
+func (r *fakeUserRepo) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (r *fakeUserRepo) GetAll(ctx context.Context) ([]*models.User, error) {
+	return r.users, nil
+}
+
+// oidcTestEnv はテスト用のプロバイダーとOIDCServiceの組
+type oidcTestEnv struct {
+	provider   *mockOIDCProvider
+	service    OIDCService
+	identities *fakeUserIdentityRepo
+	users      *fakeUserRepo
+}
+
+func newOIDCTestEnv(t *testing.T) *oidcTestEnv {
+	t.Helper()
+
+	provider := newMockOIDCProvider(t)
+	env := &oidcTestEnv{
+		provider:   provider,
+		identities: &fakeUserIdentityRepo{},
+		users:      &fakeUserRepo{},
+	}
+	env.service = NewOIDCService(
+		env.identities,
+		&fakeOIDCStateRepo{states: map[string]*models.OIDCLoginState{}},
+		env.users,
+		[]*utils.OIDCProvider{utils.NewOIDCProvider("test", "Test", provider.server.URL, testOIDCClientID, "", []string{"openid", "email"})},
+		"http://localhost:3000/auth/callback",
+	)
+	return env
+}
+
+// start は認可リクエストを開始し、プロバイダーが受け取るチャレンジとnonceを記録する
+func (env *oidcTestEnv) start(t *testing.T, userID string) string {
+	t.Helper()
+
+	authorization, err := env.service.Start(context.Background(), "test", userID)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	u, err := url.Parse(authorization.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("認可URLが不正です: %v", err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge_method") != "S256" {
+		t.Fatalf("code_challenge_method = %q, want S256", query.Get("code_challenge_method"))
+	}
+	if query.Get("state") != authorization.State {
+		t.Fatalf("認可URLのstateが一致しません")
+	}
+	env.provider.challenge = query.Get("code_challenge")
+	env.provider.nonce = query.Get("nonce")
+
+	return authorization.State
+}
+
+func (env *oidcTestEnv) callback(state string) (*models.OIDCResult, error) {
+	return env.service.Callback(context.Background(), &models.OIDCCallback{State: state, Code: "code"})
+}
+
+func TestOIDCCallbackForwardsPKCEVerifier(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	state := env.start(t, "")
+
+	result, err := env.callback(state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if env.provider.verifier == "" {
+		t.Fatal("トークンエンドポイントにコード検証値が送られていません")
+	}
+	if !result.Created || result.User.Email != "user@example.com" {
+		t.Fatalf("新しいユーザーが登録されていません: %+v", result)
+	}
+	if len(env.identities.identities) != 1 {
+		t.Fatalf("連携の数 = %d, want 1", len(env.identities.identities))
+	}
+}
+
+func TestOIDCCallbackRejectsWrongVerifier(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	state := env.start(t, "")
+
+	// 別の認可リクエストのチャレンジではコード検証値が一致しない
+	_, otherChallenge, err := utils.GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.provider.challenge = otherChallenge
+
+	if _, err := env.callback(state); err != ErrOIDCVerificationFailed {
+		t.Fatalf("err = %v, want %v", err, ErrOIDCVerificationFailed)
+	}
+}
+
+func TestOIDCCallbackRejectsReusedState(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	state := env.start(t, "")
+
+	if _, err := env.callback(state); err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if _, err := env.callback(state); err != ErrOIDCStateInvalid {
+		t.Fatalf("err = %v, want %v", err, ErrOIDCStateInvalid)
+	}
+}
+
+func TestOIDCCallbackRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		kid    string
+		claims func(claims jwt.MapClaims)
+	}{
+		{
+			name:   "nonceが一致しない",
+			claims: func(claims jwt.MapClaims) { claims["nonce"] = "other-nonce" },
+		},
+		{
+			name:   "nonceがない",
+			claims: func(claims jwt.MapClaims) { delete(claims, "nonce") },
+		},
+		{
+			name:   "対象者が一致しない",
+			claims: func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+		},
+		{
+			name: "複数の対象者でazpが一致しない",
+			claims: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{testOIDCClientID, "other-client"}
+				claims["azp"] = "other-client"
+			},
+		},
+		{
+			name:   "発行者が一致しない",
+			claims: func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		},
+		{
+			name:   "有効期限切れ",
+			claims: func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+		},
+		{
+			name:   "有効期限がない",
+			claims: func(claims jwt.MapClaims) { delete(claims, "exp") },
+		},
+		{
+			name: "不明な鍵ID",
+			kid:  "unknown-key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t)
+			env.provider.claims = tt.claims
+			if tt.kid != "" {
+				env.provider.kid = tt.kid
+			}
+			state := env.start(t, "")
+
+			if _, err := env.callback(state); err != ErrOIDCVerificationFailed {
+				t.Fatalf("err = %v, want %v", err, ErrOIDCVerificationFailed)
+			}
+			if len(env.users.users) != 0 || len(env.identities.identities) != 0 {
+				t.Fatal("検証に失敗したIDトークンでユーザーまたは連携が作成されました")
+			}
+		})
+	}
+}
+
+func TestOIDCCallbackRefusesToLinkUnverifiedEmail(t *testing.T) {
+	for _, verified := range []interface{}{false, "false", nil} {
+		env := newOIDCTestEnv(t)
+		existing := &models.User{Email: "user@example.com", Name: "既存ユーザー", Password: "hashed", Role: "user"}
+		env.users.Create(context.Background(), existing)
+
+		env.provider.claims = func(claims jwt.MapClaims) {
+			if verified == nil {
+				delete(claims, "email_verified")
+				return
+			}
+			claims["email_verified"] = verified
+		}
+		state := env.start(t, "")
+
+		if _, err := env.callback(state); err != ErrOIDCEmailUnverified {
+			t.Fatalf("email_verified=%v: err = %v, want %v", verified, err, ErrOIDCEmailUnverified)
+		}
+		if len(env.identities.identities) != 0 {
+			t.Fatalf("email_verified=%v: 確認されていないメールアドレスで既存のユーザーに連携されました", verified)
+		}
+	}
+}
+
+func TestOIDCCallbackLinksVerifiedEmail(t *testing.T) {
+	env := newOIDCTestEnv(t)
+	existing := &models.User{Email: "user@example.com", Name: "既存ユーザー", Password: "hashed", Role: "user"}
+	env.users.Create(context.Background(), existing)
+	state := env.start(t, "")
+
+	result, err := env.callback(state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+	if result.Created || result.User.ID != existing.ID {
+		t.Fatalf("既存のユーザーに連携されていません: %+v", result)
+	}
+}
+
+func TestOIDCCallbackRejectsIdentityLinkedToOtherUser(t *testing.T) {
+	env := newOIDCTestEnv(t)
+
+	// 外部アカウントで登録したユーザー
+	state := env.start(t, "")
+	owner, err := env.callback(state)
+	if err != nil {
+		t.Fatalf("Callback: %v", err)
+	}
+
+	other := &models.User{Email: "other@example.com", Name: "別のユーザー", Password: "hashed", Role: "user"}
+	env.users.Create(context.Background(), other)
+	state = env.start(t, other.ID)
+
+	if _, err := env.callback(state); err != ErrIdentityLinkedToOther {
+		t.Fatalf("err = %v, want %v", err, ErrIdentityLinkedToOther)
+	}
+	identity, _ := env.identities.GetBySubject(context.Background(), "test", "subject-1")
+	if identity == nil || identity.UserID != owner.User.ID {
+		t.Fatal("外部アカウントの連携先が変わりました")
+	}
+}
@@ -0,0 +1,300 @@
+// backend/services/organization_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+// 組織の操作で呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrOrganizationNotFound       = errors.New("組織が見つかりません")
+	ErrOrganizationNameRequired   = errors.New("組織名を入力してください")
+	ErrOrganizationForbidden      = errors.New("この組織を管理する権限がありません")
+	ErrOrganizationOwnerRequired  = errors.New("この操作は組織の所有者のみが行えます")
+	ErrOrganizationHasMaps        = errors.New("マップが残っている組織は削除できません。先にマップを移管または削除してください")
+	ErrOrganizationUserNotFound   = errors.New("指定したメールアドレスのユーザーが見つかりません")
+	ErrOrganizationMemberExists   = errors.New("このユーザーは既に組織のメンバーです")
+	ErrOrganizationMemberNotFound = errors.New("組織のメンバーが見つかりません")
+	ErrOrganizationLastOwner      = errors.New("組織には所有者が1人以上必要です")
+)
+
+// OrganizationService は組織とメンバーに関する操作を提供するインターフェース
+type OrganizationService interface {
+	Create(ctx context.Context, userID string, req *models.OrganizationCreate) (*models.Organization, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.Organization, error)
+	Get(ctx context.Context, userID, id string) (*models.OrganizationDetail, error)
+	Update(ctx context.Context, userID, id string, req *models.OrganizationUpdate) (*models.Organization, error)
+	Delete(ctx context.Context, userID, id string) error
+	AddMember(ctx context.Context, userID, id string, req *models.OrganizationMemberAdd) (*models.OrganizationMember, error)
+	UpdateMember(ctx context.Context, userID, id, memberID string, req *models.OrganizationMemberUpdate) (*models.OrganizationMember, error)
+	RemoveMember(ctx context.Context, userID, id, memberID string) error
+}
+
+// DefaultOrganizationService はOrganizationServiceの実装
+type DefaultOrganizationService struct {
+	orgRepo  repositories.OrganizationRepository
+	userRepo repositories.UserRepository
+}
+
+// NewOrganizationService は新しいOrganizationServiceを作成する
+func NewOrganizationService(
+	orgRepo repositories.OrganizationRepository,
+	userRepo repositories.UserRepository,
+) OrganizationService {
+	return &DefaultOrganizationService{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+	}
+}
+
+// Create は新しい組織を作成する（作成したユーザーが所有者になる）
+func (s *DefaultOrganizationService) Create(ctx context.Context, userID string, req *models.OrganizationCreate) (*models.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrOrganizationNameRequired
+	}
+
+	org := &models.Organization{
+		ID:   uuid.New().String(),
+		Name: name,
+	}
+	if err := s.orgRepo.Create(ctx, org, userID); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// GetByUserID はユーザーが所属する組織の一覧を取得する
+func (s *DefaultOrganizationService) GetByUserID(ctx context.Context, userID string) ([]*models.Organization, error) {
+	orgs, err := s.orgRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if orgs == nil {
+		orgs = []*models.Organization{}
+	}
+	return orgs, nil
+}
+
+// Get は組織とメンバーの一覧を取得する（メンバーのみ）
+func (s *DefaultOrganizationService) Get(ctx context.Context, userID, id string) (*models.OrganizationDetail, error) {
+	org, err := s.organizationForMember(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.GetMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrganizationDetail{Organization: org, Members: members}, nil
+}
+
+// Update は組織の情報を更新する（所有者・管理者のみ）
+func (s *DefaultOrganizationService) Update(ctx context.Context, userID, id string, req *models.OrganizationUpdate) (*models.Organization, error) {
+	org, err := s.organizationForManager(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrOrganizationNameRequired
+	}
+	org.Name = name
+
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// Delete は組織を削除する（所有者のみ）
+// マップが残っている組織は削除できない
+func (s *DefaultOrganizationService) Delete(ctx context.Context, userID, id string) error {
+	org, err := s.organizationForMember(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if org.Role != models.OrganizationRoleOwner {
+		return ErrOrganizationOwnerRequired
+	}
+
+	count, err := s.orgRepo.CountMaps(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrOrganizationHasMaps
+	}
+
+	return s.orgRepo.Delete(ctx, id)
+}
+
+// AddMember はメールアドレスで指定したユーザーを組織に追加する（所有者・管理者のみ）
+// 所有者として追加できるのは所有者のみ
+func (s *DefaultOrganizationService) AddMember(ctx context.Context, userID, id string, req *models.OrganizationMemberAdd) (*models.OrganizationMember, error) {
+	org, err := s.organizationForManager(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Role == models.OrganizationRoleOwner && org.Role != models.OrganizationRoleOwner {
+		return nil, ErrOrganizationOwnerRequired
+	}
+
+	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrOrganizationUserNotFound
+	}
+
+	existing, err := s.orgRepo.GetMember(ctx, id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrOrganizationMemberExists
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: id,
+		UserID:         user.ID,
+		Name:           user.Name,
+		Email:          user.Email,
+		Role:           req.Role,
+	}
+	if err := s.orgRepo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// UpdateMember はメンバーの役割を変更する（所有者・管理者のみ）
+// 所有者の役割の変更と所有者への変更は所有者のみが行える
+func (s *DefaultOrganizationService) UpdateMember(ctx context.Context, userID, id, memberID string, req *models.OrganizationMemberUpdate) (*models.OrganizationMember, error) {
+	org, err := s.organizationForManager(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.orgRepo.GetMember(ctx, id, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrOrganizationMemberNotFound
+	}
+	if member.Role == req.Role {
+		return member, nil
+	}
+
+	if member.Role == models.OrganizationRoleOwner || req.Role == models.OrganizationRoleOwner {
+		if org.Role != models.OrganizationRoleOwner {
+			return nil, ErrOrganizationOwnerRequired
+		}
+	}
+	if member.Role == models.OrganizationRoleOwner {
+		if err := s.checkRemainingOwner(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.orgRepo.UpdateMemberRole(ctx, id, memberID, req.Role); err != nil {
+		return nil, err
+	}
+
+	member.Role = req.Role
+	return member, nil
+}
+
+// RemoveMember はメンバーを組織から外す
+// 自分自身はいつでも退会でき、他のメンバーを外せるのは所有者・管理者（所有者を外せるのは所有者）のみ
+func (s *DefaultOrganizationService) RemoveMember(ctx context.Context, userID, id, memberID string) error {
+	org, err := s.organizationForMember(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.orgRepo.GetMember(ctx, id, memberID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return ErrOrganizationMemberNotFound
+	}
+
+	if memberID != userID {
+		if !models.IsOrganizationManager(org.Role) {
+			return ErrOrganizationForbidden
+		}
+		if member.Role == models.OrganizationRoleOwner && org.Role != models.OrganizationRoleOwner {
+			return ErrOrganizationOwnerRequired
+		}
+	}
+	if member.Role == models.OrganizationRoleOwner {
+		if err := s.checkRemainingOwner(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return s.orgRepo.RemoveMember(ctx, id, memberID)
+}
+
+// organizationForMember はユーザーがメンバーである組織を、ユーザーの役割とともに取得する
+// メンバーでない場合は組織の存在を明かさない
+func (s *DefaultOrganizationService) organizationForMember(ctx context.Context, userID, id string) (*models.Organization, error) {
+	member, err := s.orgRepo.GetMember(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrOrganizationNotFound
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if org == nil {
+		return nil, ErrOrganizationNotFound
+	}
+
+	org.Role = member.Role
+	return org, nil
+}
+
+// organizationForManager はユーザーが所有者・管理者である組織を取得する
+func (s *DefaultOrganizationService) organizationForManager(ctx context.Context, userID, id string) (*models.Organization, error) {
+	org, err := s.organizationForMember(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !models.IsOrganizationManager(org.Role) {
+		return nil, ErrOrganizationForbidden
+	}
+	return org, nil
+}
+
+// checkRemainingOwner は所有者を1人減らしても所有者が残るか確認する
+func (s *DefaultOrganizationService) checkRemainingOwner(ctx context.Context, id string) error {
+	owners, err := s.orgRepo.CountOwners(ctx, id)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrOrganizationLastOwner
+	}
+	return nil
+}
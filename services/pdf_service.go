@@ -0,0 +1,372 @@
+// backend/services/pdf_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// PDF生成に関する上限値
+const (
+	pdfQueueSize     = 16
+	pdfMaxImageBytes = 20 << 20
+)
+
+// pdfCleanupInterval は保存期間を過ぎたPDFを削除する間隔
+const pdfCleanupInterval = time.Hour
+
+// cloudinaryImageHost はフロア画像の取得を許可するホスト
+const cloudinaryImageHost = "res.cloudinary.com"
+
+// PDFService は印刷用パンフレットPDFの生成を提供するインターフェース
+type PDFService interface {
+	Enqueue(ctx context.Context, userID, mapID string) (*models.PDFJob, error)
+	GetJob(ctx context.Context, userID, jobID string) (*models.PDFJob, error)
+}
+
+// DefaultPDFService はPDFServiceの実装
+// t2.microでも負荷が偏らないよう、ジョブは1つのワーカーで順番に処理する
+type DefaultPDFService struct {
+	jobRepo    repositories.PDFJobRepository
+	mapRepo    repositories.MapRepository
+	floorRepo  repositories.FloorRepository
+	pinRepo    repositories.PinRepository
+	cloudName  string
+	fontPath   string
+	outputDir  string
+	retention  time.Duration
+	httpClient *http.Client
+	queue      chan string
+}
+
+// NewPDFService は新しいPDFServiceを作成し、バックグラウンドワーカーと保存期間を過ぎたPDFの削除を起動する
+func NewPDFService(
+	jobRepo repositories.PDFJobRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	cloudName string,
+	fontPath string,
+	outputDir string,
+	retention time.Duration,
+) PDFService {
+	s := &DefaultPDFService{
+		jobRepo:   jobRepo,
+		mapRepo:   mapRepo,
+		floorRepo: floorRepo,
+		pinRepo:   pinRepo,
+		cloudName: cloudName,
+		fontPath:  fontPath,
+		outputDir: outputDir,
+		retention: retention,
+		queue:     make(chan string, pdfQueueSize),
+	}
+	s.httpClient = &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: 10 * time.Second, Control: refusePrivateAddress}).DialContext,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 || !s.allowedImageURL(req.URL) {
+				return errors.New("許可されていない画像URLです")
+			}
+			return nil
+		},
+	}
+
+	if _, err := os.Stat(fontPath); err != nil {
+		log.Printf("PDF生成用のフォントが見つかりません。PDF_FONT_PATHを設定してください: %v", err)
+	}
+
+	go s.worker()
+	if retention > 0 {
+		go s.cleaner()
+	}
+
+	return s
+}
+
+// Enqueue はPDF生成ジョブを登録する
+func (s *DefaultPDFService) Enqueue(ctx context.Context, userID, mapID string) (*models.PDFJob, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップにアクセスする権限がありません")
+	}
+
+	job := &models.PDFJob{
+		ID:     uuid.New().String(),
+		MapID:  mapID,
+		UserID: userID,
+		Status: models.JobStatusQueued,
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queue <- job.ID:
+	default:
+		err := errors.New("PDF生成が混み合っています。しばらくしてから再度お試しください")
+		s.finish(job, err)
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetJob はPDF生成ジョブを取得する
+func (s *DefaultPDFService) GetJob(ctx context.Context, userID, jobID string) (*models.PDFJob, error) {
+	job, err := s.jobRepo.GetByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.UserID != userID {
+		return nil, nil
+	}
+
+	return job, nil
+}
+
+// worker はキューに積まれたジョブを順番に処理する
+func (s *DefaultPDFService) worker() {
+	for jobID := range s.queue {
+		ctx := context.Background()
+
+		job, err := s.jobRepo.GetByID(ctx, jobID)
+		if err != nil || job == nil {
+			log.Printf("PDF生成ジョブの取得に失敗しました: %s %v", jobID, err)
+			continue
+		}
+
+		job.Status = models.JobStatusProcessing
+		if err := s.jobRepo.Update(ctx, job); err != nil {
+			log.Printf("PDF生成ジョブの更新に失敗しました: %s %v", jobID, err)
+			continue
+		}
+
+		s.finish(job, s.generate(ctx, job))
+	}
+}
+
+// cleaner は一定間隔で保存期間を過ぎたPDFとジョブを削除する
+func (s *DefaultPDFService) cleaner() {
+	ticker := time.NewTicker(pdfCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		s.cleanup(context.Background(), time.Now().Add(-s.retention))
+		<-ticker.C
+	}
+}
+
+// cleanup は指定した時刻より前に生成したPDFファイルとジョブを削除する
+// ジョブが残っていないファイル（途中で停止した場合など）も削除する
+func (s *DefaultPDFService) cleanup(ctx context.Context, before time.Time) {
+	deleted, err := s.jobRepo.DeleteBefore(ctx, before)
+	if err != nil {
+		log.Printf("PDF生成ジョブの削除に失敗しました: %v", err)
+	} else if deleted > 0 {
+		log.Printf("%d件のPDF生成ジョブを削除しました", deleted)
+	}
+
+	entries, err := os.ReadDir(s.outputDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("PDFの保存先の読み込みに失敗しました: %v", err)
+		}
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".pdf" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(s.outputDir, entry.Name())); err != nil {
+			log.Printf("PDFの削除に失敗しました: %s %v", entry.Name(), err)
+		}
+	}
+}
+
+// finish はジョブの結果を保存する
+func (s *DefaultPDFService) finish(job *models.PDFJob, err error) {
+	now := time.Now()
+	job.CompletedAt = &now
+	job.Status = models.JobStatusCompleted
+	if err != nil {
+		job.Status = models.JobStatusFailed
+		job.Error = err.Error()
+	}
+
+	if err := s.jobRepo.Update(context.Background(), job); err != nil {
+		log.Printf("PDF生成ジョブの更新に失敗しました: %s %v", job.ID, err)
+	}
+}
+
+// generate はマップのパンフレットPDFを生成してファイルに保存する
+func (s *DefaultPDFService) generate(ctx context.Context, job *models.PDFJob) error {
+	if _, err := os.Stat(s.fontPath); err != nil {
+		return errors.New("日本語フォントが設定されていません")
+	}
+
+	map_, err := s.mapRepo.GetByID(ctx, job.MapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+
+	floors, err := s.floorRepo.GetByMapID(ctx, map_.ID)
+	if err != nil {
+		return err
+	}
+
+	floorIDs := make([]string, len(floors))
+	for i, floor := range floors {
+		floorIDs[i] = floor.ID
+	}
+	pins, err := s.pinRepo.GetByFloorIDs(ctx, floorIDs)
+	if err != nil {
+		return err
+	}
+
+	// フロア順・作成順で通し番号を振る
+	pamphletFloors := make([]utils.PamphletFloor, len(floors))
+	var entries []utils.PamphletEntry
+	number := 0
+	for i, floor := range floors {
+		pamphletFloors[i].Name = floor.Name
+		if floor.ImageURL != "" {
+			if pamphletFloors[i].Image, err = s.fetchImage(ctx, floor.ImageURL); err != nil {
+				return newMessageError("%sの画像の取得に失敗しました", floor.Name)
+			}
+		}
+
+		for _, pin := range pins {
+			if pin.FloorID != floor.ID {
+				continue
+			}
+			number++
+			pamphletFloors[i].Markers = append(pamphletFloors[i].Markers, utils.PamphletMarker{
+				Number: number,
+				X:      pin.XPosition,
+				Y:      pin.YPosition,
+			})
+			entries = append(entries, utils.PamphletEntry{
+				Number:      number,
+				Title:       pin.Title,
+				Description: pin.Description,
+				FloorName:   floor.Name,
+			})
+		}
+	}
+
+	if err := os.MkdirAll(s.outputDir, 0o755); err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.outputDir, job.ID+".pdf")
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := utils.RenderPamphlet(file, s.fontPath, map_.Title, pamphletFloors, entries); err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	job.FilePath = path
+	return nil
+}
+
+// fetchImage はフロア画像を取得する
+// 取得できるのは自分のCloudinaryアカウントにアップロードされた画像のみで、
+// CloudinaryのURLはPDFに埋め込めるJPEG形式で配信させる
+func (s *DefaultPDFService) fetchImage(ctx context.Context, imageURL string) ([]byte, error) {
+	u, err := url.Parse(imageURL)
+	if err != nil || !s.allowedImageURL(u) {
+		return nil, errors.New("許可されていない画像URLです")
+	}
+	imageURL = strings.Replace(u.String(), "/image/upload/", "/image/upload/f_jpg/", 1)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newMessageError("画像を取得できませんでした: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, pdfMaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > pdfMaxImageBytes {
+		return nil, errors.New("画像サイズが大きすぎます")
+	}
+
+	return data, nil
+}
+
+// allowedImageURL はURLが自分のCloudinaryアカウントの画像か確認する
+func (s *DefaultPDFService) allowedImageURL(u *url.URL) bool {
+	if s.cloudName == "" || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	if u.Host != cloudinaryImageHost {
+		return false
+	}
+	return strings.HasPrefix(u.Path, "/"+s.cloudName+"/image/upload/")
+}
+
+// refusePrivateAddress はループバック・プライベート・リンクローカルのアドレスへの接続を拒否する
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return newMessageError("許可されていない接続先です: %s", address)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// backend/services/personal_access_token_service.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+const (
+	// maxPersonalAccessTokens は1人のユーザーが持てる有効なアクセストークンの数
+	maxPersonalAccessTokens = 20
+	// accessTokenTouchInterval は最終使用日時を更新する間隔
+	accessTokenTouchInterval = time.Minute
+	// accessTokenPrefixLength は一覧に表示するトークンの先頭部分の長さ
+	accessTokenPrefixLength = len(models.PersonalAccessTokenPrefix) + 6
+)
+
+// 個人用アクセストークンの操作で呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrAccessTokenNameRequired = errors.New("アクセストークンの名前を入力してください")
+	ErrAccessTokenNotFound     = errors.New("アクセストークンが見つかりません")
+	ErrAccessTokenLimit        = errors.New("作成できるアクセストークンの上限に達しています")
+)
+
+// PersonalAccessTokenService は個人用アクセストークンに関する操作を提供するインターフェース
+type PersonalAccessTokenService interface {
+	Create(ctx context.Context, userID string, req *models.PersonalAccessTokenCreate) (*models.PersonalAccessTokenCreated, error)
+	GetByUserID(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error)
+	Revoke(ctx context.Context, userID, id string) error
+	Authenticate(ctx context.Context, token string) (*models.AccessTokenPrincipal, error)
+}
+
+// DefaultPersonalAccessTokenService はPersonalAccessTokenServiceの実装
+type DefaultPersonalAccessTokenService struct {
+	tokenRepo repositories.PersonalAccessTokenRepository
+	userRepo  repositories.UserRepository
+}
+
+// NewPersonalAccessTokenService は新しいPersonalAccessTokenServiceを作成する
+func NewPersonalAccessTokenService(
+	tokenRepo repositories.PersonalAccessTokenRepository,
+	userRepo repositories.UserRepository,
+) PersonalAccessTokenService {
+	return &DefaultPersonalAccessTokenService{
+		tokenRepo: tokenRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Create は新しい個人用アクセストークンを作成する
+// トークン本体はハッシュ化して保存するため、返り値でしか取得できない
+func (s *DefaultPersonalAccessTokenService) Create(ctx context.Context, userID string, req *models.PersonalAccessTokenCreate) (*models.PersonalAccessTokenCreated, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, ErrAccessTokenNameRequired
+	}
+
+	now := time.Now()
+	count, err := s.tokenRepo.CountActive(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPersonalAccessTokens {
+		return nil, ErrAccessTokenLimit
+	}
+
+	secret, err := generateToken(32)
+	if err != nil {
+		return nil, err
+	}
+	raw := models.PersonalAccessTokenPrefix + secret
+
+	token := &models.PersonalAccessToken{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Name:        name,
+		TokenHash:   hashAccessToken(raw),
+		TokenPrefix: raw[:accessTokenPrefixLength],
+		Scopes:      normalizeScopes(req.Scopes),
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := now.AddDate(0, 0, *req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, err
+	}
+
+	return &models.PersonalAccessTokenCreated{PersonalAccessToken: token, Token: raw}, nil
+}
+
+// GetByUserID はユーザーの個人用アクセストークン一覧を取得する
+func (s *DefaultPersonalAccessTokenService) GetByUserID(ctx context.Context, userID string) ([]*models.PersonalAccessToken, error) {
+	tokens, err := s.tokenRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tokens == nil {
+		tokens = []*models.PersonalAccessToken{}
+	}
+	return tokens, nil
+}
+
+// Revoke はユーザーの個人用アクセストークンを無効化する
+func (s *DefaultPersonalAccessTokenService) Revoke(ctx context.Context, userID, id string) error {
+	revoked, err := s.tokenRepo.Revoke(ctx, userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// Authenticate は個人用アクセストークンを検証し、トークンの所有者と権限を返す
+// 無効化・期限切れのトークンや、所有者が削除されたトークンはnilを返す
+func (s *DefaultPersonalAccessTokenService) Authenticate(ctx context.Context, raw string) (*models.AccessTokenPrincipal, error) {
+	if !strings.HasPrefix(raw, models.PersonalAccessTokenPrefix) {
+		return nil, nil
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, hashAccessToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if token == nil || token.RevokedAt != nil || (token.ExpiresAt != nil && !token.ExpiresAt.After(now)) {
+		return nil, nil
+	}
+
+	// 権限は作成後に変わり得るため、ロールは毎回ユーザーから取得する
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	if err := s.tokenRepo.Touch(ctx, token.ID, now, accessTokenTouchInterval); err != nil {
+		log.Printf("アクセストークンの最終使用日時の更新に失敗しました: %v", err)
+	}
+
+	return &models.AccessTokenPrincipal{
+		TokenID: token.ID,
+		UserID:  user.ID,
+		Role:    user.Role,
+		Scopes:  token.Scopes,
+	}, nil
+}
+
+// normalizeScopes は権限の重複を取り除き、定義順に並べる
+func normalizeScopes(scopes []string) []string {
+	var normalized []string
+	for _, scope := range models.PersonalAccessTokenScopes {
+		for _, requested := range scopes {
+			if requested == scope {
+				normalized = append(normalized, scope)
+				break
+			}
+		}
+	}
+	return normalized
+}
+
+// hashAccessToken はアクセストークンをハッシュ化する
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
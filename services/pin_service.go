@@ -26,9 +26,13 @@ type PinService interface {
 
 // DefaultPinService はPinServiceの実装
 type DefaultPinService struct {
-	pinRepo   repositories.PinRepository
-	floorRepo repositories.FloorRepository
-	mapRepo   repositories.MapRepository
+	pinRepo          repositories.PinRepository
+	floorRepo        repositories.FloorRepository
+	mapRepo          repositories.MapRepository
+	publicEditorRepo repositories.PublicEditorRepository
+	permissionsRepo  repositories.PublicEditPermissionsRepository
+	inviteRepo       repositories.PublicEditInviteRepository
+	filter           *contentFilter
 }
 
 // NewPinService は新しいPinServiceを作成する
@@ -36,11 +40,23 @@ func NewPinService(
 	pinRepo repositories.PinRepository,
 	floorRepo repositories.FloorRepository,
 	mapRepo repositories.MapRepository,
+	publicEditorRepo repositories.PublicEditorRepository,
+	permissionsRepo repositories.PublicEditPermissionsRepository,
+	inviteRepo repositories.PublicEditInviteRepository,
+	filterRepo repositories.ContentFilterRepository,
+	reportRepo repositories.ContentReportRepository,
 ) PinService {
 	return &DefaultPinService{
-		pinRepo:   pinRepo,
-		floorRepo: floorRepo,
-		mapRepo:   mapRepo,
+		pinRepo:          pinRepo,
+		floorRepo:        floorRepo,
+		mapRepo:          mapRepo,
+		publicEditorRepo: publicEditorRepo,
+		permissionsRepo:  permissionsRepo,
+		inviteRepo:       inviteRepo,
+		filter: &contentFilter{
+			filterRepo: filterRepo,
+			reportRepo: reportRepo,
+		},
 	}
 }
 
@@ -65,7 +81,11 @@ func (s *DefaultPinService) Create(ctx context.Context, userID string, input *mo
 	}
 
 	// 権限チェック
-	if map_.UserID != userID {
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
 		return nil, errors.New("このマップを編集する権限がありません")
 	}
 
@@ -75,6 +95,7 @@ func (s *DefaultPinService) Create(ctx context.Context, userID string, input *mo
 		FloorID:        input.FloorID,
 		Title:          input.Title,
 		Description:    input.Description,
+		Category:       input.Category,
 		XPosition:      input.XPosition,
 		YPosition:      input.YPosition,
 		ImageURL:       input.ImageURL,
@@ -108,8 +129,9 @@ func (s *DefaultPinService) CreatePublic(ctx context.Context, input *models.PinC
 	if err != nil {
 		return nil, err
 	}
-	if map_ == nil {
-		return nil, errors.New("マップが見つかりません")
+	// 公開中でないマップは公開編集者には存在しないものとして扱う
+	if map_ == nil || !isPublished(map_, time.Now()) {
+		return nil, ErrMapNotFound
 	}
 
 	if !map_.IsPubliclyEditable {
@@ -121,12 +143,37 @@ func (s *DefaultPinService) CreatePublic(ctx context.Context, input *models.PinC
 		return nil, errors.New("編集者情報が必要です")
 	}
 
+	// 編集者の登録と編集禁止・権限を確認
+	permissions, err := s.checkPublicEditor(ctx, input.EditorID, map_.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !permissions.CanCreate {
+		return nil, errors.New("このマップではピンを作成する権限がありません")
+	}
+	if input.ImageURL != "" && !permissions.CanUploadImages {
+		return nil, errors.New("このマップでは画像を追加する権限がありません")
+	}
+
+	// 内容フィルターで入力を検査する
+	violation, err := s.filter.check(
+		ctx,
+		map_.ID,
+		models.FilterField{Name: "title", Kind: models.FilterFieldTitle, Value: input.Title},
+		models.FilterField{Name: "description", Kind: models.FilterFieldDescription, Value: input.Description},
+		models.FilterField{Name: "editor_nickname", Kind: models.FilterFieldNickname, Value: input.EditorNickname},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// 新しいピンを作成
 	pin := &models.Pin{
 		ID:             uuid.New().String(),
 		FloorID:        input.FloorID,
 		Title:          input.Title,
 		Description:    input.Description,
+		Category:       input.Category,
 		XPosition:      input.XPosition,
 		YPosition:      input.YPosition,
 		ImageURL:       input.ImageURL,
@@ -136,6 +183,14 @@ func (s *DefaultPinService) CreatePublic(ctx context.Context, input *models.PinC
 		UpdatedAt:      time.Now(),
 	}
 
+	// 確認待ちにする場合は非表示にして所有者の通報一覧に載せる
+	// 公開APIに表示されないよう、ピンを保存する前に非表示にしておく
+	if violation != nil {
+		if err := s.filter.moderate(ctx, map_.ID, models.ReportTargetPin, pin.ID, violation); err != nil {
+			return nil, err
+		}
+	}
+
 	// リポジトリに保存
 	if err := s.pinRepo.Create(ctx, pin); err != nil {
 		return nil, err
@@ -189,7 +244,11 @@ func (s *DefaultPinService) Update(ctx context.Context, userID string, id string
 	}
 
 	// 権限チェック
-	if map_.UserID != userID {
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
 		return nil, errors.New("このピンを編集する権限がありません")
 	}
 
@@ -200,6 +259,9 @@ func (s *DefaultPinService) Update(ctx context.Context, userID string, id string
 	if input.Description != "" {
 		pin.Description = input.Description
 	}
+	if input.Category != "" {
+		pin.Category = input.Category
+	}
 	if input.ImageURL != "" {
 		pin.ImageURL = input.ImageURL
 	}
@@ -224,11 +286,6 @@ func (s *DefaultPinService) UpdatePublic(ctx context.Context, editorID string, i
 		return nil, errors.New("ピンが見つかりません")
 	}
 
-	// 編集者IDを確認
-	if pin.EditorID != editorID {
-		return nil, errors.New("このピンを編集する権限がありません")
-	}
-
 	// フロアが存在するか確認
 	floor, err := s.floorRepo.GetByID(ctx, pin.FloorID)
 	if err != nil {
@@ -243,14 +300,49 @@ func (s *DefaultPinService) UpdatePublic(ctx context.Context, editorID string, i
 	if err != nil {
 		return nil, err
 	}
-	if map_ == nil {
-		return nil, errors.New("マップが見つかりません")
+	// 公開中でないマップは公開編集者には存在しないものとして扱う
+	if map_ == nil || !isPublished(map_, time.Now()) {
+		return nil, ErrMapNotFound
 	}
 
 	if !map_.IsPubliclyEditable {
 		return nil, errors.New("このマップは公開編集が許可されていません")
 	}
 
+	permissions, err := s.checkPublicEditor(ctx, editorID, map_.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 他の編集者のピンは権限がある場合のみ編集できる
+	if pin.EditorID != editorID && !permissions.CanEditOthers {
+		return nil, errors.New("このピンを編集する権限がありません")
+	}
+	if input.ImageURL != "" && !permissions.CanUploadImages {
+		return nil, errors.New("このマップでは画像を追加する権限がありません")
+	}
+
+	// 変更する項目を内容フィルターで検査する
+	var fields []models.FilterField
+	if input.Title != "" {
+		fields = append(fields, models.FilterField{Name: "title", Kind: models.FilterFieldTitle, Value: input.Title})
+	}
+	if input.Description != "" {
+		fields = append(fields, models.FilterField{Name: "description", Kind: models.FilterFieldDescription, Value: input.Description})
+	}
+	violation, err := s.filter.check(ctx, map_.ID, fields...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 確認待ちにする場合は非表示にして所有者の通報一覧に載せる
+	// 変更後の内容が公開APIに表示されないよう、更新する前に非表示にしておく
+	if violation != nil {
+		if err := s.filter.moderate(ctx, map_.ID, models.ReportTargetPin, pin.ID, violation); err != nil {
+			return nil, err
+		}
+	}
+
 	// ピン情報を更新
 	if input.Title != "" {
 		pin.Title = input.Title
@@ -258,6 +350,9 @@ func (s *DefaultPinService) UpdatePublic(ctx context.Context, editorID string, i
 	if input.Description != "" {
 		pin.Description = input.Description
 	}
+	if input.Category != "" {
+		pin.Category = input.Category
+	}
 	if input.ImageURL != "" {
 		pin.ImageURL = input.ImageURL
 	}
@@ -301,7 +396,11 @@ func (s *DefaultPinService) Delete(ctx context.Context, userID string, id string
 	}
 
 	// 権限チェック
-	if map_.UserID != userID {
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
 		return errors.New("このピンを削除する権限がありません")
 	}
 
@@ -320,11 +419,6 @@ func (s *DefaultPinService) DeletePublic(ctx context.Context, editorID string, i
 		return errors.New("ピンが見つかりません")
 	}
 
-	// 編集者IDを確認
-	if pin.EditorID != editorID {
-		return errors.New("このピンを削除する権限がありません")
-	}
-
 	// フロアが存在するか確認
 	floor, err := s.floorRepo.GetByID(ctx, pin.FloorID)
 	if err != nil {
@@ -339,14 +433,43 @@ func (s *DefaultPinService) DeletePublic(ctx context.Context, editorID string, i
 	if err != nil {
 		return err
 	}
-	if map_ == nil {
-		return errors.New("マップが見つかりません")
+	// 公開中でないマップは公開編集者には存在しないものとして扱う
+	if map_ == nil || !isPublished(map_, time.Now()) {
+		return ErrMapNotFound
 	}
 
 	if !map_.IsPubliclyEditable {
 		return errors.New("このマップは公開編集が許可されていません")
 	}
 
+	permissions, err := s.checkPublicEditor(ctx, editorID, map_.ID)
+	if err != nil {
+		return err
+	}
+
+	// 他の編集者のピンは権限がある場合のみ削除できる
+	if !permissions.CanDelete || (pin.EditorID != editorID && !permissions.CanEditOthers) {
+		return errors.New("このピンを削除する権限がありません")
+	}
+
 	// ピンを削除
 	return s.pinRepo.Delete(ctx, id)
 }
+
+// checkPublicEditor は公開編集者がマップを編集できるか確認し、編集者に適用される権限を返す
+func (s *DefaultPinService) checkPublicEditor(ctx context.Context, editorID, mapID string) (*models.PublicEditPermissions, error) {
+	editor, err := s.publicEditorRepo.GetByID(ctx, editorID)
+	if err != nil {
+		return nil, err
+	}
+	if editor == nil || editor.MapID != mapID {
+		return nil, errors.New("編集者が見つかりません")
+	}
+	if editor.BannedAt != nil {
+		return nil, errors.New("この編集者は編集を禁止されています")
+	}
+	if editor.RevokedAt != nil {
+		return nil, errors.New("この編集者の編集権限は取り消されています")
+	}
+	return editorPermissions(ctx, s.permissionsRepo, s.inviteRepo, editor)
+}
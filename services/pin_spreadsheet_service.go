@@ -0,0 +1,301 @@
+// backend/services/pin_spreadsheet_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// pinColumnAliases はインポート時に受け付けるヘッダー名の別名
+var pinColumnAliases = map[string]string{
+	"外部キー":       "external_key",
+	"キー":         "external_key",
+	"フロア":        "floor",
+	"floor_id":   "floor",
+	"floor_name": "floor",
+	"タイトル":       "title",
+	"名前":         "title",
+	"説明":         "description",
+	"カテゴリー":      "category",
+	"カテゴリ":       "category",
+	"x":          "x_position",
+	"x座標":        "x_position",
+	"y":          "y_position",
+	"y座標":        "y_position",
+	"画像url":      "image_url",
+	"image":      "image_url",
+}
+
+// PinSpreadsheetService はピンのCSV/XLSX入出力を提供するインターフェース
+type PinSpreadsheetService interface {
+	Export(ctx context.Context, userID, mapID, floorID string) ([][]string, error)
+	Import(ctx context.Context, userID, mapID string, rows [][]string, dryRun bool) (*models.PinImportResult, error)
+}
+
+// DefaultPinSpreadsheetService はPinSpreadsheetServiceの実装
+type DefaultPinSpreadsheetService struct {
+	pinRepo   repositories.PinRepository
+	floorRepo repositories.FloorRepository
+	mapRepo   repositories.MapRepository
+}
+
+// NewPinSpreadsheetService は新しいPinSpreadsheetServiceを作成する
+func NewPinSpreadsheetService(
+	pinRepo repositories.PinRepository,
+	floorRepo repositories.FloorRepository,
+	mapRepo repositories.MapRepository,
+) PinSpreadsheetService {
+	return &DefaultPinSpreadsheetService{
+		pinRepo:   pinRepo,
+		floorRepo: floorRepo,
+		mapRepo:   mapRepo,
+	}
+}
+
+// Export はマップ（floorIDを指定した場合はそのフロア）のピンを表形式で出力する
+func (s *DefaultPinSpreadsheetService) Export(ctx context.Context, userID, mapID, floorID string) ([][]string, error) {
+	floors, err := s.ownedFloors(ctx, userID, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	floorNames := make(map[string]string)
+	var floorIDs []string
+	for _, floor := range floors {
+		if floorID != "" && floor.ID != floorID {
+			continue
+		}
+		floorNames[floor.ID] = floor.Name
+		floorIDs = append(floorIDs, floor.ID)
+	}
+	if floorID != "" && len(floorIDs) == 0 {
+		return nil, errors.New("フロアが見つかりません")
+	}
+
+	pins, err := s.pinRepo.GetByFloorIDs(ctx, floorIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// ピンの内容は公開編集者も入力できるため、文字列のセルは数式として実行されないようにする
+	rows := [][]string{models.PinSpreadsheetColumns}
+	for _, pin := range pins {
+		rows = append(rows, []string{
+			utils.EscapeFormula(pin.ExternalKey),
+			utils.EscapeFormula(floorNames[pin.FloorID]),
+			utils.EscapeFormula(pin.Title),
+			utils.EscapeFormula(pin.Description),
+			utils.EscapeFormula(pin.Category),
+			strconv.FormatFloat(pin.XPosition, 'f', -1, 64),
+			strconv.FormatFloat(pin.YPosition, 'f', -1, 64),
+			utils.EscapeFormula(pin.ImageURL),
+		})
+	}
+
+	return rows, nil
+}
+
+// Import は表形式のデータを検証し、外部キーでピンを作成または更新する
+// dryRunの場合、または1行でもエラーがある場合は保存しない
+// 保存は1つのトランザクションで行い、途中で失敗した場合は1行も反映しない
+func (s *DefaultPinSpreadsheetService) Import(ctx context.Context, userID, mapID string, rows [][]string, dryRun bool) (*models.PinImportResult, error) {
+	floors, err := s.ownedFloors(ctx, userID, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("ヘッダー行が必要です")
+	}
+
+	// ヘッダーから列位置を決定
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if alias, ok := pinColumnAliases[key]; ok {
+			key = alias
+		}
+		if _, dup := columns[key]; !dup {
+			columns[key] = i
+		}
+	}
+	for _, required := range []string{"floor", "title", "x_position", "y_position"} {
+		if _, ok := columns[required]; !ok {
+			return nil, newMessageError("必須の列がありません: %s", required)
+		}
+	}
+
+	// フロアはID・名前・番号のいずれでも指定できる
+	floorLookup := make(map[string]string)
+	for _, floor := range floors {
+		floorLookup[strconv.Itoa(floor.FloorNumber)] = floor.ID
+		floorLookup[floor.Name] = floor.ID
+	}
+	for _, floor := range floors {
+		floorLookup[floor.ID] = floor.ID
+	}
+
+	floorIDs := make([]string, len(floors))
+	for i, floor := range floors {
+		floorIDs[i] = floor.ID
+	}
+	existingPins, err := s.pinRepo.GetByFloorIDs(ctx, floorIDs)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*models.Pin)
+	for _, pin := range existingPins {
+		if pin.ExternalKey != "" {
+			byKey[pin.ExternalKey] = pin
+		}
+	}
+
+	result := &models.PinImportResult{
+		DryRun: dryRun,
+		Rows:   []models.PinImportRow{},
+		Errors: []models.PinImportRowError{},
+	}
+	seenKeys := make(map[string]int)
+
+	for i, record := range rows[1:] {
+		rowNumber := i + 2
+		cell := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			// 出力時に付けた数式よけの'を外す
+			return utils.UnescapeFormula(strings.TrimSpace(record[idx]))
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		rowErrors := len(result.Errors)
+		addError := func(column, message string) {
+			result.Errors = append(result.Errors, models.PinImportRowError{Row: rowNumber, Column: column, Error: message})
+		}
+
+		key := cell("external_key")
+		if key != "" {
+			if prev, dup := seenKeys[key]; dup {
+				addError("external_key", "外部キーが"+strconv.Itoa(prev)+"行目と重複しています")
+			}
+			seenKeys[key] = rowNumber
+		}
+
+		floorID, ok := floorLookup[cell("floor")]
+		if !ok {
+			addError("floor", "フロアが見つかりません")
+		}
+
+		title := cell("title")
+		if title == "" {
+			addError("title", "タイトルは必須です")
+		}
+
+		x, err := strconv.ParseFloat(cell("x_position"), 64)
+		if err != nil {
+			addError("x_position", "数値を指定してください")
+		}
+		y, err := strconv.ParseFloat(cell("y_position"), 64)
+		if err != nil {
+			addError("y_position", "数値を指定してください")
+		}
+
+		if len(result.Errors) > rowErrors {
+			continue
+		}
+
+		row := models.PinImportRow{Row: rowNumber, Action: "create"}
+		pin := &models.Pin{
+			ID:             uuid.New().String(),
+			EditorID:       userID,
+			EditorNickname: "管理者",
+			CreatedAt:      time.Now(),
+		}
+		if existing, ok := byKey[key]; ok && key != "" {
+			copied := *existing
+			pin = &copied
+			row.Action = "update"
+			row.PinID = existing.ID
+		}
+
+		pin.FloorID = floorID
+		pin.Title = title
+		pin.Description = cell("description")
+		pin.Category = cell("category")
+		pin.XPosition = x
+		pin.YPosition = y
+		pin.ImageURL = cell("image_url")
+		pin.ExternalKey = key
+		pin.UpdatedAt = time.Now()
+		row.Pin = pin
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	for _, row := range result.Rows {
+		if row.Action == "update" {
+			result.Updated++
+		} else {
+			result.Created++
+		}
+	}
+
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	// 検証済みの行をまとめて保存
+	var created, updated []*models.Pin
+	for _, row := range result.Rows {
+		if row.Action == "update" {
+			updated = append(updated, row.Pin)
+		} else {
+			created = append(created, row.Pin)
+		}
+	}
+	if err := s.pinRepo.SaveAll(ctx, created, updated); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ownedFloors はマップの所有者を確認し、マップのフロア一覧を返す
+func (s *DefaultPinSpreadsheetService) ownedFloors(ctx context.Context, userID, mapID string) ([]*models.Floor, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+
+	return s.floorRepo.GetByMapID(ctx, mapID)
+}
+
+// isBlankRecord は全ての列が空の行かどうかを判定する
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
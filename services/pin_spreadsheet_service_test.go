@@ -0,0 +1,157 @@
+// backend/services/pin_spreadsheet_service_test.go
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+const (
+	testSpreadsheetUserID = "owner"
+	testSpreadsheetMapID  = "map-1"
+)
+
+// fakeSpreadsheetMapRepo は1つのマップだけを返すMapRepository
+type fakeSpreadsheetMapRepo struct {
+	repositories.MapRepository
+	m *models.Map
+}
+
+func (r *fakeSpreadsheetMapRepo) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	if r.m.ID != id {
+		return nil, nil
+	}
+	return r.m, nil
+}
+
+// fakeSpreadsheetFloorRepo はメモリ上のフロアを返すFloorRepository
+type fakeSpreadsheetFloorRepo struct {
+	repositories.FloorRepository
+	floors []*models.Floor
+}
+
+func (r *fakeSpreadsheetFloorRepo) GetByMapID(ctx context.Context, mapID string) ([]*models.Floor, error) {
+	var floors []*models.Floor
+	for _, floor := range r.floors {
+		if floor.MapID == mapID {
+			floors = append(floors, floor)
+		}
+	}
+	return floors, nil
+}
+
+// fakeSpreadsheetPinRepo はメモリ上のピンを返すPinRepository
+type fakeSpreadsheetPinRepo struct {
+	repositories.PinRepository
+	pins []*models.Pin
+}
+
+func (r *fakeSpreadsheetPinRepo) GetByFloorIDs(ctx context.Context, floorIDs []string) ([]*models.Pin, error) {
+	var pins []*models.Pin
+	for _, pin := range r.pins {
+		for _, floorID := range floorIDs {
+			if pin.FloorID == floorID {
+				pins = append(pins, pin)
+			}
+		}
+	}
+	return pins, nil
+}
+
+// newSpreadsheetTestService は数式と解釈される文字で始まる値を持つピンを用意する
+func newSpreadsheetTestService() (PinSpreadsheetService, *models.Pin) {
+	floor := &models.Floor{ID: "floor-1", MapID: testSpreadsheetMapID, Name: "-1F", FloorNumber: 1}
+	pin := &models.Pin{
+		ID:          "pin-1",
+		FloorID:     floor.ID,
+		ExternalKey: "@SUM(A1:A9)",
+		Title:       "=HYPERLINK(\"https://example.com\",\"click\")",
+		Description: "+1+cmd|' /C calc'!A0",
+		Category:    "-2+3",
+		XPosition:   -12.5,
+		YPosition:   40,
+		ImageURL:    "=IMAGE(\"https://example.com/a.png\")",
+	}
+
+	service := NewPinSpreadsheetService(
+		&fakeSpreadsheetPinRepo{pins: []*models.Pin{pin}},
+		&fakeSpreadsheetFloorRepo{floors: []*models.Floor{floor}},
+		&fakeSpreadsheetMapRepo{m: &models.Map{ID: testSpreadsheetMapID, UserID: testSpreadsheetUserID}},
+	)
+	return service, pin
+}
+
+func TestPinSpreadsheetExportEscapesFormulas(t *testing.T) {
+	service, pin := newSpreadsheetTestService()
+
+	rows, err := service.Export(context.Background(), testSpreadsheetUserID, testSpreadsheetMapID, "")
+	if err != nil {
+		t.Fatalf("出力に失敗しました: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("行数 = %d, want 2", len(rows))
+	}
+
+	want := []string{
+		"'" + pin.ExternalKey,
+		"'-1F",
+		"'" + pin.Title,
+		"'" + pin.Description,
+		"'" + pin.Category,
+		"-12.5",
+		"40",
+		"'" + pin.ImageURL,
+	}
+	for i, value := range rows[1] {
+		if value != want[i] {
+			t.Errorf("%s = %q, want %q", models.PinSpreadsheetColumns[i], value, want[i])
+		}
+	}
+}
+
+func TestPinSpreadsheetImportRestoresEscapedValues(t *testing.T) {
+	service, pin := newSpreadsheetTestService()
+	ctx := context.Background()
+
+	rows, err := service.Export(ctx, testSpreadsheetUserID, testSpreadsheetMapID, "")
+	if err != nil {
+		t.Fatalf("出力に失敗しました: %v", err)
+	}
+
+	result, err := service.Import(ctx, testSpreadsheetUserID, testSpreadsheetMapID, rows, true)
+	if err != nil {
+		t.Fatalf("取り込みに失敗しました: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("取り込みでエラーになりました: %+v", result.Errors)
+	}
+	if len(result.Rows) != 1 || result.Rows[0].Action != "update" {
+		t.Fatalf("取り込み結果 = %+v, want 既存のピンの更新1件", result.Rows)
+	}
+
+	imported := result.Rows[0].Pin
+	if imported.ExternalKey != pin.ExternalKey || imported.Title != pin.Title ||
+		imported.Description != pin.Description || imported.Category != pin.Category ||
+		imported.ImageURL != pin.ImageURL || imported.XPosition != pin.XPosition {
+		t.Errorf("取り込んだピン = %+v, want %+v", imported, pin)
+	}
+}
+
+func TestPinSpreadsheetImportMissingColumn(t *testing.T) {
+	service, _ := newSpreadsheetTestService()
+
+	rows := [][]string{{"floor", "title", "x_position"}, {"-1F", "受付", "10"}}
+	_, err := service.Import(context.Background(), testSpreadsheetUserID, testSpreadsheetMapID, rows, true)
+
+	var messageErr *MessageError
+	if !errors.As(err, &messageErr) {
+		t.Fatalf("err = %v, want MessageError", err)
+	}
+	if messageErr.Format() != "必須の列がありません: %s" || len(messageErr.Args()) != 1 || messageErr.Args()[0] != "y_position" {
+		t.Errorf("書式 = %q, 引数 = %v, want 不足しているy_positionの列", messageErr.Format(), messageErr.Args())
+	}
+}
@@ -4,8 +4,11 @@ package services
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,46 +16,107 @@ import (
 	"github.com/shimaf4979/pamfree-backend/repositories"
 )
 
+// 公開編集者の登録時に呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrPublicEditorBanned = errors.New("この端末からの編集は禁止されています")
+	ErrInviteCodeRequired = errors.New("このマップの編集には招待コードが必要です")
+	ErrInviteCodeInvalid  = errors.New("招待コードが無効か、有効期限または利用回数の上限を過ぎています")
+)
+
 // PublicEditorService は公開編集者に関する操作を提供するインターフェース
 type PublicEditorService interface {
-	Register(ctx context.Context, mapID, nickname string) (*models.PublicEditor, error)
+	Register(ctx context.Context, mapID, nickname, inviteCode string, fingerprint *models.ClientFingerprint) (*models.PublicEditor, error)
 	Verify(ctx context.Context, editorID, token string) (*models.PublicEditor, error)
+	Authenticate(ctx context.Context, editorID, token string) (*models.PublicEditor, error)
 	GetByID(ctx context.Context, editorID string) (*models.PublicEditor, error)
 	GetByMapID(ctx context.Context, mapID string) ([]*models.PublicEditor, error)
 	UpdateLastActive(ctx context.Context, editorID string) error
+	GetEditorPermissions(ctx context.Context, editor *models.PublicEditor) (*models.PublicEditPermissions, error)
+
+	// マップの所有者向けの管理
+	GetSummaries(ctx context.Context, userID, mapID string) ([]*models.PublicEditorSummary, error)
+	GetMapPermissions(ctx context.Context, userID, mapID string) (*models.PublicEditPermissions, error)
+	Revoke(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error)
+	Ban(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error)
+	Unban(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error)
+	UpdatePermissions(ctx context.Context, userID, mapID string, input *models.PublicEditPermissionsUpdate) (*models.PublicEditPermissions, error)
+	CreateInvite(ctx context.Context, userID, mapID string, input *models.PublicEditInviteCreate) (*models.PublicEditInvite, error)
+	GetInvites(ctx context.Context, userID, mapID string) ([]*models.PublicEditInvite, error)
+	RevokeInvite(ctx context.Context, userID, inviteID string) (int64, error)
 }
 
 // DefaultPublicEditorService はPublicEditorServiceの実装
 type DefaultPublicEditorService struct {
 	publicEditorRepo repositories.PublicEditorRepository
 	mapRepo          repositories.MapRepository
+	permissionsRepo  repositories.PublicEditPermissionsRepository
+	inviteRepo       repositories.PublicEditInviteRepository
+	filter           *contentFilter
 }
 
 // NewPublicEditorService は新しいPublicEditorServiceを作成する
 func NewPublicEditorService(
 	publicEditorRepo repositories.PublicEditorRepository,
 	mapRepo repositories.MapRepository,
+	permissionsRepo repositories.PublicEditPermissionsRepository,
+	inviteRepo repositories.PublicEditInviteRepository,
+	filterRepo repositories.ContentFilterRepository,
+	reportRepo repositories.ContentReportRepository,
 ) PublicEditorService {
 	return &DefaultPublicEditorService{
 		publicEditorRepo: publicEditorRepo,
 		mapRepo:          mapRepo,
+		permissionsRepo:  permissionsRepo,
+		inviteRepo:       inviteRepo,
+		filter: &contentFilter{
+			filterRepo: filterRepo,
+			reportRepo: reportRepo,
+		},
 	}
 }
 
 // Register は新しい公開編集者を登録する
-func (s *DefaultPublicEditorService) Register(ctx context.Context, mapID, nickname string) (*models.PublicEditor, error) {
+// 編集を禁止された端末からの登録と、招待制のマップへの招待コードなしの登録は拒否する
+func (s *DefaultPublicEditorService) Register(ctx context.Context, mapID, nickname, inviteCode string, fingerprint *models.ClientFingerprint) (*models.PublicEditor, error) {
 	// マップの存在と公開編集可能性を確認
 	mapData, err := s.mapRepo.GetByID(ctx, mapID)
 	if err != nil {
 		return nil, err
 	}
-	if mapData == nil {
-		return nil, errors.New("マップが見つかりません")
+	if mapData == nil || !isPublished(mapData, time.Now()) {
+		return nil, ErrMapNotFound
 	}
 	if !mapData.IsPubliclyEditable {
 		return nil, errors.New("このマップは公開編集が許可されていません")
 	}
 
+	// ニックネームを内容フィルターで検査する
+	violation, err := s.filter.check(ctx, mapID, models.FilterField{Name: "nickname", Kind: models.FilterFieldNickname, Value: nickname})
+	if err != nil {
+		return nil, err
+	}
+
+	// 端末識別子はハッシュ化して保存する
+	var networkFingerprint, deviceFingerprint string
+	if fingerprint != nil {
+		networkFingerprint = hashFingerprint(fingerprint.Network)
+		deviceFingerprint = hashFingerprint(fingerprint.Device)
+	}
+
+	banned, err := s.publicEditorRepo.IsBanned(ctx, mapID, []string{networkFingerprint, deviceFingerprint})
+	if err != nil {
+		return nil, err
+	}
+	if banned {
+		return nil, ErrPublicEditorBanned
+	}
+
+	// 招待コードを確認し、利用回数を加算する
+	invite, err := s.useInvite(ctx, mapID, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+
 	// ランダムなトークンを生成
 	token, err := generateToken(32)
 	if err != nil {
@@ -61,12 +125,23 @@ func (s *DefaultPublicEditorService) Register(ctx context.Context, mapID, nickna
 
 	// 公開編集者を作成
 	editor := &models.PublicEditor{
-		ID:          uuid.New().String(),
-		MapID:       mapID,
-		Nickname:    nickname,
-		EditorToken: token,
-		CreatedAt:   time.Now(),
-		LastActive:  time.Now(),
+		ID:                 uuid.New().String(),
+		MapID:              mapID,
+		Nickname:           nickname,
+		EditorToken:        token,
+		NetworkFingerprint: networkFingerprint,
+		DeviceFingerprint:  deviceFingerprint,
+		InviteID:           invite,
+		CreatedAt:          time.Now(),
+		LastActive:         time.Now(),
+	}
+
+	// 確認待ちにする場合はニックネームを伏せて所有者の通報一覧に載せる
+	// 公開APIに表示されないよう、編集者を保存する前に伏せておく
+	if violation != nil {
+		if err := s.filter.moderate(ctx, mapID, models.ReportTargetEditor, editor.ID, violation); err != nil {
+			return nil, err
+		}
 	}
 
 	// リポジトリに保存
@@ -79,17 +154,19 @@ func (s *DefaultPublicEditorService) Register(ctx context.Context, mapID, nickna
 
 // Verify は編集者トークンを検証する
 func (s *DefaultPublicEditorService) Verify(ctx context.Context, editorID, token string) (*models.PublicEditor, error) {
-	editor, err := s.publicEditorRepo.GetByID(ctx, editorID)
+	editor, err := s.Authenticate(ctx, editorID, token)
 	if err != nil {
 		return nil, err
 	}
 	if editor == nil {
-		return nil, errors.New("編集者が見つかりません")
+		return nil, errors.New("無効なトークンです")
 	}
 
-	// トークンの検証
-	if editor.EditorToken != token {
-		return nil, errors.New("無効なトークンです")
+	if editor.BannedAt != nil {
+		return nil, errors.New("この編集者は編集を禁止されています")
+	}
+	if editor.RevokedAt != nil {
+		return nil, errors.New("この編集者の編集権限は取り消されています")
 	}
 
 	// 最終アクティブ時間を更新
@@ -100,6 +177,23 @@ func (s *DefaultPublicEditorService) Verify(ctx context.Context, editorID, token
 	return editor, nil
 }
 
+// Authenticate は編集者IDとトークンから公開編集者を取得する
+// 編集者が存在しないかトークンが一致しない場合はnilを返す
+// トークンの比較は一致するまでの長さから推測されないよう、一定時間で行う
+func (s *DefaultPublicEditorService) Authenticate(ctx context.Context, editorID, token string) (*models.PublicEditor, error) {
+	editor, err := s.publicEditorRepo.GetByID(ctx, editorID)
+	if err != nil {
+		return nil, err
+	}
+	if editor == nil || token == "" {
+		return nil, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(editor.EditorToken), []byte(token)) != 1 {
+		return nil, nil
+	}
+	return editor, nil
+}
+
 // GetByID はIDによって公開編集者を取得する
 func (s *DefaultPublicEditorService) GetByID(ctx context.Context, editorID string) (*models.PublicEditor, error) {
 	return s.publicEditorRepo.GetByID(ctx, editorID)
@@ -124,6 +218,296 @@ func (s *DefaultPublicEditorService) UpdateLastActive(ctx context.Context, edito
 	return s.publicEditorRepo.Update(ctx, editor)
 }
 
+// GetEditorPermissions は公開編集者に適用される権限を取得する
+func (s *DefaultPublicEditorService) GetEditorPermissions(ctx context.Context, editor *models.PublicEditor) (*models.PublicEditPermissions, error) {
+	return editorPermissions(ctx, s.permissionsRepo, s.inviteRepo, editor)
+}
+
+// GetSummaries はマップの公開編集者を活動状況と共に取得する
+func (s *DefaultPublicEditorService) GetSummaries(ctx context.Context, userID, mapID string) ([]*models.PublicEditorSummary, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	summaries, err := s.publicEditorRepo.GetSummariesByMapID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if summaries == nil {
+		summaries = []*models.PublicEditorSummary{}
+	}
+
+	return summaries, nil
+}
+
+// Revoke は公開編集者の編集権限を取り消す
+// 取り消された編集者は同じ端末から新しく登録し直すことができる
+func (s *DefaultPublicEditorService) Revoke(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error) {
+	editor, err := s.getMapEditor(ctx, userID, mapID, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if editor.RevokedAt == nil {
+		now := time.Now()
+		if err := s.publicEditorRepo.Revoke(ctx, editor.ID, now); err != nil {
+			return nil, err
+		}
+		editor.RevokedAt = &now
+	}
+
+	return editor, nil
+}
+
+// Ban は公開編集者の編集を禁止し、同じ端末からの再登録を禁止する
+func (s *DefaultPublicEditorService) Ban(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error) {
+	editor, err := s.getMapEditor(ctx, userID, mapID, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.publicEditorRepo.Ban(ctx, editor, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return editor, nil
+}
+
+// Unban は公開編集者の編集禁止を解除する
+func (s *DefaultPublicEditorService) Unban(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error) {
+	editor, err := s.getMapEditor(ctx, userID, mapID, editorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.publicEditorRepo.Unban(ctx, editor); err != nil {
+		return nil, err
+	}
+
+	return editor, nil
+}
+
+// GetMapPermissions は所有者向けにマップの公開編集者の権限を取得する
+func (s *DefaultPublicEditorService) GetMapPermissions(ctx context.Context, userID, mapID string) (*models.PublicEditPermissions, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	return s.permissionsRepo.Get(ctx, mapID)
+}
+
+// UpdatePermissions はマップの公開編集者の権限を更新する
+func (s *DefaultPublicEditorService) UpdatePermissions(ctx context.Context, userID, mapID string, input *models.PublicEditPermissionsUpdate) (*models.PublicEditPermissions, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.permissionsRepo.Get(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	input.PublicEditPermissionOverrides.ApplyTo(permissions)
+	if input.InviteOnly != nil {
+		permissions.InviteOnly = *input.InviteOnly
+	}
+
+	if err := s.permissionsRepo.Save(ctx, permissions); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// CreateInvite は公開編集者の招待コードを作成する
+func (s *DefaultPublicEditorService) CreateInvite(ctx context.Context, userID, mapID string, input *models.PublicEditInviteCreate) (*models.PublicEditInvite, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("有効期限は現在より後に設定してください")
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &models.PublicEditInvite{
+		ID:          uuid.New().String(),
+		MapID:       mapID,
+		Code:        code,
+		Label:       input.Label,
+		ExpiresAt:   input.ExpiresAt,
+		MaxUses:     input.MaxUses,
+		Permissions: input.Permissions,
+		CreatedBy:   userID,
+	}
+
+	if err := s.inviteRepo.Create(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+// GetInvites はマップの招待コード一覧を取得する
+func (s *DefaultPublicEditorService) GetInvites(ctx context.Context, userID, mapID string) ([]*models.PublicEditInvite, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	invites, err := s.inviteRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if invites == nil {
+		invites = []*models.PublicEditInvite{}
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite は招待コードを無効化し、そのコードで登録した編集者の編集権限を取り消す
+// 取り消した編集者の人数を返す
+func (s *DefaultPublicEditorService) RevokeInvite(ctx context.Context, userID, inviteID string) (int64, error) {
+	invite, err := s.inviteRepo.GetByID(ctx, inviteID)
+	if err != nil {
+		return 0, err
+	}
+	if invite == nil {
+		return 0, errors.New("招待コードが見つかりません")
+	}
+
+	if err := s.checkMapOwner(ctx, userID, invite.MapID); err != nil {
+		return 0, err
+	}
+
+	return s.inviteRepo.Revoke(ctx, invite.ID, time.Now())
+}
+
+// useInvite は招待コードを検証して利用回数を加算し、招待コードのIDを返す
+// コードが空で招待制でないマップの場合はnilを返す
+func (s *DefaultPublicEditorService) useInvite(ctx context.Context, mapID, code string) (*string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		permissions, err := s.permissionsRepo.Get(ctx, mapID)
+		if err != nil {
+			return nil, err
+		}
+		if permissions.InviteOnly {
+			return nil, ErrInviteCodeRequired
+		}
+		return nil, nil
+	}
+
+	invite, err := s.inviteRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || invite.MapID != mapID {
+		return nil, ErrInviteCodeInvalid
+	}
+
+	// 利用回数の加算と有効期限・上限の確認は同時に行う
+	ok, err := s.inviteRepo.Use(ctx, invite.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInviteCodeInvalid
+	}
+
+	return &invite.ID, nil
+}
+
+// getMapEditor はマップの所有者を確認し、マップの公開編集者を取得する
+func (s *DefaultPublicEditorService) getMapEditor(ctx context.Context, userID, mapID, editorID string) (*models.PublicEditor, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	editor, err := s.publicEditorRepo.GetByID(ctx, editorID)
+	if err != nil {
+		return nil, err
+	}
+	if editor == nil || editor.MapID != mapID {
+		return nil, errors.New("編集者が見つかりません")
+	}
+
+	return editor, nil
+}
+
+// checkMapOwner はユーザーがマップの所有者か確認する
+func (s *DefaultPublicEditorService) checkMapOwner(ctx context.Context, userID, mapID string) error {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このマップを編集する権限がありません")
+	}
+	return nil
+}
+
+// editorPermissions は公開編集者に適用される権限を取得する
+// 招待コードで登録した編集者にはマップの権限を招待コードの権限で上書きしたものを適用する
+func editorPermissions(
+	ctx context.Context,
+	permissionsRepo repositories.PublicEditPermissionsRepository,
+	inviteRepo repositories.PublicEditInviteRepository,
+	editor *models.PublicEditor,
+) (*models.PublicEditPermissions, error) {
+	permissions, err := permissionsRepo.Get(ctx, editor.MapID)
+	if err != nil {
+		return nil, err
+	}
+
+	if editor.InviteID != nil {
+		invite, err := inviteRepo.GetByID(ctx, *editor.InviteID)
+		if err != nil {
+			return nil, err
+		}
+		if invite != nil {
+			invite.Permissions.ApplyTo(permissions)
+		}
+	}
+
+	return permissions, nil
+}
+
+// generateInviteCode は入力しやすい招待コードを生成する
+// 見間違えやすい文字（0/O・1/I/L）は使わない
+func generateInviteCode() (string, error) {
+	const alphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b), nil
+}
+
+// hashFingerprint は端末識別子をハッシュ化する（空の場合は空文字）
+func hashFingerprint(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
 // generateToken はランダムなトークンを生成する
 func generateToken(length int) (string, error) {
 	b := make([]byte, length)
@@ -0,0 +1,56 @@
+// backend/services/publication_scheduler.go
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+// PublicationScheduler はマップの公開予約・公開終了を定期的に反映する
+type PublicationScheduler struct {
+	mapRepo  repositories.MapRepository
+	interval time.Duration
+}
+
+// NewPublicationScheduler は新しいPublicationSchedulerを作成し、定期実行を開始する
+func NewPublicationScheduler(mapRepo repositories.MapRepository, interval time.Duration) *PublicationScheduler {
+	s := &PublicationScheduler{
+		mapRepo:  mapRepo,
+		interval: interval,
+	}
+
+	go s.run()
+
+	return s
+}
+
+// run は一定間隔で公開状態を切り替える
+func (s *PublicationScheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.apply(context.Background(), time.Now())
+		<-ticker.C
+	}
+}
+
+// apply は予約時刻を過ぎたマップの公開状態を切り替える
+func (s *PublicationScheduler) apply(ctx context.Context, now time.Time) {
+	published, err := s.mapRepo.PublishDue(ctx, now)
+	if err != nil {
+		log.Printf("マップの予約公開に失敗しました: %v", err)
+	} else if published > 0 {
+		log.Printf("%d件のマップを予約公開しました", published)
+	}
+
+	archived, err := s.mapRepo.ArchiveDue(ctx, now)
+	if err != nil {
+		log.Printf("マップの公開終了に失敗しました: %v", err)
+	} else if archived > 0 {
+		log.Printf("%d件のマップの公開を終了しました", archived)
+	}
+}
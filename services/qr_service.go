@@ -0,0 +1,190 @@
+// backend/services/qr_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+// QRService はビューワーへのディープリンク（QRコード用）を提供するインターフェース
+type QRService interface {
+	GetTarget(ctx context.Context, userID, targetType, id string) (*models.QRTarget, error)
+	GetPinTargets(ctx context.Context, userID, mapID string) (*models.Map, []*models.QRTarget, error)
+}
+
+// DefaultQRService はQRServiceの実装
+type DefaultQRService struct {
+	mapRepo       repositories.MapRepository
+	floorRepo     repositories.FloorRepository
+	pinRepo       repositories.PinRepository
+	viewerBaseURL string
+}
+
+// NewQRService は新しいQRServiceを作成する
+func NewQRService(
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	viewerBaseURL string,
+) QRService {
+	return &DefaultQRService{
+		mapRepo:       mapRepo,
+		floorRepo:     floorRepo,
+		pinRepo:       pinRepo,
+		viewerBaseURL: strings.TrimRight(viewerBaseURL, "/"),
+	}
+}
+
+// GetTarget はマップ・フロア・ピンのいずれかへのリンクを取得する
+// 公開中でないマップの対象はメンバー以外には存在しないものとして扱う
+func (s *DefaultQRService) GetTarget(ctx context.Context, userID, targetType, id string) (*models.QRTarget, error) {
+	switch targetType {
+	case models.QRTargetMap:
+		map_, err := s.viewableMap(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if map_ == nil {
+			return nil, errors.New("マップが見つかりません")
+		}
+		return &models.QRTarget{Type: targetType, ID: id, Label: map_.Title, URL: s.viewerLink(map_.ID, "", "")}, nil
+
+	case models.QRTargetFloor:
+		floor, err := s.floorRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if floor == nil {
+			return nil, errors.New("フロアが見つかりません")
+		}
+		map_, err := s.viewableMap(ctx, userID, floor.MapID)
+		if err != nil {
+			return nil, err
+		}
+		if map_ == nil {
+			return nil, errors.New("フロアが見つかりません")
+		}
+		return &models.QRTarget{Type: targetType, ID: id, Label: floor.Name, URL: s.viewerLink(floor.MapID, floor.ID, "")}, nil
+
+	case models.QRTargetPin:
+		pin, err := s.pinRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if pin == nil {
+			return nil, errors.New("ピンが見つかりません")
+		}
+		floor, err := s.floorRepo.GetByID(ctx, pin.FloorID)
+		if err != nil {
+			return nil, err
+		}
+		if floor == nil {
+			return nil, errors.New("フロアが見つかりません")
+		}
+		map_, err := s.viewableMap(ctx, userID, floor.MapID)
+		if err != nil {
+			return nil, err
+		}
+		if map_ == nil {
+			return nil, errors.New("ピンが見つかりません")
+		}
+		return &models.QRTarget{Type: targetType, ID: id, Label: pin.Title, URL: s.viewerLink(floor.MapID, floor.ID, pin.ID)}, nil
+	}
+
+	return nil, errors.New("無効なQRコードの対象です")
+}
+
+// GetPinTargets はマップ内の全ピンへのリンクを取得する（所有者のみ）
+func (s *DefaultQRService) GetPinTargets(ctx context.Context, userID, mapID string) (*models.Map, []*models.QRTarget, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if map_ == nil {
+		return nil, nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !canEdit {
+		return nil, nil, errors.New("このマップにアクセスする権限がありません")
+	}
+
+	floors, err := s.floorRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	floorIDs := make([]string, len(floors))
+	for i, floor := range floors {
+		floorIDs[i] = floor.ID
+	}
+	pins, err := s.pinRepo.GetByFloorIDs(ctx, floorIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// フロア順に並べる
+	var targets []*models.QRTarget
+	for _, floor := range floors {
+		for _, pin := range pins {
+			if pin.FloorID != floor.ID {
+				continue
+			}
+			targets = append(targets, &models.QRTarget{
+				Type:  models.QRTargetPin,
+				ID:    pin.ID,
+				Label: floor.Name + " " + pin.Title,
+				URL:   s.viewerLink(mapID, floor.ID, pin.ID),
+			})
+		}
+	}
+
+	return map_, targets, nil
+}
+
+// viewableMap はユーザーが閲覧できるマップを取得する
+// 存在しない、または閲覧できない場合はnilを返す
+func (s *DefaultQRService) viewableMap(ctx context.Context, userID, mapID string) (*models.Map, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, nil
+	}
+
+	canView, err := canViewMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, nil
+	}
+
+	return map_, nil
+}
+
+// viewerLink はビューワーのディープリンクを組み立てる
+func (s *DefaultQRService) viewerLink(mapID, floorID, pinID string) string {
+	link := s.viewerBaseURL + "/" + url.PathEscape(mapID)
+
+	query := url.Values{}
+	if floorID != "" {
+		query.Set("floor", floorID)
+	}
+	if pinID != "" {
+		query.Set("pin", pinID)
+	}
+	if len(query) > 0 {
+		link += "?" + query.Encode()
+	}
+
+	return link
+}
@@ -0,0 +1,438 @@
+// backend/services/report_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// 通報一覧の1ページあたりの件数
+const (
+	defaultFlagPageSize = 20
+	maxFlagPageSize     = 100
+)
+
+// ReportService は不適切な内容の通報と対応に関する操作を提供するインターフェース
+type ReportService interface {
+	Report(ctx context.Context, reporter *models.Author, req *models.ContentReportCreate) error
+	GetMapFlags(ctx context.Context, userID, mapID, status string, limit, offset int) (*models.ContentFlagPage, error)
+	GetAllFlags(ctx context.Context, status string, limit, offset int) (*models.ContentFlagPage, error)
+	Resolve(ctx context.Context, userID string, isAdmin bool, flagID string, req *models.ContentFlagAction) (*models.ContentFlag, error)
+	FilterPins(ctx context.Context, mapID string, pins []*models.Pin) ([]*models.Pin, error)
+}
+
+// DefaultReportService はReportServiceの実装
+type DefaultReportService struct {
+	reportRepo       repositories.ContentReportRepository
+	mapRepo          repositories.MapRepository
+	floorRepo        repositories.FloorRepository
+	pinRepo          repositories.PinRepository
+	publicEditorRepo repositories.PublicEditorRepository
+	commentRepo      repositories.PinCommentRepository
+	hideThreshold    int
+	limiter          *utils.RateLimiter
+}
+
+// NewReportService は新しいReportServiceを作成する
+// hideThreshold件の通報を受けた内容は所有者の確認前でも自動的に非表示にする
+func NewReportService(
+	reportRepo repositories.ContentReportRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	publicEditorRepo repositories.PublicEditorRepository,
+	commentRepo repositories.PinCommentRepository,
+	hideThreshold int,
+) ReportService {
+	return &DefaultReportService{
+		reportRepo:       reportRepo,
+		mapRepo:          mapRepo,
+		floorRepo:        floorRepo,
+		pinRepo:          pinRepo,
+		publicEditorRepo: publicEditorRepo,
+		commentRepo:      commentRepo,
+		hideThreshold:    hideThreshold,
+		limiter:          utils.NewRateLimiter(10, time.Minute),
+	}
+}
+
+// Report は内容を通報する
+// 未対応の内容への通報が閾値に達した場合は自動的に非表示にする
+func (s *DefaultReportService) Report(ctx context.Context, reporter *models.Author, req *models.ContentReportCreate) error {
+	if reporter == nil {
+		return ErrAuthorRequired
+	}
+
+	mapID, err := s.targetMapID(ctx, req.TargetType, req.TargetID)
+	if err != nil {
+		return err
+	}
+
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil || !isPublished(map_, time.Now()) {
+		return errors.New("通報の対象が見つかりません")
+	}
+
+	if ok, retryAfter := s.limiter.Allow(authorKey(reporter)); !ok {
+		return &RateLimitError{RetryAfter: retryAfter}
+	}
+
+	report := &models.ContentReport{
+		Reason:       req.Reason,
+		Details:      req.Details,
+		ReporterType: reporter.Type,
+		ReporterID:   reporter.ID,
+	}
+
+	flag, added, err := s.reportRepo.AddReport(ctx, mapID, req.TargetType, req.TargetID, report)
+	if err != nil {
+		return err
+	}
+
+	if added && flag.Status == models.FlagStatusOpen && s.hideThreshold > 0 && flag.ReportCount >= s.hideThreshold {
+		if err := s.hide(ctx, flag); err != nil {
+			return err
+		}
+		now := time.Now()
+		flag.Status = models.FlagStatusHidden
+		flag.HiddenAt = &now
+		if err := s.reportRepo.UpdateFlagStatus(ctx, flag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetMapFlags はマップの通報一覧を取得する（所有者用）
+func (s *DefaultReportService) GetMapFlags(ctx context.Context, userID, mapID, status string, limit, offset int) (*models.ContentFlagPage, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+
+	return s.flagPage(ctx, mapID, status, limit, offset)
+}
+
+// GetAllFlags はすべてのマップの通報一覧を取得する（管理者用）
+func (s *DefaultReportService) GetAllFlags(ctx context.Context, status string, limit, offset int) (*models.ContentFlagPage, error) {
+	return s.flagPage(ctx, "", status, limit, offset)
+}
+
+// Resolve は通報された内容に対応する
+// マップの所有者または管理者のみ対応できる
+func (s *DefaultReportService) Resolve(ctx context.Context, userID string, isAdmin bool, flagID string, req *models.ContentFlagAction) (*models.ContentFlag, error) {
+	flag, err := s.reportRepo.GetFlagByID(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, errors.New("通報が見つかりません")
+	}
+
+	if !isAdmin {
+		map_, err := s.mapRepo.GetByID(ctx, flag.MapID)
+		if err != nil {
+			return nil, err
+		}
+		if map_ == nil {
+			return nil, errors.New("この通報に対応する権限がありません")
+		}
+		canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !canEdit {
+			return nil, errors.New("この通報に対応する権限がありません")
+		}
+	}
+
+	if flag.Status == models.FlagStatusRemoved {
+		return nil, errors.New("この内容は既に削除されています")
+	}
+
+	now := time.Now()
+	switch req.Action {
+	case models.FlagActionDismiss:
+		if err := s.restore(ctx, flag); err != nil {
+			return nil, err
+		}
+		flag.Status = models.FlagStatusDismissed
+		flag.HiddenAt = nil
+	case models.FlagActionHide:
+		if err := s.hide(ctx, flag); err != nil {
+			return nil, err
+		}
+		flag.Status = models.FlagStatusHidden
+		if flag.HiddenAt == nil {
+			flag.HiddenAt = &now
+		}
+	case models.FlagActionRemove:
+		if err := s.remove(ctx, flag); err != nil {
+			return nil, err
+		}
+		flag.Status = models.FlagStatusRemoved
+	case models.FlagActionBan:
+		if err := s.ban(ctx, flag, now); err != nil {
+			return nil, err
+		}
+		if err := s.hide(ctx, flag); err != nil {
+			return nil, err
+		}
+		flag.Status = models.FlagStatusHidden
+		if flag.HiddenAt == nil {
+			flag.HiddenAt = &now
+		}
+	default:
+		return nil, errors.New("無効な対応です")
+	}
+
+	flag.ResolvedBy = &userID
+	flag.ResolvedAt = &now
+	if err := s.reportRepo.UpdateFlagStatus(ctx, flag); err != nil {
+		return nil, err
+	}
+
+	return flag, nil
+}
+
+// FilterPins はビューワーに返すピンから非表示のピンを除き、非表示の編集者のニックネームを伏せる
+func (s *DefaultReportService) FilterPins(ctx context.Context, mapID string, pins []*models.Pin) ([]*models.Pin, error) {
+	return filterHiddenPins(ctx, s.reportRepo, mapID, pins)
+}
+
+// filterHiddenPins は通報・内容フィルターにより非表示になったピンを除き、非表示の編集者のニックネームを伏せる
+func filterHiddenPins(ctx context.Context, reportRepo repositories.ContentReportRepository, mapID string, pins []*models.Pin) ([]*models.Pin, error) {
+	hiddenPins, err := reportRepo.GetHiddenTargetIDs(ctx, mapID, models.ReportTargetPin)
+	if err != nil {
+		return nil, err
+	}
+	hiddenEditors, err := reportRepo.GetHiddenTargetIDs(ctx, mapID, models.ReportTargetEditor)
+	if err != nil {
+		return nil, err
+	}
+	if len(hiddenPins) == 0 && len(hiddenEditors) == 0 {
+		return pins, nil
+	}
+
+	filtered := make([]*models.Pin, 0, len(pins))
+	for _, pin := range pins {
+		if containsString(hiddenPins, pin.ID) {
+			continue
+		}
+		if pin.EditorID != "" && containsString(hiddenEditors, pin.EditorID) {
+			pin.EditorNickname = ""
+		}
+		filtered = append(filtered, pin)
+	}
+
+	return filtered, nil
+}
+
+// flagPage は通報一覧の1ページを作成する
+func (s *DefaultReportService) flagPage(ctx context.Context, mapID, status string, limit, offset int) (*models.ContentFlagPage, error) {
+	switch status {
+	case "", models.FlagStatusOpen, models.FlagStatusHidden, models.FlagStatusDismissed, models.FlagStatusRemoved:
+	default:
+		return nil, errors.New("無効な対応状況です")
+	}
+
+	if limit <= 0 {
+		limit = defaultFlagPageSize
+	}
+	if limit > maxFlagPageSize {
+		limit = maxFlagPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	flags, total, err := s.reportRepo.GetFlags(ctx, mapID, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	flagIDs := make([]string, len(flags))
+	for i, flag := range flags {
+		flagIDs[i] = flag.ID
+	}
+	reports, err := s.reportRepo.GetReportsByFlagIDs(ctx, flagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, flag := range flags {
+		flag.Reports = reports[flag.ID]
+		preview, err := s.preview(ctx, flag)
+		if err != nil {
+			return nil, err
+		}
+		flag.Preview = preview
+	}
+
+	if flags == nil {
+		flags = []*models.ContentFlag{}
+	}
+
+	return &models.ContentFlagPage{
+		Flags:  flags,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}, nil
+}
+
+// targetMapID は通報の対象が属するマップのIDを取得する
+func (s *DefaultReportService) targetMapID(ctx context.Context, targetType, targetID string) (string, error) {
+	switch targetType {
+	case models.ReportTargetPin:
+		pin, err := s.pinRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		if pin == nil {
+			return "", errors.New("通報の対象が見つかりません")
+		}
+		floor, err := s.floorRepo.GetByID(ctx, pin.FloorID)
+		if err != nil {
+			return "", err
+		}
+		if floor == nil {
+			return "", errors.New("通報の対象が見つかりません")
+		}
+		return floor.MapID, nil
+	case models.ReportTargetEditor:
+		editor, err := s.publicEditorRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		if editor == nil {
+			return "", errors.New("通報の対象が見つかりません")
+		}
+		return editor.MapID, nil
+	case models.ReportTargetComment:
+		comment, err := s.commentRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		if comment == nil || comment.Status != models.CommentStatusVisible {
+			return "", errors.New("通報の対象が見つかりません")
+		}
+		return comment.MapID, nil
+	default:
+		return "", errors.New("無効な通報の対象です")
+	}
+}
+
+// preview は通報一覧に表示する対象の内容を取得する
+func (s *DefaultReportService) preview(ctx context.Context, flag *models.ContentFlag) (string, error) {
+	switch flag.TargetType {
+	case models.ReportTargetPin:
+		pin, err := s.pinRepo.GetByID(ctx, flag.TargetID)
+		if err != nil || pin == nil {
+			return "", err
+		}
+		return pin.Title, nil
+	case models.ReportTargetEditor:
+		editor, err := s.publicEditorRepo.GetByID(ctx, flag.TargetID)
+		if err != nil || editor == nil {
+			return "", err
+		}
+		return editor.Nickname, nil
+	case models.ReportTargetComment:
+		comment, err := s.commentRepo.GetByID(ctx, flag.TargetID)
+		if err != nil || comment == nil {
+			return "", err
+		}
+		return comment.Body, nil
+	}
+	return "", nil
+}
+
+// hide は対象を非表示にする
+// ピンと編集者はビューワーで除外し、コメントは表示状態を切り替える
+func (s *DefaultReportService) hide(ctx context.Context, flag *models.ContentFlag) error {
+	if flag.TargetType == models.ReportTargetComment {
+		return s.commentRepo.UpdateStatus(ctx, flag.TargetID, models.CommentStatusHidden)
+	}
+	return nil
+}
+
+// restore は非表示にした対象を再表示する
+func (s *DefaultReportService) restore(ctx context.Context, flag *models.ContentFlag) error {
+	if flag.TargetType == models.ReportTargetComment && flag.Status == models.FlagStatusHidden {
+		return s.commentRepo.UpdateStatus(ctx, flag.TargetID, models.CommentStatusVisible)
+	}
+	return nil
+}
+
+// remove は対象を削除する
+// 編集者は削除せず、ニックネームを消去する
+func (s *DefaultReportService) remove(ctx context.Context, flag *models.ContentFlag) error {
+	switch flag.TargetType {
+	case models.ReportTargetPin:
+		return s.pinRepo.Delete(ctx, flag.TargetID)
+	case models.ReportTargetComment:
+		return s.commentRepo.Delete(ctx, flag.TargetID)
+	case models.ReportTargetEditor:
+		editor, err := s.publicEditorRepo.GetByID(ctx, flag.TargetID)
+		if err != nil {
+			return err
+		}
+		if editor == nil {
+			return nil
+		}
+		editor.Nickname = ""
+		return s.publicEditorRepo.Update(ctx, editor)
+	}
+	return nil
+}
+
+// ban は対象を投稿した公開編集者の編集を禁止する
+func (s *DefaultReportService) ban(ctx context.Context, flag *models.ContentFlag, now time.Time) error {
+	editorID := ""
+	switch flag.TargetType {
+	case models.ReportTargetEditor:
+		editorID = flag.TargetID
+	case models.ReportTargetPin:
+		pin, err := s.pinRepo.GetByID(ctx, flag.TargetID)
+		if err != nil {
+			return err
+		}
+		if pin != nil {
+			editorID = pin.EditorID
+		}
+	}
+
+	if editorID == "" {
+		return errors.New("この内容の投稿者は公開編集者ではありません")
+	}
+
+	editor, err := s.publicEditorRepo.GetByID(ctx, editorID)
+	if err != nil {
+		return err
+	}
+	if editor == nil {
+		return errors.New("編集者が見つかりません")
+	}
+
+	return s.publicEditorRepo.Ban(ctx, editor, now)
+}
@@ -0,0 +1,193 @@
+// backend/services/share_link_service.go
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// 共有リンクでの閲覧時に呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrShareLinkPasswordRequired = errors.New("この共有リンクにはパスワードが必要です")
+	ErrShareLinkWrongPassword    = errors.New("パスワードが正しくありません")
+)
+
+// ShareLinkService は共有リンクに関する操作を提供するインターフェース
+type ShareLinkService interface {
+	Create(ctx context.Context, userID, mapID string, req *models.ShareLinkCreate) (*models.ShareLink, error)
+	GetByMapID(ctx context.Context, userID, mapID string) ([]*models.ShareLink, error)
+	Revoke(ctx context.Context, userID, id string) error
+	Resolve(ctx context.Context, slug, password string) (*models.Map, error)
+}
+
+// DefaultShareLinkService はShareLinkServiceの実装
+type DefaultShareLinkService struct {
+	shareLinkRepo repositories.ShareLinkRepository
+	mapRepo       repositories.MapRepository
+	viewerBaseURL string
+}
+
+// NewShareLinkService は新しいShareLinkServiceを作成する
+func NewShareLinkService(
+	shareLinkRepo repositories.ShareLinkRepository,
+	mapRepo repositories.MapRepository,
+	viewerBaseURL string,
+) ShareLinkService {
+	return &DefaultShareLinkService{
+		shareLinkRepo: shareLinkRepo,
+		mapRepo:       mapRepo,
+		viewerBaseURL: strings.TrimRight(viewerBaseURL, "/"),
+	}
+}
+
+// Create は新しい共有リンクを作成する
+func (s *DefaultShareLinkService) Create(ctx context.Context, userID, mapID string, req *models.ShareLinkCreate) (*models.ShareLink, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("有効期限は現在より後に設定してください")
+	}
+
+	slug, err := generateSlug()
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		ID:        uuid.New().String(),
+		MapID:     mapID,
+		Slug:      slug,
+		ExpiresAt: req.ExpiresAt,
+		MaxUses:   req.MaxUses,
+		CreatedBy: userID,
+	}
+
+	if req.Password != "" {
+		hash, err := utils.HashPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash = hash
+		link.HasPassword = true
+	}
+
+	if err := s.shareLinkRepo.Create(ctx, link); err != nil {
+		return nil, err
+	}
+
+	link.URL = s.linkURL(link.Slug)
+	return link, nil
+}
+
+// GetByMapID はマップの共有リンク一覧を取得する
+func (s *DefaultShareLinkService) GetByMapID(ctx context.Context, userID, mapID string) ([]*models.ShareLink, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	links, err := s.shareLinkRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		link.URL = s.linkURL(link.Slug)
+	}
+
+	return links, nil
+}
+
+// Revoke は共有リンクを無効化する
+func (s *DefaultShareLinkService) Revoke(ctx context.Context, userID, id string) error {
+	link, err := s.shareLinkRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if link == nil {
+		return errors.New("共有リンクが見つかりません")
+	}
+
+	if err := s.checkMapOwner(ctx, userID, link.MapID); err != nil {
+		return err
+	}
+
+	return s.shareLinkRepo.Revoke(ctx, link.ID, time.Now())
+}
+
+// Resolve は共有リンクを検証し、閲覧対象のマップを返す
+// 無効・期限切れ・利用上限に達したリンクの場合はnilを返す
+func (s *DefaultShareLinkService) Resolve(ctx context.Context, slug, password string) (*models.Map, error) {
+	link, err := s.shareLinkRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if link == nil || link.RevokedAt != nil || (link.ExpiresAt != nil && !now.Before(*link.ExpiresAt)) {
+		return nil, nil
+	}
+
+	if link.HasPassword {
+		if password == "" {
+			return nil, ErrShareLinkPasswordRequired
+		}
+		if err := utils.CheckPassword(link.PasswordHash, password); err != nil {
+			return nil, ErrShareLinkWrongPassword
+		}
+	}
+
+	// 利用回数の加算と上限の確認は同時に行う
+	ok, err := s.shareLinkRepo.Use(ctx, link.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return s.mapRepo.GetByID(ctx, link.MapID)
+}
+
+// linkURL は共有リンクのURLを生成する
+func (s *DefaultShareLinkService) linkURL(slug string) string {
+	return s.viewerBaseURL + "/s/" + slug
+}
+
+// checkMapOwner はマップの所有者であるか確認する
+func (s *DefaultShareLinkService) checkMapOwner(ctx context.Context, userID, mapID string) error {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このマップを編集する権限がありません")
+	}
+	return nil
+}
+
+// generateSlug は推測されにくい共有リンク用のスラッグを生成する
+func generateSlug() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
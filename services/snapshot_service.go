@@ -0,0 +1,315 @@
+// backend/services/snapshot_service.go
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+// SnapshotService はマップの公開スナップショットに関する操作を提供するインターフェース
+type SnapshotService interface {
+	Publish(ctx context.Context, userID, mapID string, req *models.MapSnapshotCreate) (*models.MapSnapshot, error)
+	GetByMapID(ctx context.Context, userID, mapID string) ([]*models.MapSnapshot, error)
+	GetByID(ctx context.Context, userID, id string) (*models.MapSnapshot, error)
+	Diff(ctx context.Context, userID, id string) (*models.SnapshotDiff, error)
+	Rollback(ctx context.Context, userID, id string, req *models.MapSnapshotRollback) (*models.MapSnapshot, error)
+	Unpublish(ctx context.Context, userID, mapID string) error
+	GetPublished(ctx context.Context, mapID string) (*models.MapSnapshot, error)
+}
+
+// DefaultSnapshotService はSnapshotServiceの実装
+type DefaultSnapshotService struct {
+	snapshotRepo repositories.MapSnapshotRepository
+	mapRepo      repositories.MapRepository
+	floorRepo    repositories.FloorRepository
+	pinRepo      repositories.PinRepository
+}
+
+// NewSnapshotService は新しいSnapshotServiceを作成する
+func NewSnapshotService(
+	snapshotRepo repositories.MapSnapshotRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+) SnapshotService {
+	return &DefaultSnapshotService{
+		snapshotRepo: snapshotRepo,
+		mapRepo:      mapRepo,
+		floorRepo:    floorRepo,
+		pinRepo:      pinRepo,
+	}
+}
+
+// Publish は編集中のマップ全体をスナップショットとして保存し、ビューワーで配信する
+func (s *DefaultSnapshotService) Publish(ctx context.Context, userID, mapID string, req *models.MapSnapshotCreate) (*models.MapSnapshot, error) {
+	if _, err := s.getOwnedMap(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	data, err := s.workingCopy(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &models.MapSnapshot{
+		ID:        uuid.New().String(),
+		MapID:     mapID,
+		Note:      req.Note,
+		CreatedBy: userID,
+		Data:      data,
+	}
+
+	if err := s.snapshotRepo.Create(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	if err := s.snapshotRepo.SetPublished(ctx, mapID, snapshot.ID); err != nil {
+		return nil, err
+	}
+	snapshot.IsPublished = true
+
+	return snapshot, nil
+}
+
+// GetByMapID はマップのスナップショット一覧を取得する
+func (s *DefaultSnapshotService) GetByMapID(ctx context.Context, userID, mapID string) ([]*models.MapSnapshot, error) {
+	if _, err := s.getOwnedMap(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+	return s.snapshotRepo.GetByMapID(ctx, mapID)
+}
+
+// GetByID はスナップショットを内容とともに取得する
+func (s *DefaultSnapshotService) GetByID(ctx context.Context, userID, id string) (*models.MapSnapshot, error) {
+	return s.getOwnedSnapshot(ctx, userID, id)
+}
+
+// Diff はスナップショットと編集中のデータとの差分を取得する
+func (s *DefaultSnapshotService) Diff(ctx context.Context, userID, id string) (*models.SnapshotDiff, error) {
+	snapshot, err := s.getOwnedSnapshot(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	working, err := s.workingCopy(ctx, snapshot.MapID)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.SnapshotDiff{
+		SnapshotID: snapshot.ID,
+		Version:    snapshot.Version,
+		MapFields:  []string{},
+		Floors:     []*models.SnapshotDiffEntry{},
+		Pins:       []*models.SnapshotDiffEntry{},
+	}
+
+	if snapshot.Data.Map.Title != working.Map.Title {
+		diff.MapFields = append(diff.MapFields, "title")
+	}
+	if snapshot.Data.Map.Description != working.Map.Description {
+		diff.MapFields = append(diff.MapFields, "description")
+	}
+
+	// フロアの差分
+	oldFloors := make(map[string]*models.Floor)
+	for _, floor := range snapshot.Data.Floors {
+		oldFloors[floor.ID] = floor
+	}
+	for _, floor := range working.Floors {
+		old, ok := oldFloors[floor.ID]
+		delete(oldFloors, floor.ID)
+		if !ok {
+			diff.Floors = append(diff.Floors, &models.SnapshotDiffEntry{ID: floor.ID, Label: floor.Name, Change: models.DiffAdded})
+			continue
+		}
+		if fields := floorChanges(old, floor); len(fields) > 0 {
+			diff.Floors = append(diff.Floors, &models.SnapshotDiffEntry{ID: floor.ID, Label: floor.Name, Change: models.DiffModified, Fields: fields})
+		}
+	}
+	for _, floor := range snapshot.Data.Floors {
+		if _, ok := oldFloors[floor.ID]; ok {
+			diff.Floors = append(diff.Floors, &models.SnapshotDiffEntry{ID: floor.ID, Label: floor.Name, Change: models.DiffRemoved})
+		}
+	}
+
+	// ピンの差分
+	oldPins := make(map[string]*models.Pin)
+	for _, pin := range snapshot.Data.Pins {
+		oldPins[pin.ID] = pin
+	}
+	for _, pin := range working.Pins {
+		old, ok := oldPins[pin.ID]
+		delete(oldPins, pin.ID)
+		if !ok {
+			diff.Pins = append(diff.Pins, &models.SnapshotDiffEntry{ID: pin.ID, Label: pin.Title, Change: models.DiffAdded})
+			continue
+		}
+		if fields := pinChanges(old, pin); len(fields) > 0 {
+			diff.Pins = append(diff.Pins, &models.SnapshotDiffEntry{ID: pin.ID, Label: pin.Title, Change: models.DiffModified, Fields: fields})
+		}
+	}
+	for _, pin := range snapshot.Data.Pins {
+		if _, ok := oldPins[pin.ID]; ok {
+			diff.Pins = append(diff.Pins, &models.SnapshotDiffEntry{ID: pin.ID, Label: pin.Title, Change: models.DiffRemoved})
+		}
+	}
+
+	diff.HasChanges = len(diff.MapFields) > 0 || len(diff.Floors) > 0 || len(diff.Pins) > 0
+
+	return diff, nil
+}
+
+// Rollback は過去のスナップショットをビューワーで配信する状態に戻す
+// 指定された場合は編集中のデータもスナップショットの内容に戻す
+func (s *DefaultSnapshotService) Rollback(ctx context.Context, userID, id string, req *models.MapSnapshotRollback) (*models.MapSnapshot, error) {
+	snapshot, err := s.getOwnedSnapshot(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RestoreWorkingCopy {
+		if err := s.snapshotRepo.RestoreWorkingCopy(ctx, snapshot.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.snapshotRepo.SetPublished(ctx, snapshot.MapID, snapshot.ID); err != nil {
+		return nil, err
+	}
+	snapshot.IsPublished = true
+
+	return snapshot, nil
+}
+
+// Unpublish はスナップショットの配信をやめ、編集中のデータを配信する状態に戻す
+func (s *DefaultSnapshotService) Unpublish(ctx context.Context, userID, mapID string) error {
+	if _, err := s.getOwnedMap(ctx, userID, mapID); err != nil {
+		return err
+	}
+	return s.snapshotRepo.ClearPublished(ctx, mapID)
+}
+
+// GetPublished はビューワーで配信するスナップショットを取得する
+// スナップショットが公開されていない場合はnilを返す
+func (s *DefaultSnapshotService) GetPublished(ctx context.Context, mapID string) (*models.MapSnapshot, error) {
+	return s.snapshotRepo.GetPublished(ctx, mapID)
+}
+
+// workingCopy は編集中のマップ・フロア・ピンを取得する
+func (s *DefaultSnapshotService) workingCopy(ctx context.Context, mapID string) (*models.MapSnapshotData, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+
+	floors, err := s.floorRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if floors == nil {
+		floors = []*models.Floor{}
+	}
+
+	pins := []*models.Pin{}
+	if len(floors) > 0 {
+		floorIDs := make([]string, len(floors))
+		for i, floor := range floors {
+			floorIDs[i] = floor.ID
+		}
+
+		pins, err = s.pinRepo.GetByFloorIDs(ctx, floorIDs)
+		if err != nil {
+			return nil, err
+		}
+		if pins == nil {
+			pins = []*models.Pin{}
+		}
+	}
+
+	return &models.MapSnapshotData{
+		Map:    map_,
+		Floors: floors,
+		Pins:   pins,
+	}, nil
+}
+
+// getOwnedSnapshot は所有者であることを確認してスナップショットを取得する
+func (s *DefaultSnapshotService) getOwnedSnapshot(ctx context.Context, userID, id string) (*models.MapSnapshot, error) {
+	snapshot, err := s.snapshotRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot == nil {
+		return nil, errors.New("スナップショットが見つかりません")
+	}
+	if _, err := s.getOwnedMap(ctx, userID, snapshot.MapID); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// getOwnedMap は所有者であることを確認してマップを取得する
+func (s *DefaultSnapshotService) getOwnedMap(ctx context.Context, userID, mapID string) (*models.Map, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+	return map_, nil
+}
+
+// floorChanges はフロアの変更された項目を返す
+func floorChanges(old, cur *models.Floor) []string {
+	var fields []string
+	if old.FloorNumber != cur.FloorNumber {
+		fields = append(fields, "floor_number")
+	}
+	if old.Name != cur.Name {
+		fields = append(fields, "name")
+	}
+	if old.ImageURL != cur.ImageURL {
+		fields = append(fields, "image_url")
+	}
+	return fields
+}
+
+// pinChanges はピンの変更された項目を返す
+func pinChanges(old, cur *models.Pin) []string {
+	var fields []string
+	if old.FloorID != cur.FloorID {
+		fields = append(fields, "floor_id")
+	}
+	if old.Title != cur.Title {
+		fields = append(fields, "title")
+	}
+	if old.Description != cur.Description {
+		fields = append(fields, "description")
+	}
+	if old.Category != cur.Category {
+		fields = append(fields, "category")
+	}
+	if old.XPosition != cur.XPosition || old.YPosition != cur.YPosition {
+		fields = append(fields, "position")
+	}
+	if old.ImageURL != cur.ImageURL {
+		fields = append(fields, "image_url")
+	}
+	return fields
+}
@@ -0,0 +1,455 @@
+// backend/services/stamp_rally_service.go
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+// StampRallyService はスタンプラリーに関する操作を提供するインターフェース
+type StampRallyService interface {
+	Create(ctx context.Context, userID, mapID string, req *models.StampRallyCreate) (*models.StampRally, error)
+	GetByID(ctx context.Context, userID, id string) (*models.StampRally, error)
+	GetByMapID(ctx context.Context, userID, mapID string) ([]*models.StampRally, error)
+	Update(ctx context.Context, userID, id string, req *models.StampRallyUpdate) (*models.StampRally, error)
+	Delete(ctx context.Context, userID, id string) error
+	GetCheckpoints(ctx context.Context, userID, id string) ([]*models.RallyCheckpoint, error)
+	GetStats(ctx context.Context, userID, id string) (*models.RallyStats, error)
+
+	StartSession(ctx context.Context, userID, rallyID string) (*models.RallySession, error)
+	CheckIn(ctx context.Context, userID, rallyID string, req *models.RallyCheckIn) (*models.RallyProgress, error)
+	GetProgress(ctx context.Context, rallyID, sessionID, token string) (*models.RallyProgress, error)
+}
+
+// DefaultStampRallyService はStampRallyServiceの実装
+type DefaultStampRallyService struct {
+	rallyRepo     repositories.StampRallyRepository
+	mapRepo       repositories.MapRepository
+	floorRepo     repositories.FloorRepository
+	pinRepo       repositories.PinRepository
+	secret        []byte
+	viewerBaseURL string
+}
+
+// NewStampRallyService は新しいStampRallyServiceを作成する
+// secretはチェックインコードの署名に使用する
+func NewStampRallyService(
+	rallyRepo repositories.StampRallyRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	secret string,
+	viewerBaseURL string,
+) StampRallyService {
+	return &DefaultStampRallyService{
+		rallyRepo:     rallyRepo,
+		mapRepo:       mapRepo,
+		floorRepo:     floorRepo,
+		pinRepo:       pinRepo,
+		secret:        []byte(secret),
+		viewerBaseURL: strings.TrimRight(viewerBaseURL, "/"),
+	}
+}
+
+// Create は新しいスタンプラリーを作成する
+func (s *DefaultStampRallyService) Create(ctx context.Context, userID, mapID string, req *models.StampRallyCreate) (*models.StampRally, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	rally := &models.StampRally{
+		ID:               uuid.New().String(),
+		MapID:            mapID,
+		Title:            req.Title,
+		Description:      req.Description,
+		CompletionRule:   req.CompletionRule,
+		RequiredCount:    req.RequiredCount,
+		IsActive:         true,
+		CheckpointPinIDs: req.CheckpointPinIDs,
+	}
+
+	if err := s.validate(ctx, rally); err != nil {
+		return nil, err
+	}
+
+	if err := s.rallyRepo.Create(ctx, rally); err != nil {
+		return nil, err
+	}
+
+	return rally, nil
+}
+
+// GetByID はIDによりスタンプラリーを取得する
+// 公開中でないマップのスタンプラリーはメンバー以外にはnilを返す
+func (s *DefaultStampRallyService) GetByID(ctx context.Context, userID, id string) (*models.StampRally, error) {
+	rally, err := s.rallyRepo.GetByID(ctx, id)
+	if err != nil || rally == nil {
+		return nil, err
+	}
+
+	canView, err := s.canViewRallyMap(ctx, userID, rally)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, nil
+	}
+	return rally, nil
+}
+
+// GetByMapID はマップのスタンプラリー一覧を取得する（所有者のみ）
+func (s *DefaultStampRallyService) GetByMapID(ctx context.Context, userID, mapID string) ([]*models.StampRally, error) {
+	if err := s.checkMapOwner(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+	return s.rallyRepo.GetByMapID(ctx, mapID)
+}
+
+// Update はスタンプラリーを更新する
+func (s *DefaultStampRallyService) Update(ctx context.Context, userID, id string, req *models.StampRallyUpdate) (*models.StampRally, error) {
+	rally, err := s.getOwnedRally(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Title != "" {
+		rally.Title = req.Title
+	}
+	if req.Description != "" {
+		rally.Description = req.Description
+	}
+	if req.CompletionRule != "" {
+		rally.CompletionRule = req.CompletionRule
+	}
+	if req.RequiredCount != nil {
+		rally.RequiredCount = *req.RequiredCount
+	}
+	if req.IsActive != nil {
+		rally.IsActive = *req.IsActive
+	}
+	if req.CheckpointPinIDs != nil {
+		rally.CheckpointPinIDs = req.CheckpointPinIDs
+	}
+
+	if err := s.validate(ctx, rally); err != nil {
+		return nil, err
+	}
+
+	if err := s.rallyRepo.Update(ctx, rally); err != nil {
+		return nil, err
+	}
+
+	return rally, nil
+}
+
+// Delete はスタンプラリーを削除する
+func (s *DefaultStampRallyService) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.getOwnedRally(ctx, userID, id); err != nil {
+		return err
+	}
+	return s.rallyRepo.Delete(ctx, id)
+}
+
+// GetCheckpoints はチェックポイントごとのチェックインコードとURLを取得する
+func (s *DefaultStampRallyService) GetCheckpoints(ctx context.Context, userID, id string) ([]*models.RallyCheckpoint, error) {
+	rally, err := s.getOwnedRally(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints := []*models.RallyCheckpoint{}
+	for _, pinID := range rally.CheckpointPinIDs {
+		pin, err := s.pinRepo.GetByID(ctx, pinID)
+		if err != nil {
+			return nil, err
+		}
+		if pin == nil {
+			continue
+		}
+
+		code := s.checkInCode(rally.ID, pin.ID)
+		query := url.Values{}
+		query.Set("floor", pin.FloorID)
+		query.Set("pin", pin.ID)
+		query.Set("rally", rally.ID)
+		query.Set("code", code)
+
+		checkpoints = append(checkpoints, &models.RallyCheckpoint{
+			PinID:      pin.ID,
+			PinTitle:   pin.Title,
+			Code:       code,
+			CheckInURL: s.viewerBaseURL + "/" + url.PathEscape(rally.MapID) + "?" + query.Encode(),
+		})
+	}
+
+	return checkpoints, nil
+}
+
+// GetStats はスタンプラリーの達成状況を取得する
+func (s *DefaultStampRallyService) GetStats(ctx context.Context, userID, id string) (*models.RallyStats, error) {
+	if _, err := s.getOwnedRally(ctx, userID, id); err != nil {
+		return nil, err
+	}
+	return s.rallyRepo.GetStats(ctx, id)
+}
+
+// StartSession は来場者の匿名セッションを開始する
+func (s *DefaultStampRallyService) StartSession(ctx context.Context, userID, rallyID string) (*models.RallySession, error) {
+	rally, err := s.getActiveRally(ctx, userID, rallyID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := generateToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.RallySession{
+		ID:      uuid.New().String(),
+		RallyID: rally.ID,
+		Token:   token,
+	}
+
+	if err := s.rallyRepo.CreateSession(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// CheckIn は署名付きコードを検証してスタンプを記録する
+func (s *DefaultStampRallyService) CheckIn(ctx context.Context, userID, rallyID string, req *models.RallyCheckIn) (*models.RallyProgress, error) {
+	rally, err := s.getActiveRally(ctx, userID, rallyID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.getSession(ctx, rally.ID, req.SessionID, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if !containsString(rally.CheckpointPinIDs, req.PinID) {
+		return nil, errors.New("このピンはチェックポイントではありません")
+	}
+
+	// コードの検証（署名鍵を知らなければ生成できない）
+	expected := s.checkInCode(rally.ID, req.PinID)
+	if !hmac.Equal([]byte(expected), []byte(req.Code)) {
+		return nil, errors.New("無効なチェックインコードです")
+	}
+
+	if err := s.rallyRepo.CreateCheckIn(ctx, session.ID, req.PinID); err != nil {
+		return nil, err
+	}
+
+	return s.progress(ctx, rally, session)
+}
+
+// GetProgress はセッションの進捗を取得する
+func (s *DefaultStampRallyService) GetProgress(ctx context.Context, rallyID, sessionID, token string) (*models.RallyProgress, error) {
+	rally, err := s.rallyRepo.GetByID(ctx, rallyID)
+	if err != nil {
+		return nil, err
+	}
+	if rally == nil {
+		return nil, errors.New("スタンプラリーが見つかりません")
+	}
+
+	session, err := s.getSession(ctx, rally.ID, sessionID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.progress(ctx, rally, session)
+}
+
+// progress は達成条件を評価し、達成していればセッションを完了にする
+func (s *DefaultStampRallyService) progress(ctx context.Context, rally *models.StampRally, session *models.RallySession) (*models.RallyProgress, error) {
+	checkedIn, err := s.rallyRepo.GetCheckedInPinIDs(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 現在のチェックポイントに含まれるものだけを数える
+	collected := []string{}
+	for _, pinID := range checkedIn {
+		if containsString(rally.CheckpointPinIDs, pinID) {
+			collected = append(collected, pinID)
+		}
+	}
+
+	progress := &models.RallyProgress{
+		SessionID:       session.ID,
+		RallyID:         rally.ID,
+		CollectedPinIDs: collected,
+		CollectedCount:  len(collected),
+		RequiredCount:   requiredCount(rally),
+		CompletedAt:     session.CompletedAt,
+	}
+
+	if session.CompletedAt == nil && progress.CollectedCount >= progress.RequiredCount {
+		now := time.Now()
+		if err := s.rallyRepo.CompleteSession(ctx, session.ID, now); err != nil {
+			return nil, err
+		}
+		progress.CompletedAt = &now
+	}
+	progress.Completed = progress.CompletedAt != nil
+
+	return progress, nil
+}
+
+// validate はチェックポイントと達成条件を検証する
+func (s *DefaultStampRallyService) validate(ctx context.Context, rally *models.StampRally) error {
+	if len(rally.CheckpointPinIDs) == 0 {
+		return errors.New("チェックポイントを1つ以上指定してください")
+	}
+
+	seen := make(map[string]bool)
+	for _, pinID := range rally.CheckpointPinIDs {
+		if seen[pinID] {
+			return errors.New("チェックポイントが重複しています")
+		}
+		seen[pinID] = true
+
+		pin, err := s.pinRepo.GetByID(ctx, pinID)
+		if err != nil {
+			return err
+		}
+		if pin == nil {
+			return errors.New("ピンが見つかりません")
+		}
+		floor, err := s.floorRepo.GetByID(ctx, pin.FloorID)
+		if err != nil {
+			return err
+		}
+		if floor == nil || floor.MapID != rally.MapID {
+			return errors.New("チェックポイントはこのマップのピンから選択してください")
+		}
+	}
+
+	switch rally.CompletionRule {
+	case models.RallyRuleAll:
+		rally.RequiredCount = 0
+	case models.RallyRuleCount:
+		if rally.RequiredCount < 1 || rally.RequiredCount > len(rally.CheckpointPinIDs) {
+			return errors.New("必要なスタンプ数はチェックポイント数以下で指定してください")
+		}
+	default:
+		return errors.New("無効な達成条件です")
+	}
+
+	return nil
+}
+
+// checkInCode はピンごとのチェックインコードを生成する
+func (s *DefaultStampRallyService) checkInCode(rallyID, pinID string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("rally:" + rallyID + ":" + pinID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// getSession はセッションを取得してトークンを検証する
+func (s *DefaultStampRallyService) getSession(ctx context.Context, rallyID, sessionID, token string) (*models.RallySession, error) {
+	session, err := s.rallyRepo.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.RallyID != rallyID {
+		return nil, errors.New("セッションが見つかりません")
+	}
+	if subtle.ConstantTimeCompare([]byte(session.Token), []byte(token)) != 1 {
+		return nil, errors.New("無効なトークンです")
+	}
+	return session, nil
+}
+
+// getActiveRally は開催中のスタンプラリーを取得する
+// 公開中でないマップのスタンプラリーはメンバー以外には存在しないものとして扱う
+func (s *DefaultStampRallyService) getActiveRally(ctx context.Context, userID, id string) (*models.StampRally, error) {
+	rally, err := s.GetByID(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if rally == nil {
+		return nil, errors.New("スタンプラリーが見つかりません")
+	}
+	if !rally.IsActive {
+		return nil, errors.New("このスタンプラリーは開催されていません")
+	}
+	return rally, nil
+}
+
+// canViewRallyMap はスタンプラリーのマップをユーザーが閲覧できるか判定する
+func (s *DefaultStampRallyService) canViewRallyMap(ctx context.Context, userID string, rally *models.StampRally) (bool, error) {
+	mapData, err := s.mapRepo.GetByID(ctx, rally.MapID)
+	if err != nil || mapData == nil {
+		return false, err
+	}
+	return canViewMap(ctx, s.mapRepo, mapData, userID)
+}
+
+// getOwnedRally は所有者であることを確認してスタンプラリーを取得する
+func (s *DefaultStampRallyService) getOwnedRally(ctx context.Context, userID, id string) (*models.StampRally, error) {
+	rally, err := s.rallyRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rally == nil {
+		return nil, errors.New("スタンプラリーが見つかりません")
+	}
+	if err := s.checkMapOwner(ctx, userID, rally.MapID); err != nil {
+		return nil, err
+	}
+	return rally, nil
+}
+
+// checkMapOwner はマップの所有者であるか確認する
+func (s *DefaultStampRallyService) checkMapOwner(ctx context.Context, userID, mapID string) error {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このマップを編集する権限がありません")
+	}
+	return nil
+}
+
+// requiredCount は達成に必要なスタンプ数を返す
+func requiredCount(rally *models.StampRally) int {
+	if rally.CompletionRule == models.RallyRuleCount {
+		return rally.RequiredCount
+	}
+	return len(rally.CheckpointPinIDs)
+}
+
+// containsString はスライスに値が含まれるか判定する
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
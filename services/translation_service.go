@@ -0,0 +1,366 @@
+// backend/services/translation_service.go
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// TranslationService はマップ・フロア・ピンの多言語コンテンツに関する操作を提供するインターフェース
+type TranslationService interface {
+	Negotiate(lang, acceptLanguage string) string
+	DefaultLocale() string
+	Get(ctx context.Context, userID, mapID, locale string) (*models.MapTranslation, error)
+	Save(ctx context.Context, userID, mapID, locale string, req *models.MapTranslation) (*models.MapTranslation, error)
+	Delete(ctx context.Context, userID, mapID, locale string) error
+	Report(ctx context.Context, userID, mapID string) (*models.TranslationReport, error)
+	Apply(ctx context.Context, locale string, m *models.Map, floors []*models.Floor, pins []*models.Pin) error
+}
+
+// DefaultTranslationService はTranslationServiceの実装
+// マップ・フロア・ピンの元の項目はデフォルト言語として扱う
+type DefaultTranslationService struct {
+	translationRepo repositories.TranslationRepository
+	mapRepo         repositories.MapRepository
+	floorRepo       repositories.FloorRepository
+	pinRepo         repositories.PinRepository
+	locales         []string
+	matcher         *utils.LocaleMatcher
+}
+
+// NewTranslationService は新しいTranslationServiceを作成する
+// localesの先頭をデフォルト言語とする
+func NewTranslationService(
+	translationRepo repositories.TranslationRepository,
+	mapRepo repositories.MapRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	locales []string,
+) TranslationService {
+	return &DefaultTranslationService{
+		translationRepo: translationRepo,
+		mapRepo:         mapRepo,
+		floorRepo:       floorRepo,
+		pinRepo:         pinRepo,
+		locales:         locales,
+		matcher:         utils.NewLocaleMatcher(locales),
+	}
+}
+
+// Negotiate は?lang=またはAccept-Languageから表示する言語を決定する
+func (s *DefaultTranslationService) Negotiate(lang, acceptLanguage string) string {
+	return s.matcher.Match(lang, acceptLanguage)
+}
+
+// DefaultLocale はデフォルト言語を返す
+func (s *DefaultTranslationService) DefaultLocale() string {
+	return s.locales[0]
+}
+
+// Get はマップ全体の1言語分の翻訳を取得する
+func (s *DefaultTranslationService) Get(ctx context.Context, userID, mapID, locale string) (*models.MapTranslation, error) {
+	if err := s.checkLocale(locale); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedMap(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	translations, err := s.translationRepo.GetByMapID(ctx, mapID, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.MapTranslation{
+		Locale: locale,
+		Map:    &models.MapTranslationFields{},
+		Floors: make(map[string]*models.FloorTranslationFields),
+		Pins:   make(map[string]*models.PinTranslationFields),
+	}
+
+	for _, t := range translations {
+		value := t.Value
+		switch t.EntityType {
+		case models.TranslationEntityMap:
+			switch t.Field {
+			case models.TranslationFieldTitle:
+				result.Map.Title = &value
+			case models.TranslationFieldDescription:
+				result.Map.Description = &value
+			}
+		case models.TranslationEntityFloor:
+			if result.Floors[t.EntityID] == nil {
+				result.Floors[t.EntityID] = &models.FloorTranslationFields{}
+			}
+			if t.Field == models.TranslationFieldName {
+				result.Floors[t.EntityID].Name = &value
+			}
+		case models.TranslationEntityPin:
+			if result.Pins[t.EntityID] == nil {
+				result.Pins[t.EntityID] = &models.PinTranslationFields{}
+			}
+			switch t.Field {
+			case models.TranslationFieldTitle:
+				result.Pins[t.EntityID].Title = &value
+			case models.TranslationFieldDescription:
+				result.Pins[t.EntityID].Description = &value
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Save はマップ全体の1言語分の翻訳を保存する
+func (s *DefaultTranslationService) Save(ctx context.Context, userID, mapID, locale string, req *models.MapTranslation) (*models.MapTranslation, error) {
+	if err := s.checkLocale(locale); err != nil {
+		return nil, err
+	}
+	if _, err := s.getOwnedMap(ctx, userID, mapID); err != nil {
+		return nil, err
+	}
+
+	floorIDs, pinIDs, err := s.entityIDs(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	var translations []*models.Translation
+	add := func(entityType, entityID, field string, value *string) {
+		if value == nil {
+			return
+		}
+		translations = append(translations, &models.Translation{
+			MapID:      mapID,
+			EntityType: entityType,
+			EntityID:   entityID,
+			Locale:     locale,
+			Field:      field,
+			Value:      *value,
+		})
+	}
+
+	if req.Map != nil {
+		add(models.TranslationEntityMap, mapID, models.TranslationFieldTitle, req.Map.Title)
+		add(models.TranslationEntityMap, mapID, models.TranslationFieldDescription, req.Map.Description)
+	}
+	for floorID, fields := range req.Floors {
+		if !floorIDs[floorID] {
+			return nil, errors.New("このマップに存在しないフロアが含まれています")
+		}
+		if fields != nil {
+			add(models.TranslationEntityFloor, floorID, models.TranslationFieldName, fields.Name)
+		}
+	}
+	for pinID, fields := range req.Pins {
+		if !pinIDs[pinID] {
+			return nil, errors.New("このマップに存在しないピンが含まれています")
+		}
+		if fields != nil {
+			add(models.TranslationEntityPin, pinID, models.TranslationFieldTitle, fields.Title)
+			add(models.TranslationEntityPin, pinID, models.TranslationFieldDescription, fields.Description)
+		}
+	}
+
+	if err := s.translationRepo.Save(ctx, translations); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, userID, mapID, locale)
+}
+
+// Delete はマップの指定言語の翻訳をすべて削除する
+func (s *DefaultTranslationService) Delete(ctx context.Context, userID, mapID, locale string) error {
+	if err := s.checkLocale(locale); err != nil {
+		return err
+	}
+	if _, err := s.getOwnedMap(ctx, userID, mapID); err != nil {
+		return err
+	}
+	return s.translationRepo.DeleteByLocale(ctx, mapID, locale)
+}
+
+// Report は言語ごとの翻訳の充足状況を取得する
+// 元の値が空の項目は翻訳対象に含めない
+func (s *DefaultTranslationService) Report(ctx context.Context, userID, mapID string) (*models.TranslationReport, error) {
+	map_, err := s.getOwnedMap(ctx, userID, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	floors, err := s.floorRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	pins, err := s.getPins(ctx, floors)
+	if err != nil {
+		return nil, err
+	}
+
+	// 翻訳が必要な項目を列挙
+	var keys []*models.TranslationKey
+	addKey := func(entityType, entityID, field, source string) {
+		if source != "" {
+			keys = append(keys, &models.TranslationKey{EntityType: entityType, EntityID: entityID, Field: field})
+		}
+	}
+	addKey(models.TranslationEntityMap, map_.ID, models.TranslationFieldTitle, map_.Title)
+	addKey(models.TranslationEntityMap, map_.ID, models.TranslationFieldDescription, map_.Description)
+	for _, floor := range floors {
+		addKey(models.TranslationEntityFloor, floor.ID, models.TranslationFieldName, floor.Name)
+	}
+	for _, pin := range pins {
+		addKey(models.TranslationEntityPin, pin.ID, models.TranslationFieldTitle, pin.Title)
+		addKey(models.TranslationEntityPin, pin.ID, models.TranslationFieldDescription, pin.Description)
+	}
+
+	translations, err := s.translationRepo.GetByMapID(ctx, mapID, "")
+	if err != nil {
+		return nil, err
+	}
+	translated := make(map[string]map[models.TranslationKey]bool)
+	for _, t := range translations {
+		if translated[t.Locale] == nil {
+			translated[t.Locale] = make(map[models.TranslationKey]bool)
+		}
+		translated[t.Locale][models.TranslationKey{EntityType: t.EntityType, EntityID: t.EntityID, Field: t.Field}] = true
+	}
+
+	report := &models.TranslationReport{
+		MapID:         mapID,
+		DefaultLocale: s.DefaultLocale(),
+		Locales:       []*models.LocaleCompleteness{},
+	}
+
+	for _, locale := range s.locales[1:] {
+		completeness := &models.LocaleCompleteness{
+			Locale:  locale,
+			Total:   len(keys),
+			Missing: []*models.TranslationKey{},
+		}
+		for _, key := range keys {
+			if translated[locale][*key] {
+				completeness.Translated++
+			} else {
+				completeness.Missing = append(completeness.Missing, key)
+			}
+		}
+		if completeness.Total > 0 {
+			completeness.Percent = float64(completeness.Translated) * 100 / float64(completeness.Total)
+		} else {
+			completeness.Percent = 100
+		}
+		report.Locales = append(report.Locales, completeness)
+	}
+
+	return report, nil
+}
+
+// Apply はビューワーに返すマップ・フロア・ピンに翻訳を反映する
+// 翻訳がない項目はデフォルト言語の値のままとする
+func (s *DefaultTranslationService) Apply(ctx context.Context, locale string, m *models.Map, floors []*models.Floor, pins []*models.Pin) error {
+	if locale == s.DefaultLocale() {
+		return nil
+	}
+
+	translations, err := s.translationRepo.GetByMapID(ctx, m.ID, locale)
+	if err != nil {
+		return err
+	}
+	if len(translations) == 0 {
+		return nil
+	}
+
+	values := make(map[models.TranslationKey]string)
+	for _, t := range translations {
+		values[models.TranslationKey{EntityType: t.EntityType, EntityID: t.EntityID, Field: t.Field}] = t.Value
+	}
+	translate := func(target *string, entityType, entityID, field string) {
+		if value, ok := values[models.TranslationKey{EntityType: entityType, EntityID: entityID, Field: field}]; ok {
+			*target = value
+		}
+	}
+
+	translate(&m.Title, models.TranslationEntityMap, m.ID, models.TranslationFieldTitle)
+	translate(&m.Description, models.TranslationEntityMap, m.ID, models.TranslationFieldDescription)
+	for _, floor := range floors {
+		translate(&floor.Name, models.TranslationEntityFloor, floor.ID, models.TranslationFieldName)
+	}
+	for _, pin := range pins {
+		translate(&pin.Title, models.TranslationEntityPin, pin.ID, models.TranslationFieldTitle)
+		translate(&pin.Description, models.TranslationEntityPin, pin.ID, models.TranslationFieldDescription)
+	}
+
+	return nil
+}
+
+// checkLocale は翻訳を保存できる言語か確認する
+func (s *DefaultTranslationService) checkLocale(locale string) error {
+	if !s.matcher.Supports(locale) {
+		return errors.New("対応していない言語です")
+	}
+	if locale == s.DefaultLocale() {
+		return errors.New("デフォルト言語の内容はマップ・フロア・ピンを直接編集してください")
+	}
+	return nil
+}
+
+// entityIDs はマップに属するフロアとピンのIDを取得する
+func (s *DefaultTranslationService) entityIDs(ctx context.Context, mapID string) (map[string]bool, map[string]bool, error) {
+	floors, err := s.floorRepo.GetByMapID(ctx, mapID)
+	if err != nil {
+		return nil, nil, err
+	}
+	pins, err := s.getPins(ctx, floors)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	floorIDs := make(map[string]bool)
+	for _, floor := range floors {
+		floorIDs[floor.ID] = true
+	}
+	pinIDs := make(map[string]bool)
+	for _, pin := range pins {
+		pinIDs[pin.ID] = true
+	}
+
+	return floorIDs, pinIDs, nil
+}
+
+// getPins はフロアに属するピンを取得する
+func (s *DefaultTranslationService) getPins(ctx context.Context, floors []*models.Floor) ([]*models.Pin, error) {
+	if len(floors) == 0 {
+		return nil, nil
+	}
+
+	floorIDs := make([]string, len(floors))
+	for i, floor := range floors {
+		floorIDs[i] = floor.ID
+	}
+
+	return s.pinRepo.GetByFloorIDs(ctx, floorIDs)
+}
+
+// getOwnedMap は所有者であることを確認してマップを取得する
+func (s *DefaultTranslationService) getOwnedMap(ctx context.Context, userID, mapID string) (*models.Map, error) {
+	map_, err := s.mapRepo.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if map_ == nil {
+		return nil, errors.New("マップが見つかりません")
+	}
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canEdit {
+		return nil, errors.New("このマップを編集する権限がありません")
+	}
+	return map_, nil
+}
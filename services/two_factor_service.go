@@ -0,0 +1,332 @@
+// backend/services/two_factor_service.go
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+	"github.com/shimaf4979/pamfree-backend/utils"
+)
+
+// recoveryCodeCount は一度に発行する回復コードの数
+const recoveryCodeCount = 10
+
+// 二段階認証で呼び出し元がステータスコードを判定するためのエラー
+var (
+	ErrTwoFactorInvalidCode     = errors.New("確認コードが正しくありません")
+	ErrTwoFactorNotEnabled      = errors.New("二段階認証が有効になっていません")
+	ErrTwoFactorNotStarted      = errors.New("先に二段階認証の設定を開始してください")
+	ErrTwoFactorAlreadyEnabled  = errors.New("二段階認証は既に有効です")
+	ErrTwoFactorWrongPassword   = errors.New("パスワードが正しくありません")
+	ErrTwoFactorRequiredByAdmin = errors.New("管理者は二段階認証を無効にできません")
+)
+
+// TwoFactorService はTOTPによる二段階認証とセキュリティ設定を提供するインターフェース
+type TwoFactorService interface {
+	GetStatus(ctx context.Context, userID string) (*models.TwoFactorStatus, error)
+	Setup(ctx context.Context, userID string) (*models.TwoFactorSetup, error)
+	Enable(ctx context.Context, userID, code string) ([]string, error)
+	Disable(ctx context.Context, userID string, req *models.TwoFactorDisable) error
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	IsEnabled(ctx context.Context, userID string) (bool, error)
+	Verify(ctx context.Context, userID, code string) (bool, error)
+	AdminTwoFactorRequired(ctx context.Context) (bool, error)
+	GetSettings(ctx context.Context) (*models.SecuritySettings, error)
+	UpdateSettings(ctx context.Context, req *models.SecuritySettingsUpdate) (*models.SecuritySettings, error)
+}
+
+// DefaultTwoFactorService はTwoFactorServiceの実装
+type DefaultTwoFactorService struct {
+	twoFactorRepo repositories.TwoFactorRepository
+	settingsRepo  repositories.SecuritySettingsRepository
+	userRepo      repositories.UserRepository
+	issuer        string
+}
+
+// NewTwoFactorService は新しいTwoFactorServiceを作成する
+func NewTwoFactorService(
+	twoFactorRepo repositories.TwoFactorRepository,
+	settingsRepo repositories.SecuritySettingsRepository,
+	userRepo repositories.UserRepository,
+	issuer string,
+) TwoFactorService {
+	return &DefaultTwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		settingsRepo:  settingsRepo,
+		userRepo:      userRepo,
+		issuer:        issuer,
+	}
+}
+
+// GetStatus はユーザーの二段階認証の状態を取得する
+func (s *DefaultTwoFactorService) GetStatus(ctx context.Context, userID string) (*models.TwoFactorStatus, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactorRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{}
+	if twoFactor.Enabled() {
+		status.Enabled = true
+		status.EnabledAt = twoFactor.EnabledAt
+		if status.RecoveryCodesRemaining, err = s.twoFactorRepo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if status.Required, err = s.requiredFor(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Setup は新しい秘密鍵を発行し、認証アプリへの登録情報を返す
+// 確認コードを検証するまで二段階認証は有効にならない
+func (s *DefaultTwoFactorService) Setup(ctx context.Context, userID string) (*models.TwoFactorSetup, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	twoFactor, err := s.twoFactorRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.SavePending(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// Enable は認証アプリの確認コードを検証して二段階認証を有効にし、回復コードを返す
+// 回復コードはハッシュ化して保存するため、平文を返すのはこのときだけ
+func (s *DefaultTwoFactorService) Enable(ctx context.Context, userID, code string) ([]string, error) {
+	twoFactor, err := s.twoFactorRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor == nil {
+		return nil, ErrTwoFactorNotStarted
+	}
+	if twoFactor.Enabled() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	now := time.Now()
+	step, ok := utils.ValidateTOTP(twoFactor.Secret, code, now, twoFactor.LastUsedStep)
+	if !ok {
+		return nil, ErrTwoFactorInvalidCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.Enable(ctx, userID, step, now, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable はパスワードと確認コードを検証して二段階認証を無効にする
+// パスワードを持たないユーザーは確認コードのみを検証する
+func (s *DefaultTwoFactorService) Disable(ctx context.Context, userID string, req *models.TwoFactorDisable) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Password != "" {
+		if err := utils.CheckPassword(user.Password, req.Password); err != nil {
+			return ErrTwoFactorWrongPassword
+		}
+	}
+
+	required, err := s.requiredFor(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorRequiredByAdmin
+	}
+
+	if _, err := s.Verify(ctx, userID, req.Code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.Delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes は確認コードを検証して回復コードを発行し直す
+// 以前の回復コードはすべて使えなくなる
+func (s *DefaultTwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	if _, err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// IsEnabled はユーザーの二段階認証が有効か判定する
+func (s *DefaultTwoFactorService) IsEnabled(ctx context.Context, userID string) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return twoFactor.Enabled(), nil
+}
+
+// Verify は認証アプリの確認コードまたは回復コードを検証する
+// 回復コードを使った場合はtrueを返す。どちらのコードも一度しか使えない
+func (s *DefaultTwoFactorService) Verify(ctx context.Context, userID, code string) (bool, error) {
+	twoFactor, err := s.twoFactorRepo.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !twoFactor.Enabled() {
+		return false, ErrTwoFactorNotEnabled
+	}
+
+	now := time.Now()
+	if step, ok := utils.ValidateTOTP(twoFactor.Secret, code, now, twoFactor.LastUsedStep); ok {
+		// 同じ確認コードが同時に使われた場合に備え、間隔番号の更新で再利用を判定する
+		used, err := s.twoFactorRepo.UseStep(ctx, userID, step)
+		if err != nil {
+			return false, err
+		}
+		if !used {
+			return false, ErrTwoFactorInvalidCode
+		}
+		return false, nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, ErrTwoFactorInvalidCode
+	}
+	used, err := s.twoFactorRepo.UseRecoveryCode(ctx, userID, hashRecoveryCode(normalized), now)
+	if err != nil {
+		return false, err
+	}
+	if !used {
+		return false, ErrTwoFactorInvalidCode
+	}
+
+	return true, nil
+}
+
+// AdminTwoFactorRequired は管理者に二段階認証が必須となっているか判定する
+func (s *DefaultTwoFactorService) AdminTwoFactorRequired(ctx context.Context) (bool, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return false, err
+	}
+	return settings.RequireAdminTwoFactor, nil
+}
+
+// GetSettings はセキュリティ設定を取得する
+func (s *DefaultTwoFactorService) GetSettings(ctx context.Context) (*models.SecuritySettings, error) {
+	return s.settingsRepo.Get(ctx)
+}
+
+// UpdateSettings はセキュリティ設定のうち指定された項目を更新する
+func (s *DefaultTwoFactorService) UpdateSettings(ctx context.Context, req *models.SecuritySettingsUpdate) (*models.SecuritySettings, error) {
+	settings, err := s.settingsRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RequireAdminTwoFactor != nil {
+		settings.RequireAdminTwoFactor = *req.RequireAdminTwoFactor
+	}
+
+	if err := s.settingsRepo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+// getUser はユーザーを取得する
+func (s *DefaultTwoFactorService) getUser(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("ユーザーが見つかりません")
+	}
+	return user, nil
+}
+
+// requiredFor はユーザーに二段階認証が必須となっているか判定する
+func (s *DefaultTwoFactorService) requiredFor(ctx context.Context, user *models.User) (bool, error) {
+	if user.Role != "admin" {
+		return false, nil
+	}
+	return s.AdminTwoFactorRequired(ctx)
+}
+
+// generateRecoveryCodes は回復コードとそのハッシュを生成する
+// 回復コードは読み間違えにくい文字で「XXXXX-XXXXX」の形式とする
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		raw, err := generateInviteCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = hashRecoveryCode(raw)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode は入力された回復コードから区切り文字と空白を取り除き、大文字にそろえる
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code))
+}
+
+// hashRecoveryCode は回復コードをハッシュ化する
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,183 @@
+// backend/services/upload_service.go
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shimaf4979/pamfree-backend/models"
+	"github.com/shimaf4979/pamfree-backend/repositories"
+)
+
+// UploadService は署名付き直接アップロードに関する操作を提供するインターフェース
+type UploadService interface {
+	CreateTicket(ctx context.Context, userID string, req *models.UploadSignRequest, ttl time.Duration) (*models.UploadTicket, error)
+	GetPendingTicket(ctx context.Context, userID, publicID string) (*models.UploadTicket, error)
+	CompleteTicket(ctx context.Context, ticket *models.UploadTicket, imageURL string) (interface{}, error)
+}
+
+// DefaultUploadService はUploadServiceの実装
+type DefaultUploadService struct {
+	ticketRepo repositories.UploadTicketRepository
+	floorRepo  repositories.FloorRepository
+	pinRepo    repositories.PinRepository
+	mapRepo    repositories.MapRepository
+}
+
+// NewUploadService は新しいUploadServiceを作成する
+func NewUploadService(
+	ticketRepo repositories.UploadTicketRepository,
+	floorRepo repositories.FloorRepository,
+	pinRepo repositories.PinRepository,
+	mapRepo repositories.MapRepository,
+) UploadService {
+	return &DefaultUploadService{
+		ticketRepo: ticketRepo,
+		floorRepo:  floorRepo,
+		pinRepo:    pinRepo,
+		mapRepo:    mapRepo,
+	}
+}
+
+// CreateTicket はフロアまたはピンに紐づくアップロードチケットを発行する
+func (s *DefaultUploadService) CreateTicket(ctx context.Context, userID string, req *models.UploadSignRequest, ttl time.Duration) (*models.UploadTicket, error) {
+	// 対象の所有者を確認
+	if err := s.checkTargetOwner(ctx, userID, req.TargetType, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	// 期限切れのチケットを掃除
+	if err := s.ticketRepo.DeleteExpired(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// 公開IDは対象ごとのフォルダに固定し、別の対象へ流用できないようにする
+	ticket := &models.UploadTicket{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		PublicID:   req.TargetType + "s/" + req.TargetID + "/" + uuid.New().String(),
+		Status:     models.UploadStatusPending,
+		ExpiresAt:  time.Now().Add(ttl),
+	}
+
+	if err := s.ticketRepo.Create(ctx, ticket); err != nil {
+		return nil, err
+	}
+
+	return ticket, nil
+}
+
+// GetPendingTicket は完了待ちのアップロードチケットを取得する
+func (s *DefaultUploadService) GetPendingTicket(ctx context.Context, userID, publicID string) (*models.UploadTicket, error) {
+	ticket, err := s.ticketRepo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, errors.New("アップロードチケットが見つかりません")
+	}
+
+	if ticket.UserID != userID {
+		return nil, errors.New("このアップロードを完了する権限がありません")
+	}
+
+	if ticket.Status != models.UploadStatusPending {
+		return nil, errors.New("このアップロードは既に完了しています")
+	}
+
+	if time.Now().After(ticket.ExpiresAt) {
+		return nil, errors.New("アップロードの有効期限が切れています")
+	}
+
+	return ticket, nil
+}
+
+// CompleteTicket はアップロード済みの画像を対象のフロアまたはピンに設定する
+func (s *DefaultUploadService) CompleteTicket(ctx context.Context, ticket *models.UploadTicket, imageURL string) (interface{}, error) {
+	// 発行後に権限が変わっていないか再確認
+	if err := s.checkTargetOwner(ctx, ticket.UserID, ticket.TargetType, ticket.TargetID); err != nil {
+		return nil, err
+	}
+
+	var result interface{}
+	switch ticket.TargetType {
+	case models.UploadTargetFloor:
+		floor, err := s.floorRepo.GetByID(ctx, ticket.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		floor.ImageURL = imageURL
+		if err := s.floorRepo.Update(ctx, floor); err != nil {
+			return nil, err
+		}
+		result = floor
+	case models.UploadTargetPin:
+		pin, err := s.pinRepo.GetByID(ctx, ticket.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		pin.ImageURL = imageURL
+		if err := s.pinRepo.Update(ctx, pin); err != nil {
+			return nil, err
+		}
+		result = pin
+	default:
+		return nil, errors.New("無効なアップロード対象です")
+	}
+
+	if err := s.ticketRepo.UpdateStatus(ctx, ticket.ID, models.UploadStatusCompleted); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// checkTargetOwner はアップロード対象のマップ所有者であるか確認する
+func (s *DefaultUploadService) checkTargetOwner(ctx context.Context, userID, targetType, targetID string) error {
+	floorID := targetID
+
+	switch targetType {
+	case models.UploadTargetFloor:
+	case models.UploadTargetPin:
+		pin, err := s.pinRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if pin == nil {
+			return errors.New("ピンが見つかりません")
+		}
+		floorID = pin.FloorID
+	default:
+		return errors.New("無効なアップロード対象です")
+	}
+
+	floor, err := s.floorRepo.GetByID(ctx, floorID)
+	if err != nil {
+		return err
+	}
+	if floor == nil {
+		return errors.New("フロアが見つかりません")
+	}
+
+	map_, err := s.mapRepo.GetByID(ctx, floor.MapID)
+	if err != nil {
+		return err
+	}
+	if map_ == nil {
+		return errors.New("マップが見つかりません")
+	}
+
+	canEdit, err := canEditMap(ctx, s.mapRepo, map_, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return errors.New("このマップを編集する権限がありません")
+	}
+
+	return nil
+}
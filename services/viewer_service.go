@@ -9,16 +9,26 @@ import (
 	"github.com/shimaf4979/pamfree-backend/repositories"
 )
 
+// 閲覧できないフロア・ピンは存在しないものとして扱うためのエラー
+var (
+	ErrViewerFloorNotFound = errors.New("フロアが見つかりません")
+	ErrViewerPinNotFound   = errors.New("ピンが見つかりません")
+)
+
 // ViewerService はビューワー機能に関する操作を提供するインターフェース
 type ViewerService interface {
-	GetMapData(ctx context.Context, mapID string) (*models.ViewerData, error)
+	GetMapData(ctx context.Context, userID, mapID string) (*models.ViewerData, error)
+	GetFloorData(ctx context.Context, userID, floorID string) (*models.ViewerData, error)
+	GetPin(ctx context.Context, userID, pinID string) (*models.Pin, error)
 }
 
 // DefaultViewerService はViewerServiceの実装
 type DefaultViewerService struct {
-	mapRepo   repositories.MapRepository
-	floorRepo repositories.FloorRepository
-	pinRepo   repositories.PinRepository
+	mapRepo      repositories.MapRepository
+	floorRepo    repositories.FloorRepository
+	pinRepo      repositories.PinRepository
+	snapshotRepo repositories.MapSnapshotRepository
+	reportRepo   repositories.ContentReportRepository
 }
 
 // NewViewerService は新しいViewerServiceを作成する
@@ -26,33 +36,94 @@ func NewViewerService(
 	mapRepo repositories.MapRepository,
 	floorRepo repositories.FloorRepository,
 	pinRepo repositories.PinRepository,
+	snapshotRepo repositories.MapSnapshotRepository,
+	reportRepo repositories.ContentReportRepository,
 ) ViewerService {
 	return &DefaultViewerService{
-		mapRepo:   mapRepo,
-		floorRepo: floorRepo,
-		pinRepo:   pinRepo,
+		mapRepo:      mapRepo,
+		floorRepo:    floorRepo,
+		pinRepo:      pinRepo,
+		snapshotRepo: snapshotRepo,
+		reportRepo:   reportRepo,
 	}
 }
 
 // GetMapData はマップの全データを取得する
-func (s *DefaultViewerService) GetMapData(ctx context.Context, mapID string) (*models.ViewerData, error) {
+// 公開中でないマップはメンバー以外には存在しないものとして扱う
+// 公開スナップショットがある場合、メンバー以外には編集中のデータではなくスナップショットの内容を返す
+// メンバー以外には通報・内容フィルターにより非表示になったピンを返さない
+// ピンの編集者IDは誰にも返さない
+func (s *DefaultViewerService) GetMapData(ctx context.Context, userID, mapID string) (*models.ViewerData, error) {
+	viewerData, err := s.mapData(ctx, userID, mapID)
+	if err != nil {
+		return nil, err
+	}
+	viewerData.Pins = models.WithoutEditorIDs(viewerData.Pins)
+	return viewerData, nil
+}
+
+// mapData は閲覧者に応じたマップの全データを取得する
+func (s *DefaultViewerService) mapData(ctx context.Context, userID, mapID string) (*models.ViewerData, error) {
 	// マップデータを取得
 	mapData, err := s.mapRepo.GetByID(ctx, mapID)
 	if err != nil {
 		return nil, err
 	}
 	if mapData == nil {
-		return nil, errors.New("マップが見つかりません")
+		return nil, ErrMapNotFound
+	}
+
+	canView, err := canViewMap(ctx, s.mapRepo, mapData, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !canView {
+		return nil, ErrMapNotFound
+	}
+
+	isMember, err := canEditMap(ctx, s.mapRepo, mapData, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return s.workingCopy(ctx, mapData)
 	}
 
+	var viewerData *models.ViewerData
+	snapshot, err := s.snapshotRepo.GetPublished(ctx, mapData.ID)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		viewerData = snapshotViewerData(mapData, snapshot)
+	} else {
+		viewerData, err = s.workingCopy(ctx, mapData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	viewerData.Pins, err = filterHiddenPins(ctx, s.reportRepo, mapData.ID, viewerData.Pins)
+	if err != nil {
+		return nil, err
+	}
+
+	return viewerData, nil
+}
+
+// workingCopy は編集中のマップ・フロア・ピンを取得する
+func (s *DefaultViewerService) workingCopy(ctx context.Context, mapData *models.Map) (*models.ViewerData, error) {
 	// フロアデータを取得
 	floors, err := s.floorRepo.GetByMapID(ctx, mapData.ID)
 	if err != nil {
 		return nil, err
 	}
+	if floors == nil {
+		floors = []*models.Floor{}
+	}
 
 	// ピンデータを取得
-	var pins []*models.Pin
+	pins := []*models.Pin{}
 	if len(floors) > 0 {
 		// フロアIDのスライスを作成
 		floorIDs := make([]string, len(floors))
@@ -65,6 +136,9 @@ func (s *DefaultViewerService) GetMapData(ctx context.Context, mapID string) (*m
 		if err != nil {
 			return nil, err
 		}
+		if pins == nil {
+			pins = []*models.Pin{}
+		}
 	}
 
 	// ビューワーデータを構築
@@ -76,3 +150,95 @@ func (s *DefaultViewerService) GetMapData(ctx context.Context, mapID string) (*m
 
 	return viewerData, nil
 }
+
+// GetFloorData はフロアとフロアに属するピンを取得する
+// 返り値のFloorsには対象のフロアのみが含まれる
+func (s *DefaultViewerService) GetFloorData(ctx context.Context, userID, floorID string) (*models.ViewerData, error) {
+	floor, err := s.floorRepo.GetByID(ctx, floorID)
+	if err != nil {
+		return nil, err
+	}
+	if floor == nil {
+		return nil, ErrViewerFloorNotFound
+	}
+
+	data, err := s.GetMapData(ctx, userID, floor.MapID)
+	if err == ErrMapNotFound {
+		return nil, ErrViewerFloorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range data.Floors {
+		if f.ID != floorID {
+			continue
+		}
+
+		pins := []*models.Pin{}
+		for _, pin := range data.Pins {
+			if pin.FloorID == floorID {
+				pins = append(pins, pin)
+			}
+		}
+
+		return &models.ViewerData{
+			Map:    data.Map,
+			Floors: []*models.Floor{f},
+			Pins:   pins,
+		}, nil
+	}
+
+	return nil, ErrViewerFloorNotFound
+}
+
+// GetPin はIDによりピンを取得する
+func (s *DefaultViewerService) GetPin(ctx context.Context, userID, pinID string) (*models.Pin, error) {
+	pin, err := s.pinRepo.GetByID(ctx, pinID)
+	if err != nil {
+		return nil, err
+	}
+	if pin == nil {
+		return nil, ErrViewerPinNotFound
+	}
+
+	data, err := s.GetFloorData(ctx, userID, pin.FloorID)
+	if err == ErrViewerFloorNotFound {
+		return nil, ErrViewerPinNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range data.Pins {
+		if p.ID == pinID {
+			return p, nil
+		}
+	}
+
+	return nil, ErrViewerPinNotFound
+}
+
+// snapshotViewerData は公開スナップショットの内容をビューワーデータにする
+// 公開状態は常に最新のマップのものを使用する
+func snapshotViewerData(mapData *models.Map, snapshot *models.MapSnapshot) *models.ViewerData {
+	snapshotMap := *snapshot.Data.Map
+	snapshotMap.Status = mapData.Status
+	snapshotMap.PublishAt = mapData.PublishAt
+	snapshotMap.UnpublishAt = mapData.UnpublishAt
+
+	floors := snapshot.Data.Floors
+	if floors == nil {
+		floors = []*models.Floor{}
+	}
+	pins := snapshot.Data.Pins
+	if pins == nil {
+		pins = []*models.Pin{}
+	}
+
+	return &models.ViewerData{
+		Map:    &snapshotMap,
+		Floors: floors,
+		Pins:   pins,
+	}
+}
@@ -0,0 +1,108 @@
+// backend/utils/geo.go
+package utils
+
+import (
+	"errors"
+	"math"
+)
+
+// AffineTransform は2次元アフィン変換 [a, b, c, d, e, f] を表す
+// u = a*x + b*y + c, v = d*x + e*y + f
+type AffineTransform [6]float64
+
+// FitAffine は対応点から最小二乗法でアフィン変換を求める
+func FitAffine(src, dst [][2]float64) (AffineTransform, error) {
+	var t AffineTransform
+	if len(src) != len(dst) || len(src) < 3 {
+		return t, errors.New("対応点は3点以上必要です")
+	}
+
+	// 正規方程式 (A^T A) p = A^T b を組み立てる（AはN行 [x, y, 1]）
+	var ata [3][3]float64
+	var atu, atv [3]float64
+	for i := range src {
+		row := [3]float64{src[i][0], src[i][1], 1}
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				ata[j][k] += row[j] * row[k]
+			}
+			atu[j] += row[j] * dst[i][0]
+			atv[j] += row[j] * dst[i][1]
+		}
+	}
+
+	inv, ok := invert3x3(ata)
+	if !ok {
+		return t, errors.New("対応点が一直線上に並んでいるため変換を計算できません")
+	}
+
+	for j := 0; j < 3; j++ {
+		for k := 0; k < 3; k++ {
+			t[j] += inv[j][k] * atu[k]
+			t[3+j] += inv[j][k] * atv[k]
+		}
+	}
+
+	return t, nil
+}
+
+// Apply は座標に変換を適用する
+func (t AffineTransform) Apply(x, y float64) (float64, float64) {
+	return t[0]*x + t[1]*y + t[2], t[3]*x + t[4]*y + t[5]
+}
+
+// Inverse は逆変換を返す
+func (t AffineTransform) Inverse() (AffineTransform, error) {
+	var inv AffineTransform
+	det := t[0]*t[4] - t[1]*t[3]
+	if math.Abs(det) < 1e-18 {
+		return inv, errors.New("変換が逆変換を持ちません")
+	}
+
+	inv[0] = t[4] / det
+	inv[1] = -t[1] / det
+	inv[3] = -t[3] / det
+	inv[4] = t[0] / det
+	inv[2] = -(inv[0]*t[2] + inv[1]*t[5])
+	inv[5] = -(inv[3]*t[2] + inv[4]*t[5])
+
+	return inv, nil
+}
+
+// RMSError は対応点に対する変換の二乗平均平方根誤差を返す
+func (t AffineTransform) RMSError(src, dst [][2]float64) float64 {
+	if len(src) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := range src {
+		u, v := t.Apply(src[i][0], src[i][1])
+		sum += (u-dst[i][0])*(u-dst[i][0]) + (v-dst[i][1])*(v-dst[i][1])
+	}
+
+	return math.Sqrt(sum / float64(len(src)))
+}
+
+// invert3x3 は3x3行列の逆行列を求める
+func invert3x3(m [3][3]float64) ([3][3]float64, bool) {
+	var inv [3][3]float64
+	det := m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+	if math.Abs(det) < 1e-12 {
+		return inv, false
+	}
+
+	inv[0][0] = (m[1][1]*m[2][2] - m[1][2]*m[2][1]) / det
+	inv[0][1] = (m[0][2]*m[2][1] - m[0][1]*m[2][2]) / det
+	inv[0][2] = (m[0][1]*m[1][2] - m[0][2]*m[1][1]) / det
+	inv[1][0] = (m[1][2]*m[2][0] - m[1][0]*m[2][2]) / det
+	inv[1][1] = (m[0][0]*m[2][2] - m[0][2]*m[2][0]) / det
+	inv[1][2] = (m[0][2]*m[1][0] - m[0][0]*m[1][2]) / det
+	inv[2][0] = (m[1][0]*m[2][1] - m[1][1]*m[2][0]) / det
+	inv[2][1] = (m[0][1]*m[2][0] - m[0][0]*m[2][1]) / det
+	inv[2][2] = (m[0][0]*m[1][1] - m[0][1]*m[1][0]) / det
+
+	return inv, true
+}
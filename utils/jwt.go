@@ -13,15 +13,34 @@ type JWTClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// TwoFactor は二段階認証を完了してログインしたかどうか
+	TwoFactor bool `json:"two_factor,omitempty"`
 	jwt.StandardClaims
 }
 
+// loginChallengeAudience は二段階認証の確認待ちを表すトークンの用途
+const loginChallengeAudience = "login_challenge"
+
+// LoginChallengeTTL は二段階認証の確認待ちトークンの有効期間
+const LoginChallengeTTL = 5 * time.Minute
+
 // GenerateToken はJWTトークンを生成する
 func GenerateToken(userID, email, role, jwtSecret string) (string, error) {
+	return generateToken(userID, email, role, false, jwtSecret)
+}
+
+// GenerateTwoFactorToken は二段階認証を完了したことを示すJWTトークンを生成する
+func GenerateTwoFactorToken(userID, email, role, jwtSecret string) (string, error) {
+	return generateToken(userID, email, role, true, jwtSecret)
+}
+
+// generateToken はJWTトークンを生成する
+func generateToken(userID, email, role string, twoFactor bool, jwtSecret string) (string, error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		TwoFactor: twoFactor,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(), // 1日有効
 			IssuedAt:  time.Now().Unix(),
@@ -37,6 +56,42 @@ func GenerateToken(userID, email, role, jwtSecret string) (string, error) {
 	return signedToken, nil
 }
 
+// GenerateLoginChallenge はパスワードの確認後、二段階認証の確認まで使う短期間のトークンを生成する
+// このトークンは通常の認証には使えない
+func GenerateLoginChallenge(userID, jwtSecret string) (string, error) {
+	claims := jwt.StandardClaims{
+		Subject:   userID,
+		Audience:  loginChallengeAudience,
+		ExpiresAt: time.Now().Add(LoginChallengeTTL).Unix(),
+		IssuedAt:  time.Now().Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(jwtSecret))
+}
+
+// ValidateLoginChallenge は二段階認証の確認待ちトークンを検証し、ユーザーIDを返す
+func ValidateLoginChallenge(tokenString, jwtSecret string) (string, error) {
+	claims := &jwt.StandardClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("署名方式が不正です")
+		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid || !claims.VerifyAudience(loginChallengeAudience, true) || claims.Subject == "" {
+		return "", errors.New("トークンが無効です")
+	}
+
+	return claims.Subject, nil
+}
+
 // ValidateToken はJWTトークンを検証する
 func ValidateToken(tokenString, jwtSecret string) (*JWTClaims, error) {
 	claims := &JWTClaims{}
@@ -49,7 +104,8 @@ func ValidateToken(tokenString, jwtSecret string) (*JWTClaims, error) {
 		return nil, err
 	}
 
-	if !token.Valid {
+	// 二段階認証の確認待ちトークンは通常の認証に使えない
+	if !token.Valid || claims.Audience == loginChallengeAudience {
 		return nil, errors.New("トークンが無効です")
 	}
 
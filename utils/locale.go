@@ -0,0 +1,58 @@
+// backend/utils/locale.go
+package utils
+
+import (
+	"golang.org/x/text/language"
+)
+
+// LocaleMatcher は対応言語の中から閲覧者に最適な言語を選択する
+type LocaleMatcher struct {
+	locales []string
+	matcher language.Matcher
+}
+
+// NewLocaleMatcher は新しいLocaleMatcherを作成する
+// localesの先頭はデフォルト言語とする
+func NewLocaleMatcher(locales []string) *LocaleMatcher {
+	tags := make([]language.Tag, len(locales))
+	for i, locale := range locales {
+		tags[i] = language.Make(locale)
+	}
+
+	return &LocaleMatcher{
+		locales: locales,
+		matcher: language.NewMatcher(tags),
+	}
+}
+
+// Match は?lang=の指定を優先し、なければAccept-Languageから言語を選択する
+// どちらにも対応言語がない場合はデフォルト言語を返す
+func (m *LocaleMatcher) Match(lang, acceptLanguage string) string {
+	if lang != "" {
+		if tag, err := language.Parse(lang); err == nil {
+			if _, index, confidence := m.matcher.Match(tag); confidence != language.No {
+				return m.locales[index]
+			}
+		}
+	}
+
+	if acceptLanguage != "" {
+		if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(tags) > 0 {
+			if _, index, confidence := m.matcher.Match(tags...); confidence != language.No {
+				return m.locales[index]
+			}
+		}
+	}
+
+	return m.locales[0]
+}
+
+// Supports は対応言語か判定する
+func (m *LocaleMatcher) Supports(locale string) bool {
+	for _, l := range m.locales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
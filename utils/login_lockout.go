@@ -0,0 +1,108 @@
+// backend/utils/login_lockout.go
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// 失敗の記録を保持する期間と、ロックする時間の上限
+const (
+	loginFailureTTL = 24 * time.Hour
+	maxLoginLockout = time.Hour
+)
+
+// loginFailures はキーごとのログイン失敗の記録
+type loginFailures struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// LoginLockout はログインの失敗が続いたキーを段階的に長くロックする
+// maxFailures回失敗するとbaseの間ロックし、以降は失敗するたびにロックする時間を2倍にする
+// 失敗の記録はプロセス内にのみ保持するため、RATE_LIMIT_BACKEND=mysqlでも複数のサーバー間では共有されない
+// （サーバーをまたいだ総当たりはログインの回数制限で抑える）
+type LoginLockout struct {
+	maxFailures int
+	base        time.Duration
+	mu          sync.Mutex
+	failures    map[string]*loginFailures
+	swept       time.Time
+}
+
+// NewLoginLockout は新しいLoginLockoutを作成する
+func NewLoginLockout(maxFailures int, base time.Duration) *LoginLockout {
+	return &LoginLockout{
+		maxFailures: maxFailures,
+		base:        base,
+		failures:    make(map[string]*loginFailures),
+		swept:       time.Now(),
+	}
+}
+
+// Locked はキーがロックされているか判定し、ロックが解除されるまでの時間を返す
+func (l *LoginLockout) Locked(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry, ok := l.failures[key]
+	if !ok {
+		return 0
+	}
+
+	if remaining := time.Until(entry.lockedUntil); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// Fail はログインの失敗を記録する
+// ロックした場合はロックが解除されるまでの時間を返す
+func (l *LoginLockout) Fail(key string) time.Duration {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 古い記録を定期的に削除する
+	if now.Sub(l.swept) > time.Hour {
+		for k, entry := range l.failures {
+			if now.Sub(entry.lastFailure) > loginFailureTTL && !now.Before(entry.lockedUntil) {
+				delete(l.failures, k)
+			}
+		}
+		l.swept = now
+	}
+
+	entry, ok := l.failures[key]
+	if !ok || now.Sub(entry.lastFailure) > loginFailureTTL {
+		entry = &loginFailures{}
+		l.failures[key] = entry
+	}
+	entry.count++
+	entry.lastFailure = now
+
+	if l.maxFailures <= 0 || entry.count < l.maxFailures {
+		return 0
+	}
+
+	lockout := l.base
+	for i := l.maxFailures; i < entry.count && lockout < maxLoginLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > maxLoginLockout {
+		lockout = maxLoginLockout
+	}
+
+	entry.lockedUntil = now.Add(lockout)
+	return lockout
+}
+
+// Reset はログインに成功したキーの失敗の記録を消去する
+func (l *LoginLockout) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.failures, key)
+}
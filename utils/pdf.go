@@ -0,0 +1,195 @@
+// backend/utils/pdf.go
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // 画像サイズ取得用デコーダー
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// PamphletFloor はPDFに描画するフロア1ページ分の情報
+type PamphletFloor struct {
+	Name    string
+	Image   []byte // 画像がない場合はnil
+	Markers []PamphletMarker
+}
+
+// PamphletMarker はフロア画像上の番号付きマーカー
+type PamphletMarker struct {
+	Number int
+	X      float64 // 画像ピクセル座標
+	Y      float64
+}
+
+// PamphletEntry は索引ページの1項目
+type PamphletEntry struct {
+	Number      int
+	Title       string
+	Description string
+	FloorName   string
+}
+
+// 描画に使用するフォント名
+const pamphletFont = "pamphlet"
+
+// RenderPamphlet はフロア画像と索引ページからなるA4のパンフレットPDFを出力する
+// 日本語を表示するため、fontPathにはUTF-8対応のTrueTypeフォントを指定する
+func RenderPamphlet(w io.Writer, fontPath, title string, floors []PamphletFloor, entries []PamphletEntry) error {
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(true, 10)
+	pdf.AddUTF8FontFromBytes(pamphletFont, "", font)
+	if pdf.Err() {
+		return pdf.Error()
+	}
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 20
+
+	// フロアページ
+	for i, floor := range floors {
+		pdf.AddPage()
+		pdf.SetFont(pamphletFont, "", 16)
+		pdf.CellFormat(contentWidth, 10, title+" - "+floor.Name, "", 1, "L", false, 0, "")
+
+		top := pdf.GetY() + 2
+		maxHeight := pageHeight - top - 10
+
+		if floor.Image == nil {
+			pdf.SetFont(pamphletFont, "", 12)
+			pdf.Rect(10, top, contentWidth, maxHeight/2, "D")
+			pdf.SetXY(10, top+maxHeight/4-5)
+			pdf.CellFormat(contentWidth, 10, "画像がありません", "", 0, "C", false, 0, "")
+			continue
+		}
+
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(floor.Image))
+		if err != nil || cfg.Width == 0 || cfg.Height == 0 {
+			return errors.New(floor.Name + "の画像を読み込めません")
+		}
+
+		// ページに収まるよう縦横比を保って縮小
+		scale := contentWidth / float64(cfg.Width)
+		if float64(cfg.Height)*scale > maxHeight {
+			scale = maxHeight / float64(cfg.Height)
+		}
+		drawWidth := float64(cfg.Width) * scale
+		drawHeight := float64(cfg.Height) * scale
+		left := 10 + (contentWidth-drawWidth)/2
+
+		imageName := "floor-" + strconv.Itoa(i)
+		options := fpdf.ImageOptions{ImageType: strings.ToUpper(format)}
+		pdf.RegisterImageOptionsReader(imageName, options, bytes.NewReader(floor.Image))
+		pdf.ImageOptions(imageName, left, top, drawWidth, drawHeight, false, options, 0, "")
+
+		// 番号付きマーカー
+		pdf.SetFont(pamphletFont, "", 7)
+		pdf.SetFillColor(220, 38, 38)
+		pdf.SetDrawColor(255, 255, 255)
+		pdf.SetTextColor(255, 255, 255)
+		for _, marker := range floor.Markers {
+			x := left + marker.X*scale
+			y := top + marker.Y*scale
+			pdf.Circle(x, y, 2.5, "FD")
+			pdf.SetXY(x-2.5, y-2.5)
+			pdf.CellFormat(5, 5, strconv.Itoa(marker.Number), "", 0, "C", false, 0, "")
+		}
+		pdf.SetDrawColor(0, 0, 0)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	// 索引ページ
+	pdf.AddPage()
+	pdf.SetFont(pamphletFont, "", 16)
+	pdf.CellFormat(contentWidth, 10, "ピン一覧", "", 1, "L", false, 0, "")
+	pdf.Ln(2)
+
+	for _, entry := range entries {
+		pdf.SetFont(pamphletFont, "", 11)
+		heading := strconv.Itoa(entry.Number) + ". " + entry.Title
+		if entry.FloorName != "" {
+			heading += "（" + entry.FloorName + "）"
+		}
+		pdf.MultiCell(contentWidth, 6, heading, "", "L", false)
+
+		if entry.Description != "" {
+			pdf.SetFont(pamphletFont, "", 9)
+			pdf.SetX(15)
+			pdf.MultiCell(contentWidth-5, 5, entry.Description, "", "L", false)
+		}
+		pdf.Ln(2)
+	}
+
+	if pdf.Err() {
+		return pdf.Error()
+	}
+
+	return pdf.Output(w)
+}
+
+// QRLabel はQRコードシートに印刷する1枚分の情報
+type QRLabel struct {
+	Label string
+	PNG   []byte
+}
+
+// RenderQRSheet はラベル付きQRコードをA4に並べた印刷用PDFを出力する
+func RenderQRSheet(w io.Writer, fontPath, title string, labels []QRLabel) error {
+	font, err := os.ReadFile(fontPath)
+	if err != nil {
+		return err
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(false, 10)
+	pdf.AddUTF8FontFromBytes(pamphletFont, "", font)
+	if pdf.Err() {
+		return pdf.Error()
+	}
+
+	// 3列×4行で配置
+	const columns, rows = 3, 4
+	const cellWidth, cellHeight, codeSize = 63.0, 66.0, 45.0
+
+	for i, label := range labels {
+		if i%(columns*rows) == 0 {
+			pdf.AddPage()
+			pdf.SetFont(pamphletFont, "", 12)
+			pdf.CellFormat(190, 8, title, "", 1, "L", false, 0, "")
+		}
+
+		slot := i % (columns * rows)
+		left := 10 + float64(slot%columns)*cellWidth
+		top := 20 + float64(slot/columns)*cellHeight
+
+		imageName := "qr-" + strconv.Itoa(i)
+		options := fpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(imageName, options, bytes.NewReader(label.PNG))
+		pdf.ImageOptions(imageName, left+(cellWidth-codeSize)/2, top, codeSize, codeSize, false, options, 0, "")
+
+		pdf.SetFont(pamphletFont, "", 9)
+		pdf.SetXY(left+2, top+codeSize+1)
+		pdf.MultiCell(cellWidth-4, 4, label.Label, "", "C", false)
+	}
+
+	if pdf.Err() {
+		return pdf.Error()
+	}
+
+	return pdf.Output(w)
+}
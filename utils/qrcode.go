@@ -0,0 +1,59 @@
+// backend/utils/qrcode.go
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ParseQRLevel は誤り訂正レベル(L/M/Q/H)を解析する
+func ParseQRLevel(level string) (qrcode.RecoveryLevel, error) {
+	switch strings.ToUpper(level) {
+	case "L":
+		return qrcode.Low, nil
+	case "", "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+	return qrcode.Medium, errors.New("誤り訂正レベルはL/M/Q/Hのいずれかを指定してください")
+}
+
+// QRCodePNG はQRコードをPNG画像として生成する
+func QRCodePNG(content string, size int, level qrcode.RecoveryLevel) ([]byte, error) {
+	return qrcode.Encode(content, level, size)
+}
+
+// QRCodeSVG はQRコードをSVG画像として生成する
+func QRCodeSVG(content string, size int, level qrcode.RecoveryLevel) ([]byte, error) {
+	q, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+
+	bitmap := q.Bitmap()
+	modules := strconv.Itoa(len(bitmap))
+
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	buf.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="` + strconv.Itoa(size) + `" height="` + strconv.Itoa(size) +
+		`" viewBox="0 0 ` + modules + ` ` + modules + `" shape-rendering="crispEdges">` + "\n")
+	buf.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>` + "\n")
+	buf.WriteString(`<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				buf.WriteString("M" + strconv.Itoa(x) + " " + strconv.Itoa(y) + "h1v1h-1z")
+			}
+		}
+	}
+	buf.WriteString(`"/>` + "\n</svg>\n")
+
+	return buf.Bytes(), nil
+}
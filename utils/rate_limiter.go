@@ -0,0 +1,61 @@
+// backend/utils/rate_limiter.go
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter はキーごとに一定時間内の回数を制限する
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+	mu     sync.Mutex
+	hits   map[string][]time.Time
+	swept  time.Time
+}
+
+// NewRateLimiter は新しいRateLimiterを作成する
+// windowの間にlimit回まで許可する
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+// Allow はキーの操作を許可するか判定し、許可した場合は回数に数える
+// 拒否した場合は次に許可されるまでの時間を返す
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// 古い記録を定期的に削除する
+	if now.Sub(l.swept) > l.window {
+		for k, hits := range l.hits {
+			if len(hits) == 0 || !hits[len(hits)-1].After(cutoff) {
+				delete(l.hits, k)
+			}
+		}
+		l.swept = now
+	}
+
+	hits := l.hits[key]
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	hits = hits[i:]
+
+	if len(hits) >= l.limit {
+		l.hits[key] = hits
+		return false, hits[0].Sub(cutoff)
+	}
+
+	l.hits[key] = append(hits, now)
+	return true, 0
+}
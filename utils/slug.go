@@ -0,0 +1,47 @@
+// backend/utils/slug.go
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// スラッグの長さの範囲
+const (
+	MinSlugLength = 3
+	MaxSlugLength = 50
+)
+
+// slugPattern は英小文字・数字・ハイフンのみ、先頭と末尾は英数字
+var slugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+
+// reservedSlugs はURLやシステムと衝突するため使用できないスラッグ
+var reservedSlugs = map[string]bool{
+	"about": true, "account": true, "admin": true, "api": true, "app": true,
+	"assets": true, "auth": true, "dashboard": true, "edit": true, "editor": true,
+	"floors": true, "health": true, "help": true, "login": true, "logout": true,
+	"map": true, "maps": true, "new": true, "pins": true, "privacy": true,
+	"public": true, "register": true, "s": true, "settings": true, "share": true,
+	"signup": true, "static": true, "support": true, "terms": true, "viewer": true,
+	"www": true, "pamfree": true,
+}
+
+// NormalizeSlug はスラッグを小文字化し、前後の空白を取り除く
+func NormalizeSlug(slug string) string {
+	return strings.ToLower(strings.TrimSpace(slug))
+}
+
+// ValidateSlug はスラッグの形式と予約語を検証する
+func ValidateSlug(slug string) error {
+	if len(slug) < MinSlugLength || len(slug) > MaxSlugLength {
+		return errors.New("スラッグは3文字以上50文字以内で指定してください")
+	}
+	if !slugPattern.MatchString(slug) || strings.Contains(slug, "--") {
+		return errors.New("スラッグには英小文字・数字・ハイフンのみ使用でき、先頭と末尾は英数字にしてください")
+	}
+	if reservedSlugs[slug] {
+		return errors.New("このスラッグは予約されているため使用できません")
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// backend/utils/spreadsheet.go
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM はUTF-8のバイトオーダーマーク
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// formulaPrefixes は表計算ソフトが数式として解釈するセルの先頭の文字
+const formulaPrefixes = "=+-@\t\r"
+
+// ReadCSV はCSVを読み込む（UTF-8 BOM付き/なし、Shift_JISに対応）
+func ReadCSV(data []byte) ([][]string, error) {
+	var reader io.Reader
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		reader = bytes.NewReader(data[len(utf8BOM):])
+	case utf8.Valid(data):
+		reader = bytes.NewReader(data)
+	default:
+		// UTF-8として不正な場合はExcelが出力するShift_JISとみなす
+		reader = transform.NewReader(bytes.NewReader(data), japanese.ShiftJIS.NewDecoder())
+	}
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	return r.ReadAll()
+}
+
+// ReadXLSX はXLSXの最初のシートを読み込む
+func ReadXLSX(data []byte) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("シートが見つかりません")
+	}
+
+	return f.GetRows(sheets[0])
+}
+
+// WriteCSV はExcelで文字化けしないようUTF-8 BOM付きのCSVを出力する
+func WriteCSV(rows [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(utf8BOM)
+
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EscapeFormula は表計算ソフトで数式として実行されないよう、数式と解釈される文字で始まる値の先頭に'を付ける
+func EscapeFormula(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeFormula はEscapeFormulaで付けた先頭の'を外す
+func UnescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// WriteXLSX は1シートのXLSXを出力する
+func WriteXLSX(sheetName string, rows [][]string) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		return nil, err
+	}
+
+	for i, row := range rows {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			values[j] = value
+		}
+		if err := f.SetSheetRow(sheetName, cell, &values); err != nil {
+			return nil, err
+		}
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
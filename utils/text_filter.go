@@ -0,0 +1,51 @@
+// backend/utils/text_filter.go
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// smallKana は小書きのカタカナを通常の大きさに対応させる
+var smallKana = map[rune]rune{
+	'ァ': 'ア', 'ィ': 'イ', 'ゥ': 'ウ', 'ェ': 'エ', 'ォ': 'オ',
+	'ッ': 'ツ', 'ャ': 'ヤ', 'ュ': 'ユ', 'ョ': 'ヨ', 'ヮ': 'ワ',
+	'ヵ': 'カ', 'ヶ': 'ケ',
+}
+
+// urlPattern はURLやドメイン名らしい文字列に一致する
+var urlPattern = regexp.MustCompile(`(?i)(https?://|www\.|[a-z0-9-]+\.(com|net|org|info|biz|jp|io|co|me|ly|xyz|app|dev|site|link)\b)`)
+
+// NormalizeText は禁止語の照合のために文字列を正規化する
+// 全角・半角の違い、ひらがな・カタカナの違い、小書きの仮名、大文字・小文字を同一視し、
+// 空白・記号を取り除く
+func NormalizeText(s string) string {
+	// 全角英数字を半角に、半角カナを全角にそろえる
+	s = strings.ToLower(norm.NFKC.String(s))
+
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= 'ぁ' && r <= 'ゖ':
+			// ひらがなをカタカナにそろえる
+			r += 'ァ' - 'ぁ'
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+			continue
+		}
+		if large, ok := smallKana[r]; ok {
+			r = large
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// ContainsURL は文字列にURLやドメイン名が含まれるか判定する
+// 全角で書かれたURLも検出する
+func ContainsURL(s string) bool {
+	return urlPattern.MatchString(norm.NFKC.String(s))
+}
@@ -0,0 +1,96 @@
+// backend/utils/token_bucket.go
+package utils
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Rate はトークンバケットの容量と補充の間隔を表す
+// Burst回まで連続で許可し、Everyごとに1回分ずつ回復する
+type Rate struct {
+	Burst int
+	Every time.Duration
+}
+
+// Refill は経過時間に応じてトークンを補充し、1回分を消費できるか判定する
+// 消費した場合は残りのトークン数を、できない場合は次に消費できるまでの時間を返す
+func (r Rate) Refill(tokens float64, elapsed time.Duration) (float64, bool, time.Duration) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(r.Burst), tokens+float64(elapsed)/float64(r.Every))
+	}
+	if tokens >= 1 {
+		return tokens - 1, true, 0
+	}
+	return tokens, false, time.Duration((1 - tokens) * float64(r.Every))
+}
+
+// RateLimitStore はキーごとのトークンバケットを保持する
+// 複数のサーバーで回数を共有する場合は共有のストアを使う
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rate Rate) (bool, time.Duration, error)
+}
+
+// tokenBucket はメモリ上のトークンバケット
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryRateLimitStore はメモリ上にトークンバケットを保持するRateLimitStore
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	swept   time.Time
+}
+
+// NewMemoryRateLimitStore は新しいMemoryRateLimitStoreを作成する
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		swept:   time.Now(),
+	}
+}
+
+// Take はキーのバケットからトークンを1回分消費する
+// 消費できない場合は次に消費できるまでの時間を返す
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, rate Rate) (bool, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 満杯まで回復したバケットを定期的に削除する
+	if now.Sub(s.swept) > time.Minute {
+		for k, bucket := range s.buckets {
+			if now.Sub(bucket.updated) > time.Hour {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(rate.Burst), updated: now}
+		s.buckets[key] = bucket
+	}
+
+	tokens, allowed, retryAfter := rate.Refill(bucket.tokens, now.Sub(bucket.updated))
+	bucket.tokens = tokens
+	bucket.updated = now
+
+	return allowed, retryAfter, nil
+}
+
+// RetryAfterSeconds はRetry-Afterヘッダーに設定する秒数（切り上げ）を返す
+func RetryAfterSeconds(d time.Duration) string {
+	seconds := int(math.Ceil(d.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}